@@ -0,0 +1,35 @@
+// Package api описание REST API в формате OpenAPI 3 (openapi.json).
+// Спецификация отдаётся по GET /api/openapi.json; по ней написан клиент pkg/client.
+// Новый или изменённый маршрут в internal/app правится одновременно в openapi.json
+// и в pkg/client — при запуске сервер пишет в лог маршруты, которых нет в спецификации.
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Spec спецификация OpenAPI в JSON
+//
+//go:embed openapi.json
+var Spec []byte
+
+// Operations операции спецификации в виде "GET /api/users/{id}"
+func Operations() (map[string]struct{}, error) {
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(Spec, &doc); err != nil {
+		return nil, fmt.Errorf("invalid openapi.json: %w", err)
+	}
+
+	ops := make(map[string]struct{})
+	for path, methods := range doc.Paths {
+		for method := range methods {
+			ops[strings.ToUpper(method)+" "+path] = struct{}{}
+		}
+	}
+	return ops, nil
+}
@@ -1,12 +1,18 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/WhoYa/subscription-manager/internal/app"
 	"github.com/WhoYa/subscription-manager/internal/util/healthcheck"
+
+	// образ собирается FROM scratch без zoneinfo, а REMINDER_TZ нужен часовой пояс
+	_ "time/tzdata"
 )
 
 var healthCheck = flag.Bool("health", false, "run health check and exit")
@@ -21,7 +27,19 @@ func main() {
 		os.Exit(0)
 	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	a := app.New()
+	a.Jobs.Start(ctx)
+
+	go func() {
+		<-ctx.Done()
+		if err := a.Shutdown(); err != nil {
+			log.Printf("Server shutdown error: %v", err)
+		}
+	}()
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
@@ -30,4 +48,5 @@ func main() {
 		log.Fatalf("Server failed: %v", err)
 	}
 
+	a.Jobs.Wait()
 }
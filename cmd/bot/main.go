@@ -1,12 +1,18 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/WhoYa/subscription-manager/internal/bot"
+	"github.com/WhoYa/subscription-manager/internal/bot/state"
+	"github.com/WhoYa/subscription-manager/internal/bot/types"
 )
 
 func main() {
@@ -22,6 +28,12 @@ func main() {
 		apiBaseURL = "http://localhost:8080"
 	}
 
+	// Ключ доступа к API: тот же API_KEY, что задан для API-сервера
+	apiKey := os.Getenv("API_KEY")
+	if apiKey == "" {
+		log.Fatal("API_KEY environment variable is required")
+	}
+
 	// Получаем список админов из переменной окружения
 	adminsEnv := os.Getenv("ADMINS")
 	if adminsEnv == "" {
@@ -50,14 +62,68 @@ func main() {
 	log.Printf("Configured %d admin users", len(adminUserIDs))
 	log.Printf("API Base URL: %s", apiBaseURL)
 
+	// Незаконченные диалоги хранятся в файле BOT_STATE_FILE, без него — только в памяти
+	stateTTL := state.DefaultTTL
+	if v := os.Getenv("BOT_STATE_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil || ttl <= 0 {
+			log.Fatalf("Invalid BOT_STATE_TTL '%s': use a duration like 24h", v)
+		}
+		stateTTL = ttl
+	}
+	var states types.StateStore
+	if path := os.Getenv("BOT_STATE_FILE"); path != "" {
+		fileStore, err := state.NewFileStore(path, stateTTL)
+		if err != nil {
+			log.Fatalf("Failed to open bot state file: %v", err)
+		}
+		states = fileStore
+		log.Printf("Dialog states are stored in %s (TTL %s)", path, stateTTL)
+	} else {
+		states = state.NewMemoryStore(stateTTL)
+		log.Printf("BOT_STATE_FILE is not set: dialog states are kept in memory and lost on restart")
+	}
+
 	// Создаем и запускаем бота
-	botInstance, err := bot.NewBot(token, apiBaseURL, adminUserIDs)
+	botInstance, err := bot.NewBot(token, apiBaseURL, apiKey, adminUserIDs, states)
 	if err != nil {
 		log.Fatalf("Failed to create bot: %v", err)
 	}
 
+	if v := os.Getenv("BOT_WORKERS"); v != "" {
+		workers, err := strconv.Atoi(v)
+		if err != nil || workers <= 0 {
+			log.Fatalf("Invalid BOT_WORKERS '%s': must be a positive number", v)
+		}
+		botInstance.Workers = workers
+	}
+
+	// Режим получения обновлений: polling (по умолчанию) или webhook
+	switch mode := os.Getenv("BOT_MODE"); mode {
+	case "", "polling":
+	case "webhook":
+		listen := os.Getenv("WEBHOOK_LISTEN")
+		if listen == "" {
+			listen = ":8081"
+		}
+		botInstance.Webhook = &bot.WebhookConfig{
+			Listen: listen,
+			URL:    os.Getenv("WEBHOOK_URL"),
+			Secret: os.Getenv("WEBHOOK_SECRET"),
+		}
+		if err := botInstance.Webhook.Validate(); err != nil {
+			log.Fatalf("Invalid webhook configuration: %v", err)
+		}
+	default:
+		log.Fatalf("Invalid BOT_MODE '%s': use polling or webhook", mode)
+	}
+
+	// SIGTERM от docker stop: бот дообрабатывает полученные обновления и выходит
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	log.Println("Starting Telegram bot...")
-	if err := botInstance.Start(); err != nil {
+	if err := botInstance.Start(ctx); err != nil {
 		log.Fatalf("Bot failed: %v", err)
 	}
 }
@@ -2,14 +2,33 @@ package app
 
 import (
 	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-gormigrate/gormigrate/v2"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
 
+	apispec "github.com/WhoYa/subscription-manager/api"
 	"github.com/WhoYa/subscription-manager/internal/handlers"
+	"github.com/WhoYa/subscription-manager/internal/jobs"
+	"github.com/WhoYa/subscription-manager/internal/notify"
+	"github.com/WhoYa/subscription-manager/internal/rates"
+	keyRepo "github.com/WhoYa/subscription-manager/internal/repository/apikey"
+	auditRepo "github.com/WhoYa/subscription-manager/internal/repository/audit"
+	curRepo "github.com/WhoYa/subscription-manager/internal/repository/currency"
 	crRepo "github.com/WhoYa/subscription-manager/internal/repository/currencyrate"
 	gsRepo "github.com/WhoYa/subscription-manager/internal/repository/globalsettings"
+	idemRepo "github.com/WhoYa/subscription-manager/internal/repository/idempotency"
+	invRepo "github.com/WhoYa/subscription-manager/internal/repository/invoice"
+	ledgerRepo "github.com/WhoYa/subscription-manager/internal/repository/ledger"
+	claimRepo "github.com/WhoYa/subscription-manager/internal/repository/paymentclaim"
 	payRepo "github.com/WhoYa/subscription-manager/internal/repository/paymentlog"
+	prRepo "github.com/WhoYa/subscription-manager/internal/repository/pricingrule"
+	remRepo "github.com/WhoYa/subscription-manager/internal/repository/reminder"
 	subRepo "github.com/WhoYa/subscription-manager/internal/repository/subscription"
 	userRepo "github.com/WhoYa/subscription-manager/internal/repository/user"
 	usRepo "github.com/WhoYa/subscription-manager/internal/repository/usersubscription"
@@ -20,7 +39,39 @@ import (
 	"github.com/WhoYa/subscription-manager/pkg/db/migrations"
 )
 
-func New() *fiber.App {
+// billingInterval как часто планировщик проверяет предстоящие списания
+const billingInterval = time.Hour
+
+// remindersInterval как часто проверяются напоминания; должен быть меньше часа,
+// чтобы напоминание ушло вскоре после окончания тихих часов
+const remindersInterval = 15 * time.Minute
+
+// defaultRatesInterval как часто обновляются курсы, если RATES_INTERVAL не задан
+const defaultRatesInterval = time.Hour
+
+// trashInterval как часто корзина очищается от записей старше TRASH_RETENTION_DAYS
+const trashInterval = 24 * time.Hour
+
+// defaultTrashRetentionDays сколько дней удалённые записи хранятся в корзине, если TRASH_RETENTION_DAYS не задан
+const defaultTrashRetentionDays = 30
+
+// idempotencyTTL сколько хранится ответ на запрос с Idempotency-Key
+const idempotencyTTL = 24 * time.Hour
+
+// expiredKeysRetention сколько истёкший ключ доступа ещё виден в списке ключей до удаления
+const expiredKeysRetention = 7 * 24 * time.Hour
+
+// idempotencyLease сколько ключ держится за незавершённым запросом; потом повтор
+// считает, что запрос прервался, и выполняет его заново
+const idempotencyLease = time.Minute
+
+// App HTTP-приложение вместе с фоновыми задачами
+type App struct {
+	*fiber.App
+	Jobs *jobs.Runner
+}
+
+func New() *App {
 
 	// DB + Migrations ---------------------------------------------------------
 	gormDB, err := db.Open()
@@ -30,6 +81,23 @@ func New() *fiber.App {
 	m := gormigrate.New(gormDB, gormigrate.DefaultOptions, []*gormigrate.Migration{
 		migrations.InitialMigration(),
 		migrations.AddAllTables(),
+		migrations.BillingCycles(),
+		migrations.InvoiceLifecycle(),
+		migrations.Ledger(),
+		migrations.CurrencyRateHistory(),
+		migrations.MultiCurrency(),
+		migrations.CurrencyRegistry(),
+		migrations.DecimalMoney(),
+		migrations.PricingRules(),
+		migrations.FamilySplit(),
+		migrations.MembershipProration(),
+		migrations.APIKeys(),
+		migrations.Reminders(),
+		migrations.PaymentClaims(),
+		migrations.AuditLog(),
+		migrations.Trash(),
+		migrations.IdempotencyKeys(),
+		migrations.LedgerCurrency(),
 	})
 	if err := m.Migrate(); err != nil {
 		log.Fatalf("Could not migrate: %v", err)
@@ -43,99 +111,442 @@ func New() *fiber.App {
 	pRepo := payRepo.NewPaymentLogRepo(gormDB)
 	gsRepo := gsRepo.NewGlobalSettingsRepository(gormDB)
 	crRepo := crRepo.NewCurrencyRateRepo(gormDB)
+	curRepo := curRepo.NewCurrencyRepo(gormDB)
+	iRepo := invRepo.NewInvoiceRepo(gormDB)
+	lRepo := ledgerRepo.NewLedgerRepo(gormDB)
+	prRepo := prRepo.NewPricingRuleRepo(gormDB)
+	kRepo := keyRepo.NewAPIKeyRepo(gormDB)
+	rRepo := remRepo.NewReminderRepo(gormDB)
+	cRepo := claimRepo.NewPaymentClaimRepo(gormDB)
+	aRepo := auditRepo.NewAuditRepo(gormDB)
+	idRepo := idemRepo.NewIdempotencyRepo(gormDB)
 
 	// Services ----------------------------------------------------------------
-	paymentService := service.NewService(usRepo, sRepo, crRepo, gsRepo)
-	profitService := service.NewProfitAnalytics(pRepo, uRepo, sRepo)
-
-	// Handlers ----------------------------------------------------------------
-	uH := handlers.NewUserHandler(uRepo)
-	sH := handlers.NewSubscriptionHandler(sRepo)
-	usH := handlers.NewUserSubscriptionHandler(usRepo)
-	pH := handlers.NewPaymentLogHandler(pRepo, paymentService)
-	gsH := handlers.NewGlobalSettingsHandler(gsRepo)
-	crH := handlers.NewCurrencyRateHandler(crRepo)
-	calcH := handlers.NewCalculateHandler(paymentService)
-	adminH := handlers.NewAdminHandler(uRepo, crRepo)
-	profitH := handlers.NewProfitHandler(profitService, uRepo)
-
-	// Fiber + Routes ----------------------------------------------------------
-	app := fiber.New()
+	currencyService := service.NewCurrencies(curRepo)
+	converter := service.NewConverter(crRepo)
+	pricingService := service.NewPricingRules(prRepo, uRepo, sRepo, currencyService)
+	paymentService := service.NewService(uRepo, usRepo, sRepo, gsRepo, prRepo, converter, currencyService)
+	profitService := service.NewProfitAnalytics(pRepo, uRepo, sRepo, converter)
+	transactor := service.NewTransactor(gormDB)
+	ledgerService := service.NewLedger(lRepo, transactor)
+	billingService := service.NewBilling(usRepo, iRepo, paymentService, transactor)
+	invoiceService := service.NewInvoicing(iRepo, usRepo, paymentService, transactor)
+	paymentsService := service.NewPayments(paymentService, invoiceService, converter, transactor)
+	claimService := service.NewPaymentClaims(cRepo, uRepo, usRepo, invoiceService, paymentsService, currencyService, transactor)
+	authService := service.NewAuth(kRepo, uRepo)
+	auditService := service.NewAudit(aRepo, uRepo)
+	idempotencyService := service.NewIdempotency(idRepo, idempotencyLease)
+
+	// ключ бота и других сервисов задаётся в окружении, остальные выпускает администратор
+	if key := os.Getenv("API_KEY"); key != "" {
+		if err := authService.EnsureServiceKey("service", key); err != nil {
+			log.Fatalf("API_KEY error: %v", err)
+		}
+	} else {
+		log.Println("API_KEY is not set: only keys issued earlier are accepted")
+	}
+
+	// вход через Telegram Mini App и напоминания работают с токеном того же бота
+	var webAppService service.WebAppAuth
+	var reminderService service.Reminders
+	if token := os.Getenv("TOKEN"); token != "" {
+		webAppService = service.NewWebAppAuth(token, authService, uRepo)
+		reminderService = service.NewReminders(reminderConfig(), notify.NewTelegram(token, "", nil),
+			rRepo, uRepo, usRepo, iRepo, paymentService)
+	} else {
+		log.Println("TOKEN is not set: Telegram Mini App login and reminders are disabled")
+	}
+
+	// Background jobs ---------------------------------------------------------
+	runner := jobs.NewRunner(
+		jobs.NewBillingJob(billingService, invoiceService, billingInterval),
+		jobs.NewIdempotencyJob(idempotencyService, idempotencyTTL, time.Hour),
+		jobs.NewAPIKeysJob(authService, expiredKeysRetention, time.Hour),
+	)
+	if reminderService != nil {
+		runner.Add(jobs.NewRemindersJob(reminderService, remindersInterval))
+	}
+	if retention := trashRetention(); retention > 0 {
+		runner.Add(jobs.NewTrashJob(retention, trashInterval, map[string]jobs.Purger{
+			"users":          uRepo,
+			"subscriptions":  sRepo,
+			"currency rates": crRepo,
+			"pricing rules":  prRepo,
+		}))
+	} else {
+		log.Println("TRASH_RETENTION_DAYS is 0: deleted records are kept forever")
+	}
+	if providers := rateProviders(); len(providers) > 0 {
+		runner.Add(jobs.NewRatesJob(crRepo, currencyService, ratesInterval(), providers...))
+	}
+
+	// Handlers + Routes -------------------------------------------------------
+	app := newRouter(routeHandlers{
+		users:             handlers.NewUserHandler(uRepo, ledgerService, currencyService),
+		subscriptions:     handlers.NewSubscriptionHandler(sRepo, currencyService),
+		userSubscriptions: handlers.NewUserSubscriptionHandler(usRepo, billingService),
+		payments:          handlers.NewPaymentLogHandler(pRepo, paymentsService, currencyService),
+		claims:            handlers.NewPaymentClaimHandler(claimService),
+		ledger:            handlers.NewLedgerHandler(ledgerService, uRepo, currencyService),
+		invoices:          handlers.NewInvoiceHandler(iRepo, invoiceService),
+		settings:          handlers.NewGlobalSettingsHandler(gsRepo),
+		rates:             handlers.NewCurrencyRateHandler(crRepo, converter, currencyService),
+		currencies:        handlers.NewCurrencyHandler(currencyService),
+		calculate:         handlers.NewCalculateHandler(paymentService),
+		pricingRules:      handlers.NewPricingRuleHandler(pricingService),
+		admin:             handlers.NewAdminHandler(uRepo, crRepo, currencyService),
+		profit:            handlers.NewProfitHandler(profitService, uRepo, currencyService),
+		auth:              handlers.NewAuthHandler(authService, webAppService),
+		reminders:         handlers.NewReminderHandler(uRepo, reminderService),
+		audit:             handlers.NewAuditHandler(auditService),
+		trash:             handlers.NewTrashHandler(uRepo, sRepo, crRepo, prRepo),
+		idempotency:       handlers.NewIdempotencyHandler(idempotencyService),
+	})
+
+	checkOpenAPI(app)
+
+	return &App{App: app, Jobs: runner}
+}
+
+// routeHandlers обработчики, из которых собираются маршруты API
+type routeHandlers struct {
+	users             *handlers.UserHandler
+	subscriptions     *handlers.SubscriptionHandler
+	userSubscriptions *handlers.UserSubscriptionHandler
+	payments          *handlers.PaymentLogHandler
+	claims            *handlers.PaymentClaimHandler
+	ledger            *handlers.LedgerHandler
+	invoices          *handlers.InvoiceHandler
+	settings          *handlers.GlobalSettingsHandler
+	rates             *handlers.CurrencyRateHandler
+	currencies        *handlers.CurrencyHandler
+	calculate         *handlers.CalculateHandler
+	pricingRules      *handlers.PricingRuleHandler
+	admin             *handlers.AdminHandler
+	profit            *handlers.ProfitHandler
+	auth              *handlers.AuthHandler
+	reminders         *handlers.ReminderHandler
+	audit             *handlers.AuditHandler
+	trash             *handlers.TrashHandler
+	idempotency       *handlers.IdempotencyHandler
+}
+
+// newRouter собирает приложение fiber со всеми маршрутами API
+func newRouter(h routeHandlers) *fiber.App {
+	// ошибки обработчиков отдаются в формате application/problem+json
+	app := fiber.New(fiber.Config{ErrorHandler: handlers.ErrorHandler})
+	// паника в обработчике — 500 через ErrorHandler, а не падение всего сервера
+	app.Use(recover.New())
 	api := app.Group("/api")
 
-	// health
+	// health (без ключа: его проверяет docker healthcheck)
 	api.Get("/healthz", handlers.Healthz)
 
+	// описание API (без ключа: по нему генерируют клиентов)
+	api.Get("/openapi.json", handlers.OpenAPI)
+
+	// вход участника через Telegram Mini App (без ключа: ключ он и выдаёт)
+	api.Post("/auth/telegram", h.auth.TelegramLogin) // POST /api/auth/telegram {"init_data": "..."}
+
+	// все остальные маршруты — по ключу доступа; RequireAdmin — только администратор,
+	// RequireUser — администратор или сам пользователь с ключом member
+	api.Use(h.auth.Authenticate)
+	api.Use(h.audit.Attach)       // изменения записываются в журнал от имени ключа запроса
+	api.Use(h.idempotency.Handle) // повтор POST с тем же Idempotency-Key получает первый ответ
+	api.Get("/auth/me", h.auth.Me)
+
 	// calculate payment amount (for testing)
-	api.Get("/calculate/:userID/:subscriptionID", calcH.CalculatePayment)
+	api.Get("/calculate/:userID/:subscriptionID", handlers.RequireUser("userID"), h.calculate.CalculatePayment)
 
 	// users
 	u := api.Group("/users")
-	u.Post("/", uH.Create)
-	u.Get("/", uH.List)
-	u.Get("/:id", uH.Get)
-	u.Get("/tgid/:tgid", uH.FindByTGID)
-	u.Patch("/:id", uH.Update)
-	u.Delete("/:id", uH.Delete)
+	u.Post("/", handlers.RequireAdmin, h.users.Create)
+	u.Get("/", handlers.RequireAdmin, h.users.List)
+	u.Get("/:id", handlers.RequireUser("id"), h.users.Get)
+	u.Get("/tgid/:tgid", handlers.RequireAdmin, h.users.FindByTGID)
+	u.Patch("/:id", handlers.RequireAdmin, h.users.Update)
+	u.Delete("/:id", handlers.RequireAdmin, h.users.Delete)
+	u.Get("/:id/balance", handlers.RequireUser("id"), h.ledger.Balance) // GET /api/users/:id/balance?from=2025-03-01&to=2025-03-31
+	u.Get("/:id/reminders", handlers.RequireUser("id"), h.reminders.Get)
+	u.Put("/:id/reminders", handlers.RequireUser("id"), h.reminders.Update) // PUT /api/users/:id/reminders {"enabled": false, "quiet_hours": "23-8"}
 
 	// users -> subscriptions (user-sub join)
 	us := u.Group("/:userID/subscriptions")
-	us.Post("/", usH.Create)
-	us.Get("/", usH.ListByUser)
-	us.Patch("/:id", usH.UpdateSettings)
-	us.Delete("/:id", usH.Delete)
+	us.Post("/", handlers.RequireAdmin, h.userSubscriptions.Create)
+	us.Get("/", handlers.RequireUser("userID"), h.userSubscriptions.ListByUser)
+	us.Patch("/:id", handlers.RequireAdmin, h.userSubscriptions.UpdateSettings)
+	us.Post("/:id/leave", handlers.RequireAdmin, h.userSubscriptions.Leave) // POST /api/users/:userID/subscriptions/:id/leave {"date": "2025-03-15"}
+	us.Delete("/:id", handlers.RequireAdmin, h.userSubscriptions.Delete)
 
 	// users -> payments
 	up := u.Group("/:userID/payments")
-	up.Get("/", pH.ListByUser)
-	up.Post("/", pH.Create)
+	up.Get("/", handlers.RequireUser("userID"), h.payments.ListByUser)
+	up.Post("/", handlers.RequireAdmin, h.payments.Create)
+
+	// users -> payment claims
+	uc := u.Group("/:userID/payment_claims", handlers.RequireUser("userID"))
+	uc.Get("/", h.claims.ListByUser) // GET  /api/users/:userID/payment_claims?status=
+	uc.Post("/", h.claims.Create)    // POST /api/users/:userID/payment_claims
+
+	// users -> invoices
+	ui := u.Group("/:userID/invoices")
+	ui.Get("/", handlers.RequireUser("userID"), h.invoices.ListByUser)
+	ui.Post("/", handlers.RequireAdmin, h.invoices.Create)
 
 	// subscriptions
 	s := api.Group("/subscriptions")
-	s.Post("/", sH.Create)
-	s.Get("/", sH.List)
-	s.Get("/:id", sH.Get)
-	s.Patch("/:id", sH.Update)
-	s.Delete("/:id", sH.Delete)
+	s.Post("/", handlers.RequireAdmin, h.subscriptions.Create)
+	s.Get("/", h.subscriptions.List)
+	s.Get("/:id", h.subscriptions.Get)
+	s.Patch("/:id", handlers.RequireAdmin, h.subscriptions.Update)
+	s.Delete("/:id", handlers.RequireAdmin, h.subscriptions.Delete)
+
+	// subscriptions -> members (с долями в цене семейного тарифа)
+	s.Get("/:subID/members", handlers.RequireAdmin, h.userSubscriptions.ListBySubscription)
 
 	// subscriptions -> payments
-	sp := s.Group("/:subID/payments")
-	sp.Get("/", pH.ListBySubscription)
+	sp := s.Group("/:subID/payments", handlers.RequireAdmin)
+	sp.Get("/", h.payments.ListBySubscription)
 
 	// standalone payments list
-	api.Get("/payments", pH.ListAll)
+	api.Get("/payments", handlers.RequireAdmin, h.payments.ListAll)
+
+	// invoices ------------------------------------------------------------
+	inv := api.Group("/invoices", handlers.RequireAdmin)
+	inv.Get("/", h.invoices.List)            // GET  /api/invoices?status=outstanding&from=2025-03-01&to=2025-03-31
+	inv.Get("/:id", h.invoices.Get)          // GET  /api/invoices/:id
+	inv.Post("/:id/issue", h.invoices.Issue) // POST /api/invoices/:id/issue
+	inv.Post("/:id/void", h.invoices.Void)   // POST /api/invoices/:id/void
 
 	// global settings (singleton)
-	settings := api.Group("/settings")
-	settings.Get("/", gsH.Get)
-	settings.Post("/", gsH.Create)
-	settings.Put("/", gsH.Update)
+	settings := api.Group("/settings", handlers.RequireAdmin)
+	settings.Get("/", h.settings.Get)
+	settings.Post("/", h.settings.Create)
+	settings.Put("/", h.settings.Update)
+
+	// currencies registry ---------------------------------------------------
+	api.Get("/currencies", h.currencies.List) // GET /api/currencies?all=true
 
 	// currency rates ------------------------------------------------------
 	cr := api.Group("/currency_rates")
-	cr.Post("/", crH.Create)                // POST   /api/currency_rates
-	cr.Get("/", crH.List)                   // GET    /api/currency_rates?limit=&offset=
-	cr.Get("/:id", crH.Get)                 // GET    /api/currency_rates/:id
-	cr.Get("/latest/:currency", crH.Latest) // GET    /api/currency_rates/latest/USD
-	cr.Put("/:id", crH.Update)              // PUT    /api/currency_rates/:id
-	cr.Delete("/:id", crH.Delete)           // DELETE /api/currency_
+	cr.Post("/", handlers.RequireAdmin, h.rates.Create)      // POST   /api/currency_rates
+	cr.Get("/", h.rates.List)                                // GET    /api/currency_rates?currency=USD&sort=-fetched_at&limit=&cursor=
+	cr.Get("/convert", h.rates.Convert)                      // GET    /api/currency_rates/convert?from=USD&to=KZT&amount=10
+	cr.Get("/:id", h.rates.Get)                              // GET    /api/currency_rates/:id
+	cr.Get("/latest/:currency", h.rates.Latest)              // GET    /api/currency_rates/latest/USD
+	cr.Get("/history/:currency", h.rates.History)            // GET    /api/currency_rates/history/USD?from=&to=
+	cr.Put("/:id", handlers.RequireAdmin, h.rates.Update)    // PUT    /api/currency_rates/:id
+	cr.Delete("/:id", handlers.RequireAdmin, h.rates.Delete) // DELETE /api/currency_
 
 	// admin routes (profit analytics + currency management) --------------
 	admin := api.Group("/admin/:adminUserID")
-	admin.Use(adminH.CheckAdminAccess) // middleware для проверки admin прав
+	admin.Use(h.admin.CheckAdminAccess) // middleware для проверки admin прав
 
 	// profit analytics
 	profit := admin.Group("/profit")
-	profit.Get("/monthly/:year/:month", profitH.GetMonthlyProfit)    // GET /api/admin/:adminUserID/profit/monthly/2024/7
-	profit.Get("/users", profitH.GetUserProfitStats)                 // GET /api/admin/:adminUserID/profit/users?from=...&to=...
-	profit.Get("/subscriptions", profitH.GetSubscriptionProfitStats) // GET /api/admin/:adminUserID/profit/subscriptions?from=...&to=...
-	profit.Get("/total", profitH.GetTotalProfit)                     // GET /api/admin/:adminUserID/profit/total
+	profit.Get("/monthly/:year/:month", h.profit.GetMonthlyProfit)    // GET /api/admin/:adminUserID/profit/monthly/2024/7
+	profit.Get("/users", h.profit.GetUserProfitStats)                 // GET /api/admin/:adminUserID/profit/users?from=...&to=...
+	profit.Get("/subscriptions", h.profit.GetSubscriptionProfitStats) // GET /api/admin/:adminUserID/profit/subscriptions?from=...&to=...
+	profit.Get("/total", h.profit.GetTotalProfit)                     // GET /api/admin/:adminUserID/profit/total
+
+	// member balance adjustments
+	admin.Post("/users/:userID/adjustments", h.ledger.Adjust) // POST /api/admin/:adminUserID/users/:userID/adjustments
 
 	// currency management
 	currency := admin.Group("/currency")
-	currency.Post("/set", adminH.SetManualRate)     // POST /api/admin/:adminUserID/currency/set
-	currency.Post("/bulk", adminH.SetMultipleRates) // POST /api/admin/:adminUserID/currency/bulk
-	currency.Get("/status", adminH.GetCurrentRates) // GET /api/admin/:adminUserID/currency/status
+	currency.Post("/set", h.admin.SetManualRate)     // POST /api/admin/:adminUserID/currency/set
+	currency.Post("/bulk", h.admin.SetMultipleRates) // POST /api/admin/:adminUserID/currency/bulk
+	currency.Get("/status", h.admin.GetCurrentRates) // GET /api/admin/:adminUserID/currency/status
+
+	// currencies registry management
+	admin.Post("/currencies", h.currencies.Create)        // POST  /api/admin/:adminUserID/currencies
+	admin.Patch("/currencies/:code", h.currencies.Update) // PATCH /api/admin/:adminUserID/currencies/KZT
+
+	// pricing rules
+	rules := admin.Group("/pricing_rules")
+	rules.Get("/", h.pricingRules.List)         // GET    /api/admin/:adminUserID/pricing_rules?scope=&user_id=&subscription_id=
+	rules.Post("/", h.pricingRules.Create)      // POST   /api/admin/:adminUserID/pricing_rules
+	rules.Get("/:id", h.pricingRules.Get)       // GET    /api/admin/:adminUserID/pricing_rules/:id
+	rules.Patch("/:id", h.pricingRules.Update)  // PATCH  /api/admin/:adminUserID/pricing_rules/:id
+	rules.Delete("/:id", h.pricingRules.Delete) // DELETE /api/admin/:adminUserID/pricing_rules/:id
+
+	// payment claims: очередь сообщений об оплате на подтверждение
+	claims := admin.Group("/payment_claims")
+	claims.Get("/", h.claims.Queue)               // GET  /api/admin/:adminUserID/payment_claims?status=pending&user_id=
+	claims.Get("/:id", h.claims.Get)              // GET  /api/admin/:adminUserID/payment_claims/:id
+	claims.Post("/:id/approve", h.claims.Approve) // POST /api/admin/:adminUserID/payment_claims/:id/approve
+	claims.Post("/:id/reject", h.claims.Reject)   // POST /api/admin/:adminUserID/payment_claims/:id/reject {"reason": "..."}
+
+	// api keys
+	keys := admin.Group("/api_keys")
+	keys.Get("/", h.auth.List)         // GET    /api/admin/:adminUserID/api_keys?user_id=
+	keys.Post("/", h.auth.Create)      // POST   /api/admin/:adminUserID/api_keys
+	keys.Delete("/:id", h.auth.Revoke) // DELETE /api/admin/:adminUserID/api_keys/:id
+
+	// audit log
+	admin.Get("/audit", h.audit.List) // GET /api/admin/:adminUserID/audit?entity=subscription&entity_id=
+
+	// trash: удалённые записи и их восстановление
+	tr := admin.Group("/trash")
+	tr.Get("/users", h.trash.Users)                                     // GET  /api/admin/:adminUserID/trash/users?deleted_at[gte]=2025-07-01
+	tr.Post("/users/:id/restore", h.trash.RestoreUser)                  // POST /api/admin/:adminUserID/trash/users/:id/restore
+	tr.Get("/subscriptions", h.trash.Subscriptions)                     // GET  /api/admin/:adminUserID/trash/subscriptions
+	tr.Post("/subscriptions/:id/restore", h.trash.RestoreSubscription)  // POST /api/admin/:adminUserID/trash/subscriptions/:id/restore
+	tr.Get("/currency_rates", h.trash.CurrencyRates)                    // GET  /api/admin/:adminUserID/trash/currency_rates
+	tr.Post("/currency_rates/:id/restore", h.trash.RestoreCurrencyRate) // POST /api/admin/:adminUserID/trash/currency_rates/:id/restore
+	tr.Get("/pricing_rules", h.trash.PricingRules)                      // GET  /api/admin/:adminUserID/trash/pricing_rules
+	tr.Post("/pricing_rules/:id/restore", h.trash.RestorePricingRule)   // POST /api/admin/:adminUserID/trash/pricing_rules/:id/restore
 
 	return app
 }
+
+// checkOpenAPI пишет в лог расхождения маршрутов с api/openapi.json,
+// чтобы новый маршрут не остался без описания и клиента; тест TestOpenAPIMatchesRoutes
+// не даёт таким расхождениям попасть в сборку
+func checkOpenAPI(app *fiber.App) {
+	undocumented, unregistered, err := openAPIDrift(app)
+	if err != nil {
+		log.Printf("OpenAPI check skipped: %v", err)
+		return
+	}
+	for _, op := range undocumented {
+		log.Printf("[WARN] Route %s is not described in api/openapi.json", op)
+	}
+	for _, op := range unregistered {
+		log.Printf("[WARN] api/openapi.json describes %s, but the route is not registered", op)
+	}
+}
+
+// openAPIDrift сравнивает маршруты приложения с api/openapi.json: undocumented — маршруты
+// без описания, unregistered — описанные операции без маршрута; оба списка отсортированы
+func openAPIDrift(app *fiber.App) (undocumented, unregistered []string, err error) {
+	ops, err := apispec.Operations()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	registered := make(map[string]struct{})
+	for _, r := range app.GetRoutes(true) {
+		if r.Method == fiber.MethodHead {
+			continue // fiber сам добавляет HEAD к каждому GET
+		}
+		op := r.Method + " " + openAPIPath(r.Path)
+		if _, seen := registered[op]; seen {
+			continue
+		}
+		registered[op] = struct{}{}
+		if _, ok := ops[op]; !ok {
+			undocumented = append(undocumented, op)
+		}
+	}
+	for op := range ops {
+		if _, ok := registered[op]; !ok {
+			unregistered = append(unregistered, op)
+		}
+	}
+	slices.Sort(undocumented)
+	slices.Sort(unregistered)
+	return undocumented, unregistered, nil
+}
+
+// openAPIPath переводит путь fiber в шаблон OpenAPI: /api/users/:id/ → /api/users/{id}
+func openAPIPath(path string) string {
+	parts := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, ":") {
+			parts[i] = "{" + strings.TrimPrefix(part, ":") + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// rateProviders собирает источники курсов из RATE_PROVIDERS (через запятую, по умолчанию Cifra).
+// Пустое значение отключает автоматическое обновление курсов.
+func rateProviders() []rates.RateProvider {
+	names, ok := os.LookupEnv("RATE_PROVIDERS")
+	if !ok {
+		names = string(db.Cifra)
+	}
+
+	var providers []rates.RateProvider
+	for _, name := range strings.Split(names, ",") {
+		switch db.RateSource(strings.TrimSpace(name)) {
+		case db.Cifra:
+			providers = append(providers, rates.NewCifra(os.Getenv("CIFRA_RATES_URL"), nil))
+		case db.FF:
+			providers = append(providers, rates.NewFF(os.Getenv("FF_RATES_URL"), nil))
+		case "":
+		default:
+			log.Printf("Unknown rate provider %q, skipped", name)
+		}
+	}
+	return providers
+}
+
+// ratesInterval читает RATES_INTERVAL (например, 30m)
+func ratesInterval() time.Duration {
+	if v := os.Getenv("RATES_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err == nil && d > 0 {
+			return d
+		}
+		log.Printf("Invalid RATES_INTERVAL %q, using %s", v, defaultRatesInterval)
+	}
+	return defaultRatesInterval
+}
+
+// trashRetention сколько удалённые записи хранятся в корзине: TRASH_RETENTION_DAYS,
+// по умолчанию 30 дней; 0 — корзина не очищается
+func trashRetention() time.Duration {
+	days := defaultTrashRetentionDays
+	if v := os.Getenv("TRASH_RETENTION_DAYS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err == nil && n >= 0 {
+			days = n
+		} else {
+			log.Printf("Invalid TRASH_RETENTION_DAYS %q, using %d", v, defaultTrashRetentionDays)
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// reminderConfig читает расписание напоминаний из окружения:
+// REMINDER_OFFSETS (дни относительно даты списания, по умолчанию -3,0,2),
+// REMINDER_QUIET_HOURS (по умолчанию 22-9) и REMINDER_TZ (по умолчанию Europe/Moscow)
+func reminderConfig() service.ReminderConfig {
+	cfg := service.ReminderConfig{Offsets: []int{-3, 0, 2}, QuietFrom: 22, QuietTo: 9}
+
+	if v, ok := os.LookupEnv("REMINDER_OFFSETS"); ok {
+		cfg.Offsets = nil
+		for _, part := range strings.Split(v, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			offset, err := strconv.Atoi(part)
+			if err != nil {
+				log.Fatalf("Invalid REMINDER_OFFSETS %q: %v", v, err)
+			}
+			cfg.Offsets = append(cfg.Offsets, offset)
+		}
+	}
+
+	if v := os.Getenv("REMINDER_QUIET_HOURS"); v != "" {
+		from, to, err := service.ParseQuietHours(v)
+		if err != nil {
+			log.Fatalf("Invalid REMINDER_QUIET_HOURS: %v", err)
+		}
+		cfg.QuietFrom, cfg.QuietTo = from, to
+	}
+
+	tz := os.Getenv("REMINDER_TZ")
+	if tz == "" {
+		tz = "Europe/Moscow"
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		log.Fatalf("Invalid REMINDER_TZ %q: %v", tz, err)
+	}
+	cfg.Location = loc
+	return cfg
+}
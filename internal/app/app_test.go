@@ -0,0 +1,37 @@
+package app
+
+import (
+	"testing"
+)
+
+// TestOpenAPIMatchesRoutes новый или удалённый маршрут должен попасть в api/openapi.json
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	app := newRouter(routeHandlers{})
+
+	undocumented, unregistered, err := openAPIDrift(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, op := range undocumented {
+		t.Errorf("route %s is not described in api/openapi.json", op)
+	}
+	for _, op := range unregistered {
+		t.Errorf("api/openapi.json describes %s, but the route is not registered", op)
+	}
+	if len(app.GetRoutes(true)) == 0 {
+		t.Fatal("no routes registered")
+	}
+}
+
+func TestOpenAPIPath(t *testing.T) {
+	tests := map[string]string{
+		"/api/users/":                         "/api/users",
+		"/api/users/:id":                      "/api/users/{id}",
+		"/api/admin/:adminUserID/trash/users": "/api/admin/{adminUserID}/trash/users",
+	}
+	for path, want := range tests {
+		if got := openAPIPath(path); got != want {
+			t.Errorf("openAPIPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -1,25 +1,34 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
-	"github.com/WhoYa/subscription-manager/internal/bot/api"
 	"github.com/WhoYa/subscription-manager/internal/bot/keyboards"
 	"github.com/WhoYa/subscription-manager/internal/bot/types"
+	"github.com/WhoYa/subscription-manager/pkg/apierr"
+	"github.com/WhoYa/subscription-manager/pkg/client"
+	"github.com/WhoYa/subscription-manager/pkg/db"
 )
 
 // Bot основная структура бота
 type Bot struct {
 	API     *tgbotapi.BotAPI
 	Context *types.BotContext
+	Workers int            // сколько чатов обслуживается параллельно; 0 — defaultWorkers
+	Webhook *WebhookConfig // nil — long polling
 }
 
-// NewBot создает новый экземпляр бота
-func NewBot(token, apiBaseURL string, adminUserIDs []int64) (*Bot, error) {
+// stateExpireInterval как часто из хранилища удаляются брошенные диалоги
+const stateExpireInterval = 10 * time.Minute
+
+// NewBot создает новый экземпляр бота; states хранит незаконченные диалоги пользователей
+func NewBot(token, apiBaseURL, apiKey string, adminUserIDs []int64, states types.StateStore) (*Bot, error) {
 	botAPI, err := tgbotapi.NewBotAPI(token)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create bot: %w", err)
@@ -28,7 +37,7 @@ func NewBot(token, apiBaseURL string, adminUserIDs []int64) (*Bot, error) {
 	botAPI.Debug = false
 
 	// Создаем API клиент
-	apiClient := api.NewClient(apiBaseURL)
+	apiClient := client.New(apiBaseURL, apiKey)
 
 	log.Printf("Bot initialized with %d admin user(s): %v", len(adminUserIDs), adminUserIDs)
 
@@ -36,7 +45,7 @@ func NewBot(token, apiBaseURL string, adminUserIDs []int64) (*Bot, error) {
 		Bot:          botAPI,
 		APIClient:    apiClient,
 		APIBaseURL:   apiBaseURL,
-		UserStates:   make(map[int64]*types.UserData),
+		UserStates:   states,
 		AdminUserIDs: adminUserIDs,
 	}
 
@@ -46,35 +55,103 @@ func NewBot(token, apiBaseURL string, adminUserIDs []int64) (*Bot, error) {
 	}, nil
 }
 
-// Start запускает бота
-func (b *Bot) Start() error {
+// Start запускает бота и работает до отмены ctx. Обновления приходят через webhook,
+// если задан Bot.Webhook, иначе через long polling. Обновления разных чатов обрабатываются
+// параллельно, одного чата — по порядку. При остановке бот перестаёт получать обновления
+// и дожидается обработки уже полученных.
+func (b *Bot) Start(ctx context.Context) error {
 	log.Printf("Authorized on account %s", b.API.Self.UserName)
 
+	pool := newUpdatePool(b.Workers, b.handleUpdate)
+	go b.expireStates(ctx)
+
+	var err error
+	if b.Webhook != nil {
+		err = b.serveWebhook(ctx, pool)
+	} else {
+		err = b.poll(ctx, pool)
+	}
+
+	pool.Close()
+	log.Println("Bot stopped")
+	return err
+}
+
+// poll получает обновления через long polling
+func (b *Bot) poll(ctx context.Context, pool *updatePool) error {
+	// getUpdates не работает, пока у бота установлен webhook
+	if _, err := b.API.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 
 	updates := b.API.GetUpdatesChan(u)
-
-	for update := range updates {
-		b.handleUpdate(update)
+	log.Println("Receiving updates via long polling")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Stopping bot: finishing received updates...")
+			b.API.StopReceivingUpdates()
+			// уже полученные обновления обрабатываются, новые Telegram отдаст после перезапуска
+			for drained := false; !drained; {
+				select {
+				case update, ok := <-updates:
+					if !ok {
+						drained = true
+						break
+					}
+					pool.Submit(update)
+				default:
+					drained = true
+				}
+			}
+			return nil
+		case update, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			pool.Submit(update)
+		}
 	}
+}
+
+// expireStates периодически удаляет брошенные диалоги
+func (b *Bot) expireStates(ctx context.Context) {
+	expire := time.NewTicker(stateExpireInterval)
+	defer expire.Stop()
 
-	return nil
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-expire.C:
+			if n, err := b.Context.UserStates.Expire(now); err != nil {
+				logError("ExpireStates", err)
+			} else if n > 0 {
+				log.Printf("Expired %d abandoned dialog state(s)", n)
+			}
+		}
+	}
 }
 
 // handleUpdate обрабатывает входящие обновления
 func (b *Bot) handleUpdate(update tgbotapi.Update) {
 	if update.Message != nil {
 		b.handleMessage(update.Message)
+		b.saveUserState(update.Message.From.ID)
 	} else if update.CallbackQuery != nil {
 		b.handleCallbackQuery(update.CallbackQuery)
+		b.saveUserState(update.CallbackQuery.From.ID)
 	}
 }
 
 // handleMessage обрабатывает входящие сообщения
 func (b *Bot) handleMessage(message *tgbotapi.Message) {
 	if !b.isAdmin(message.From.ID) {
-		b.sendSimpleMessage(message.Chat.ID, MessageNoAccess)
+		b.handleMemberMessage(message)
 		return
 	}
 
@@ -126,6 +203,8 @@ func (b *Bot) handleMessage(message *tgbotapi.Message) {
 		b.handleEditUserFullnameInput(message)
 	case types.StateEditingUserUsername:
 		b.handleEditUserUsernameInput(message)
+	case types.StateAwaitingClaimRejectReason:
+		b.handleClaimRejectReasonInput(message)
 	default:
 		log.Printf("User %d sent message in unhandled state %s: %s", message.From.ID, userState.State, message.Text)
 		b.sendSimpleMessage(message.Chat.ID, MessageUseStart)
@@ -141,7 +220,7 @@ func (b *Bot) handleCommand(message *tgbotapi.Message) {
 			// Проверяем, является ли пользователь админом в списке
 			if b.isAdmin(message.From.ID) {
 				// Проверяем, есть ли пользователь в БД
-				user, err := b.Context.APIClient.FindUserByTGID(message.From.ID)
+				user, err := b.Context.APIClient.FindUserByTGID(context.Background(), message.From.ID)
 				if err != nil {
 					// Пользователь не найден в БД, создаем его
 					log.Printf("Admin user not found in database, creating...")
@@ -196,7 +275,7 @@ func (b *Bot) handleHelpCommand(message *tgbotapi.Message) {
 // handleCallbackQuery обрабатывает callback запросы
 func (b *Bot) handleCallbackQuery(query *tgbotapi.CallbackQuery) {
 	if !b.isAdmin(query.From.ID) {
-		b.answerCallbackQuery(query.ID, "❌ У вас нет прав доступа.")
+		b.handleMemberCallback(query)
 		return
 	}
 
@@ -251,6 +330,8 @@ func (b *Bot) handleCallbackQuery(query *tgbotapi.CallbackQuery) {
 		b.handleEditUser(query.Message.Chat.ID, query.Message.MessageID)
 	case "cancel":
 		b.cancelCurrentOperation(query.From.ID, query.Message.Chat.ID)
+	case "claim_cancel":
+		b.handlePaymentClaimRejectCancel(query)
 	case "step_back":
 		b.handleStepBack(query.From.ID, query.Message.Chat.ID)
 	default:
@@ -264,6 +345,16 @@ func (b *Bot) handleCallbackQuery(query *tgbotapi.CallbackQuery) {
 			b.handleEditUserCallback(query)
 		} else if strings.HasPrefix(query.Data, "toggle_") {
 			b.handleToggleCallback(query)
+		} else if strings.HasPrefix(query.Data, "calc_user_") {
+			b.showUserPayments(query.Message.Chat.ID, query.Message.MessageID, strings.TrimPrefix(query.Data, "calc_user_"))
+		} else if id, ok := strings.CutPrefix(query.Data, "history_sub_"); ok {
+			b.showEntityHistory(query, db.AuditSubscription, id, "edit_sub_"+id)
+		} else if id, ok := strings.CutPrefix(query.Data, "history_user_"); ok {
+			b.showEntityHistory(query, db.AuditUser, id, "edit_user_"+id)
+		} else if strings.HasPrefix(query.Data, "claim_ok_") {
+			b.handlePaymentClaimApprove(query, strings.TrimPrefix(query.Data, "claim_ok_"))
+		} else if strings.HasPrefix(query.Data, "claim_no_") {
+			b.handlePaymentClaimReject(query, strings.TrimPrefix(query.Data, "claim_no_"))
 		} else {
 			b.sendSimpleMessage(query.Message.Chat.ID, "Функция пока не реализована.")
 		}
@@ -374,7 +465,7 @@ func (b *Bot) showMenu(chatID int64, messageID int, menuType string) {
 		keyboard = keyboards.UserManagementKeyboard()
 	case "global_settings":
 		// Получаем настройки для глобальных настроек
-		settings, err := b.Context.APIClient.GetGlobalSettings()
+		settings, err := b.Context.APIClient.GetGlobalSettings(context.Background())
 		if err != nil {
 			text = "⚙️ Глобальные настройки\n\nНастройки еще не созданы.\n\nСоздать глобальные настройки?"
 			keyboard = tgbotapi.NewInlineKeyboardMarkup(
@@ -386,7 +477,7 @@ func (b *Bot) showMenu(chatID int64, messageID int, menuType string) {
 				),
 			)
 		} else {
-			text = fmt.Sprintf("⚙️ Глобальные настройки\n\nТекущая глобальная надбавка: %.2f%%\n\nВыберите действие:", settings.GlobalMarkupPercent)
+			text = fmt.Sprintf("⚙️ Глобальные настройки\n\nТекущая глобальная надбавка: %.2f%%\n\nВыберите действие:", settings.GlobalMarkupPercent.Float64())
 			keyboard = tgbotapi.NewInlineKeyboardMarkup(
 				tgbotapi.NewInlineKeyboardRow(
 					tgbotapi.NewInlineKeyboardButtonData("📝 Изменить надбавку", "edit_global_markup"),
@@ -436,18 +527,16 @@ func (b *Bot) isAdmin(userID int64) bool {
 	return false
 }
 
-// getUserState получает состояние пользователя
+// getUserState получает состояние пользователя; изменения сохраняются после обработки обновления
 func (b *Bot) getUserState(userID int64) *types.UserData {
-	if state, exists := b.Context.UserStates[userID]; exists {
-		return state
-	}
+	return b.Context.UserStates.Get(userID)
+}
 
-	// Создаем новое состояние если его нет
-	newState := &types.UserData{
-		State: types.StateIdle,
+// saveUserState сохраняет состояние пользователя, чтобы диалог пережил перезапуск бота
+func (b *Bot) saveUserState(userID int64) {
+	if err := b.Context.UserStates.Save(userID); err != nil {
+		logError("SaveUserState", err)
 	}
-	b.Context.UserStates[userID] = newState
-	return newState
 }
 
 // setUserState устанавливает состояние пользователя
@@ -463,7 +552,7 @@ func (b *Bot) answerCallbackQuery(queryID, text string) {
 }
 
 // getOrCreateAdminUser находит админа в БД или создает его если он админ
-func (b *Bot) getOrCreateAdminUser(tgID int64, firstName, lastName, username string) (*api.User, error) {
+func (b *Bot) getOrCreateAdminUser(tgID int64, firstName, lastName, username string) (*db.User, error) {
 	log.Printf("Getting admin user info for TGID: %d", tgID)
 
 	// Проверяем, является ли пользователь админом в списке ADMINS
@@ -473,7 +562,7 @@ func (b *Bot) getOrCreateAdminUser(tgID int64, firstName, lastName, username str
 	}
 
 	// Сначала пытаемся найти пользователя
-	user, err := b.Context.APIClient.FindUserByTGID(tgID)
+	user, err := b.Context.APIClient.FindUserByTGID(context.Background(), tgID)
 	if err == nil {
 		log.Printf("Found existing user: %s (ID: %s, IsAdmin: %t)", user.Fullname, user.ID, user.IsAdmin)
 
@@ -486,8 +575,8 @@ func (b *Bot) getOrCreateAdminUser(tgID int64, firstName, lastName, username str
 		// Если пользователь не админ, но находится в списке админов, повышаем его
 		log.Printf("User %d is in admin list but not admin in DB – updating role", tgID)
 		isAdmin := true
-		updateReq := api.UpdateUserRequest{IsAdmin: &isAdmin}
-		updated, upErr := b.Context.APIClient.UpdateUser(user.ID, updateReq)
+		updateReq := client.UpdateUserRequest{IsAdmin: &isAdmin}
+		updated, upErr := b.Context.APIClient.UpdateUser(actorCtx(tgID), user.ID, updateReq)
 		if upErr != nil {
 			log.Printf("Failed to update user role: %v", upErr)
 			return user, nil // возвращаем как есть, если не удалось обновить
@@ -511,29 +600,29 @@ func (b *Bot) getOrCreateAdminUser(tgID int64, firstName, lastName, username str
 	log.Printf("Creating new admin user: %s (TGID: %d)", fullname, tgID)
 
 	// Создаем админа автоматически
-	req := api.CreateUserRequest{
+	req := client.CreateUserRequest{
 		TGID:     tgID,
 		Username: username,
 		Fullname: fullname,
 		IsAdmin:  true,
 	}
 
-	user, err = b.Context.APIClient.CreateUser(req)
+	user, err = b.Context.APIClient.CreateUser(actorCtx(tgID), req)
 	if err != nil {
 		log.Printf("Failed to create user: %v", err)
 
 		// Если ошибка 409 (дубликат TGID), пытаемся найти пользователя еще раз
-		if strings.Contains(err.Error(), "409") || strings.Contains(err.Error(), "duplicate") {
+		if client.ErrorCode(err) == apierr.CodeDuplicateTGID {
 			log.Printf("Duplicate TGID error, trying to find user again")
-			user, findErr := b.Context.APIClient.FindUserByTGID(tgID)
+			user, findErr := b.Context.APIClient.FindUserByTGID(context.Background(), tgID)
 			if findErr == nil {
 				log.Printf("Found existing user after duplicate error: %s (ID: %s, IsAdmin: %t)", user.Fullname, user.ID, user.IsAdmin)
 				// Если пользователь найден, но не админ, делаем его админом
 				if !user.IsAdmin {
 					log.Printf("User found but not admin, upgrading...")
 					isAdmin := true
-					updateReq := api.UpdateUserRequest{IsAdmin: &isAdmin}
-					updated, upErr := b.Context.APIClient.UpdateUser(user.ID, updateReq)
+					updateReq := client.UpdateUserRequest{IsAdmin: &isAdmin}
+					updated, upErr := b.Context.APIClient.UpdateUser(actorCtx(tgID), user.ID, updateReq)
 					if upErr != nil {
 						log.Printf("Failed to update user role: %v", upErr)
 						return user, nil
@@ -577,8 +666,8 @@ func (b *Bot) handleCurrencySelection(query *tgbotapi.CallbackQuery) {
 		userState.State = types.StateAwaitingSubscriptionPeriod
 		log.Printf("Setting currency for subscription. After: %+v", userState.SubscriptionData)
 
-		text := fmt.Sprintf("📝 Создание новой подписки\n\n**Шаг 4/4:** Введите период списания в днях\n\n✅ Название: %s\n✅ Цена: %.2f %s\n\n*Например: 30 для ежемесячной подписки*",
-			userState.SubscriptionData.ServiceName, userState.SubscriptionData.BasePrice, currency)
+		text := fmt.Sprintf("📝 Создание новой подписки\n\n**Шаг 4/4:** Введите период списания в днях\n\n✅ Название: %s\n✅ Цена: %s %s\n\n*Например: 30 для ежемесячной подписки*",
+			userState.SubscriptionData.ServiceName, userState.SubscriptionData.BasePrice.StringFixed(2), currency)
 		keyboard := keyboards.CreateProcessKeyboard("process")
 		b.editMessage(userState.CurrentChatID, userState.CurrentMessageID, text, &keyboard)
 
@@ -592,11 +681,12 @@ func (b *Bot) handleCurrencySelection(query *tgbotapi.CallbackQuery) {
 		}
 
 		// Обновляем подписку
-		req := api.UpdateSubscriptionRequest{
-			BaseCurrency: &currency,
+		code := db.Currency(currency)
+		req := client.UpdateSubscriptionRequest{
+			BaseCurrency: &code,
 		}
 
-		subscription, err := b.Context.APIClient.UpdateSubscription(userState.EditData.EntityID, req)
+		subscription, err := b.Context.APIClient.UpdateSubscription(actorCtx(query.From.ID), userState.EditData.EntityID, req)
 		if err != nil {
 			text := fmt.Sprintf("❌ Ошибка при обновлении подписки: %v", err)
 			keyboard := keyboards.CreateSuccessKeyboard("manage_subscriptions")
@@ -629,7 +719,7 @@ func (b *Bot) editMessage(chatID int64, messageID int, text string, keyboard *tg
 }
 
 // getAdminUser получает информацию об админе из Telegram и создает/находит в системе
-func (b *Bot) getAdminUser(adminUserID int64) (*api.User, error) {
+func (b *Bot) getAdminUser(adminUserID int64) (*db.User, error) {
 	log.Printf("Getting admin user info for TGID: %d", adminUserID)
 
 	// Получаем информацию о пользователе из Telegram
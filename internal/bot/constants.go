@@ -17,10 +17,10 @@ const (
 	MessageSubscriptionNameEmpty    = "📝 Создание новой подписки\n\n**Шаг 1/4:** Введите название сервиса\n\n❌ Название сервиса не может быть пустым. Попробуйте снова:\n\n*Например: Netflix, Spotify*"
 	MessageSubscriptionPriceStep    = "📝 Создание новой подписки\n\n**Шаг 2/4:** Введите базовую стоимость\n\n✅ Название: %s\n\n*Например: 9.99*"
 	MessageSubscriptionPriceError   = "📝 Создание новой подписки\n\n**Шаг 2/4:** Введите базовую стоимость\n\n✅ Название: %s\n\n❌ Неверный формат цены. Введите число больше 0:\n\n*Например: 9.99*"
-	MessageSubscriptionCurrencyStep = "📝 Создание новой подписки\n\n**Шаг 3/4:** Выберите валюту\n\n✅ Название: %s\n✅ Цена: %.2f"
-	MessageSubscriptionPeriodStep   = "📝 Создание новой подписки\n\n**Шаг 4/4:** Введите период списания в днях\n\n✅ Название: %s\n✅ Цена: %.2f %s\n\n*Например: 30 для ежемесячной подписки*"
-	MessageSubscriptionPeriodError  = "📝 Создание новой подписки\n\n**Шаг 4/4:** Введите период списания в днях\n\n✅ Название: %s\n✅ Цена: %.2f %s\n\n❌ Неверный формат периода. Введите целое число больше 0:\n\n*Например: 30 для ежемесячной подписки*"
-	MessageSubscriptionConfirm      = "📝 Подтверждение создания подписки\n\n🏷️ Сервис: %s\n💰 Цена: %.2f %s\n📅 Период: %d дней\n\n❓ Создать подписку?"
+	MessageSubscriptionCurrencyStep = "📝 Создание новой подписки\n\n**Шаг 3/4:** Выберите валюту\n\n✅ Название: %s\n✅ Цена: %s"
+	MessageSubscriptionPeriodStep   = "📝 Создание новой подписки\n\n**Шаг 4/4:** Введите период списания в днях\n\n✅ Название: %s\n✅ Цена: %s %s\n\n*Например: 30 для ежемесячной подписки*"
+	MessageSubscriptionPeriodError  = "📝 Создание новой подписки\n\n**Шаг 4/4:** Введите период списания в днях\n\n✅ Название: %s\n✅ Цена: %s %s\n\n❌ Неверный формат периода. Введите целое число больше 0:\n\n*Например: 30 для ежемесячной подписки*"
+	MessageSubscriptionConfirm      = "📝 Подтверждение создания подписки\n\n🏷️ Сервис: %s\n💰 Цена: %s %s\n📅 Период: %d дней\n\n❓ Создать подписку?"
 	MessageSubscriptionCreated      = "✅ Подписка успешно создана!\n\n🏷️ Сервис: %s\n💰 Цена: %.2f %s\n📅 Период: %d дней\n🆔 ID: %s"
 	MessageSubscriptionCreateError  = "❌ Ошибка при создании подписки: %v"
 
@@ -48,6 +48,59 @@ const (
 	MessageEditUserEmpty         = "📝 Редактирование пользователей\n\n📭 Пользователи не найдены.\n\nСоздайте первого пользователя!"
 	MessageEditUserMenu          = "📝 Редактирование пользователя\n\n👤 ФИО: %s\n🆔 Telegram ID: %d\n📝 Username: %s\n🔑 Роль: %s\n\nЧто хотите изменить?"
 
+	// Сообщения расчёта платежей
+	MessageUserPaymentsTitle   = "💳 Расчёт платежей: %s"
+	MessageUserPaymentsEmpty   = "💳 Расчёт платежей: %s\n\n📭 У пользователя нет подписок."
+	MessageUserPaymentLine     = "🏷️ %s — %.2f %s к оплате %s"
+	MessageUserPaymentBase     = "   База: %.2f %s (%s → %s по курсу %.4f)"
+	MessageUserPaymentStep     = "   • %s: %+.2f"
+	MessageUserPaymentProrated = "   📅 Неполный цикл %s–%s: %d из %d дн."
+	MessageUserPaymentLeft     = "🏷️ %s — вышел(а) %s"
+	MessageUserPaymentError    = "🏷️ %s — ❌ %s"
+
+	// Сообщения участника
+	MessageMemberNotFound      = "👋 Вас пока нет в списке участников.\n\nПопросите администратора добавить вас, указав ваш Telegram ID: %d"
+	MessageMemberMenu          = "👋 Привет, %s!\n\nЗдесь можно посмотреть свои подписки, ближайшие платежи и баланс, а также сообщить об оплате.\n\nВыберите действие:"
+	MessageMemberHelp          = "📖 Справка\n\n📋 Мои подписки — ближайшие платежи и кнопка «Я оплатил(а)»\n💰 Баланс и история — начисления и платежи\n🔔 Напоминания — включить или выключить напоминания об оплате\n\nКоманды:\n/start, /menu - Главное меню\n/help - Эта справка"
+	MessageMemberSubsTitle     = "📋 Мои подписки"
+	MessageMemberSubsEmpty     = "📋 Мои подписки\n\n📭 Вы пока не участвуете ни в одной подписке."
+	MessageMemberSubLine       = "🏷️ %s\n   💳 %.2f %s до %s"
+	MessageMemberSubLeft       = "🏷️ %s — участие завершено %s"
+	MessageMemberSubError      = "🏷️ %s — ❌ %s"
+	MessageMemberBalanceTitle  = "💰 Баланс: %s %s"
+	MessageMemberBalanceDebt   = "Долг к оплате: %s %s"
+	MessageMemberBalanceCredit = "Переплата: %s %s — зачтётся в следующие платежи"
+	MessageMemberBalanceZero   = "Задолженности нет"
+	MessageMemberHistoryTitle  = "🧾 Последние операции:"
+	MessageMemberHistoryEmpty  = "🧾 Операций за последние %d дней нет"
+	MessageMemberHistoryLine   = "%s  %s  %s"
+	MessageMemberPaidSent      = "✅ Сообщение об оплате %s (%s %s) отправлено администратору.\n\nБаланс обновится после подтверждения."
+	MessageMemberPaidApproved  = "✅ Оплата %s (%s %s) подтверждена. Спасибо!"
+	MessageMemberPaidRejected  = "❌ Оплата %s (%s %s) не подтверждена администратором.\n\n📝 Причина: %s"
+	MessageMemberRemindersOn   = "🔔 Напоминания включены\n\nЯ напомню о платеже за несколько дней до даты списания и после неё, если оплата не поступит."
+	MessageMemberRemindersOff  = "🔕 Напоминания выключены\n\nО платежах можно узнать в разделе «Мои подписки»."
+	MessageMemberQuietHours    = "🌙 Тихие часы: %s — в это время напоминания не приходят"
+
+	// Сообщения об оплате
+	MessageClaimAskAmount       = "💳 Оплата %s\n\nПо расчёту к оплате %s %s. Нажмите кнопку, если заплатили эту сумму, или отправьте сумму, которую перевели (например, 499.90):"
+	MessageClaimAskMethod       = "💳 Оплата %s: %s %s\n\nКак вы оплатили?"
+	MessageClaimAskReceipt      = "🧾 Отправьте фото чека или нажмите «Без чека»"
+	MessageClaimUseButtons      = "Пожалуйста, выберите способ оплаты кнопками выше."
+	MessageClaimExpired         = "Сообщение об оплате устарело. Откройте «Мои подписки» и начните заново."
+	MessageClaimForAdmin        = "💳 Сообщение об оплате\n\n👤 %s\n🏷️ %s\n💰 %s %s\n💳 %s\n🕒 %s\n\nПодтвердить платёж?"
+	MessageClaimAskRejectReason = "📝 Напишите причину отказа — её получит участник:"
+	MessageClaimRejectCancelled = "Отказ отменён, сообщение об оплате ждёт решения."
+	MessageClaimApproved        = "✅ Платёж подтверждён\n\n👤 %s\n🏷️ %s\n💰 %s %s"
+	MessageClaimRejected        = "❌ Оплата отклонена\n\n👤 %s\n🏷️ %s\n💰 %s %s\n📝 %s"
+	MessageClaimNotFound        = "Сообщение об оплате уже обработано другим администратором."
+
+	// Сообщения истории изменений
+	MessageHistoryTitle  = "🕘 История изменений"
+	MessageHistoryEmpty  = "🕘 История изменений\n\n📭 Изменений пока нет."
+	MessageHistoryEntry  = "🕒 %s — %s (%s)"
+	MessageHistoryChange = "   • %s: %s → %s"
+	MessageHistoryMore   = "Показаны последние %d изменений."
+
 	// Сообщения редактирования полей
 	MessageEditSubscriptionNamePrompt   = "📝 Введите новое название подписки:"
 	MessageEditSubscriptionPricePrompt  = "💰 Введите новую цену подписки:"
@@ -98,4 +151,16 @@ const (
 	ButtonEditUsername       = "📱 Username"
 	ButtonToggleStatus       = "🔄 Статус"
 	ButtonToggleRole         = "🔑 Роль"
+	ButtonUserPayments       = "💳 Расчёт платежей"
+	ButtonHistory            = "🕘 История"
+	ButtonMemberPaid         = "✅ Я оплатил(а): %s"
+	ButtonMemberMenu         = "🏠 Меню"
+	ButtonRemindersOn        = "🔔 Включить напоминания"
+	ButtonRemindersOff       = "🔕 Выключить напоминания"
+	ButtonClaimAmount        = "✅ Оплатил(а) %s %s"
+	ButtonClaimSkipReceipt   = "➡️ Без чека"
+	ButtonMethodCard         = "💳 Картой"
+	ButtonMethodTransfer     = "🏦 Переводом / СБП"
+	ButtonMethodCash         = "💵 Наличными"
+	ButtonMethodOther        = "❔ Другое"
 )
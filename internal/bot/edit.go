@@ -1,24 +1,26 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
-	"github.com/WhoYa/subscription-manager/internal/bot/api"
 	"github.com/WhoYa/subscription-manager/internal/bot/keyboards"
 	"github.com/WhoYa/subscription-manager/internal/bot/types"
+	"github.com/WhoYa/subscription-manager/pkg/client"
 )
 
 // handleEditSubscription показывает список подписок для редактирования
 func (b *Bot) handleEditSubscription(chatID int64, messageID int) {
-	subscriptions, err := b.Context.APIClient.GetSubscriptions(25, 0)
+	page, err := b.Context.APIClient.ListSubscriptions(context.Background(), client.ListOptions{Limit: 25})
 	if err != nil {
 		b.sendErrorMessage(chatID, messageID, err, "manage_subscriptions")
 		return
 	}
+	subscriptions := page.Items
 
 	if len(subscriptions) == 0 {
 		keyboard := tgbotapi.NewInlineKeyboardMarkup(
@@ -39,7 +41,7 @@ func (b *Bot) handleEditSubscription(chatID int64, messageID int) {
 
 	for _, sub := range subscriptions {
 		status := getSubscriptionStatus(sub.IsActive)
-		buttonText := fmt.Sprintf("%s %s (%.2f %s)", status, sub.ServiceName, sub.BasePrice, sub.BaseCurrency)
+		buttonText := fmt.Sprintf("%s %s (%.2f %s)", status, sub.ServiceName, sub.BasePrice.Float64(), sub.BaseCurrency)
 		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(buttonText, fmt.Sprintf("edit_sub_%s", sub.ID)),
 		))
@@ -56,11 +58,12 @@ func (b *Bot) handleEditSubscription(chatID int64, messageID int) {
 
 // handleEditUser показывает список пользователей для редактирования
 func (b *Bot) handleEditUser(chatID int64, messageID int) {
-	users, err := b.Context.APIClient.GetUsers(25, 0)
+	page, err := b.Context.APIClient.ListUsers(context.Background(), client.ListOptions{Limit: 25})
 	if err != nil {
 		b.sendErrorMessage(chatID, messageID, err, "manage_users")
 		return
 	}
+	users := page.Items
 
 	if len(users) == 0 {
 		keyboard := tgbotapi.NewInlineKeyboardMarkup(
@@ -102,7 +105,7 @@ func (b *Bot) handleEditUser(chatID int64, messageID int) {
 
 // showSubscriptionEditMenu показывает меню редактирования конкретной подписки
 func (b *Bot) showSubscriptionEditMenu(chatID int64, messageID int, subscriptionID string) {
-	subscription, err := b.Context.APIClient.GetSubscription(subscriptionID)
+	subscription, err := b.Context.APIClient.GetSubscription(context.Background(), subscriptionID)
 	if err != nil {
 		b.sendErrorMessage(chatID, messageID, err, "edit_subscription")
 		return
@@ -110,7 +113,7 @@ func (b *Bot) showSubscriptionEditMenu(chatID int64, messageID int, subscription
 
 	status := getSubscriptionStatus(subscription.IsActive)
 
-	text := fmt.Sprintf(MessageEditSubscriptionMenu, subscription.ServiceName, subscription.BasePrice, subscription.BaseCurrency, subscription.PeriodDays, status)
+	text := fmt.Sprintf(MessageEditSubscriptionMenu, subscription.ServiceName, subscription.BasePrice.Float64(), subscription.BaseCurrency, subscription.PeriodDays, status)
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
@@ -128,6 +131,9 @@ func (b *Bot) showSubscriptionEditMenu(chatID int64, messageID int, subscription
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(ButtonToggleStatus, fmt.Sprintf("toggle_sub_status_%s", subscriptionID)),
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ButtonHistory, fmt.Sprintf("history_sub_%s", subscriptionID)),
+		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(ButtonBack, "edit_subscription"),
 		),
@@ -138,7 +144,7 @@ func (b *Bot) showSubscriptionEditMenu(chatID int64, messageID int, subscription
 
 // showUserEditMenu показывает меню редактирования конкретного пользователя
 func (b *Bot) showUserEditMenu(chatID int64, messageID int, userID string) {
-	user, err := b.Context.APIClient.GetUser(userID)
+	user, err := b.Context.APIClient.GetUser(context.Background(), userID)
 	if err != nil {
 		b.sendErrorMessage(chatID, messageID, err, "edit_user")
 		return
@@ -159,6 +165,12 @@ func (b *Bot) showUserEditMenu(chatID int64, messageID int, userID string) {
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(ButtonToggleRole, fmt.Sprintf("toggle_user_admin_%s", userID)),
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ButtonUserPayments, fmt.Sprintf("calc_user_%s", userID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ButtonHistory, fmt.Sprintf("history_user_%s", userID)),
+		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(ButtonBack, "edit_user"),
 		),
@@ -182,11 +194,11 @@ func (b *Bot) handleEditSubscriptionNameInput(message *tgbotapi.Message) {
 	}
 
 	// Обновляем подписку
-	req := api.UpdateSubscriptionRequest{
+	req := client.UpdateSubscriptionRequest{
 		ServiceName: &newName,
 	}
 
-	subscription, err := b.Context.APIClient.UpdateSubscription(userState.EditData.EntityID, req)
+	subscription, err := b.Context.APIClient.UpdateSubscription(actorCtx(message.From.ID), userState.EditData.EntityID, req)
 	if err != nil {
 		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Ошибка при обновлении подписки: %v", err))
 		return
@@ -209,19 +221,18 @@ func (b *Bot) handleEditSubscriptionPriceInput(message *tgbotapi.Message) {
 		return
 	}
 
-	priceStr := strings.TrimSpace(message.Text)
-	price, err := strconv.ParseFloat(priceStr, 64)
-	if err != nil || price <= 0 {
-		b.sendMessage(message.Chat.ID, "❌ Неверный формат цены. Введите число больше 0:")
+	basePrice, err := parsePrice(message.Text)
+	if err != nil {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ %v. Введите цену больше 0:", err))
 		return
 	}
 
 	// Обновляем подписку
-	req := api.UpdateSubscriptionRequest{
-		BasePrice: &price,
+	req := client.UpdateSubscriptionRequest{
+		BasePrice: &basePrice,
 	}
 
-	subscription, err := b.Context.APIClient.UpdateSubscription(userState.EditData.EntityID, req)
+	subscription, err := b.Context.APIClient.UpdateSubscription(actorCtx(message.From.ID), userState.EditData.EntityID, req)
 	if err != nil {
 		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Ошибка при обновлении подписки: %v", err))
 		return
@@ -231,7 +242,7 @@ func (b *Bot) handleEditSubscriptionPriceInput(message *tgbotapi.Message) {
 	userState.State = types.StateIdle
 	userState.EditData = nil
 
-	text := fmt.Sprintf("✅ Цена подписки обновлена: %.2f %s", subscription.BasePrice, subscription.BaseCurrency)
+	text := fmt.Sprintf("✅ Цена подписки обновлена: %.2f %s", subscription.BasePrice.Float64(), subscription.BaseCurrency)
 	msg := tgbotapi.NewMessage(message.Chat.ID, text)
 	msg.ReplyMarkup = keyboards.BackKeyboard(fmt.Sprintf("edit_sub_%s", subscription.ID))
 	b.API.Send(msg)
@@ -252,11 +263,11 @@ func (b *Bot) handleEditSubscriptionPeriodInput(message *tgbotapi.Message) {
 	}
 
 	// Обновляем подписку
-	req := api.UpdateSubscriptionRequest{
+	req := client.UpdateSubscriptionRequest{
 		PeriodDays: &period,
 	}
 
-	subscription, err := b.Context.APIClient.UpdateSubscription(userState.EditData.EntityID, req)
+	subscription, err := b.Context.APIClient.UpdateSubscription(actorCtx(message.From.ID), userState.EditData.EntityID, req)
 	if err != nil {
 		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Ошибка при обновлении подписки: %v", err))
 		return
@@ -287,11 +298,11 @@ func (b *Bot) handleEditUserFullnameInput(message *tgbotapi.Message) {
 	}
 
 	// Обновляем пользователя
-	req := api.UpdateUserRequest{
+	req := client.UpdateUserRequest{
 		Fullname: &newFullname,
 	}
 
-	user, err := b.Context.APIClient.UpdateUser(userState.EditData.EntityID, req)
+	user, err := b.Context.APIClient.UpdateUser(actorCtx(message.From.ID), userState.EditData.EntityID, req)
 	if err != nil {
 		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Ошибка при обновлении пользователя: %v", err))
 		return
@@ -318,11 +329,11 @@ func (b *Bot) handleEditUserUsernameInput(message *tgbotapi.Message) {
 	// Username может быть пустым
 
 	// Обновляем пользователя
-	req := api.UpdateUserRequest{
+	req := client.UpdateUserRequest{
 		Username: &newUsername,
 	}
 
-	user, err := b.Context.APIClient.UpdateUser(userState.EditData.EntityID, req)
+	user, err := b.Context.APIClient.UpdateUser(actorCtx(message.From.ID), userState.EditData.EntityID, req)
 	if err != nil {
 		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Ошибка при обновлении пользователя: %v", err))
 		return
@@ -448,8 +459,14 @@ func (b *Bot) startEditSubscriptionCurrency(userID, chatID int64, subscriptionID
 		EntityID:   subscriptionID,
 	}
 
+	codes, err := b.currencyCodes()
+	if err != nil {
+		b.sendErrorMessage(chatID, 0, err, fmt.Sprintf("edit_sub_%s", subscriptionID))
+		return
+	}
+
 	text := "💱 Выберите новую валюту:"
-	keyboard := keyboards.CurrencyKeyboard()
+	keyboard := keyboards.CurrencyKeyboard(codes)
 	b.sendMessageWithKeyboard(chatID, text, &keyboard)
 }
 
@@ -489,18 +506,18 @@ func (b *Bot) startEditUserUsername(userID, chatID int64, targetUserID string) {
 
 // Методы для переключения статусов
 func (b *Bot) toggleSubscriptionStatus(chatID int64, subscriptionID string) {
-	subscription, err := b.Context.APIClient.GetSubscription(subscriptionID)
+	subscription, err := b.Context.APIClient.GetSubscription(context.Background(), subscriptionID)
 	if err != nil {
 		b.sendMessage(chatID, fmt.Sprintf("❌ Ошибка при загрузке подписки: %v", err))
 		return
 	}
 
 	newStatus := !subscription.IsActive
-	req := api.UpdateSubscriptionRequest{
+	req := client.UpdateSubscriptionRequest{
 		IsActive: &newStatus,
 	}
 
-	updatedSubscription, err := b.Context.APIClient.UpdateSubscription(subscriptionID, req)
+	updatedSubscription, err := b.Context.APIClient.UpdateSubscription(actorCtx(chatID), subscriptionID, req)
 	if err != nil {
 		b.sendMessage(chatID, fmt.Sprintf("❌ Ошибка при обновлении статуса: %v", err))
 		return
@@ -518,18 +535,18 @@ func (b *Bot) toggleSubscriptionStatus(chatID int64, subscriptionID string) {
 }
 
 func (b *Bot) toggleUserAdminStatus(chatID int64, userID string) {
-	user, err := b.Context.APIClient.GetUser(userID)
+	user, err := b.Context.APIClient.GetUser(context.Background(), userID)
 	if err != nil {
 		b.sendMessage(chatID, fmt.Sprintf("❌ Ошибка при загрузке пользователя: %v", err))
 		return
 	}
 
 	newStatus := !user.IsAdmin
-	req := api.UpdateUserRequest{
+	req := client.UpdateUserRequest{
 		IsAdmin: &newStatus,
 	}
 
-	updatedUser, err := b.Context.APIClient.UpdateUser(userID, req)
+	updatedUser, err := b.Context.APIClient.UpdateUser(actorCtx(chatID), userID, req)
 	if err != nil {
 		b.sendMessage(chatID, fmt.Sprintf("❌ Ошибка при обновлении роли: %v", err))
 		return
@@ -1,15 +1,18 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
-	"github.com/WhoYa/subscription-manager/internal/bot/api"
 	"github.com/WhoYa/subscription-manager/internal/bot/keyboards"
 	"github.com/WhoYa/subscription-manager/internal/bot/types"
+	"github.com/WhoYa/subscription-manager/pkg/apierr"
+	"github.com/WhoYa/subscription-manager/pkg/client"
+	"github.com/WhoYa/subscription-manager/pkg/db"
 )
 
 // Subscription handlers
@@ -65,7 +68,7 @@ func (b *Bot) handleSubscriptionPriceInput(message *tgbotapi.Message) {
 	log.Printf("SUBSCRIPTION_PRICE: Processing input from user %d: %s", message.From.ID, message.Text)
 	log.Printf("SUBSCRIPTION_PRICE: Current SubscriptionData: %+v", userState.SubscriptionData)
 
-	price, err := validateFloat64(message.Text, 0.01)
+	price, err := parsePrice(message.Text)
 	if err != nil {
 		log.Printf("SUBSCRIPTION_PRICE: Validation failed for user %d: %v", message.From.ID, err)
 		text := fmt.Sprintf(MessageSubscriptionPriceError, userState.SubscriptionData.ServiceName)
@@ -82,11 +85,16 @@ func (b *Bot) handleSubscriptionPriceInput(message *tgbotapi.Message) {
 	userState.SubscriptionData.BasePrice = price
 	userState.State = types.StateAwaitingSubscriptionCurrency
 
-	log.Printf("SUBSCRIPTION_PRICE: Set price for user %d: %.2f", message.From.ID, price)
+	log.Printf("SUBSCRIPTION_PRICE: Set price for user %d: %s", message.From.ID, price)
 	log.Printf("SUBSCRIPTION_PRICE: Updated SubscriptionData: %+v", userState.SubscriptionData)
 
-	text := fmt.Sprintf(MessageSubscriptionCurrencyStep, userState.SubscriptionData.ServiceName, price)
-	keyboard := keyboards.CurrencyKeyboardWithNav()
+	codes, err := b.currencyCodes()
+	if err != nil {
+		b.sendErrorMessage(userState.CurrentChatID, userState.CurrentMessageID, err, "manage_subscriptions")
+		return
+	}
+	text := fmt.Sprintf(MessageSubscriptionCurrencyStep, userState.SubscriptionData.ServiceName, price.StringFixed(2))
+	keyboard := keyboards.CurrencyKeyboardWithNav(codes)
 	b.editMessage(userState.CurrentChatID, userState.CurrentMessageID, text, &keyboard)
 }
 
@@ -100,7 +108,7 @@ func (b *Bot) handleSubscriptionPeriodInput(message *tgbotapi.Message) {
 	period, err := validateInt(message.Text, 1)
 	if err != nil {
 		log.Printf("SUBSCRIPTION_PERIOD: Validation failed for user %d: %v", message.From.ID, err)
-		text := fmt.Sprintf(MessageSubscriptionPeriodError, userState.SubscriptionData.ServiceName, userState.SubscriptionData.BasePrice, userState.SubscriptionData.BaseCurrency)
+		text := fmt.Sprintf(MessageSubscriptionPeriodError, userState.SubscriptionData.ServiceName, userState.SubscriptionData.BasePrice.StringFixed(2), userState.SubscriptionData.BaseCurrency)
 		keyboard := keyboards.CreateProcessKeyboard("process")
 		b.editMessage(userState.CurrentChatID, userState.CurrentMessageID, text, &keyboard)
 		return
@@ -118,7 +126,7 @@ func (b *Bot) handleSubscriptionPeriodInput(message *tgbotapi.Message) {
 
 	// Показываем итоговую информацию и просим подтверждения
 	data := userState.SubscriptionData
-	text := fmt.Sprintf(MessageSubscriptionConfirm, data.ServiceName, data.BasePrice, data.BaseCurrency, data.PeriodDays)
+	text := fmt.Sprintf(MessageSubscriptionConfirm, data.ServiceName, data.BasePrice.StringFixed(2), data.BaseCurrency, data.PeriodDays)
 
 	keyboard := keyboards.CreateConfirmKeyboard("create_subscription")
 	b.editMessage(userState.CurrentChatID, userState.CurrentMessageID, text, &keyboard)
@@ -242,10 +250,10 @@ func (b *Bot) startEditGlobalMarkup(userID, chatID int64, messageID int) {
 	userState.CurrentChatID = chatID
 
 	// Получаем текущие настройки для отображения
-	settings, err := b.Context.APIClient.GetGlobalSettings()
+	settings, err := b.Context.APIClient.GetGlobalSettings(context.Background())
 	currentMarkup := StatusUnknown
 	if err == nil {
-		currentMarkup = fmt.Sprintf("%.2f%%", settings.GlobalMarkupPercent)
+		currentMarkup = fmt.Sprintf("%.2f%%", settings.GlobalMarkupPercent.Float64())
 	}
 
 	text := fmt.Sprintf(MessageGlobalMarkupStart, currentMarkup)
@@ -258,36 +266,37 @@ func (b *Bot) startEditGlobalMarkup(userID, chatID int64, messageID int) {
 func (b *Bot) handleGlobalMarkupInput(message *tgbotapi.Message) {
 	userState := b.getUserState(message.From.ID)
 
-	markup, err := validateFloat64(message.Text, 0)
+	markup, err := parsePercent(message.Text)
 	if err != nil {
 		keyboard := keyboards.CreateProcessKeyboard("process")
 		b.editMessage(userState.CurrentChatID, userState.CurrentMessageID, MessageGlobalMarkupError, &keyboard)
 		return
 	}
 
-	logInfo("GlobalMarkup", fmt.Sprintf("User input: %s, parsed value: %.2f%%", message.Text, markup))
+	logInfo("GlobalMarkup", fmt.Sprintf("User input: %s, parsed value: %s%%", message.Text, markup))
 
 	// Сначала пытаемся обновить настройки
-	updateReq := api.UpdateGlobalSettingsRequest{
+	updateReq := client.GlobalSettingsRequest{
 		GlobalMarkupPercent: markup,
 	}
 
 	logInfo("GlobalMarkup", fmt.Sprintf("Sending update request: %+v", updateReq))
 
-	settings, err := b.Context.APIClient.UpdateGlobalSettings(updateReq)
+	settings, err := b.Context.APIClient.UpdateGlobalSettings(actorCtx(message.From.ID), updateReq)
 	if err != nil {
 		logError("UpdateGlobalSettings", err)
 
-		// Если ошибка 404, значит настройки не существуют, создаем их
-		if strings.Contains(err.Error(), "404") || strings.Contains(err.Error(), "not found") {
+		// Если настроек ещё нет, создаем их
+		if client.ErrorCode(err) == apierr.CodeSettingsNotFound {
 			logInfo("GlobalMarkup", "Settings not found, creating new ones")
-			createReq := api.CreateGlobalSettingsRequest{
+			createReq := client.GlobalSettingsRequest{
 				GlobalMarkupPercent: markup,
 			}
 
 			logInfo("GlobalMarkup", fmt.Sprintf("Sending create request: %+v", createReq))
 
-			settings, err = b.Context.APIClient.CreateGlobalSettings(createReq)
+			settings, err = b.Context.APIClient.CreateGlobalSettings(
+				confirmCtx(message.From.ID, message.Chat.ID, message.MessageID, "create_settings", createReq), createReq)
 			if err != nil {
 				logError("CreateGlobalSettings", err)
 				errorText := fmt.Sprintf(MessageError, handleAPIError(err, "CreateGlobalSettings"))
@@ -307,16 +316,16 @@ func (b *Bot) handleGlobalMarkupInput(message *tgbotapi.Message) {
 
 	// Проверяем, что settings не nil перед использованием
 	if settings != nil {
-		logInfo("GlobalMarkup", fmt.Sprintf("Settings saved successfully. Returned value: %.2f%%", settings.GlobalMarkupPercent))
-		text := fmt.Sprintf(MessageGlobalMarkupSet, settings.GlobalMarkupPercent)
-		logInfo("GlobalMarkup", fmt.Sprintf("Showing confirmation with value: %.2f%%", settings.GlobalMarkupPercent))
+		logInfo("GlobalMarkup", fmt.Sprintf("Settings saved successfully. Returned value: %.2f%%", settings.GlobalMarkupPercent.Float64()))
+		text := fmt.Sprintf(MessageGlobalMarkupSet, settings.GlobalMarkupPercent.Float64())
+		logInfo("GlobalMarkup", fmt.Sprintf("Showing confirmation with value: %.2f%%", settings.GlobalMarkupPercent.Float64()))
 		keyboard := keyboards.CreateSuccessKeyboard("global_settings")
 		b.editMessage(userState.CurrentChatID, userState.CurrentMessageID, text, &keyboard)
 	} else {
 		logError("GlobalMarkup", fmt.Errorf("settings is nil"))
 		// Показываем сообщение с введенным значением, если нет данных от сервера
-		text := fmt.Sprintf(MessageGlobalMarkupSetWithNote, markup)
-		logInfo("GlobalMarkup", fmt.Sprintf("Showing confirmation with input value: %.2f%%", markup))
+		text := fmt.Sprintf(MessageGlobalMarkupSetWithNote, markup.Float64())
+		logInfo("GlobalMarkup", fmt.Sprintf("Showing confirmation with input value: %s%%", markup))
 		keyboard := keyboards.CreateSuccessKeyboard("global_settings")
 		b.editMessage(userState.CurrentChatID, userState.CurrentMessageID, text, &keyboard)
 	}
@@ -354,16 +363,17 @@ func (b *Bot) confirmCreateSubscription(userID, _ int64, userState *types.UserDa
 	log.Printf("Subscription data for user %d: %+v", userID, data)
 
 	// Создаем подписку через API
-	req := api.CreateSubscriptionRequest{
+	req := client.CreateSubscriptionRequest{
 		ServiceName:  data.ServiceName,
 		BasePrice:    data.BasePrice,
-		BaseCurrency: data.BaseCurrency,
+		BaseCurrency: db.Currency(data.BaseCurrency),
 		PeriodDays:   data.PeriodDays,
 	}
 
 	log.Printf("Creating subscription request: %+v", req)
 
-	subscription, err := b.Context.APIClient.CreateSubscription(req)
+	subscription, err := b.Context.APIClient.CreateSubscription(
+		confirmCtx(userID, userState.CurrentChatID, userState.CurrentMessageID, "create_subscription", req), req)
 	if err != nil {
 		log.Printf("ERROR: Failed to create subscription for user %d: %v", userID, err)
 		errorText := fmt.Sprintf(MessageSubscriptionCreateError, handleAPIError(err, "CreateSubscription"))
@@ -374,7 +384,7 @@ func (b *Bot) confirmCreateSubscription(userID, _ int64, userState *types.UserDa
 
 	log.Printf("Subscription created successfully: %+v", subscription)
 
-	text := fmt.Sprintf(MessageSubscriptionCreated, subscription.ServiceName, subscription.BasePrice, subscription.BaseCurrency, subscription.PeriodDays, subscription.ID)
+	text := fmt.Sprintf(MessageSubscriptionCreated, subscription.ServiceName, subscription.BasePrice.Float64(), subscription.BaseCurrency, subscription.PeriodDays, subscription.ID)
 
 	// Сбрасываем состояние
 	b.resetUserState(userID)
@@ -398,7 +408,7 @@ func (b *Bot) confirmCreateUser(userID, _ int64, userState *types.UserData) {
 	log.Printf("User create data for user %d: %+v", userID, data)
 
 	// Создаем пользователя через API
-	req := api.CreateUserRequest{
+	req := client.CreateUserRequest{
 		TGID:     data.TGID,
 		Username: data.Username,
 		Fullname: data.Fullname,
@@ -407,7 +417,8 @@ func (b *Bot) confirmCreateUser(userID, _ int64, userState *types.UserData) {
 
 	log.Printf("Creating user request: %+v", req)
 
-	user, err := b.Context.APIClient.CreateUser(req)
+	user, err := b.Context.APIClient.CreateUser(
+		confirmCtx(userID, userState.CurrentChatID, userState.CurrentMessageID, "create_user", req), req)
 	if err != nil {
 		log.Printf("ERROR: Failed to create user for user %d: %v", userID, err)
 		errorText := fmt.Sprintf(MessageUserCreateError, handleAPIError(err, "CreateUser"))
@@ -488,8 +499,13 @@ func (b *Bot) handleStepBack(userID, chatID int64) {
 
 	case types.StateAwaitingSubscriptionPeriod:
 		userState.State = types.StateAwaitingSubscriptionCurrency
-		text := fmt.Sprintf("📝 Создание новой подписки\n\n**Шаг 3/4:** Выберите валюту\n\n✅ Название: %s\n✅ Цена: %.2f", userState.SubscriptionData.ServiceName, userState.SubscriptionData.BasePrice)
-		keyboard := keyboards.CurrencyKeyboardWithNav()
+		text := fmt.Sprintf("📝 Создание новой подписки\n\n**Шаг 3/4:** Выберите валюту\n\n✅ Название: %s\n✅ Цена: %s", userState.SubscriptionData.ServiceName, userState.SubscriptionData.BasePrice.StringFixed(2))
+		codes, err := b.currencyCodes()
+		if err != nil {
+			b.sendErrorMessage(userState.CurrentChatID, userState.CurrentMessageID, err, "manage_subscriptions")
+			return
+		}
+		keyboard := keyboards.CurrencyKeyboardWithNav(codes)
 		b.editMessage(userState.CurrentChatID, userState.CurrentMessageID, text, &keyboard)
 
 	// Создание пользователя
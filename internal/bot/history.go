@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/WhoYa/subscription-manager/pkg/client"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+const (
+	// auditHistoryLimit сколько последних изменений показывает кнопка «История»
+	auditHistoryLimit = 10
+	// auditValueLength длиннее значение поля обрезается, чтобы сообщение влезло в лимит Telegram
+	auditValueLength = 40
+)
+
+// auditActions названия действий журнала аудита
+var auditActions = map[db.AuditAction]string{
+	db.AuditCreate:  "создание",
+	db.AuditUpdate:  "изменение",
+	db.AuditDelete:  "удаление",
+	db.AuditRestore: "восстановление",
+}
+
+// showEntityHistory показывает последние изменения записи из журнала аудита
+func (b *Bot) showEntityHistory(query *tgbotapi.CallbackQuery, entity db.AuditEntity, entityID, backAction string) {
+	chatID, messageID := query.Message.Chat.ID, query.Message.MessageID
+
+	admin, err := b.getAdminUser(query.From.ID)
+	if err != nil {
+		b.sendErrorMessage(chatID, messageID, err, backAction)
+		return
+	}
+	page, err := b.Context.APIClient.ListAudit(context.Background(), admin.ID, client.AuditFilter{
+		Entity:      entity,
+		EntityID:    entityID,
+		ListOptions: client.ListOptions{Limit: auditHistoryLimit},
+	})
+	if err != nil {
+		b.sendErrorMessage(chatID, messageID, err, backAction)
+		return
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ButtonBack, backAction),
+		),
+	)
+	if len(page.Items) == 0 {
+		b.editMessage(chatID, messageID, MessageHistoryEmpty, &keyboard)
+		return
+	}
+
+	lines := []string{MessageHistoryTitle}
+	for _, entry := range page.Items {
+		lines = append(lines, "", fmt.Sprintf(MessageHistoryEntry,
+			formatDateTime(entry.CreatedAt), auditActions[entry.Action], formatAuditActor(entry)))
+		if entry.Action != db.AuditUpdate {
+			continue // при создании и удалении все поля меняются, список ничего не добавит
+		}
+
+		fields := make([]string, 0, len(entry.Changes))
+		for field := range entry.Changes {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			change := entry.Changes[field]
+			lines = append(lines, fmt.Sprintf(MessageHistoryChange,
+				field, formatAuditValue(change.Before), formatAuditValue(change.After)))
+		}
+	}
+	if page.NextCursor != "" {
+		lines = append(lines, "", fmt.Sprintf(MessageHistoryMore, auditHistoryLimit))
+	}
+
+	b.editMessage(chatID, messageID, strings.Join(lines, "\n"), &keyboard)
+}
+
+// formatAuditActor кто внёс изменение: ключ и Telegram ID администратора, если бот его передал
+func formatAuditActor(entry db.AuditEntry) string {
+	actor := entry.ActorName
+	if actor == "" {
+		actor = StatusUnknown
+	}
+	if entry.ActorTGID != nil {
+		actor += fmt.Sprintf(", TG %d", *entry.ActorTGID)
+	}
+	return actor
+}
+
+// formatAuditValue значение поля из журнала в одну короткую строку
+func formatAuditValue(v any) string {
+	var s string
+	switch x := v.(type) {
+	case nil:
+		return "—"
+	case string:
+		s = x
+	case float64:
+		s = strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		s = strconv.FormatBool(x)
+	default:
+		raw, _ := json.Marshal(x)
+		s = string(raw)
+	}
+	if r := []rune(s); len(r) > auditValueLength {
+		s = string(r[:auditValueLength]) + "…"
+	}
+	return s
+}
@@ -24,6 +24,48 @@ func MainAdminKeyboard() tgbotapi.InlineKeyboardMarkup {
 	)
 }
 
+// MemberKeyboard меню участника
+func MemberKeyboard() tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📋 Мои подписки", "member_subs"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("💰 Баланс и история", "member_balance"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔔 Напоминания", "member_reminders"),
+		),
+	)
+}
+
+// PaymentMethodKeyboard выбор способа оплаты в сообщении об оплате
+func PaymentMethodKeyboard() tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("💳 Картой", "member_claim_method_card"),
+			tgbotapi.NewInlineKeyboardButtonData("🏦 Переводом / СБП", "member_claim_method_transfer"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("💵 Наличными", "member_claim_method_cash"),
+			tgbotapi.NewInlineKeyboardButtonData("❔ Другое", "member_claim_method_other"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "member_menu"),
+		),
+	)
+}
+
+// PaymentClaimKeyboard решение администратора по сообщению об оплате
+func PaymentClaimKeyboard(claimID string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Подтвердить", fmt.Sprintf("claim_ok_%s", claimID)),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отклонить", fmt.Sprintf("claim_no_%s", claimID)),
+		),
+	)
+}
+
 // SubscriptionManagementKeyboard меню управления подписками
 func SubscriptionManagementKeyboard() tgbotapi.InlineKeyboardMarkup {
 	return tgbotapi.NewInlineKeyboardMarkup(
@@ -99,17 +141,26 @@ func AnalyticsKeyboard() tgbotapi.InlineKeyboardMarkup {
 	)
 }
 
-// CurrencyKeyboard выбор валюты
-func CurrencyKeyboard() tgbotapi.InlineKeyboardMarkup {
-	return tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("💵 USD", "currency_USD"),
-			tgbotapi.NewInlineKeyboardButtonData("💶 EUR", "currency_EUR"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "cancel"),
-		),
-	)
+// CurrencyKeyboard выбор валюты из справочника
+func CurrencyKeyboard(codes []string) tgbotapi.InlineKeyboardMarkup {
+	rows := currencyRows(codes)
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "cancel"),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// currencyRows кнопки валют по три в ряд
+func currencyRows(codes []string) [][]tgbotapi.InlineKeyboardButton {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(codes); i += 3 {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, code := range codes[i:min(i+3, len(codes))] {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData("💱 "+code, "currency_"+code))
+		}
+		rows = append(rows, row)
+	}
+	return rows
 }
 
 // ConfirmKeyboard подтверждение действия
@@ -256,18 +307,14 @@ func CreateSuccessKeyboard(backTo string) tgbotapi.InlineKeyboardMarkup {
 	)
 }
 
-// CurrencyKeyboardWithNav выбор валюты с навигацией
-func CurrencyKeyboardWithNav() tgbotapi.InlineKeyboardMarkup {
-	return tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("💵 USD", "currency_USD"),
-			tgbotapi.NewInlineKeyboardButtonData("💶 EUR", "currency_EUR"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("◀️ Назад", "step_back"),
-			tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "cancel"),
-		),
-	)
+// CurrencyKeyboardWithNav выбор валюты из справочника с навигацией
+func CurrencyKeyboardWithNav(codes []string) tgbotapi.InlineKeyboardMarkup {
+	rows := currencyRows(codes)
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("◀️ Назад", "step_back"),
+		tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "cancel"),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
 // BackToMenuKeyboard кнопка для возврата в определенное меню
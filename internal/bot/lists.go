@@ -1,6 +1,7 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -8,17 +9,20 @@ import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"github.com/WhoYa/subscription-manager/internal/bot/keyboards"
+	"github.com/WhoYa/subscription-manager/pkg/client"
+	"github.com/WhoYa/subscription-manager/pkg/money"
 )
 
 // Subscriptions list handlers
 
 // handleListSubscriptions показывает список подписок
 func (b *Bot) handleListSubscriptions(chatID int64) {
-	subscriptions, err := b.Context.APIClient.GetSubscriptions(25, 0)
+	page, err := b.Context.APIClient.ListSubscriptions(context.Background(), client.ListOptions{Limit: 25})
 	if err != nil {
 		b.sendMessage(chatID, fmt.Sprintf("❌ Ошибка при загрузке подписок: %v", err))
 		return
 	}
+	subscriptions := page.Items
 
 	if len(subscriptions) == 0 {
 		text := `
@@ -54,7 +58,7 @@ func (b *Bot) handleListSubscriptions(chatID int64) {
 
 		textBuilder.WriteString(fmt.Sprintf("%d. %s %s\n", i+1, status, sub.ServiceName))
 		textBuilder.WriteString(fmt.Sprintf("   💰 %.2f %s, период: %d дней\n",
-			sub.BasePrice, sub.BaseCurrency, sub.PeriodDays))
+			sub.BasePrice.Float64(), sub.BaseCurrency, sub.PeriodDays))
 		textBuilder.WriteString(fmt.Sprintf("   🆔 ID: %s\n\n", sub.ID))
 	}
 
@@ -82,11 +86,12 @@ func (b *Bot) handleListSubscriptions(chatID int64) {
 
 // handleListUsers показывает список пользователей
 func (b *Bot) handleListUsers(chatID int64) {
-	users, err := b.Context.APIClient.GetUsers(25, 0)
+	page, err := b.Context.APIClient.ListUsers(context.Background(), client.ListOptions{Limit: 25})
 	if err != nil {
 		b.sendMessage(chatID, fmt.Sprintf("❌ Ошибка при загрузке пользователей: %v", err))
 		return
 	}
+	users := page.Items
 
 	if len(users) == 0 {
 		text := `
@@ -160,7 +165,7 @@ func (b *Bot) handleAnalyticsTotal(chatID, adminUserID int64) {
 		return
 	}
 
-	stats, err := b.Context.APIClient.GetTotalProfit(adminUser.ID)
+	stats, err := b.Context.APIClient.GetTotalProfit(context.Background(), adminUser.ID, "")
 	if err != nil {
 		b.sendMessage(chatID, fmt.Sprintf("❌ Ошибка при загрузке статистики: %v", err))
 		return
@@ -169,10 +174,10 @@ func (b *Bot) handleAnalyticsTotal(chatID, adminUserID int64) {
 	text := fmt.Sprintf(`
 📈 Общая статистика прибыли
 
-💰 Общая прибыль: %.2f руб.
+💰 Общая прибыль: %s руб.
 🧾 Количество платежей: %d
-📊 Средняя прибыль с платежа: %.2f руб.`,
-		stats.TotalProfit, stats.PaymentCount, stats.AverageProfit)
+📊 Средняя прибыль с платежа: %s руб.`,
+		stats.TotalProfit.StringFixed(2), stats.TotalPayments, stats.AverageProfit.StringFixed(2))
 
 	keyboard := keyboards.BackKeyboard("analytics")
 
@@ -191,7 +196,7 @@ func (b *Bot) handleAnalyticsMonthly(chatID, adminUserID int64) {
 
 	// Получаем текущую дату для показа статистики за текущий месяц
 	// Для примера возьмем 2024 год, 7 месяц
-	stats, err := b.Context.APIClient.GetMonthlyProfit(adminUser.ID, 2024, 7)
+	stats, err := b.Context.APIClient.GetMonthlyProfit(context.Background(), adminUser.ID, 2024, 7, "")
 	if err != nil {
 		b.sendMessage(chatID, fmt.Sprintf("❌ Ошибка при загрузке статистики: %v", err))
 		return
@@ -200,10 +205,10 @@ func (b *Bot) handleAnalyticsMonthly(chatID, adminUserID int64) {
 	text := fmt.Sprintf(`
 📅 Статистика за июль 2024
 
-💰 Прибыль за месяц: %.2f руб.
+💰 Прибыль за месяц: %s руб.
 🧾 Количество платежей: %d
-📊 Средняя прибыль с платежа: %.2f руб.`,
-		stats.TotalProfit, stats.PaymentCount, stats.AverageProfit)
+📊 Средняя прибыль с платежа: %s руб.`,
+		stats.TotalProfit.StringFixed(2), stats.TotalPayments, stats.AverageProfit.StringFixed(2))
 
 	keyboard := keyboards.BackKeyboard("analytics")
 
@@ -222,7 +227,7 @@ func (b *Bot) handleAnalyticsUsers(chatID, adminUserID int64) {
 
 	// Получаем статистику за последний месяц
 	from, to := b.getLastMonthRange()
-	stats, err := b.Context.APIClient.GetUserProfitStats(adminUser.ID, from, to)
+	stats, err := b.Context.APIClient.GetUserProfitStats(context.Background(), adminUser.ID, from, to)
 	if err != nil {
 		b.sendMessage(chatID, fmt.Sprintf("❌ Ошибка при загрузке статистики: %v", err))
 		return
@@ -241,21 +246,21 @@ func (b *Bot) handleAnalyticsUsers(chatID, adminUserID int64) {
 
 	var textBuilder strings.Builder
 	textBuilder.WriteString("👥 Аналитика по пользователям\n")
-	textBuilder.WriteString(fmt.Sprintf("📅 Период: %s - %s\n\n", from[:10], to[:10]))
+	textBuilder.WriteString(fmt.Sprintf("📅 Период: %s - %s\n\n", from.Format(client.DateLayout), to.Format(client.DateLayout)))
 
-	totalProfit := 0.0
-	totalPayments := 0
+	var totalProfit money.Decimal
+	var totalPayments int64
 
 	for i, stat := range stats {
-		textBuilder.WriteString(fmt.Sprintf("%d. %s\n", i+1, stat.UserFullname))
-		textBuilder.WriteString(fmt.Sprintf("   💰 Прибыль: %.2f руб.\n", stat.TotalProfit))
+		textBuilder.WriteString(fmt.Sprintf("%d. %s\n", i+1, stat.Username))
+		textBuilder.WriteString(fmt.Sprintf("   💰 Прибыль: %s %s\n", stat.TotalProfit.StringFixed(2), stat.Currency))
 		textBuilder.WriteString(fmt.Sprintf("   🧾 Платежей: %d\n\n", stat.PaymentCount))
 
-		totalProfit += stat.TotalProfit
+		totalProfit = totalProfit.Add(stat.TotalProfit)
 		totalPayments += stat.PaymentCount
 	}
 
-	textBuilder.WriteString(fmt.Sprintf("📊 Итого: %.2f руб. (%d платежей)", totalProfit, totalPayments))
+	textBuilder.WriteString(fmt.Sprintf("📊 Итого: %s руб. (%d платежей)", totalProfit.StringFixed(2), totalPayments))
 
 	keyboard := keyboards.BackKeyboard("analytics")
 	msg := tgbotapi.NewMessage(chatID, textBuilder.String())
@@ -273,7 +278,7 @@ func (b *Bot) handleAnalyticsSubscriptions(chatID, adminUserID int64) {
 
 	// Получаем статистику за последний месяц
 	from, to := b.getLastMonthRange()
-	stats, err := b.Context.APIClient.GetSubscriptionProfitStats(adminUser.ID, from, to)
+	stats, err := b.Context.APIClient.GetSubscriptionProfitStats(context.Background(), adminUser.ID, from, to, "")
 	if err != nil {
 		b.sendMessage(chatID, fmt.Sprintf("❌ Ошибка при загрузке статистики: %v", err))
 		return
@@ -292,21 +297,21 @@ func (b *Bot) handleAnalyticsSubscriptions(chatID, adminUserID int64) {
 
 	var textBuilder strings.Builder
 	textBuilder.WriteString("📱 Аналитика по подпискам\n")
-	textBuilder.WriteString(fmt.Sprintf("📅 Период: %s - %s\n\n", from[:10], to[:10]))
+	textBuilder.WriteString(fmt.Sprintf("📅 Период: %s - %s\n\n", from.Format(client.DateLayout), to.Format(client.DateLayout)))
 
-	totalProfit := 0.0
-	totalPayments := 0
+	var totalProfit money.Decimal
+	var totalPayments int64
 
 	for i, stat := range stats {
-		textBuilder.WriteString(fmt.Sprintf("%d. %s\n", i+1, stat.SubscriptionName))
-		textBuilder.WriteString(fmt.Sprintf("   💰 Прибыль: %.2f руб.\n", stat.TotalProfit))
+		textBuilder.WriteString(fmt.Sprintf("%d. %s\n", i+1, stat.ServiceName))
+		textBuilder.WriteString(fmt.Sprintf("   💰 Прибыль: %s %s\n", stat.TotalProfit.StringFixed(2), stat.Currency))
 		textBuilder.WriteString(fmt.Sprintf("   🧾 Платежей: %d\n\n", stat.PaymentCount))
 
-		totalProfit += stat.TotalProfit
+		totalProfit = totalProfit.Add(stat.TotalProfit)
 		totalPayments += stat.PaymentCount
 	}
 
-	textBuilder.WriteString(fmt.Sprintf("📊 Итого: %.2f руб. (%d платежей)", totalProfit, totalPayments))
+	textBuilder.WriteString(fmt.Sprintf("📊 Итого: %s руб. (%d платежей)", totalProfit.StringFixed(2), totalPayments))
 
 	keyboard := keyboards.BackKeyboard("analytics")
 	msg := tgbotapi.NewMessage(chatID, textBuilder.String())
@@ -314,24 +319,24 @@ func (b *Bot) handleAnalyticsSubscriptions(chatID, adminUserID int64) {
 	b.API.Send(msg)
 }
 
-// getLastMonthRange возвращает диапазон дат за последний месяц в формате RFC3339
-func (b *Bot) getLastMonthRange() (string, string) {
+// getLastMonthRange возвращает диапазон дат за последний месяц
+func (b *Bot) getLastMonthRange() (time.Time, time.Time) {
 	now := time.Now()
 	from := now.AddDate(0, -1, 0) // месяц назад
-	to := now
 
-	return from.Format(time.RFC3339), to.Format(time.RFC3339)
+	return from, now
 }
 
 // Edit versions of list functions
 
 // handleListSubscriptionsEdit показывает список подписок через редактирование сообщения
 func (b *Bot) handleListSubscriptionsEdit(chatID int64, messageID int) {
-	subscriptions, err := b.Context.APIClient.GetSubscriptions(25, 0)
+	page, err := b.Context.APIClient.ListSubscriptions(context.Background(), client.ListOptions{Limit: 25})
 	if err != nil {
 		b.editMessage(chatID, messageID, fmt.Sprintf("❌ Ошибка при загрузке подписок: %v", err), nil)
 		return
 	}
+	subscriptions := page.Items
 
 	if len(subscriptions) == 0 {
 		text := `📋 Список подписок
@@ -364,7 +369,7 @@ func (b *Bot) handleListSubscriptionsEdit(chatID int64, messageID int) {
 
 		textBuilder.WriteString(fmt.Sprintf("%d. %s %s\n", i+1, status, sub.ServiceName))
 		textBuilder.WriteString(fmt.Sprintf("   💰 %.2f %s, период: %d дней\n",
-			sub.BasePrice, sub.BaseCurrency, sub.PeriodDays))
+			sub.BasePrice.Float64(), sub.BaseCurrency, sub.PeriodDays))
 		textBuilder.WriteString(fmt.Sprintf("   🆔 ID: %s\n\n", sub.ID))
 	}
 
@@ -388,11 +393,12 @@ func (b *Bot) handleListSubscriptionsEdit(chatID int64, messageID int) {
 
 // handleListUsersEdit показывает список пользователей через редактирование сообщения
 func (b *Bot) handleListUsersEdit(chatID int64, messageID int) {
-	users, err := b.Context.APIClient.GetUsers(25, 0)
+	page, err := b.Context.APIClient.ListUsers(context.Background(), client.ListOptions{Limit: 25})
 	if err != nil {
 		b.editMessage(chatID, messageID, fmt.Sprintf("❌ Ошибка при загрузке пользователей: %v", err), nil)
 		return
 	}
+	users := page.Items
 
 	if len(users) == 0 {
 		text := `📋 Список пользователей
@@ -0,0 +1,551 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/WhoYa/subscription-manager/internal/bot/keyboards"
+	"github.com/WhoYa/subscription-manager/internal/bot/types"
+	"github.com/WhoYa/subscription-manager/pkg/apierr"
+	"github.com/WhoYa/subscription-manager/pkg/client"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+// historyDays за сколько дней участнику показывается история операций
+const historyDays = 90
+
+// historyLimit сколько последних операций показывается участнику
+const historyLimit = 10
+
+// handleMemberMessage обрабатывает сообщения участников, не входящих в ADMINS
+func (b *Bot) handleMemberMessage(message *tgbotapi.Message) {
+	log.Printf("Received message from member %d: %s", message.From.ID, message.Text)
+
+	if message.IsCommand() && message.Command() == "help" {
+		b.sendSimpleMessage(message.Chat.ID, MessageMemberHelp)
+		return
+	}
+	if message.IsCommand() && message.Command() != "start" && message.Command() != "menu" {
+		b.sendSimpleMessage(message.Chat.ID, MessageUnknownCommand)
+		return
+	}
+
+	user, ok := b.memberUser(message.Chat.ID, 0, message.From.ID)
+	if !ok {
+		return
+	}
+
+	// шаги сообщения об оплате; команда прерывает его
+	userState := b.getUserState(message.From.ID)
+	if !message.IsCommand() {
+		switch userState.State {
+		case types.StateAwaitingClaimAmount:
+			b.handleClaimAmountInput(message)
+			return
+		case types.StateAwaitingClaimMethod:
+			b.sendSimpleMessage(message.Chat.ID, MessageClaimUseButtons)
+			return
+		case types.StateAwaitingClaimReceipt:
+			b.handleClaimReceiptInput(message, user)
+			return
+		}
+	}
+	b.resetUserState(message.From.ID)
+	b.showMemberMenu(message.Chat.ID, 0, user)
+}
+
+// handleMemberCallback обрабатывает нажатия кнопок участником
+func (b *Bot) handleMemberCallback(query *tgbotapi.CallbackQuery) {
+	b.answerCallbackQuery(query.ID, "")
+
+	chatID, messageID := query.Message.Chat.ID, query.Message.MessageID
+	user, ok := b.memberUser(chatID, messageID, query.From.ID)
+	if !ok {
+		return
+	}
+
+	switch {
+	case query.Data == "member_menu":
+		b.resetUserState(query.From.ID)
+		b.showMemberMenu(chatID, messageID, user)
+	case query.Data == "member_subs":
+		b.showMemberSubscriptions(chatID, messageID, user)
+	case query.Data == "member_balance":
+		b.showMemberBalance(chatID, messageID, user)
+	case query.Data == "member_reminders":
+		b.showMemberReminders(chatID, messageID, user)
+	case query.Data == "member_reminders_on", query.Data == "member_reminders_off":
+		b.toggleMemberReminders(chatID, messageID, user, query.Data == "member_reminders_on")
+	case strings.HasPrefix(query.Data, "member_paid_"):
+		b.handleMemberPaid(query.From.ID, chatID, messageID, user, strings.TrimPrefix(query.Data, "member_paid_"))
+	case query.Data == "member_claim_amount":
+		b.handleClaimAmountConfirm(query.From.ID, chatID, messageID)
+	case strings.HasPrefix(query.Data, "member_claim_method_"):
+		b.handleClaimMethod(query.From.ID, chatID, messageID, strings.TrimPrefix(query.Data, "member_claim_method_"))
+	case query.Data == "member_claim_skip":
+		b.submitPaymentClaim(query.From.ID, chatID, messageID, user, "")
+	default:
+		b.sendSimpleMessage(chatID, MessageUnknownAction)
+	}
+}
+
+// memberUser находит участника по Telegram ID; если его нет, сообщает об этом
+func (b *Bot) memberUser(chatID int64, messageID int, tgID int64) (*db.User, bool) {
+	user, err := b.Context.APIClient.FindUserByTGID(context.Background(), tgID)
+	if err != nil {
+		if client.IsNotFound(err) {
+			text := fmt.Sprintf(MessageMemberNotFound, tgID)
+			if messageID > 0 {
+				b.editMessage(chatID, messageID, text, nil)
+			} else {
+				b.sendSimpleMessage(chatID, text)
+			}
+			return nil, false
+		}
+		b.sendErrorMessage(chatID, messageID, fmt.Errorf("%s", handleAPIError(err, "FindUserByTGID")), "member_menu")
+		return nil, false
+	}
+	return user, true
+}
+
+// showMemberMenu показывает меню участника
+func (b *Bot) showMemberMenu(chatID int64, messageID int, user *db.User) {
+	text := fmt.Sprintf(MessageMemberMenu, user.Fullname)
+	keyboard := keyboards.MemberKeyboard()
+	if messageID > 0 {
+		b.editMessage(chatID, messageID, text, &keyboard)
+	} else {
+		b.sendMessageWithKeyboard(chatID, text, &keyboard)
+	}
+}
+
+// showMemberSubscriptions показывает подписки участника с ближайшими платежами
+// и кнопками «Я оплатил(а)»
+func (b *Bot) showMemberSubscriptions(chatID int64, messageID int, user *db.User) {
+	page, err := b.Context.APIClient.ListUserSubscriptions(context.Background(), user.ID, client.ListOptions{})
+	if err != nil {
+		b.sendErrorMessage(chatID, messageID, fmt.Errorf("%s", handleAPIError(err, "ListUserSubscriptions")), "member_menu")
+		return
+	}
+	userSubs := page.Items
+
+	backRow := tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(ButtonBack, "member_menu"))
+	if len(userSubs) == 0 {
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(backRow)
+		b.editMessage(chatID, messageID, MessageMemberSubsEmpty, &keyboard)
+		return
+	}
+
+	lines := []string{MessageMemberSubsTitle}
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, us := range userSubs {
+		name := us.Subscription.ServiceName
+		lines = append(lines, "")
+
+		if us.LeftAt != nil {
+			lines = append(lines, fmt.Sprintf(MessageMemberSubLeft, name, formatDate(*us.LeftAt)))
+			continue
+		}
+
+		calc, err := b.Context.APIClient.CalculatePayment(context.Background(), user.ID, us.SubscriptionID, time.Time{})
+		if err != nil {
+			lines = append(lines, fmt.Sprintf(MessageMemberSubError, name, handleAPIError(err, "CalculatePayment")))
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf(MessageMemberSubLine, name, calc.AmountRubles.Float64(), calc.Currency, formatDate(calc.DueDate)))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf(ButtonMemberPaid, name), "member_paid_"+us.SubscriptionID),
+		))
+	}
+
+	rows = append(rows, backRow)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	b.editMessage(chatID, messageID, strings.Join(lines, "\n"), &keyboard)
+}
+
+// showMemberBalance показывает баланс участника и последние операции
+func (b *Bot) showMemberBalance(chatID int64, messageID int, user *db.User) {
+	from := time.Now().AddDate(0, 0, -historyDays)
+	st, err := b.Context.APIClient.GetBalance(context.Background(), user.ID, from, time.Time{})
+	if err != nil {
+		b.sendErrorMessage(chatID, messageID, fmt.Errorf("%s", handleAPIError(err, "GetBalance")), "member_menu")
+		return
+	}
+
+	lines := []string{fmt.Sprintf(MessageMemberBalanceTitle, formatKopecks(st.Balance), st.Currency)}
+	switch {
+	case st.Balance < 0:
+		lines = append(lines, fmt.Sprintf(MessageMemberBalanceDebt, formatKopecks(-st.Balance), st.Currency))
+	case st.Balance > 0:
+		lines = append(lines, fmt.Sprintf(MessageMemberBalanceCredit, formatKopecks(st.Balance), st.Currency))
+	default:
+		lines = append(lines, MessageMemberBalanceZero)
+	}
+
+	lines = append(lines, "")
+	if len(st.Entries) == 0 {
+		lines = append(lines, fmt.Sprintf(MessageMemberHistoryEmpty, historyDays))
+	} else {
+		lines = append(lines, MessageMemberHistoryTitle)
+		// новые операции сверху
+		for i := len(st.Entries) - 1; i >= 0 && i >= len(st.Entries)-historyLimit; i-- {
+			e := st.Entries[i]
+			amount := "+" + formatKopecks(e.Credit-e.Debit)
+			if e.Debit > e.Credit {
+				amount = "−" + formatKopecks(e.Debit-e.Credit)
+			}
+			lines = append(lines, fmt.Sprintf(MessageMemberHistoryLine, formatDate(e.OccurredAt), amount, ledgerEntryLabel(e)))
+		}
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔄 Обновить", "member_balance"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ButtonBack, "member_menu"),
+		),
+	)
+	b.editMessage(chatID, messageID, strings.Join(lines, "\n"), &keyboard)
+}
+
+// showMemberReminders показывает, включены ли напоминания об оплате
+func (b *Bot) showMemberReminders(chatID int64, messageID int, user *db.User) {
+	settings, err := b.Context.APIClient.GetReminderSettings(context.Background(), user.ID)
+	if err != nil {
+		b.sendErrorMessage(chatID, messageID, fmt.Errorf("%s", handleAPIError(err, "GetReminderSettings")), "member_menu")
+		return
+	}
+	b.showReminderSettings(chatID, messageID, settings)
+}
+
+// toggleMemberReminders включает или выключает напоминания участника
+func (b *Bot) toggleMemberReminders(chatID int64, messageID int, user *db.User, enabled bool) {
+	settings, err := b.Context.APIClient.UpdateReminderSettings(actorCtx(user.TGID), user.ID, client.UpdateReminderSettingsRequest{Enabled: &enabled})
+	if err != nil {
+		b.sendErrorMessage(chatID, messageID, fmt.Errorf("%s", handleAPIError(err, "UpdateReminderSettings")), "member_menu")
+		return
+	}
+	b.showReminderSettings(chatID, messageID, settings)
+}
+
+// showReminderSettings показывает настройки напоминаний с кнопкой переключения
+func (b *Bot) showReminderSettings(chatID int64, messageID int, settings *client.ReminderSettings) {
+	text, button, action := MessageMemberRemindersOff, ButtonRemindersOn, "member_reminders_on"
+	if settings.Enabled {
+		text, button, action = MessageMemberRemindersOn, ButtonRemindersOff, "member_reminders_off"
+	}
+	if settings.QuietHours != "" {
+		text += "\n\n" + fmt.Sprintf(MessageMemberQuietHours, settings.QuietHours)
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(button, action),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ButtonBack, "member_menu"),
+		),
+	)
+	b.editMessage(chatID, messageID, text, &keyboard)
+}
+
+// handleMemberPaid начинает сообщение об оплате: сумма по умолчанию берётся из расчёта ближайшего платежа
+func (b *Bot) handleMemberPaid(tgID, chatID int64, messageID int, user *db.User, subscriptionID string) {
+	calc, err := b.Context.APIClient.CalculatePayment(context.Background(), user.ID, subscriptionID, time.Time{})
+	if err != nil {
+		b.sendErrorMessage(chatID, messageID, fmt.Errorf("%s", handleAPIError(err, "CalculatePayment")), "member_subs")
+		return
+	}
+	sub, err := b.Context.APIClient.GetSubscription(context.Background(), subscriptionID)
+	if err != nil {
+		b.sendErrorMessage(chatID, messageID, fmt.Errorf("%s", handleAPIError(err, "GetSubscription")), "member_subs")
+		return
+	}
+
+	userState := b.getUserState(tgID)
+	userState.ClaimData = &types.ClaimData{
+		SubscriptionID: subscriptionID,
+		ServiceName:    sub.ServiceName,
+		Amount:         calc.Amount,
+		Currency:       string(calc.Currency),
+	}
+	userState.State = types.StateAwaitingClaimAmount
+
+	claim := userState.ClaimData
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf(ButtonClaimAmount, formatKopecks(claim.Amount), claim.Currency), "member_claim_amount"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ButtonCancel, "member_menu"),
+		),
+	)
+	b.editMessage(chatID, messageID, fmt.Sprintf(MessageClaimAskAmount, claim.ServiceName, formatKopecks(claim.Amount), claim.Currency), &keyboard)
+}
+
+// handleClaimAmountInput участник ввёл сумму, которую заплатил
+func (b *Bot) handleClaimAmountInput(message *tgbotapi.Message) {
+	userState := b.getUserState(message.From.ID)
+	amount, err := parseKopecks(message.Text)
+	if err != nil {
+		b.sendSimpleMessage(message.Chat.ID, fmt.Sprintf(MessageError, err))
+		return
+	}
+	userState.ClaimData.Amount = amount
+	b.askClaimMethod(message.From.ID, message.Chat.ID, 0)
+}
+
+// handleClaimAmountConfirm участник заплатил рассчитанную сумму
+func (b *Bot) handleClaimAmountConfirm(tgID, chatID int64, messageID int) {
+	if b.getUserState(tgID).State != types.StateAwaitingClaimAmount {
+		b.editMessage(chatID, messageID, MessageClaimExpired, nil)
+		return
+	}
+	b.askClaimMethod(tgID, chatID, messageID)
+}
+
+// askClaimMethod спрашивает способ оплаты
+func (b *Bot) askClaimMethod(tgID, chatID int64, messageID int) {
+	userState := b.getUserState(tgID)
+	userState.State = types.StateAwaitingClaimMethod
+
+	claim := userState.ClaimData
+	text := fmt.Sprintf(MessageClaimAskMethod, claim.ServiceName, formatKopecks(claim.Amount), claim.Currency)
+	keyboard := keyboards.PaymentMethodKeyboard()
+	if messageID > 0 {
+		b.editMessage(chatID, messageID, text, &keyboard)
+	} else {
+		b.sendMessageWithKeyboard(chatID, text, &keyboard)
+	}
+}
+
+// handleClaimMethod участник выбрал способ оплаты, остаётся фото чека
+func (b *Bot) handleClaimMethod(tgID, chatID int64, messageID int, method string) {
+	userState := b.getUserState(tgID)
+	if userState.State != types.StateAwaitingClaimMethod {
+		b.editMessage(chatID, messageID, MessageClaimExpired, nil)
+		return
+	}
+	userState.ClaimData.Method = method
+	userState.State = types.StateAwaitingClaimReceipt
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ButtonClaimSkipReceipt, "member_claim_skip"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ButtonCancel, "member_menu"),
+		),
+	)
+	b.editMessage(chatID, messageID, MessageClaimAskReceipt, &keyboard)
+}
+
+// handleClaimReceiptInput участник прислал фото чека
+func (b *Bot) handleClaimReceiptInput(message *tgbotapi.Message, user *db.User) {
+	if len(message.Photo) == 0 {
+		b.sendSimpleMessage(message.Chat.ID, MessageClaimAskReceipt)
+		return
+	}
+	// последний размер фото — самый крупный
+	photo := message.Photo[len(message.Photo)-1]
+	b.submitPaymentClaim(message.From.ID, message.Chat.ID, message.MessageID, user, photo.FileID)
+}
+
+// submitPaymentClaim отправляет сообщение об оплате в API и запрос на подтверждение администраторам.
+// messageID — сообщение, которым участник подтвердил оплату: кнопка или фото чека.
+func (b *Bot) submitPaymentClaim(tgID, chatID int64, messageID int, user *db.User, receiptFileID string) {
+	userState := b.getUserState(tgID)
+	data := userState.ClaimData
+	if data == nil || data.Method == "" {
+		b.sendSimpleMessage(chatID, MessageClaimExpired)
+		return
+	}
+
+	req := client.CreatePaymentClaimRequest{
+		SubscriptionID: data.SubscriptionID,
+		Amount:         data.Amount,
+		Currency:       db.Currency(data.Currency),
+		Method:         db.PaymentMethod(data.Method),
+		ReceiptFileID:  receiptFileID,
+	}
+	claim, err := b.Context.APIClient.CreatePaymentClaim(confirmCtx(tgID, chatID, messageID, "payment_claim", req), user.ID, req)
+	if err != nil {
+		b.sendErrorMessage(chatID, 0, fmt.Errorf("%s", handleAPIError(err, "CreatePaymentClaim")), "member_subs")
+		return
+	}
+	b.resetUserState(tgID)
+	log.Printf("Member %s reported payment for %s: %s %s (claim %s)", user.ID, data.ServiceName, formatKopecks(claim.Amount), claim.Currency, claim.ID)
+
+	text := fmt.Sprintf(MessageClaimForAdmin, user.Fullname, data.ServiceName, formatKopecks(claim.Amount), claim.Currency,
+		paymentMethodLabel(string(claim.Method)), formatDateTime(claim.PaidAt))
+	keyboard := keyboards.PaymentClaimKeyboard(claim.ID)
+	for _, adminID := range b.Context.AdminUserIDs {
+		if claim.ReceiptFileID != "" {
+			photo := tgbotapi.NewPhoto(adminID, tgbotapi.FileID(claim.ReceiptFileID))
+			photo.Caption = text
+			photo.ReplyMarkup = keyboard
+			b.API.Send(photo)
+		} else {
+			b.sendMessageWithKeyboard(adminID, text, &keyboard)
+		}
+	}
+
+	back := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ButtonMemberMenu, "member_menu"),
+		),
+	)
+	b.sendMessageWithKeyboard(chatID, fmt.Sprintf(MessageMemberPaidSent, data.ServiceName, formatKopecks(claim.Amount), claim.Currency), &back)
+}
+
+// handlePaymentClaimApprove администратор подтвердил оплату: API регистрирует платёж и гасит счёт
+func (b *Bot) handlePaymentClaimApprove(query *tgbotapi.CallbackQuery, claimID string) {
+	chatID, messageID := query.Message.Chat.ID, query.Message.MessageID
+
+	admin, err := b.getAdminUser(query.From.ID)
+	if err != nil {
+		b.sendSimpleMessage(chatID, fmt.Sprintf(MessageError, err))
+		return
+	}
+	claim, err := b.Context.APIClient.ApprovePaymentClaim(
+		confirmCtx(query.From.ID, chatID, messageID, "approve_claim", claimID), admin.ID, claimID)
+	if client.ErrorCode(err) == apierr.CodePaymentClaimResolved {
+		b.closeClaimPrompt(chatID, messageID, MessageClaimNotFound)
+		return
+	} else if err != nil {
+		// сообщение остаётся в очереди, решение можно повторить
+		b.sendSimpleMessage(chatID, fmt.Sprintf(MessageError, handleAPIError(err, "ApprovePaymentClaim")))
+		return
+	}
+
+	log.Printf("Admin %d approved payment claim %s", query.From.ID, claimID)
+	member, serviceName := b.claimParties(claim)
+	b.closeClaimPrompt(chatID, messageID, fmt.Sprintf(MessageClaimApproved, member.Fullname, serviceName, formatKopecks(claim.Amount), claim.Currency))
+	if member.TGID != 0 {
+		b.sendSimpleMessage(member.TGID, fmt.Sprintf(MessageMemberPaidApproved, serviceName, formatKopecks(claim.Amount), claim.Currency))
+	}
+}
+
+// handlePaymentClaimReject администратор отклоняет оплату: бот спрашивает причину для участника
+func (b *Bot) handlePaymentClaimReject(query *tgbotapi.CallbackQuery, claimID string) {
+	userState := b.getUserState(query.From.ID)
+	userState.State = types.StateAwaitingClaimRejectReason
+	userState.CurrentEntityID = claimID
+	userState.CurrentChatID = query.Message.Chat.ID
+	userState.CurrentMessageID = query.Message.MessageID
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ButtonCancel, "claim_cancel"),
+		),
+	)
+	b.sendMessageWithKeyboard(query.Message.Chat.ID, MessageClaimAskRejectReason, &keyboard)
+}
+
+// handlePaymentClaimRejectCancel администратор передумал отклонять: запрос с кнопками остаётся
+func (b *Bot) handlePaymentClaimRejectCancel(query *tgbotapi.CallbackQuery) {
+	b.resetUserState(query.From.ID)
+	b.editMessage(query.Message.Chat.ID, query.Message.MessageID, MessageClaimRejectCancelled, nil)
+}
+
+// handleClaimRejectReasonInput администратор ввёл причину отказа
+func (b *Bot) handleClaimRejectReasonInput(message *tgbotapi.Message) {
+	userState := b.getUserState(message.From.ID)
+	reason, err := validateString(message.Text, false)
+	if err != nil {
+		b.sendSimpleMessage(message.Chat.ID, fmt.Sprintf(MessageError, err))
+		return
+	}
+
+	admin, err := b.getAdminUser(message.From.ID)
+	if err != nil {
+		b.sendSimpleMessage(message.Chat.ID, fmt.Sprintf(MessageError, err))
+		return
+	}
+	claimID, promptChatID, promptMessageID := userState.CurrentEntityID, userState.CurrentChatID, userState.CurrentMessageID
+	claim, err := b.Context.APIClient.RejectPaymentClaim(
+		confirmCtx(message.From.ID, message.Chat.ID, message.MessageID, "reject_claim", []string{claimID, reason}), admin.ID, claimID, reason)
+	if client.ErrorCode(err) == apierr.CodePaymentClaimResolved {
+		b.resetUserState(message.From.ID)
+		b.closeClaimPrompt(promptChatID, promptMessageID, MessageClaimNotFound)
+		return
+	} else if err != nil {
+		b.sendSimpleMessage(message.Chat.ID, fmt.Sprintf(MessageError, handleAPIError(err, "RejectPaymentClaim")))
+		return
+	}
+	b.resetUserState(message.From.ID)
+
+	log.Printf("Admin %d rejected payment claim %s", message.From.ID, claimID)
+	member, serviceName := b.claimParties(claim)
+	b.closeClaimPrompt(promptChatID, promptMessageID, fmt.Sprintf(MessageClaimRejected, member.Fullname, serviceName, formatKopecks(claim.Amount), claim.Currency, reason))
+	if member.TGID != 0 {
+		b.sendSimpleMessage(member.TGID, fmt.Sprintf(MessageMemberPaidRejected, serviceName, formatKopecks(claim.Amount), claim.Currency, reason))
+	}
+}
+
+// claimParties участник и название подписки для текста решения; при ошибке API — ID
+func (b *Bot) claimParties(claim *db.PaymentClaim) (*db.User, string) {
+	member, err := b.Context.APIClient.GetUser(context.Background(), claim.UserID)
+	if err != nil {
+		logError("GetUser", err)
+		member = &db.User{ID: claim.UserID, Fullname: claim.UserID}
+	}
+	serviceName := claim.SubscriptionID
+	if sub, err := b.Context.APIClient.GetSubscription(context.Background(), claim.SubscriptionID); err == nil {
+		serviceName = sub.ServiceName
+	} else {
+		logError("GetSubscription", err)
+	}
+	return member, serviceName
+}
+
+// closeClaimPrompt убирает кнопки решения с запроса администратору и сообщает итог.
+// Запрос может быть фото чека, поэтому текст отправляется отдельным сообщением.
+func (b *Bot) closeClaimPrompt(chatID int64, messageID int, text string) {
+	if messageID > 0 {
+		b.API.Send(tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, tgbotapi.InlineKeyboardMarkup{
+			InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{},
+		}))
+	}
+	b.sendSimpleMessage(chatID, text)
+}
+
+// paymentMethodLabel подпись способа оплаты
+func paymentMethodLabel(method string) string {
+	switch method {
+	case "card":
+		return ButtonMethodCard
+	case "transfer":
+		return ButtonMethodTransfer
+	case "cash":
+		return ButtonMethodCash
+	default:
+		return ButtonMethodOther
+	}
+}
+
+// ledgerEntryLabel подпись операции в истории участника
+func ledgerEntryLabel(e client.StatementLine) string {
+	if e.Description != "" {
+		return e.Description
+	}
+	switch e.Type {
+	case db.LedgerCharge:
+		return "Начисление по счёту"
+	case db.LedgerPayment:
+		return "Платёж"
+	case db.LedgerReversal:
+		return "Отмена начисления"
+	case db.LedgerAdjustment:
+		return "Корректировка"
+	default:
+		return string(e.Type)
+	}
+}
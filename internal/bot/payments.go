@@ -0,0 +1,73 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/WhoYa/subscription-manager/pkg/client"
+)
+
+// showUserPayments показывает расчёт ближайших платежей пользователя по всем его подпискам
+// с расшифровкой надбавок, скидок и неполного цикла
+func (b *Bot) showUserPayments(chatID int64, messageID int, userID string) {
+	backAction := fmt.Sprintf("edit_user_%s", userID)
+
+	user, err := b.Context.APIClient.GetUser(context.Background(), userID)
+	if err != nil {
+		b.sendErrorMessage(chatID, messageID, err, backAction)
+		return
+	}
+
+	page, err := b.Context.APIClient.ListUserSubscriptions(context.Background(), userID, client.ListOptions{})
+	if err != nil {
+		b.sendErrorMessage(chatID, messageID, err, backAction)
+		return
+	}
+	userSubs := page.Items
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ButtonBack, backAction),
+		),
+	)
+
+	if len(userSubs) == 0 {
+		b.editMessage(chatID, messageID, fmt.Sprintf(MessageUserPaymentsEmpty, user.Fullname), &keyboard)
+		return
+	}
+
+	lines := []string{fmt.Sprintf(MessageUserPaymentsTitle, user.Fullname)}
+	for _, us := range userSubs {
+		name := us.Subscription.ServiceName
+		lines = append(lines, "")
+
+		if us.LeftAt != nil {
+			lines = append(lines, fmt.Sprintf(MessageUserPaymentLeft, name, formatDate(*us.LeftAt)))
+			continue
+		}
+
+		calc, err := b.Context.APIClient.CalculatePayment(context.Background(), userID, us.SubscriptionID, time.Time{})
+		if err != nil {
+			lines = append(lines, fmt.Sprintf(MessageUserPaymentError, name, handleAPIError(err, "CalculatePayment")))
+			continue
+		}
+
+		lines = append(lines,
+			fmt.Sprintf(MessageUserPaymentLine, name, calc.AmountRubles.Float64(), calc.Currency, formatDate(calc.DueDate)),
+			fmt.Sprintf(MessageUserPaymentBase, calc.BaseAmount.Float64(), calc.Currency, calc.SourceCurrency, calc.Currency, calc.ExchangeRate.Float64()),
+		)
+		for _, step := range calc.Breakdown {
+			lines = append(lines, fmt.Sprintf(MessageUserPaymentStep, step.Name, step.Delta.Float64()))
+		}
+		if p := calc.Proration; p != nil {
+			lines = append(lines, fmt.Sprintf(MessageUserPaymentProrated,
+				formatDate(p.BilledFrom), formatDate(p.BilledTo), p.BilledDays, p.PeriodDays))
+		}
+	}
+
+	b.editMessage(chatID, messageID, strings.Join(lines, "\n"), &keyboard)
+}
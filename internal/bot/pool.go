@@ -0,0 +1,95 @@
+package bot
+
+import (
+	"hash/fnv"
+	"log"
+	"runtime/debug"
+	"strconv"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// defaultWorkers сколько обновлений обрабатывается параллельно, если Bot.Workers не задан
+const defaultWorkers = 8
+
+// workerQueueSize сколько обновлений может ждать своей очереди у одного обработчика
+const workerQueueSize = 64
+
+// updatePool обрабатывает обновления параллельно по чатам и строго по порядку внутри чата:
+// все обновления одного чата попадают в очередь одного и того же обработчика
+type updatePool struct {
+	queues []chan tgbotapi.Update
+	handle func(tgbotapi.Update)
+	wg     sync.WaitGroup
+}
+
+// newUpdatePool запускает workers обработчиков
+func newUpdatePool(workers int, handle func(tgbotapi.Update)) *updatePool {
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	p := &updatePool{
+		queues: make([]chan tgbotapi.Update, workers),
+		handle: handle,
+	}
+	for i := range p.queues {
+		p.queues[i] = make(chan tgbotapi.Update, workerQueueSize)
+		p.wg.Add(1)
+		go p.work(p.queues[i])
+	}
+	return p
+}
+
+// Submit ставит обновление в очередь его чата; если очередь заполнена, ждёт
+func (p *updatePool) Submit(update tgbotapi.Update) {
+	p.queues[p.shard(updateChatKey(update))] <- update
+}
+
+// Close перестаёт принимать обновления и ждёт, пока обработаются уже поставленные в очередь
+func (p *updatePool) Close() {
+	for _, q := range p.queues {
+		close(q)
+	}
+	p.wg.Wait()
+}
+
+func (p *updatePool) work(queue <-chan tgbotapi.Update) {
+	defer p.wg.Done()
+	for update := range queue {
+		p.safeHandle(update)
+	}
+}
+
+// safeHandle обрабатывает обновление; паника в обработчике не останавливает бота
+func (p *updatePool) safeHandle(update tgbotapi.Update) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[ERROR] Panic while handling update %d: %v\n%s", update.UpdateID, r, debug.Stack())
+		}
+	}()
+	p.handle(update)
+}
+
+// shard номер обработчика для чата
+func (p *updatePool) shard(key int64) int {
+	h := fnv.New32a()
+	h.Write([]byte(strconv.FormatInt(key, 10)))
+	return int(h.Sum32() % uint32(len(p.queues)))
+}
+
+// updateChatKey чат обновления; для кнопок под inline-сообщениями без чата — пользователь.
+// В личных чатах ID чата совпадает с ID пользователя, поэтому состояние пользователя
+// меняет только один обработчик.
+func updateChatKey(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil:
+		return update.Message.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return update.CallbackQuery.Message.Chat.ID
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From.ID
+	default:
+		return 0
+	}
+}
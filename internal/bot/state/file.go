@@ -0,0 +1,148 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/internal/bot/types"
+)
+
+// savedState состояние пользователя в файле
+type savedState struct {
+	Data    json.RawMessage `json:"data"`
+	SavedAt time.Time       `json:"saved_at"`
+}
+
+// FileStore хранит состояния в JSON-файле, чтобы незаконченные диалоги переживали перезапуск бота.
+// Файл переписывается целиком при каждом Save через временный файл и rename,
+// поэтому падение посреди записи не портит сохранённые состояния.
+type FileStore struct {
+	mu    sync.Mutex
+	path  string
+	ttl   time.Duration
+	now   func() time.Time
+	live  map[int64]*types.UserData // состояния, выданные через Get в этом процессе
+	saved map[int64]savedState      // последнее сохранённое состояние каждого пользователя
+}
+
+// NewFileStore открывает хранилище в файле path; если файла нет, он будет создан при первом Save.
+// ttl <= 0 — DefaultTTL.
+func NewFileStore(path string, ttl time.Duration) (*FileStore, error) {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	s := &FileStore{
+		path:  path,
+		ttl:   ttl,
+		now:   time.Now,
+		live:  make(map[int64]*types.UserData),
+		saved: make(map[int64]savedState),
+	}
+
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &s.saved); err != nil {
+			return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+		}
+	}
+	return s, nil
+}
+
+// Get возвращает состояние пользователя: из памяти, из файла или новое в StateIdle
+func (s *FileStore) Get(userID int64) *types.UserData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if data, ok := s.live[userID]; ok && !s.expired(userID) {
+		return data
+	}
+
+	data := &types.UserData{State: types.StateIdle}
+	if st, ok := s.saved[userID]; ok && !s.expired(userID) {
+		if err := json.Unmarshal(st.Data, data); err != nil {
+			// повреждённое состояние — начинаем диалог заново
+			data = &types.UserData{State: types.StateIdle}
+		}
+	}
+	s.live[userID] = data
+	return data
+}
+
+// Save записывает состояние пользователя в файл
+func (s *FileStore) Save(userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.live[userID]
+	if !ok {
+		return nil
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode state of user %d: %w", userID, err)
+	}
+	s.saved[userID] = savedState{Data: raw, SavedAt: s.now()}
+	return s.flush()
+}
+
+// Expire удаляет брошенные состояния из памяти и файла
+func (s *FileStore) Expire(now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for userID, st := range s.saved {
+		if now.Sub(st.SavedAt) > s.ttl {
+			delete(s.saved, userID)
+			delete(s.live, userID)
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	return n, s.flush()
+}
+
+// expired не сохранялось ли состояние дольше TTL; состояние, которое ещё ни разу
+// не сохранялось, живёт до первого Save
+func (s *FileStore) expired(userID int64) bool {
+	st, ok := s.saved[userID]
+	return ok && s.now().Sub(st.SavedAt) > s.ttl
+}
+
+// flush переписывает файл сохранёнными состояниями
+func (s *FileStore) flush() error {
+	raw, err := json.Marshal(s.saved)
+	if err != nil {
+		return fmt.Errorf("failed to encode states: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
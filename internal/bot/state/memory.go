@@ -0,0 +1,77 @@
+package state
+
+import (
+	"sync"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/internal/bot/types"
+)
+
+// DefaultTTL через сколько брошенный диалог сбрасывается
+const DefaultTTL = 24 * time.Hour
+
+// entry состояние пользователя и время последнего сохранения
+type entry struct {
+	data    *types.UserData
+	savedAt time.Time
+}
+
+// MemoryStore хранит состояния в памяти процесса: после перезапуска бота они теряются
+type MemoryStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[int64]*entry
+}
+
+// NewMemoryStore создаёт хранилище в памяти; ttl <= 0 — DefaultTTL
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &MemoryStore{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[int64]*entry),
+	}
+}
+
+// Get возвращает состояние пользователя, создавая новое в StateIdle
+func (s *MemoryStore) Get(userID int64) *types.UserData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if e, ok := s.entries[userID]; ok && now.Sub(e.savedAt) <= s.ttl {
+		return e.data
+	}
+	e := &entry{data: &types.UserData{State: types.StateIdle}, savedAt: now}
+	s.entries[userID] = e
+	return e.data
+}
+
+// Save продлевает срок жизни состояния
+func (s *MemoryStore) Save(userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[userID]; ok {
+		e.savedAt = s.now()
+	}
+	return nil
+}
+
+// Expire удаляет брошенные состояния
+func (s *MemoryStore) Expire(now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for userID, e := range s.entries {
+		if now.Sub(e.savedAt) > s.ttl {
+			delete(s.entries, userID)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -1,7 +1,10 @@
 package types
 
 import (
-	"github.com/WhoYa/subscription-manager/internal/bot/api"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/client"
+	"github.com/WhoYa/subscription-manager/pkg/money"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
@@ -26,6 +29,12 @@ const (
 	StateEditingSubscriptionPeriod   UserState = "editing_subscription_period"
 	StateEditingUserFullname         UserState = "editing_user_fullname"
 	StateEditingUserUsername         UserState = "editing_user_username"
+
+	// Состояния сообщения об оплате
+	StateAwaitingClaimAmount       UserState = "awaiting_claim_amount"        // участник вводит сумму
+	StateAwaitingClaimMethod       UserState = "awaiting_claim_method"        // участник выбирает способ оплаты
+	StateAwaitingClaimReceipt      UserState = "awaiting_claim_receipt"       // участник отправляет фото чека
+	StateAwaitingClaimRejectReason UserState = "awaiting_claim_reject_reason" // администратор вводит причину отказа
 )
 
 // UserData содержит временные данные для создания/редактирования
@@ -34,6 +43,7 @@ type UserData struct {
 	SubscriptionData   *SubscriptionCreateData
 	UserCreateData     *UserCreateData
 	EditData           *EditData
+	ClaimData          *ClaimData
 	CurrentEntityID    string // ID редактируемой сущности
 	CurrentMessageID   int    // ID текущего сообщения для редактирования
 	CurrentChatID      int64  // ID чата для редактирования сообщения
@@ -43,7 +53,7 @@ type UserData struct {
 // SubscriptionCreateData временные данные для создания подписки
 type SubscriptionCreateData struct {
 	ServiceName  string
-	BasePrice    float64
+	BasePrice    money.Decimal
 	BaseCurrency string
 	PeriodDays   int
 }
@@ -63,12 +73,35 @@ type EditData struct {
 	UpdatedFields  map[string]interface{} // обновленные поля
 }
 
+// ClaimData черновик сообщения участника об оплате
+type ClaimData struct {
+	SubscriptionID string
+	ServiceName    string
+	Amount         int64 // в копейках Currency
+	Currency       string
+	Method         string
+}
+
+// StateStore хранилище состояний диалогов с пользователями.
+// Состояние, которое не сохранялось дольше TTL, считается брошенным и сбрасывается в StateIdle.
+type StateStore interface {
+	// Get возвращает состояние пользователя; отсутствующее или истёкшее — новое в StateIdle.
+	// Поля меняются прямо в возвращённой структуре и записываются вызовом Save.
+	Get(userID int64) *UserData
+
+	// Save записывает состояние пользователя и продлевает его срок жизни
+	Save(userID int64) error
+
+	// Expire удаляет состояния, не сохранявшиеся дольше TTL, и возвращает их число
+	Expire(now time.Time) (int, error)
+}
+
 // BotContext содержит контекст бота и API клиенты
 type BotContext struct {
 	Bot          *tgbotapi.BotAPI
-	APIClient    *api.Client
+	APIClient    *client.Client
 	APIBaseURL   string
-	UserStates   map[int64]*UserData
+	UserStates   StateStore
 	AdminUserIDs []int64
 }
 
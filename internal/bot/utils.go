@@ -1,16 +1,27 @@
 package bot
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"github.com/WhoYa/subscription-manager/internal/bot/types"
+	"github.com/WhoYa/subscription-manager/pkg/apierr"
+	"github.com/WhoYa/subscription-manager/pkg/client"
+	"github.com/WhoYa/subscription-manager/pkg/money"
 )
 
+// maxAmountInput самая большая сумма, которую можно ввести в боте, в целых единицах валюты
+const maxAmountInput = 1_000_000_000
+
 // Общие функции для работы с сообщениями
 
 // sendErrorMessage отправляет сообщение об ошибке с кнопкой "Назад"
@@ -58,28 +69,110 @@ func (b *Bot) resetUserState(userID int64) {
 	userState.SubscriptionData = nil
 	userState.UserCreateData = nil
 	userState.EditData = nil
+	userState.ClaimData = nil
 	userState.CurrentEntityID = ""
 }
 
+// actorCtx контекст запроса к API от имени пользователя Telegram tgID:
+// изменение попадёт в журнал аудита с ним, а не только с ключом бота
+func actorCtx(tgID int64) context.Context {
+	return client.WithActor(context.Background(), tgID)
+}
+
+// confirmCtx actorCtx с ключом идемпотентности подтверждения: ключ собирается из сообщения,
+// которым подтверждено действие, и данных запроса. Повторное нажатие той же кнопки или
+// повторная доставка того же сообщения получат первый ответ API, а не создадут запись ещё раз.
+func confirmCtx(tgID, chatID int64, messageID int, action string, req any) context.Context {
+	raw, _ := json.Marshal(req)
+	sum := sha256.Sum256(raw)
+	key := fmt.Sprintf("tg-%d-%d-%s-%x", chatID, messageID, action, sum[:8])
+	return client.WithIdempotencyKey(actorCtx(tgID), key)
+}
+
+// currencyCodes коды включённых валют для клавиатуры выбора
+func (b *Bot) currencyCodes() ([]string, error) {
+	currencies, err := b.Context.APIClient.ListCurrencies(context.Background(), false)
+	if err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, len(currencies))
+	for _, c := range currencies {
+		codes = append(codes, string(c.Code))
+	}
+	return codes, nil
+}
+
 // Функции валидации
 
-// validateFloat64 проверяет и парсит число с плавающей точкой
-func validateFloat64(input string, minValue float64) (float64, error) {
-	input = strings.TrimSpace(input)
-	if input == "" {
-		return 0, fmt.Errorf("пустое значение")
+// normalizeNumber убирает из введённого числа пробелы и заменяет десятичную запятую точкой
+func normalizeNumber(input string) string {
+	return strings.NewReplacer(" ", "", "\u00a0", "", ",", ".").Replace(strings.TrimSpace(input))
+}
+
+// parsePrice разбирает введённую цену с точностью до копейки, по правилам parseKopecks
+func parsePrice(input string) (money.Decimal, error) {
+	kopecks, err := parseKopecks(input)
+	if err != nil {
+		return money.Zero, err
 	}
+	return money.NewFromMinor(kopecks, 2), nil
+}
 
-	value, err := strconv.ParseFloat(input, 64)
+// parsePercent разбирает введённый процент: "15", "15,5", "0". Не меньше нуля,
+// не больше двух знаков после запятой.
+func parsePercent(input string) (money.Decimal, error) {
+	input = normalizeNumber(input)
+	if input == "" {
+		return money.Zero, fmt.Errorf("пустое значение")
+	}
+	intPart, fracPart, _ := strings.Cut(input, ".")
+	if intPart == "" || !isDigits(intPart) || !isDigits(fracPart) || len(fracPart) > 2 {
+		return money.Zero, fmt.Errorf("неверный формат процента, например: 15,5")
+	}
+	value, err := money.Parse(input)
 	if err != nil {
-		return 0, fmt.Errorf("неверный формат числа")
+		return money.Zero, fmt.Errorf("неверный формат процента, например: 15,5")
+	}
+	return value, nil
+}
+
+// parseKopecks разбирает введённую сумму в сотые доли валюты: "499", "499,90", "1 499.9".
+// Сумма должна быть больше нуля, не больше maxAmountInput и не точнее копейки.
+func parseKopecks(input string) (int64, error) {
+	input = normalizeNumber(input)
+	if input == "" {
+		return 0, fmt.Errorf("пустое значение")
+	}
+	if strings.HasPrefix(input, "-") {
+		return 0, fmt.Errorf("сумма должна быть больше нуля")
+	}
+	intPart, fracPart, _ := strings.Cut(input, ".")
+	if intPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, fmt.Errorf("неверный формат суммы, например: 499,90")
+	}
+	if len(fracPart) > 2 {
+		return 0, fmt.Errorf("укажите сумму с точностью до копейки: не больше двух знаков после запятой")
 	}
 
-	if value < minValue {
-		return 0, fmt.Errorf("значение должно быть больше или равно %.2f", minValue)
+	value, err := money.Parse(input)
+	if err != nil || value.Cmp(money.NewFromInt(maxAmountInput)) > 0 {
+		return 0, fmt.Errorf("слишком большая сумма: не больше %d", maxAmountInput)
 	}
+	kopecks := value.Minor(2, money.HalfUp)
+	if kopecks <= 0 {
+		return 0, fmt.Errorf("сумма должна быть больше нуля")
+	}
+	return kopecks, nil
+}
 
-	return value, nil
+// isDigits строка из одних цифр (пустая тоже подходит)
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
 }
 
 // validateInt проверяет и парсит целое число
@@ -155,6 +248,25 @@ func formatUsername(username string) string {
 	return "@" + username
 }
 
+// formatDate переводит дату из API в ДД.ММ.ГГГГ
+func formatDate(t time.Time) string {
+	return t.Format("02.01.2006")
+}
+
+// formatDateTime переводит время из API в ДД.ММ.ГГГГ ЧЧ:ММ по местному времени бота
+func formatDateTime(t time.Time) string {
+	return t.Local().Format("02.01.2006 15:04")
+}
+
+// formatKopecks сумма в сотых долях валюты: 123456 → "1234.56"
+func formatKopecks(v int64) string {
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/100, v%100)
+}
+
 // Функции для работы с callback данными
 
 // Функции для работы с логированием
@@ -175,17 +287,43 @@ func logInfo(context string, message string) {
 func handleAPIError(err error, context string) string {
 	logError(context, err)
 
-	errStr := err.Error()
-	switch {
-	case strings.Contains(errStr, "404") || strings.Contains(errStr, "not found"):
+	switch client.ErrorCode(err) {
+	case apierr.CodeUserNotFound:
+		return "Пользователь не найден"
+	case apierr.CodeSubscriptionNotFound:
+		return "Подписка не найдена"
+	case apierr.CodeUserSubscriptionNotFound:
+		return "Участник не состоит в этой подписке"
+	case apierr.CodeDuplicateTGID:
+		return "Пользователь с таким Telegram ID уже существует"
+	case apierr.CodeDuplicateServiceName:
+		return "Подписка с таким названием уже существует"
+	case apierr.CodeDuplicateUserSubscription:
+		return "Пользователь уже состоит в этой подписке"
+	case apierr.CodeMembershipEnded:
+		return "Участие в подписке уже завершено"
+	case apierr.CodePaymentClaimResolved:
+		return "Заявка уже рассмотрена"
+	case apierr.CodeInvoiceExists:
+		return "Счёт за этот период уже выставлен"
+	case apierr.CodeUnsupportedCurrency, apierr.CodeInvalidCurrency:
+		return "Неподдерживаемая валюта"
+	case apierr.CodeExchangeRateNotFound:
+		return "Нет курса валюты на эту дату"
+	case apierr.CodePaymentNotCalculated:
+		return "Платёж за период ещё не рассчитан"
+	}
+
+	switch status := client.StatusCode(err); {
+	case status == http.StatusNotFound:
 		return "Запрашиваемая информация не найдена"
-	case strings.Contains(errStr, "409") || strings.Contains(errStr, "duplicate"):
+	case status == http.StatusConflict:
 		return "Данные уже существуют"
-	case strings.Contains(errStr, "400") || strings.Contains(errStr, "bad request"):
+	case status == http.StatusBadRequest || status == http.StatusUnprocessableEntity:
 		return "Неверные данные запроса"
-	case strings.Contains(errStr, "403") || strings.Contains(errStr, "forbidden"):
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
 		return "Недостаточно прав"
-	case strings.Contains(errStr, "500") || strings.Contains(errStr, "internal server"):
+	case status >= http.StatusInternalServerError:
 		return "Внутренняя ошибка сервера"
 	default:
 		return "Неизвестная ошибка сервера"
@@ -0,0 +1,72 @@
+package bot
+
+import "testing"
+
+func TestParseKopecks(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"499", 49900, false},
+		{"499,90", 49990, false},
+		{"499.9", 49990, false},
+		{" 1 499,05 ", 149905, false},
+		{"1 000", 100000, false},
+		{"0,01", 1, false},
+		{"1000000000", 100000000000, false},
+		{"", 0, true},
+		{"0", 0, true},
+		{"0,00", 0, true},
+		{"-100", 0, true},
+		{"499,999", 0, true},
+		{"1e3", 0, true},
+		{"NaN", 0, true},
+		{"abc", 0, true},
+		{",5", 0, true},
+		{"1000000000,01", 0, true},
+		{"92233720368547758.07", 0, true},
+		{"999999999999999999999999999999", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseKopecks(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseKopecks(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseKopecks(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePercent(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"15", "15", false},
+		{"15,5", "15.5", false},
+		{" 0 ", "0", false},
+		{"12.34", "12.34", false},
+		{"", "", true},
+		{"-5", "", true},
+		{"1,234", "", true},
+		{"1e2", "", true},
+		{"NaN", "", true},
+		{",5", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parsePercent(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePercent(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("parsePercent(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package bot
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// secretTokenHeader заголовок, в котором Telegram присылает секрет webhook
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// defaultWebhookPath путь webhook, если URL не задан
+const defaultWebhookPath = "/telegram"
+
+// maxUpdateSize предельный размер тела запроса с обновлением
+const maxUpdateSize = 1 << 20
+
+// webhookShutdownTimeout сколько ждать завершения запросов, уже принятых webhook
+const webhookShutdownTimeout = 10 * time.Second
+
+// secretTokenRe допустимый секрет webhook по правилам Telegram
+var secretTokenRe = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// WebhookConfig настройки приёма обновлений через webhook
+type WebhookConfig struct {
+	Listen string // адрес HTTP-сервера, например ":8081"
+	URL    string // публичный HTTPS-адрес для setWebhook; пусто — webhook не регистрируется (локальная отладка)
+	Secret string // секрет, который Telegram присылает в X-Telegram-Bot-Api-Secret-Token
+}
+
+// Validate проверяет настройки webhook
+func (c *WebhookConfig) Validate() error {
+	if c.Listen == "" {
+		return errors.New("webhook listen address is required")
+	}
+	if !secretTokenRe.MatchString(c.Secret) {
+		return errors.New("webhook secret must be 1-256 characters: A-Z, a-z, 0-9, _ and -")
+	}
+	if c.URL != "" {
+		u, err := url.Parse(c.URL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("webhook URL must be an absolute https URL, got %q", c.URL)
+		}
+	}
+	return nil
+}
+
+// path путь, на который Telegram отправляет обновления
+func (c *WebhookConfig) path() string {
+	if u, err := url.Parse(c.URL); err == nil && u.Path != "" && u.Path != "/" {
+		return u.Path
+	}
+	return defaultWebhookPath
+}
+
+// serveWebhook принимает обновления по HTTP до отмены ctx. При запуске регистрирует webhook
+// в Telegram, при остановке снимает его — обновления копятся в Telegram до следующего запуска.
+func (b *Bot) serveWebhook(ctx context.Context, pool *updatePool) error {
+	cfg := b.Webhook
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(cfg.path(), webhookHandler(cfg.Secret, pool.Submit))
+	srv := &http.Server{
+		Addr:              cfg.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+	log.Printf("Receiving updates via webhook on %s%s", cfg.Listen, cfg.path())
+
+	if cfg.URL != "" {
+		if err := b.setWebhook(cfg); err != nil {
+			srv.Close()
+			return err
+		}
+		log.Printf("Webhook registered: %s", cfg.URL)
+	} else {
+		log.Println("WEBHOOK_URL is not set: webhook is not registered in Telegram, POST updates to the listener manually")
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+		log.Println("Stopping bot: finishing received updates...")
+	case err = <-serveErr:
+		err = fmt.Errorf("webhook server failed: %w", err)
+	}
+
+	if cfg.URL != "" {
+		if _, delErr := b.API.Request(tgbotapi.DeleteWebhookConfig{}); delErr != nil {
+			logError("DeleteWebhook", delErr)
+		} else {
+			log.Println("Webhook deleted")
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), webhookShutdownTimeout)
+	defer cancel()
+	if shutdownErr := srv.Shutdown(shutdownCtx); shutdownErr != nil {
+		logError("WebhookShutdown", shutdownErr)
+	}
+	return err
+}
+
+// setWebhook регистрирует webhook с секретом; tgbotapi v5 не умеет передавать secret_token,
+// поэтому запрос собирается вручную
+func (b *Bot) setWebhook(cfg *WebhookConfig) error {
+	params := tgbotapi.Params{}
+	params["url"] = cfg.URL
+	params["secret_token"] = cfg.Secret
+	if err := params.AddInterface("allowed_updates", []string{"message", "callback_query"}); err != nil {
+		return err
+	}
+	if _, err := b.API.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("failed to set webhook: %w", err)
+	}
+	return nil
+}
+
+// webhookHandler проверяет секрет и передаёт обновление в submit.
+// Ответ 200 уходит, как только обновление поставлено в очередь его чата.
+func webhookHandler(secret string, submit func(tgbotapi.Update)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		got := r.Header.Get(secretTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(got), []byte(secret)) != 1 {
+			log.Printf("[WARN] Webhook request from %s with invalid secret token", r.RemoteAddr)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var update tgbotapi.Update
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUpdateSize)).Decode(&update); err != nil {
+			http.Error(w, "invalid update", http.StatusBadRequest)
+			return
+		}
+
+		submit(update)
+		w.WriteHeader(http.StatusOK)
+	})
+}
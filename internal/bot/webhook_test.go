@@ -0,0 +1,118 @@
+package bot
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const testWebhookSecret = "test_webhook-secret"
+
+// readFixture обновление Telegram, записанное в testdata
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+// postUpdate отправляет тело на webhook с секретом secret; пустой secret — без заголовка
+func postUpdate(t *testing.T, url, secret string, body []byte) int {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.Header.Set(secretTokenHeader, secret)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestWebhookHandlerRejects(t *testing.T) {
+	message := readFixture(t, "update_message.json")
+	var submitted atomic.Int32
+	srv := httptest.NewServer(webhookHandler(testWebhookSecret, func(tgbotapi.Update) { submitted.Add(1) }))
+	defer srv.Close()
+
+	tests := []struct {
+		name   string
+		secret string
+		body   []byte
+		want   int
+	}{
+		{"no secret", "", message, http.StatusUnauthorized},
+		{"wrong secret", "other-secret", message, http.StatusUnauthorized},
+		{"secret prefix", testWebhookSecret[:4], message, http.StatusUnauthorized},
+		{"invalid update", testWebhookSecret, []byte(`{"update_id": "x"`), http.StatusBadRequest},
+		{"too large", testWebhookSecret, bytes.Repeat([]byte(" "), maxUpdateSize+1), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := postUpdate(t, srv.URL, tt.secret, tt.body); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed || resp.Header.Get("Allow") != http.MethodPost {
+		t.Errorf("GET status = %d, Allow = %q", resp.StatusCode, resp.Header.Get("Allow"))
+	}
+
+	if n := submitted.Load(); n != 0 {
+		t.Errorf("rejected requests submitted %d update(s)", n)
+	}
+}
+
+func TestWebhookHandlerDispatchesThroughPool(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		handled []int
+	)
+	pool := newUpdatePool(4, func(u tgbotapi.Update) {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, u.UpdateID)
+	})
+	srv := httptest.NewServer(webhookHandler(testWebhookSecret, pool.Submit))
+	defer srv.Close()
+
+	// сообщение и нажатие кнопки из одного чата обрабатываются в порядке получения
+	fixtures := []string{"update_message.json", "update_callback_query.json", "update_message.json"}
+	for _, name := range fixtures {
+		if got := postUpdate(t, srv.URL, testWebhookSecret, readFixture(t, name)); got != http.StatusOK {
+			t.Fatalf("%s: status = %d, want %d", name, got, http.StatusOK)
+		}
+	}
+	pool.Close()
+
+	want := []int{715309021, 715309022, 715309021}
+	if len(handled) != len(want) {
+		t.Fatalf("handled %v, want %v", handled, want)
+	}
+	for i := range want {
+		if handled[i] != want[i] {
+			t.Fatalf("handled %v, want %v", handled, want)
+		}
+	}
+}
@@ -7,7 +7,10 @@ import (
 
 	crRepo "github.com/WhoYa/subscription-manager/internal/repository/currencyrate"
 	userRepo "github.com/WhoYa/subscription-manager/internal/repository/user"
+	"github.com/WhoYa/subscription-manager/internal/service"
+	"github.com/WhoYa/subscription-manager/pkg/apierr"
 	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/WhoYa/subscription-manager/pkg/money"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
@@ -15,31 +18,43 @@ import (
 type AdminHandler struct {
 	userRepo     userRepo.UserRepository
 	currencyRepo crRepo.CurrencyRateRepository
+	currencies   service.Currencies
 }
 
-func NewAdminHandler(uRepo userRepo.UserRepository, crRepo crRepo.CurrencyRateRepository) *AdminHandler {
+func NewAdminHandler(uRepo userRepo.UserRepository, crRepo crRepo.CurrencyRateRepository, currencies service.Currencies) *AdminHandler {
 	return &AdminHandler{
 		userRepo:     uRepo,
 		currencyRepo: crRepo,
+		currencies:   currencies,
 	}
 }
 
-// CheckAdminAccess middleware для проверки админских прав
+// CheckAdminAccess middleware для проверки админских прав. Права даёт ключ запроса:
+// adminUserID лишь указывает, от имени какого администратора действует ключ сервиса,
+// а ключ администратора может действовать только от своего имени.
 func (h *AdminHandler) CheckAdminAccess(c *fiber.Ctx) error {
 	adminUserID := c.Params("adminUserID")
 	if adminUserID == "" {
-		return fiber.NewError(http.StatusBadRequest, "admin user ID required")
+		return badRequest("admin user ID required")
+	}
+
+	principal := currentPrincipal(c)
+	if !principal.IsAdmin() {
+		return forbidden("admin access required")
+	}
+	if principal.UserID != "" && principal.UserID != adminUserID {
+		return forbidden("api key belongs to another admin")
 	}
 
 	user, err := h.userRepo.FindByID(adminUserID)
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return fiber.NewError(http.StatusNotFound, "admin user not found")
+		return notFound(apierr.CodeUserNotFound, "admin user not found")
 	} else if err != nil {
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	if !user.IsAdmin {
-		return fiber.NewError(http.StatusForbidden, "admin access required")
+		return forbidden("admin access required")
 	}
 
 	return c.Next()
@@ -48,24 +63,25 @@ func (h *AdminHandler) CheckAdminAccess(c *fiber.Ctx) error {
 // SetManualRate быстрый ввод курса валюты админом
 func (h *AdminHandler) SetManualRate(c *fiber.Ctx) error {
 	var body struct {
-		Currency string  `json:"currency"`
-		Rate     float64 `json:"rate"`
+		Currency string        `json:"currency"`
+		Rate     money.Decimal `json:"rate"`
 	}
 	if err := c.BodyParser(&body); err != nil {
-		return fiber.NewError(http.StatusBadRequest, "invalid JSON")
+		return badRequest("invalid JSON")
 	}
 
-	// Валидация валюты
-	curr := db.Currency(body.Currency)
-	switch curr {
-	case db.USD, db.EUR, db.RUB:
-	default:
-		return fiber.NewError(http.StatusBadRequest, "supported currencies: USD, EUR, RUB")
+	// Валидация валюты: курс задаётся к рублю
+	curr, err := h.currencies.Validate(body.Currency)
+	if err != nil {
+		return err
+	}
+	if curr == db.RUB {
+		return badRequest("rate is quoted in RUB, choose another currency")
 	}
 
 	// Валидация курса
-	if body.Rate <= 0 {
-		return fiber.NewError(http.StatusBadRequest, "rate must be greater than 0")
+	if body.Rate.Sign() <= 0 {
+		return badRequest("rate must be greater than 0")
 	}
 
 	// Создаем новый курс
@@ -78,8 +94,9 @@ func (h *AdminHandler) SetManualRate(c *fiber.Ctx) error {
 	}
 
 	if err := h.currencyRepo.Create(currencyRate); err != nil {
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
+		return err
 	}
+	recordAudit(c, db.AuditCurrencyRate, currencyRate.ID, nil, currencyRate)
 
 	return c.Status(http.StatusCreated).JSON(fiber.Map{
 		"message":  "Manual currency rate set successfully",
@@ -93,10 +110,17 @@ func (h *AdminHandler) SetManualRate(c *fiber.Ctx) error {
 
 // GetCurrentRates получение текущих курсов всех валют
 func (h *AdminHandler) GetCurrentRates(c *fiber.Ctx) error {
-	currencies := []db.Currency{db.USD, db.EUR, db.RUB}
+	currencies, err := h.currencies.List(true)
+	if err != nil {
+		return err
+	}
 	rates := make(map[string]interface{})
 
-	for _, currency := range currencies {
+	for _, info := range currencies {
+		currency := info.Code
+		if currency == db.RUB {
+			continue // курсы котируются к рублю
+		}
 		rate, err := h.currencyRepo.LatestByCurrency(currency)
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			rates[string(currency)] = map[string]interface{}{
@@ -129,16 +153,16 @@ func (h *AdminHandler) GetCurrentRates(c *fiber.Ctx) error {
 func (h *AdminHandler) SetMultipleRates(c *fiber.Ctx) error {
 	var body struct {
 		Rates []struct {
-			Currency string  `json:"currency"`
-			Rate     float64 `json:"rate"`
+			Currency string        `json:"currency"`
+			Rate     money.Decimal `json:"rate"`
 		} `json:"rates"`
 	}
 	if err := c.BodyParser(&body); err != nil {
-		return fiber.NewError(http.StatusBadRequest, "invalid JSON")
+		return badRequest("invalid JSON")
 	}
 
 	if len(body.Rates) == 0 {
-		return fiber.NewError(http.StatusBadRequest, "at least one rate required")
+		return badRequest("at least one rate required")
 	}
 
 	now := time.Now()
@@ -146,20 +170,21 @@ func (h *AdminHandler) SetMultipleRates(c *fiber.Ctx) error {
 
 	for _, rateData := range body.Rates {
 		// Валидация валюты
-		curr := db.Currency(rateData.Currency)
-		switch curr {
-		case db.USD, db.EUR, db.RUB:
-		default:
+		curr, err := h.currencies.Validate(rateData.Currency)
+		if err == nil && curr == db.RUB {
+			err = errors.New("rate is quoted in RUB")
+		}
+		if err != nil {
 			results = append(results, map[string]interface{}{
 				"currency": rateData.Currency,
 				"success":  false,
-				"error":    "unsupported currency",
+				"error":    err.Error(),
 			})
 			continue
 		}
 
 		// Валидация курса
-		if rateData.Rate <= 0 {
+		if rateData.Rate.Sign() <= 0 {
 			results = append(results, map[string]interface{}{
 				"currency": rateData.Currency,
 				"success":  false,
@@ -183,6 +208,7 @@ func (h *AdminHandler) SetMultipleRates(c *fiber.Ctx) error {
 				"error":    err.Error(),
 			})
 		} else {
+			recordAudit(c, db.AuditCurrencyRate, currencyRate.ID, nil, currencyRate)
 			results = append(results, map[string]interface{}{
 				"currency": rateData.Currency,
 				"success":  true,
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"log"
+	"strconv"
+
+	auditrepo "github.com/WhoYa/subscription-manager/internal/repository/audit"
+	"github.com/WhoYa/subscription-manager/internal/service"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// auditKey ключ в c.Locals, под которым лежит service.Audit
+	auditKey = "audit"
+	// HeaderActorTGID Telegram ID администратора, от имени которого бот вносит изменение.
+	// Учитывается только для ключа сервиса: у остальных ключей пользователь уже известен.
+	HeaderActorTGID = "X-Actor-TG-ID"
+)
+
+type AuditHandler struct {
+	audit service.Audit
+}
+
+func NewAuditHandler(audit service.Audit) *AuditHandler {
+	return &AuditHandler{audit: audit}
+}
+
+// Attach middleware: обработчики изменений записывают журнал через recordAudit
+func (h *AuditHandler) Attach(c *fiber.Ctx) error {
+	c.Locals(auditKey, h.audit)
+	return c.Next()
+}
+
+// List журнал изменений, новые первыми
+// GET /api/admin/:adminUserID/audit?entity=subscription&entity_id=&action=&actor_user_id=&created_at[gte]=&limit=&cursor=
+func (h *AuditHandler) List(c *fiber.Ctx) error {
+	spec, err := listSpec(c, auditrepo.Fields, 50)
+	if err != nil {
+		return err
+	}
+	page, err := h.audit.List(spec)
+	if err != nil {
+		return err
+	}
+	return sendPage(c, page)
+}
+
+// recordAudit записывает изменение в журнал. Изменение уже сохранено,
+// поэтому ошибка журнала только пишется в лог и не меняет ответ.
+func recordAudit(c *fiber.Ctx, entity db.AuditEntity, entityID string, before, after any) {
+	audit, _ := c.Locals(auditKey).(service.Audit)
+	if audit == nil {
+		return
+	}
+	if err := audit.Record(currentActor(c), entity, entityID, before, after); err != nil {
+		log.Printf("[ERROR] AUDIT: Failed to record %s %s: %v", entity, entityID, err)
+	}
+}
+
+// recordRestore записывает в журнал восстановление из корзины, ошибка только пишется в лог
+func recordRestore(c *fiber.Ctx, entity db.AuditEntity, entityID string, deleted any) {
+	audit, _ := c.Locals(auditKey).(service.Audit)
+	if audit == nil {
+		return
+	}
+	if err := audit.Restored(currentActor(c), entity, entityID, deleted); err != nil {
+		log.Printf("[ERROR] AUDIT: Failed to record restore of %s %s: %v", entity, entityID, err)
+	}
+}
+
+// currentActor кто вносит изменение: владелец ключа, администратор из пути
+// или администратор бота из заголовка HeaderActorTGID
+func currentActor(c *fiber.Ctx) service.Actor {
+	principal := currentPrincipal(c)
+	if principal == nil {
+		return service.Actor{}
+	}
+	actor := service.Actor{KeyID: principal.KeyID, Name: principal.Name, UserID: principal.UserID}
+	if actor.UserID != "" {
+		return actor
+	}
+	// CheckAdminAccess уже проверил, что это администратор
+	actor.UserID = c.Params("adminUserID")
+	if tgID, err := strconv.ParseInt(c.Get(HeaderActorTGID), 10, 64); err == nil && principal.IsAdmin() {
+		actor.TGID = tgID
+	}
+	return actor
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	keyrepo "github.com/WhoYa/subscription-manager/internal/repository/apikey"
+	"github.com/WhoYa/subscription-manager/internal/service"
+	"github.com/WhoYa/subscription-manager/pkg/apierr"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// principalKey ключ в c.Locals, под которым лежит *service.Principal запроса
+	principalKey = "principal"
+	// headerAPIKey альтернатива заголовку Authorization
+	headerAPIKey = "X-API-Key"
+)
+
+type AuthHandler struct {
+	auth   service.Auth
+	webApp service.WebAppAuth // nil — вход через Mini App выключен
+}
+
+func NewAuthHandler(auth service.Auth, webApp service.WebAppAuth) *AuthHandler {
+	return &AuthHandler{auth: auth, webApp: webApp}
+}
+
+// Authenticate middleware: ключ берётся из "Authorization: Bearer <key>" или "X-API-Key: <key>"
+func (h *AuthHandler) Authenticate(c *fiber.Ctx) error {
+	token := c.Get(headerAPIKey)
+	if auth := c.Get(fiber.HeaderAuthorization); token == "" && auth != "" {
+		scheme, value, _ := strings.Cut(auth, " ")
+		if strings.EqualFold(scheme, "Bearer") {
+			token = strings.TrimSpace(value)
+		}
+	}
+
+	principal, err := h.auth.Authenticate(token)
+	if err != nil {
+		if errors.Is(err, service.ErrUnauthorized) {
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="api"`)
+			return err
+		}
+		return err
+	}
+
+	c.Locals(principalKey, principal)
+	return c.Next()
+}
+
+// RequireAdmin middleware: только ключи с правами администратора
+func RequireAdmin(c *fiber.Ctx) error {
+	if !currentPrincipal(c).IsAdmin() {
+		return forbidden("admin access required")
+	}
+	return c.Next()
+}
+
+// RequireUser middleware: администратор или сам пользователь из параметра маршрута param
+func RequireUser(param string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !currentPrincipal(c).CanAccessUser(c.Params(param)) {
+			return forbidden("access to this user is not allowed")
+		}
+		return c.Next()
+	}
+}
+
+// currentPrincipal от чьего имени выполняется запрос; nil — запрос не прошёл Authenticate
+func currentPrincipal(c *fiber.Ctx) *service.Principal {
+	principal, _ := c.Locals(principalKey).(*service.Principal)
+	return principal
+}
+
+// Me GET /api/auth/me — кому принадлежит ключ запроса
+func (h *AuthHandler) Me(c *fiber.Ctx) error {
+	return c.JSON(currentPrincipal(c))
+}
+
+// TelegramLogin вход участника через Telegram Mini App: initData из Telegram.WebApp.initData
+// обменивается на ключ сессии, который дальше передаётся в Authorization
+// POST /api/auth/telegram
+func (h *AuthHandler) TelegramLogin(c *fiber.Ctx) error {
+	if h.webApp == nil {
+		return apierr.New(503, apierr.CodeUnavailable, "telegram login is not configured")
+	}
+
+	var body struct {
+		InitData string `json:"init_data"`
+	}
+	if err := c.BodyParser(&body); err != nil || body.InitData == "" {
+		return badRequest("init_data is required")
+	}
+
+	session, err := h.webApp.Login(body.InitData)
+	if err != nil {
+		// участнику без записи в системе вход закрыт, а не «не найден»
+		if errors.Is(err, service.ErrUserNotFound) {
+			return apierr.New(403, apierr.CodeUserNotFound, err.Error())
+		}
+		return err
+	}
+	return c.Status(201).JSON(session)
+}
+
+// Create выпускает ключ; сам ключ есть только в этом ответе
+// POST /api/admin/:adminUserID/api_keys
+func (h *AuthHandler) Create(c *fiber.Ctx) error {
+	var body struct {
+		Name      string `json:"name"`
+		Scope     string `json:"scope"`
+		UserID    string `json:"user_id"`
+		ExpiresAt string `json:"expires_at"` // YYYY-MM-DD, пусто — бессрочно
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return badRequest("invalid request")
+	}
+
+	expiresAt, err := optionalDate(body.ExpiresAt)
+	if err != nil {
+		return badRequest("invalid expires_at format, use YYYY-MM-DD")
+	}
+
+	key := db.APIKey{
+		Name:      body.Name,
+		Scope:     db.APIKeyScope(body.Scope),
+		UserID:    optionalString(body.UserID),
+		ExpiresAt: expiresAt,
+	}
+	token, err := h.auth.Issue(&key)
+	if err != nil {
+		return err
+	}
+	recordAudit(c, db.AuditAPIKey, key.ID, nil, key)
+	return c.Status(201).JSON(fiber.Map{
+		"key":     token,
+		"api_key": key,
+	})
+}
+
+// List GET /api/admin/:adminUserID/api_keys?user_id=&scope=member&sort=&limit=&cursor=
+func (h *AuthHandler) List(c *fiber.Ctx) error {
+	spec, err := listSpec(c, keyrepo.Fields, 50)
+	if err != nil {
+		return err
+	}
+	page, err := h.auth.List(spec)
+	if err != nil {
+		return err
+	}
+	return sendPage(c, page)
+}
+
+// Revoke DELETE /api/admin/:adminUserID/api_keys/:id
+func (h *AuthHandler) Revoke(c *fiber.Ctx) error {
+	before, err := h.auth.Get(c.Params("id"))
+	if err != nil {
+		return err
+	}
+	key, err := h.auth.Revoke(before.ID)
+	if err != nil {
+		return err
+	}
+	recordAudit(c, db.AuditAPIKey, key.ID, before, key)
+	return c.SendStatus(204)
+}
@@ -24,7 +24,7 @@ func (h *CalculateHandler) CalculatePayment(c *fiber.Ctx) error {
 	subscriptionID := c.Params("subscriptionID")
 
 	if userID == "" || subscriptionID == "" {
-		return c.Status(400).JSON(fiber.Map{"error": "userID and subscriptionID are required"})
+		return badRequest("userID and subscriptionID are required")
 	}
 
 	// Парсим дату списания из query параметра
@@ -35,7 +35,7 @@ func (h *CalculateHandler) CalculatePayment(c *fiber.Ctx) error {
 	if dueDateParam != "" {
 		dueDate, err = time.Parse("2006-01-02", dueDateParam)
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{"error": "invalid due_date format, use YYYY-MM-DD"})
+			return badRequest("invalid due_date format, use YYYY-MM-DD")
 		}
 	} else {
 		// По умолчанию завтра
@@ -45,7 +45,7 @@ func (h *CalculateHandler) CalculatePayment(c *fiber.Ctx) error {
 	// Рассчитываем сумму
 	payment, err := h.paymentService.CalculateUserPayment(userID, subscriptionID, dueDate)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
 	return c.JSON(payment)
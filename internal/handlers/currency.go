@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"github.com/WhoYa/subscription-manager/internal/service"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CurrencyHandler struct {
+	currencies service.Currencies
+}
+
+func NewCurrencyHandler(currencies service.Currencies) *CurrencyHandler {
+	return &CurrencyHandler{currencies: currencies}
+}
+
+// List справочник валют, по умолчанию только включённые
+// GET /api/currencies?all=true
+func (h *CurrencyHandler) List(c *fiber.Ctx) error {
+	list, err := h.currencies.List(!c.QueryBool("all"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(list)
+}
+
+// Create добавляет валюту в справочник
+// POST /api/admin/:adminUserID/currencies
+func (h *CurrencyHandler) Create(c *fiber.Ctx) error {
+	var body struct {
+		Code        string `json:"code"`
+		Name        string `json:"name"`
+		Symbol      string `json:"symbol"`
+		MinorDigits *int   `json:"minor_digits"` // по умолчанию 2
+		Enabled     *bool  `json:"enabled"`      // по умолчанию true
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return badRequest("invalid request")
+	}
+
+	info := db.CurrencyInfo{
+		Code:        db.Currency(body.Code),
+		Name:        body.Name,
+		Symbol:      body.Symbol,
+		MinorDigits: 2,
+		Enabled:     true,
+	}
+	if body.MinorDigits != nil {
+		info.MinorDigits = *body.MinorDigits
+	}
+	if body.Enabled != nil {
+		info.Enabled = *body.Enabled
+	}
+
+	if err := h.currencies.Create(&info); err != nil {
+		return err
+	}
+	recordAudit(c, db.AuditCurrency, string(info.Code), nil, info)
+	return c.Status(201).JSON(info)
+}
+
+// Update меняет название, символ, число знаков или включает/выключает валюту
+// PATCH /api/admin/:adminUserID/currencies/:code
+func (h *CurrencyHandler) Update(c *fiber.Ctx) error {
+	info, err := h.currencies.Get(db.Currency(c.Params("code")))
+	if err != nil {
+		return err
+	}
+
+	before := *info
+
+	var body struct {
+		Name        *string `json:"name"`
+		Symbol      *string `json:"symbol"`
+		MinorDigits *int    `json:"minor_digits"`
+		Enabled     *bool   `json:"enabled"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return badRequest("invalid request")
+	}
+	if body.Name != nil {
+		info.Name = *body.Name
+	}
+	if body.Symbol != nil {
+		info.Symbol = *body.Symbol
+	}
+	if body.MinorDigits != nil {
+		info.MinorDigits = *body.MinorDigits
+	}
+	if body.Enabled != nil {
+		info.Enabled = *body.Enabled
+	}
+
+	if err := h.currencies.Update(info); err != nil {
+		return err
+	}
+	recordAudit(c, db.AuditCurrency, string(info.Code), before, info)
+	return c.JSON(info)
+}
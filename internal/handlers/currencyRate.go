@@ -2,56 +2,69 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
-	"strconv"
 	"time"
 
 	repo "github.com/WhoYa/subscription-manager/internal/repository/currencyrate"
+	"github.com/WhoYa/subscription-manager/internal/service"
+	"github.com/WhoYa/subscription-manager/pkg/apierr"
 	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/WhoYa/subscription-manager/pkg/money"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
 type CurrencyRateHandler struct {
-	repo repo.CurrencyRateRepository
+	repo       repo.CurrencyRateRepository
+	converter  service.Converter
+	currencies service.Currencies
 }
 
-func NewCurrencyRateHandler(r repo.CurrencyRateRepository) *CurrencyRateHandler {
-	return &CurrencyRateHandler{repo: r}
+func NewCurrencyRateHandler(r repo.CurrencyRateRepository, converter service.Converter, currencies service.Currencies) *CurrencyRateHandler {
+	return &CurrencyRateHandler{repo: r, converter: converter, currencies: currencies}
 }
 
 func (h *CurrencyRateHandler) Create(c *fiber.Ctx) error {
 	var body struct {
-		Currency  string  `json:"currency"`
-		Value     float64 `json:"value"`
-		Source    string  `json:"source"`
-		FetchedAt string  `json:"fetched_at"` // optional ISO8601
+		Currency      string        `json:"currency"`
+		QuoteCurrency string        `json:"quote_currency"` // optional, RUB по умолчанию
+		Value         money.Decimal `json:"value"`
+		Source        string        `json:"source"`
+		FetchedAt     string        `json:"fetched_at"` // optional ISO8601
 	}
 	if err := c.BodyParser(&body); err != nil {
-		return fiber.NewError(http.StatusBadRequest, "invalid JSON")
+		return badRequest("invalid JSON")
 	}
 
-	curr := db.Currency(body.Currency)
-	switch curr {
-	case db.USD, db.EUR, db.RUB:
-	default:
-		return fiber.NewError(http.StatusBadRequest, "unsupported currency")
+	curr, err := h.currencies.Validate(body.Currency)
+	if err != nil {
+		return err
+	}
+	quote := db.RUB
+	if body.QuoteCurrency != "" {
+		if quote, err = h.currencies.Validate(body.QuoteCurrency); err != nil {
+			return fmt.Errorf("quote_currency: %w", err)
+		}
+	}
+	if quote == curr {
+		return badRequest("currency and quote_currency must differ")
 	}
-	if body.Value <= 0 {
-		return fiber.NewError(http.StatusBadRequest, "value must be > 0")
+	if body.Value.Sign() <= 0 {
+		return badRequest("value must be > 0")
 	}
 	src := db.RateSource(body.Source)
 	switch src {
 	case db.Cifra, db.FF, db.Manual:
 	default:
-		return fiber.NewError(http.StatusBadRequest, "unsupported source")
+		return badRequest("unsupported source")
 	}
 
 	var fetched time.Time
 	if body.FetchedAt != "" {
 		t, err := time.Parse(time.RFC3339, body.FetchedAt)
 		if err != nil {
-			return fiber.NewError(http.StatusBadRequest, "invalid fetched_at")
+			return badRequest("invalid fetched_at")
 		}
 		fetched = t
 	} else {
@@ -59,14 +72,16 @@ func (h *CurrencyRateHandler) Create(c *fiber.Ctx) error {
 	}
 
 	cr := db.CurrencyRate{
-		Currency:  curr,
-		Value:     body.Value,
-		Source:    src,
-		FetchedAt: fetched,
+		Currency:      curr,
+		QuoteCurrency: quote,
+		Value:         body.Value,
+		Source:        src,
+		FetchedAt:     fetched,
 	}
 	if err := h.repo.Create(&cr); err != nil {
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
+		return err
 	}
+	recordAudit(c, db.AuditCurrencyRate, cr.ID, nil, cr)
 	return c.Status(http.StatusCreated).JSON(cr)
 }
 
@@ -75,95 +90,177 @@ func (h *CurrencyRateHandler) Get(c *fiber.Ctx) error {
 	id := c.Params("id")
 	cr, err := h.repo.FindByID(id)
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return fiber.NewError(http.StatusNotFound, "currency rate not found")
+		return notFound(apierr.CodeCurrencyRateNotFound, "currency rate not found")
 	} else if err != nil {
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	return c.JSON(cr)
 }
 
-// List with pagination
+// List GET /api/currency_rates?currency=USD&fetched_at[gte]=2025-03-01&limit=&cursor=
 func (h *CurrencyRateHandler) List(c *fiber.Ctx) error {
-	limit, err := strconv.Atoi(c.Query("limit", "25"))
-	if err != nil || limit <= 0 {
-		limit = 25
-	}
-	offset, err := strconv.Atoi(c.Query("offset", "0"))
-	if err != nil || offset < 0 {
-		offset = 0
+	spec, err := listSpec(c, repo.Fields, 25)
+	if err != nil {
+		return err
 	}
-	ary, err := h.repo.List(limit, offset)
+	page, err := h.repo.List(spec)
 	if err != nil {
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
+		return err
 	}
-	return c.JSON(ary)
+	return sendPage(c, page)
 }
 
 // Latest by currency
 func (h *CurrencyRateHandler) Latest(c *fiber.Ctx) error {
-	curr := db.Currency(c.Params("currency"))
-	switch curr {
-	case db.USD, db.EUR, db.RUB:
-	default:
-		return fiber.NewError(http.StatusBadRequest, "unsupported currency")
+	curr, err := h.currencies.Validate(c.Params("currency"))
+	if err != nil {
+		return err
 	}
 	cr, err := h.repo.LatestByCurrency(curr)
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return fiber.NewError(http.StatusNotFound, "no rates for this currency")
+		return notFound(apierr.CodeCurrencyRateNotFound, "no rates for this currency")
 	} else if err != nil {
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	return c.JSON(cr)
 }
 
+// History курсы валюты за период
+// GET /api/currency_rates/history/:currency?from=2025-03-01T00:00:00Z&to=2025-03-31T23:59:59Z
+// по умолчанию — последние 30 дней
+func (h *CurrencyRateHandler) History(c *fiber.Ctx) error {
+	curr, err := h.currencies.Validate(c.Params("currency"))
+	if err != nil {
+		return err
+	}
+
+	to := time.Now().UTC()
+	if toStr := c.Query("to"); toStr != "" {
+		t, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return badRequest("invalid to date")
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -30)
+	if fromStr := c.Query("from"); fromStr != "" {
+		f, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return badRequest("invalid from date")
+		}
+		from = f
+	}
+	if to.Before(from) {
+		return badRequest("to must not be before from")
+	}
+
+	ary, err := h.repo.History(curr, from, to)
+	if err != nil {
+		return err
+	}
+	return c.JSON(ary)
+}
+
+// Convert пересчёт суммы между валютами по прямому или кросс-курсу
+// GET /api/currency_rates/convert?from=USD&to=KZT&amount=10&at=2025-03-01T00:00:00Z
+func (h *CurrencyRateHandler) Convert(c *fiber.Ctx) error {
+	from, err := h.currencies.Validate(c.Query("from"))
+	if err != nil {
+		return fmt.Errorf("from: %w", err)
+	}
+	to, err := h.currencies.Validate(c.Query("to"))
+	if err != nil {
+		return fmt.Errorf("to: %w", err)
+	}
+
+	amount := money.NewFromInt(1)
+	if amountStr := c.Query("amount"); amountStr != "" {
+		a, err := money.Parse(amountStr)
+		if err != nil {
+			return badRequest("invalid amount")
+		}
+		amount = a
+	}
+
+	at := time.Now().UTC()
+	if atStr := c.Query("at"); atStr != "" {
+		t, err := time.Parse(time.RFC3339, atStr)
+		if err != nil {
+			return badRequest("invalid at")
+		}
+		at = t
+	}
+
+	result, conv, err := h.converter.Convert(amount, from, to, at)
+	if errors.Is(err, service.ErrExchangeRateNotFound) {
+		return notFound(apierr.CodeExchangeRateNotFound, err.Error())
+	} else if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"amount":     amount,
+		"result":     result,
+		"conversion": conv,
+	})
+}
+
 // Update
 func (h *CurrencyRateHandler) Update(c *fiber.Ctx) error {
 	id := c.Params("id")
 	existing, err := h.repo.FindByID(id)
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return fiber.NewError(http.StatusNotFound, "currency rate not found")
+		return notFound(apierr.CodeCurrencyRateNotFound, "currency rate not found")
 	} else if err != nil {
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
+	before := *existing
+
 	var body struct {
-		Value     *float64 `json:"value"`
-		Source    *string  `json:"source"`
-		FetchedAt *string  `json:"fetched_at"`
+		Value     *money.Decimal `json:"value"`
+		Source    *string        `json:"source"`
+		FetchedAt *string        `json:"fetched_at"`
 	}
 	if err := c.BodyParser(&body); err != nil {
-		return fiber.NewError(http.StatusBadRequest, "invalid JSON")
+		return badRequest("invalid JSON")
 	}
 
-	if body.Value != nil && *body.Value > 0 {
+	if body.Value != nil && body.Value.Sign() > 0 {
 		existing.Value = *body.Value
 	}
 	if body.Source != nil {
 		src := db.RateSource(*body.Source)
 		if src != db.Cifra && src != db.FF && src != db.Manual {
-			return fiber.NewError(http.StatusBadRequest, "unsupported source")
+			return badRequest("unsupported source")
 		}
 		existing.Source = src
 	}
 	if body.FetchedAt != nil {
 		t, err := time.Parse(time.RFC3339, *body.FetchedAt)
 		if err != nil {
-			return fiber.NewError(http.StatusBadRequest, "invalid fetched_at")
+			return badRequest("invalid fetched_at")
 		}
 		existing.FetchedAt = t
 	}
 	existing.UpdatedAt = time.Now().UTC()
 
 	if err := h.repo.Update(existing); err != nil {
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
+		return err
 	}
+	recordAudit(c, db.AuditCurrencyRate, existing.ID, before, existing)
 	return c.JSON(existing)
 }
 
 // Delete
 func (h *CurrencyRateHandler) Delete(c *fiber.Ctx) error {
-	if err := h.repo.Delete(c.Params("id")); err != nil {
-		return fiber.NewError(http.StatusInternalServerError, err.Error())
+	id := c.Params("id")
+	before, err := h.repo.FindByID(id)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err := h.repo.Delete(id); err != nil {
+		return err
 	}
+	recordAudit(c, db.AuditCurrencyRate, id, before, nil)
 	return c.SendStatus(http.StatusNoContent)
 }
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	currepo "github.com/WhoYa/subscription-manager/internal/repository/currency"
+	invrepo "github.com/WhoYa/subscription-manager/internal/repository/invoice"
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	subrepo "github.com/WhoYa/subscription-manager/internal/repository/subscription"
+	userrepo "github.com/WhoYa/subscription-manager/internal/repository/user"
+	usrepo "github.com/WhoYa/subscription-manager/internal/repository/usersubscription"
+	"github.com/WhoYa/subscription-manager/internal/service"
+	"github.com/WhoYa/subscription-manager/pkg/apierr"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/WhoYa/subscription-manager/pkg/money"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// sentinelErrors HTTP-статус и код для ошибок сервисов и репозиториев, которые обработчик
+// возвращает как есть. Проверяются по порядку через errors.Is.
+var sentinelErrors = []struct {
+	err    error
+	status int
+	code   apierr.Code
+}{
+	{service.ErrUserNotFound, http.StatusNotFound, apierr.CodeUserNotFound},
+	{service.ErrSubscriptionNotFound, http.StatusNotFound, apierr.CodeSubscriptionNotFound},
+	{service.ErrUserSubscriptionNotFound, http.StatusNotFound, apierr.CodeUserSubscriptionNotFound},
+	{service.ErrInvoiceNotFound, http.StatusNotFound, apierr.CodeInvoiceNotFound},
+	{service.ErrPaymentClaimNotFound, http.StatusNotFound, apierr.CodePaymentClaimNotFound},
+	{service.ErrPricingRuleNotFound, http.StatusNotFound, apierr.CodePricingRuleNotFound},
+	{service.ErrAPIKeyNotFound, http.StatusNotFound, apierr.CodeAPIKeyNotFound},
+	{service.ErrCurrencyNotFound, http.StatusNotFound, apierr.CodeCurrencyNotFound},
+
+	{service.ErrCurrencyExists, http.StatusConflict, apierr.CodeCurrencyExists},
+	{currepo.ErrDuplicateCurrency, http.StatusConflict, apierr.CodeCurrencyExists},
+	{service.ErrInvoiceExists, http.StatusConflict, apierr.CodeInvoiceExists},
+	{invrepo.ErrDuplicateInvoice, http.StatusConflict, apierr.CodeInvoiceExists},
+	{service.ErrInvalidInvoiceTransition, http.StatusConflict, apierr.CodeInvalidInvoiceTransition},
+	{service.ErrPaymentClaimResolved, http.StatusConflict, apierr.CodePaymentClaimResolved},
+	{service.ErrMembershipEnded, http.StatusConflict, apierr.CodeMembershipEnded},
+	{userrepo.ErrDuplicateTGID, http.StatusConflict, apierr.CodeDuplicateTGID},
+	{subrepo.ErrDuplicateServiceName, http.StatusConflict, apierr.CodeDuplicateServiceName},
+	{usrepo.ErrDuplicateUserSubscription, http.StatusConflict, apierr.CodeDuplicateUserSubscription},
+	{service.ErrIdempotencyKeyInProgress, http.StatusConflict, apierr.CodeIdempotencyKeyInProgress},
+
+	{service.ErrUnsupportedCurrency, http.StatusBadRequest, apierr.CodeUnsupportedCurrency},
+	{service.ErrInvalidCurrency, http.StatusBadRequest, apierr.CodeInvalidCurrency},
+	{db.ErrInvalidSplit, http.StatusBadRequest, apierr.CodeInvalidSplit},
+	{service.ErrInvalidPricingRule, http.StatusBadRequest, apierr.CodeInvalidPricingRule},
+	{service.ErrInvalidPaymentClaim, http.StatusBadRequest, apierr.CodeInvalidPaymentClaim},
+	{service.ErrInvalidAdjustment, http.StatusBadRequest, apierr.CodeInvalidAdjustment},
+	{service.ErrInvalidLeaveDate, http.StatusBadRequest, apierr.CodeInvalidLeaveDate},
+	{service.ErrInvalidQuietHours, http.StatusBadRequest, apierr.CodeInvalidQuietHours},
+	{service.ErrInvalidAPIKey, http.StatusBadRequest, apierr.CodeInvalidAPIKey},
+	{service.ErrPaymentNotCalculated, http.StatusBadRequest, apierr.CodePaymentNotCalculated},
+	{service.ErrInvalidInitData, http.StatusUnauthorized, apierr.CodeInvalidInitData},
+	{service.ErrUnauthorized, http.StatusUnauthorized, apierr.CodeUnauthorized},
+	{service.ErrExchangeRateNotFound, http.StatusUnprocessableEntity, apierr.CodeExchangeRateNotFound},
+	{service.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, apierr.CodeIdempotencyKeyReused},
+	{money.ErrOverflow, http.StatusUnprocessableEntity, apierr.CodeAmountOutOfRange},
+	{money.ErrDivisionByZero, http.StatusUnprocessableEntity, apierr.CodeAmountOutOfRange},
+	{query.ErrInvalidSpec, http.StatusBadRequest, apierr.CodeInvalidQuery},
+
+	{gorm.ErrRecordNotFound, http.StatusNotFound, apierr.CodeNotFound},
+}
+
+// ErrorHandler центральный обработчик ошибок fiber: отвечает application/problem+json (RFC 7807).
+// Понимает *apierr.Error, *fiber.Error и ошибки из sentinelErrors; остальное — 500
+// без подробностей в ответе, причина пишется в лог.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	apiErr := toAPIError(err)
+	if apiErr.Status >= http.StatusInternalServerError {
+		log.Printf("[ERROR] %s %s: %v", c.Method(), c.OriginalURL(), err)
+	}
+
+	return c.Status(apiErr.Status).JSON(apiErr.Problem(c.Path()), apierr.ContentType)
+}
+
+// toAPIError переводит ошибку обработчика в ошибку API со статусом и кодом
+func toAPIError(err error) *apierr.Error {
+	var apiErr *apierr.Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		// маршрут не найден, метод не поддерживается, слишком большое тело
+		return apierr.New(fiberErr.Code, "", fiberErr.Message)
+	}
+	for _, s := range sentinelErrors {
+		if errors.Is(err, s.err) {
+			return apierr.New(s.status, s.code, err.Error())
+		}
+	}
+	return apierr.New(http.StatusInternalServerError, apierr.CodeInternal, http.StatusText(http.StatusInternalServerError))
+}
+
+// badRequest неверный запрос: не разбирается тело или параметр
+func badRequest(detail string) error {
+	return apierr.New(http.StatusBadRequest, apierr.CodeInvalidRequest, detail)
+}
+
+// notFound сущность не найдена
+func notFound(code apierr.Code, detail string) error {
+	return apierr.New(http.StatusNotFound, code, detail)
+}
+
+// conflict запрос противоречит текущему состоянию
+func conflict(code apierr.Code, detail string) error {
+	return apierr.New(http.StatusConflict, code, detail)
+}
+
+// forbidden у ключа нет прав на запрос
+func forbidden(detail string) error {
+	return apierr.New(http.StatusForbidden, apierr.CodeForbidden, detail)
+}
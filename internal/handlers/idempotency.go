@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+
+	"github.com/WhoYa/subscription-manager/internal/service"
+	"github.com/WhoYa/subscription-manager/pkg/apierr"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// HeaderIdempotencyKey ключ, по которому повтор POST-запроса получает первый ответ
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed есть в ответе, который отдан из сохранённого, а не выполнен заново
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+	// maxIdempotencyKeyLength длина колонки ключа
+	maxIdempotencyKeyLength = 255
+)
+
+type IdempotencyHandler struct {
+	idempotency service.Idempotency
+}
+
+func NewIdempotencyHandler(idempotency service.Idempotency) *IdempotencyHandler {
+	return &IdempotencyHandler{idempotency: idempotency}
+}
+
+// Handle middleware: POST с заголовком Idempotency-Key выполняется один раз, повтор с тем же
+// ключом и телом получает сохранённый ответ. Сохраняются только успешные ответы: изменения
+// выполняются в транзакции, и запрос с ошибкой, в том числе 5xx, ничего не сохранил —
+// его повтор с тем же ключом выполняется заново.
+func (h *IdempotencyHandler) Handle(c *fiber.Ctx) error {
+	key := c.Get(HeaderIdempotencyKey)
+	principal := currentPrincipal(c)
+	if c.Method() != fiber.MethodPost || key == "" || principal == nil {
+		return c.Next()
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return apierr.New(fiber.StatusBadRequest, apierr.CodeInvalidIdempotencyKey, "idempotency key is too long")
+	}
+
+	record, err := h.idempotency.Begin(principal.KeyID, key, requestHash(c))
+	if err != nil {
+		return err
+	}
+	if record.Status != 0 {
+		c.Set(HeaderIdempotentReplayed, "true")
+		c.Set(fiber.HeaderContentType, record.ContentType)
+		return c.Status(record.Status).Send(record.Response)
+	}
+
+	if err := c.Next(); err != nil {
+		// ответ с ошибкой формируется здесь, чтобы знать его статус
+		if err := c.App().ErrorHandler(c, err); err != nil {
+			return err
+		}
+	}
+
+	status := c.Response().StatusCode()
+	if status >= fiber.StatusBadRequest {
+		if err := h.idempotency.Release(record); err != nil {
+			log.Printf("[ERROR] IDEMPOTENCY: %v", err)
+		}
+		return nil
+	}
+	body := append([]byte(nil), c.Response().Body()...)
+	if err := h.idempotency.Complete(record, status, string(c.Response().Header.ContentType()), body); err != nil {
+		// ответ уже готов; повтор с этим ключом получит 409, пока не истечёт аренда ключа
+		log.Printf("[ERROR] IDEMPOTENCY: %v", err)
+	}
+	return nil
+}
+
+// requestHash SHA-256 метода, пути с параметрами и тела: ключ с другим запросом отклоняется
+func requestHash(c *fiber.Ctx) string {
+	sum := sha256.New()
+	sum.Write([]byte(c.Method() + " " + c.OriginalURL() + "\n"))
+	sum.Write(c.Body())
+	return hex.EncodeToString(sum.Sum(nil))
+}
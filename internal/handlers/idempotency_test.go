@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/WhoYa/subscription-manager/internal/service"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/gofiber/fiber/v2"
+)
+
+// memIdempotency сервис ключей в памяти без проверки хэша и аренды
+type memIdempotency struct {
+	service.Idempotency
+	records map[string]*db.IdempotencyKey
+}
+
+func (s *memIdempotency) Begin(_, key, hash string) (*db.IdempotencyKey, error) {
+	if r, ok := s.records[key]; ok {
+		return r, nil
+	}
+	r := &db.IdempotencyKey{Key: key, RequestHash: hash}
+	s.records[key] = r
+	return r, nil
+}
+
+func (s *memIdempotency) Complete(r *db.IdempotencyKey, status int, contentType string, body []byte) error {
+	r.Status, r.ContentType, r.Response = status, contentType, body
+	return nil
+}
+
+func (s *memIdempotency) Release(r *db.IdempotencyKey) error {
+	delete(s.records, r.Key)
+	return nil
+}
+
+func TestIdempotencyHandle(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		wantReplayed bool
+		wantCalls    int
+	}{
+		{"success is replayed", nil, true, 1},
+		{"4xx is released", badRequest("invalid request"), false, 2},
+		{"5xx is released", fiber.NewError(fiber.StatusInternalServerError, "boom"), false, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idem := &memIdempotency{records: map[string]*db.IdempotencyKey{}}
+			calls := 0
+
+			app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals(principalKey, &service.Principal{KeyID: "api-key", Scope: db.KeyScopeAdmin})
+				return c.Next()
+			})
+			app.Use(NewIdempotencyHandler(idem).Handle)
+			app.Post("/payments", func(c *fiber.Ctx) error {
+				calls++
+				if tt.err != nil {
+					return tt.err
+				}
+				return c.Status(fiber.StatusCreated).JSON(fiber.Map{"id": "p1"})
+			})
+
+			var statuses []int
+			var bodies []string
+			var replayed []string
+			for range 2 {
+				req := httptest.NewRequest(fiber.MethodPost, "/payments", nil)
+				req.Header.Set(HeaderIdempotencyKey, "k")
+				resp, err := app.Test(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body, _ := io.ReadAll(resp.Body)
+				statuses = append(statuses, resp.StatusCode)
+				bodies = append(bodies, string(body))
+				replayed = append(replayed, resp.Header.Get(HeaderIdempotentReplayed))
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("handler called %d times, want %d", calls, tt.wantCalls)
+			}
+			if statuses[0] != statuses[1] || bodies[0] != bodies[1] {
+				t.Errorf("retry got %d %s, first response %d %s", statuses[1], bodies[1], statuses[0], bodies[0])
+			}
+			if got := replayed[1] == "true"; got != tt.wantReplayed {
+				t.Errorf("retry replayed = %v, want %v", got, tt.wantReplayed)
+			}
+			if replayed[0] != "" {
+				t.Error("first response marked as replayed")
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	invrepo "github.com/WhoYa/subscription-manager/internal/repository/invoice"
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	"github.com/WhoYa/subscription-manager/internal/service"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/gofiber/fiber/v2"
+)
+
+type InvoiceHandler struct {
+	repo      invrepo.InvoiceRepository
+	invoicing service.Invoicing
+}
+
+func NewInvoiceHandler(r invrepo.InvoiceRepository, invoicing service.Invoicing) *InvoiceHandler {
+	return &InvoiceHandler{repo: r, invoicing: invoicing}
+}
+
+var validInvoiceStatuses = map[db.InvoiceStatus]struct{}{
+	db.InvoiceDraft:         {},
+	db.InvoiceIssued:        {},
+	db.InvoicePartiallyPaid: {},
+	db.InvoicePaid:          {},
+	db.InvoiceOverdue:       {},
+	db.InvoiceVoided:        {},
+}
+
+// Create создаёт черновик счёта, а при issue=true сразу выставляет его
+// POST /api/users/:userID/invoices
+func (h *InvoiceHandler) Create(c *fiber.Ctx) error {
+	userID := c.Params("userID")
+	var body struct {
+		SubscriptionID string `json:"subscription_id"`
+		DueDate        string `json:"due_date"` // YYYY-MM-DD, по умолчанию сегодня
+		Issue          bool   `json:"issue"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return badRequest("invalid request")
+	}
+	if body.SubscriptionID == "" {
+		return badRequest("subscription_id is required")
+	}
+
+	dueDate := time.Now().UTC()
+	if body.DueDate != "" {
+		t, err := time.Parse("2006-01-02", body.DueDate)
+		if err != nil {
+			return badRequest("invalid due_date format, use YYYY-MM-DD")
+		}
+		dueDate = t
+	}
+
+	inv, err := h.invoicing.CreateDraft(userID, body.SubscriptionID, dueDate)
+	if err != nil {
+		return err
+	}
+	if body.Issue {
+		if inv, err = h.invoicing.Issue(inv.ID); err != nil {
+			return err
+		}
+	}
+	recordAudit(c, db.AuditInvoice, inv.ID, nil, inv)
+	return c.Status(201).JSON(inv)
+}
+
+// Get GET /api/invoices/:id
+func (h *InvoiceHandler) Get(c *fiber.Ctx) error {
+	inv, err := h.invoicing.Get(c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(inv)
+}
+
+// List возвращает счета с фильтрами
+// GET /api/invoices?user_id=&subscription_id=&status=outstanding&from=2025-03-01&to=2025-03-31&amount[gte]=&sort=&limit=&cursor=
+// status принимает список через запятую; outstanding = issued,partially_paid,overdue.
+// from и to — включительно по дате списания, остальные фильтры — по invrepo.Fields
+func (h *InvoiceHandler) List(c *fiber.Ctx) error {
+	return h.list(c, c.Query("user_id"))
+}
+
+// ListByUser GET /api/users/:userID/invoices
+func (h *InvoiceHandler) ListByUser(c *fiber.Ctx) error {
+	return h.list(c, c.Params("userID"))
+}
+
+func (h *InvoiceHandler) list(c *fiber.Ctx, userID string) error {
+	spec, err := listSpec(c, invrepo.Fields, 25, "status", "from", "to")
+	if err != nil {
+		return err
+	}
+	if userID != "" {
+		spec.Where("user_id", query.Eq, userID)
+	}
+
+	if statusParam := c.Query("status"); statusParam != "" {
+		var statuses []any
+		for _, st := range strings.Split(statusParam, ",") {
+			if st == "outstanding" {
+				for _, s := range db.OutstandingInvoiceStatuses {
+					statuses = append(statuses, s)
+				}
+				continue
+			}
+			status := db.InvoiceStatus(st)
+			if _, ok := validInvoiceStatuses[status]; !ok {
+				return badRequest("unknown invoice status: " + st)
+			}
+			statuses = append(statuses, status)
+		}
+		spec.Where("status", query.In, statuses)
+	}
+
+	if fromStr := c.Query("from"); fromStr != "" {
+		f, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			return badRequest("invalid from date, use YYYY-MM-DD")
+		}
+		spec.Where("due_date", query.Gte, f)
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		t, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			return badRequest("invalid to date, use YYYY-MM-DD")
+		}
+		spec.Where("due_date", query.Lte, t.AddDate(0, 0, 1).Add(-time.Nanosecond)) // включительно
+	}
+
+	page, err := h.repo.List(spec)
+	if err != nil {
+		return err
+	}
+	return sendPage(c, page)
+}
+
+// Issue POST /api/invoices/:id/issue
+func (h *InvoiceHandler) Issue(c *fiber.Ctx) error {
+	before, err := h.invoicing.Get(c.Params("id"))
+	if err != nil {
+		return err
+	}
+	inv, err := h.invoicing.Issue(before.ID)
+	if err != nil {
+		return err
+	}
+	recordAudit(c, db.AuditInvoice, inv.ID, before, inv)
+	return c.JSON(inv)
+}
+
+// Void POST /api/invoices/:id/void
+func (h *InvoiceHandler) Void(c *fiber.Ctx) error {
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&body); err != nil {
+			return badRequest("invalid request")
+		}
+	}
+	if len(body.Reason) > 500 {
+		return badRequest("reason must be at most 500 characters")
+	}
+
+	before, err := h.invoicing.Get(c.Params("id"))
+	if err != nil {
+		return err
+	}
+	inv, err := h.invoicing.Void(before.ID, body.Reason)
+	if err != nil {
+		return err
+	}
+	recordAudit(c, db.AuditInvoice, inv.ID, before, inv)
+	return c.JSON(inv)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	userRepo "github.com/WhoYa/subscription-manager/internal/repository/user"
+	"github.com/WhoYa/subscription-manager/internal/service"
+	"github.com/WhoYa/subscription-manager/pkg/apierr"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type LedgerHandler struct {
+	ledger     service.Ledger
+	userRepo   userRepo.UserRepository
+	currencies service.Currencies
+}
+
+func NewLedgerHandler(ledger service.Ledger, uRepo userRepo.UserRepository, currencies service.Currencies) *LedgerHandler {
+	return &LedgerHandler{ledger: ledger, userRepo: uRepo, currencies: currencies}
+}
+
+// Balance возвращает текущий баланс участника и выписку за период в одной валюте
+// GET /api/users/:id/balance?from=2025-03-01&to=2025-03-31&currency=USD
+// без from выписка строится с начала истории, без to — по текущий момент,
+// без currency — в валюте расчётов участника
+func (h *LedgerHandler) Balance(c *fiber.Ctx) error {
+	userID := c.Params("id")
+	user, err := h.userRepo.FindByID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFound(apierr.CodeUserNotFound, "user not found")
+	} else if err != nil {
+		return err
+	}
+
+	from := time.Unix(0, 0).UTC()
+	if fromStr := c.Query("from"); fromStr != "" {
+		f, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			return badRequest("invalid from date, use YYYY-MM-DD")
+		}
+		from = f
+	}
+	to := time.Now().UTC()
+	if toStr := c.Query("to"); toStr != "" {
+		t, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			return badRequest("invalid to date, use YYYY-MM-DD")
+		}
+		to = t.AddDate(0, 0, 1).Add(-time.Nanosecond) // включительно
+	}
+	if to.Before(from) {
+		return badRequest("to must not be before from")
+	}
+	currency, err := h.currency(c.Query("currency"), user)
+	if err != nil {
+		return err
+	}
+
+	st, err := h.ledger.Statement(userID, currency, from, to)
+	if err != nil {
+		return err
+	}
+	return c.JSON(st)
+}
+
+// Adjust ручная корректировка баланса участника
+// POST /api/admin/:adminUserID/users/:userID/adjustments
+// amount в копейках: > 0 — в пользу участника, < 0 — доначисление;
+// без currency корректировка проводится в валюте расчётов участника
+func (h *LedgerHandler) Adjust(c *fiber.Ctx) error {
+	userID := c.Params("userID")
+	var body struct {
+		Amount      int64  `json:"amount"`
+		Currency    string `json:"currency"`
+		Description string `json:"description"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return badRequest("invalid request")
+	}
+	if len(body.Description) > 500 {
+		return badRequest("description must be at most 500 characters")
+	}
+
+	user, err := h.userRepo.FindByID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFound(apierr.CodeUserNotFound, "user not found")
+	} else if err != nil {
+		return err
+	}
+	currency, err := h.currency(body.Currency, user)
+	if err != nil {
+		return err
+	}
+
+	entry, err := h.ledger.Adjust(userID, body.Amount, currency, body.Description, c.Params("adminUserID"))
+	if err != nil {
+		return err
+	}
+	recordAudit(c, db.AuditLedgerEntry, entry.ID, nil, entry)
+	return c.Status(201).JSON(entry)
+}
+
+// currency валюта лицевого счёта из запроса; пустая — валюта расчётов участника
+func (h *LedgerHandler) currency(code string, user *db.User) (db.Currency, error) {
+	if code == "" {
+		return service.SettlementCurrency(user), nil
+	}
+	currency, err := h.currencies.Validate(code)
+	if err != nil {
+		return "", fmt.Errorf("currency: %w", err)
+	}
+	return currency, nil
+}
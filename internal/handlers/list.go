@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Заголовки ответа со списком: тело остаётся массивом, как и раньше
+const (
+	HeaderTotalCount = "X-Total-Count" // всего записей по фильтрам
+	HeaderNextCursor = "X-Next-Cursor" // курсор следующей страницы; нет заголовка — страница последняя
+)
+
+// listSpec разбирает limit, offset, cursor, sort и фильтры по полям репозитория.
+// Параметры own обработчик разбирает сам.
+func listSpec(c *fiber.Ctx, fields query.Fields, defaultLimit int, own ...string) (query.Spec, error) {
+	spec, err := query.Parse(c.Queries(), fields, own...)
+	if err != nil {
+		return spec, err
+	}
+	if spec.Limit == 0 {
+		spec.Limit = defaultLimit
+	}
+	return spec, nil
+}
+
+// sendPage отдаёт записи страницы, общее число и курсор следующей страницы
+func sendPage[T any](c *fiber.Ctx, page query.Page[T]) error {
+	c.Set(HeaderTotalCount, strconv.FormatInt(page.Total, 10))
+	if page.NextCursor != "" {
+		c.Set(HeaderNextCursor, page.NextCursor)
+	}
+	return c.JSON(page.Items)
+}
@@ -0,0 +1,12 @@
+package handlers
+
+import (
+	"github.com/WhoYa/subscription-manager/api"
+	"github.com/gofiber/fiber/v2"
+)
+
+// OpenAPI GET /api/openapi.json — описание API для клиентов и генераторов кода
+func OpenAPI(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	return c.Send(api.Spec)
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"time"
+
+	claimrepo "github.com/WhoYa/subscription-manager/internal/repository/paymentclaim"
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	"github.com/WhoYa/subscription-manager/internal/service"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PaymentClaimHandler struct {
+	claims service.PaymentClaims
+}
+
+func NewPaymentClaimHandler(claims service.PaymentClaims) *PaymentClaimHandler {
+	return &PaymentClaimHandler{claims: claims}
+}
+
+// Create участник сообщает об оплате
+// POST /api/users/:userID/payment_claims
+// {"subscription_id": "...", "amount": 49900, "currency": "RUB", "method": "card", "receipt_file_id": "...", "paid_at": "..."}
+func (h *PaymentClaimHandler) Create(c *fiber.Ctx) error {
+	var body struct {
+		SubscriptionID string `json:"subscription_id"`
+		InvoiceID      string `json:"invoice_id"` // опционально - по умолчанию самый старый неоплаченный счёт
+		Amount         int64  `json:"amount"`     // копейки
+		Currency       string `json:"currency"`   // опционально - по умолчанию валюта расчётов участника
+		Method         string `json:"method"`     // card, transfer, cash, other
+		ReceiptFileID  string `json:"receipt_file_id"`
+		PaidAt         string `json:"paid_at"` // RFC3339, по умолчанию сейчас
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return badRequest("invalid request")
+	}
+
+	claim := db.PaymentClaim{
+		UserID:         c.Params("userID"),
+		SubscriptionID: body.SubscriptionID,
+		InvoiceID:      optionalString(body.InvoiceID),
+		Amount:         body.Amount,
+		Currency:       db.Currency(body.Currency),
+		Method:         db.PaymentMethod(body.Method),
+		ReceiptFileID:  body.ReceiptFileID,
+	}
+	if body.PaidAt != "" {
+		paidAt, err := time.Parse(time.RFC3339, body.PaidAt)
+		if err != nil {
+			return badRequest("invalid paid_at")
+		}
+		claim.PaidAt = paidAt
+	}
+
+	if err := h.claims.Submit(&claim); err != nil {
+		return err
+	}
+	recordAudit(c, db.AuditPaymentClaim, claim.ID, nil, claim)
+	return c.Status(201).JSON(claim)
+}
+
+// ListByUser сообщения участника об оплате
+// GET /api/users/:userID/payment_claims?status=pending&sort=-created_at&limit=&cursor=
+func (h *PaymentClaimHandler) ListByUser(c *fiber.Ctx) error {
+	spec, err := listSpec(c, claimrepo.Fields, 50)
+	if err != nil {
+		return err
+	}
+	spec.Where("user_id", query.Eq, c.Params("userID"))
+	return h.list(c, spec)
+}
+
+// Queue очередь сообщений на подтверждение, старые первыми; без status — только pending
+// GET /api/admin/:adminUserID/payment_claims?status=pending&user_id=&amount[gte]=&limit=&cursor=
+func (h *PaymentClaimHandler) Queue(c *fiber.Ctx) error {
+	spec, err := listSpec(c, claimrepo.Fields, 50)
+	if err != nil {
+		return err
+	}
+	if !spec.Has("status") {
+		spec.Where("status", query.Eq, db.ClaimPending)
+	}
+	return h.list(c, spec)
+}
+
+func (h *PaymentClaimHandler) list(c *fiber.Ctx, spec query.Spec) error {
+	page, err := h.claims.List(spec)
+	if err != nil {
+		return err
+	}
+	return sendPage(c, page)
+}
+
+// Get GET /api/admin/:adminUserID/payment_claims/:id
+func (h *PaymentClaimHandler) Get(c *fiber.Ctx) error {
+	claim, err := h.claims.Get(c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(claim)
+}
+
+// Approve подтверждает оплату: регистрирует платёж и гасит счёт
+// POST /api/admin/:adminUserID/payment_claims/:id/approve
+func (h *PaymentClaimHandler) Approve(c *fiber.Ctx) error {
+	before, err := h.claims.Get(c.Params("id"))
+	if err != nil {
+		return err
+	}
+	claim, err := h.claims.Approve(before.ID, c.Params("adminUserID"))
+	if err != nil {
+		return err
+	}
+	recordAudit(c, db.AuditPaymentClaim, claim.ID, before, claim)
+	return c.JSON(claim)
+}
+
+// Reject отклоняет сообщение об оплате
+// POST /api/admin/:adminUserID/payment_claims/:id/reject {"reason": "Платёж не найден"}
+func (h *PaymentClaimHandler) Reject(c *fiber.Ctx) error {
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return badRequest("invalid request")
+	}
+
+	before, err := h.claims.Get(c.Params("id"))
+	if err != nil {
+		return err
+	}
+	claim, err := h.claims.Reject(before.ID, c.Params("adminUserID"), body.Reason)
+	if err != nil {
+		return err
+	}
+	recordAudit(c, db.AuditPaymentClaim, claim.ID, before, claim)
+	return c.JSON(claim)
+}
@@ -5,82 +5,71 @@ import (
 	"time"
 
 	"github.com/WhoYa/subscription-manager/internal/repository/paymentlog"
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
 	"github.com/WhoYa/subscription-manager/internal/service"
+	"github.com/WhoYa/subscription-manager/pkg/apierr"
 	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/WhoYa/subscription-manager/pkg/money"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
 type PaymentLogHandler struct {
-	repo           paymentlog.PaymentLogRepository
-	paymentService service.Service
+	repo       paymentlog.PaymentLogRepository
+	payments   service.Payments
+	currencies service.Currencies
 }
 
-func NewPaymentLogHandler(r paymentlog.PaymentLogRepository, paymentService service.Service) *PaymentLogHandler {
+func NewPaymentLogHandler(
+	r paymentlog.PaymentLogRepository,
+	payments service.Payments,
+	currencies service.Currencies,
+) *PaymentLogHandler {
 	return &PaymentLogHandler{
-		repo:           r,
-		paymentService: paymentService,
+		repo:       r,
+		payments:   payments,
+		currencies: currencies,
 	}
 }
 
 func (h *PaymentLogHandler) Create(c *fiber.Ctx) error {
-	userID := c.Params("userID")
 	var body struct {
-		SubscriptionID string  `json:"subscription_id"`
-		Amount         int64   `json:"amount"` // опционально - можем рассчитать автоматически
-		Currency       string  `json:"currency"`
-		RateUsed       float64 `json:"rate_used"` // опционально - можем взять текущий
-		PaidAt         string  `json:"paid_at"`   // ISO8601
+		SubscriptionID string        `json:"subscription_id"`
+		Amount         int64         `json:"amount"`     // опционально - можем рассчитать автоматически
+		Currency       string        `json:"currency"`   // опционально - валюта суммы amount, по умолчанию валюта расчётов
+		RateUsed       money.Decimal `json:"rate_used"`  // опционально - можем взять текущий
+		PaidAt         string        `json:"paid_at"`    // ISO8601
+		InvoiceID      string        `json:"invoice_id"` // опционально - по умолчанию самый старый неоплаченный счёт
 	}
 	if err := c.BodyParser(&body); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+		return badRequest("invalid request")
 	}
 
 	paidAt, err := time.Parse(time.RFC3339, body.PaidAt)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid paid_at"})
+		return badRequest("invalid paid_at")
 	}
 
-	curr := db.Currency(body.Currency)
-	if curr != db.USD && curr != db.EUR && curr != db.RUB {
-		return c.Status(400).JSON(fiber.Map{"error": "unsupported currency"})
+	var curr db.Currency
+	if body.Currency != "" {
+		if curr, err = h.currencies.Validate(body.Currency); err != nil {
+			return err
+		}
 	}
 
-	// Рассчитываем платеж чтобы получить базовую сумму и прибыль
-	paymentCalc, err := h.paymentService.CalculateUserPayment(userID, body.SubscriptionID, paidAt)
-	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "failed to calculate payment: " + err.Error()})
-	}
-
-	// Используем рассчитанные значения или переданные пользователем
-	finalAmount := paymentCalc.Amount // копейки
-	if body.Amount > 0 {
-		finalAmount = body.Amount // если пользователь передал свою сумму
-	}
-
-	finalRate := paymentCalc.ExchangeRate
-	if body.RateUsed > 0 {
-		finalRate = body.RateUsed // если пользователь передал свой курс
-	}
-
-	// Рассчитываем базовую сумму и прибыль в копейках
-	baseAmountKopecks := int64(paymentCalc.BaseAmount * 100)
-	profitAmountKopecks := int64(paymentCalc.ProfitAmount * 100)
-
-	pl := db.PaymentLog{
-		UserID:         userID,
+	pl, err := h.payments.Record(service.PaymentRequest{
+		UserID:         c.Params("userID"),
 		SubscriptionID: body.SubscriptionID,
-		Amount:         finalAmount,
-		BaseAmount:     baseAmountKopecks,
-		ProfitAmount:   profitAmountKopecks,
+		InvoiceID:      body.InvoiceID,
+		Amount:         body.Amount,
 		Currency:       curr,
-		RateUsed:       finalRate,
+		RateUsed:       body.RateUsed,
 		PaidAt:         paidAt,
+	})
+	if err != nil {
+		return err
 	}
-
-	if err := h.repo.Create(&pl); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-	}
+	recordAudit(c, db.AuditPayment, pl.ID, nil, pl)
 	return c.Status(201).JSON(pl)
 }
 
@@ -88,65 +77,60 @@ func (h *PaymentLogHandler) Get(c *fiber.Ctx) error {
 	id := c.Params("id")
 	pl, err := h.repo.FindByID(id)
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return c.Status(404).JSON(fiber.Map{"error": "payment not found"})
+		return notFound(apierr.CodePaymentNotFound, "payment not found")
 	} else if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 	return c.JSON(pl)
 }
 
+// ListByUser платежи пользователя
+// GET /api/users/:userID/payments?from=&to=&subscription_id=&amount[gte]=&sort=&limit=&cursor=
 func (h *PaymentLogHandler) ListByUser(c *fiber.Ctx) error {
-	userID := c.Params("userID")
-	fromStr, toStr := c.Query("from"), c.Query("to")
-	f, err := time.Parse(time.RFC3339, fromStr)
-	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid from date"})
-	}
-	t, err := time.Parse(time.RFC3339, toStr)
-	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid to date"})
-	}
-	logs, err := h.repo.FindByUser(userID, f, t)
-
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-	}
-	return c.JSON(logs)
+	return h.list(c, "user_id", c.Params("userID"))
 }
 
+// ListBySubscription платежи по подписке
+// GET /api/subscriptions/:subID/payments?from=&to=&sort=&limit=&cursor=
 func (h *PaymentLogHandler) ListBySubscription(c *fiber.Ctx) error {
-	subID := c.Params("subID")
-	fromStr, toStr := c.Query("from"), c.Query("to")
-	f, err := time.Parse(time.RFC3339, fromStr)
-	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid from date"})
-	}
-	t, err := time.Parse(time.RFC3339, toStr)
-	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid to date"})
-	}
-	logs, err := h.repo.FindBySubscription(subID, f, t)
-
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-	}
-	return c.JSON(logs)
+	return h.list(c, "subscription_id", c.Params("subID"))
 }
 
+// ListAll все платежи
+// GET /api/payments?from=&to=&user_id=&currency=&sort=&limit=&cursor=
 func (h *PaymentLogHandler) ListAll(c *fiber.Ctx) error {
-	fromStr, toStr := c.Query("from"), c.Query("to")
-	f, err := time.Parse(time.RFC3339, fromStr)
+	return h.list(c, "", "")
+}
+
+// list платежи по фильтрам; from и to (RFC 3339) ограничивают дату оплаты включительно.
+// Без limit отдаются все платежи, как до появления пагинации;
+// field и value — условие из пути запроса
+func (h *PaymentLogHandler) list(c *fiber.Ctx, field, value string) error {
+	spec, err := listSpec(c, paymentlog.Fields, 0, "from", "to")
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid from date"})
+		return err
 	}
-	t, err := time.Parse(time.RFC3339, toStr)
-	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid to date"})
+	if field != "" {
+		spec.Where(field, query.Eq, value)
+	}
+	if fromStr := c.Query("from"); fromStr != "" {
+		f, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return badRequest("invalid from date")
+		}
+		spec.Where("paid_at", query.Gte, f)
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		t, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return badRequest("invalid to date")
+		}
+		spec.Where("paid_at", query.Lte, t)
 	}
-	logs, err := h.repo.FindAll(f, t)
 
+	page, err := h.repo.List(spec)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
-	return c.JSON(logs)
+	return sendPage(c, page)
 }
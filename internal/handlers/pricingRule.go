@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	prrepo "github.com/WhoYa/subscription-manager/internal/repository/pricingrule"
+	"github.com/WhoYa/subscription-manager/internal/service"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/WhoYa/subscription-manager/pkg/money"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PricingRuleHandler struct {
+	rules service.PricingRules
+}
+
+func NewPricingRuleHandler(rules service.PricingRules) *PricingRuleHandler {
+	return &PricingRuleHandler{rules: rules}
+}
+
+// pricingRuleBody тело запроса на создание и изменение правила; nil — поле не меняется.
+// Пустая строка в currency, valid_from и valid_to снимает ограничение.
+type pricingRuleBody struct {
+	Name           *string        `json:"name"`
+	Kind           *string        `json:"kind"`
+	Scope          *string        `json:"scope"`
+	UserID         *string        `json:"user_id"`
+	SubscriptionID *string        `json:"subscription_id"`
+	Value          *money.Decimal `json:"value"`
+	Currency       *string        `json:"currency"`
+	Priority       *int           `json:"priority"`
+	ValidFrom      *string        `json:"valid_from"` // YYYY-MM-DD
+	ValidTo        *string        `json:"valid_to"`   // YYYY-MM-DD, не включительно
+}
+
+// apply переносит заданные поля в правило
+func (b *pricingRuleBody) apply(rule *db.PricingRule) error {
+	if b.Name != nil {
+		rule.Name = *b.Name
+	}
+	if b.Kind != nil {
+		rule.Kind = db.PricingRuleKind(*b.Kind)
+	}
+	if b.Scope != nil {
+		rule.Scope = db.PricingRuleScope(*b.Scope)
+	}
+	if b.UserID != nil {
+		rule.UserID = optionalString(*b.UserID)
+	}
+	if b.SubscriptionID != nil {
+		rule.SubscriptionID = optionalString(*b.SubscriptionID)
+	}
+	if b.Value != nil {
+		rule.Value = *b.Value
+	}
+	if b.Currency != nil {
+		rule.Currency = nil
+		if *b.Currency != "" {
+			curr := db.Currency(*b.Currency)
+			rule.Currency = &curr
+		}
+	}
+	if b.Priority != nil {
+		rule.Priority = *b.Priority
+	}
+	if b.ValidFrom != nil {
+		t, err := optionalDate(*b.ValidFrom)
+		if err != nil {
+			return errors.New("invalid valid_from format, use YYYY-MM-DD")
+		}
+		rule.ValidFrom = t
+	}
+	if b.ValidTo != nil {
+		t, err := optionalDate(*b.ValidTo)
+		if err != nil {
+			return errors.New("invalid valid_to format, use YYYY-MM-DD")
+		}
+		rule.ValidTo = t
+	}
+	return nil
+}
+
+// Create добавляет правило ценообразования
+// POST /api/admin/:adminUserID/pricing_rules
+func (h *PricingRuleHandler) Create(c *fiber.Ctx) error {
+	var body pricingRuleBody
+	if err := c.BodyParser(&body); err != nil {
+		return badRequest("invalid request")
+	}
+	if body.Kind == nil || body.Scope == nil || body.Value == nil {
+		return badRequest("kind, scope and value are required")
+	}
+
+	var rule db.PricingRule
+	if err := body.apply(&rule); err != nil {
+		return badRequest(err.Error())
+	}
+	if err := h.rules.Create(&rule); err != nil {
+		return err
+	}
+	recordAudit(c, db.AuditPricingRule, rule.ID, nil, rule)
+	return c.Status(201).JSON(rule)
+}
+
+// List правила, по умолчанию в порядке применения
+// GET /api/admin/:adminUserID/pricing_rules?scope=user&user_id=&subscription_id=&sort=&limit=&cursor=
+func (h *PricingRuleHandler) List(c *fiber.Ctx) error {
+	spec, err := listSpec(c, prrepo.Fields, 50)
+	if err != nil {
+		return err
+	}
+	page, err := h.rules.List(spec)
+	if err != nil {
+		return err
+	}
+	return sendPage(c, page)
+}
+
+// Get GET /api/admin/:adminUserID/pricing_rules/:id
+func (h *PricingRuleHandler) Get(c *fiber.Ctx) error {
+	rule, err := h.rules.Get(c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(rule)
+}
+
+// Update меняет переданные поля правила
+// PATCH /api/admin/:adminUserID/pricing_rules/:id
+func (h *PricingRuleHandler) Update(c *fiber.Ctx) error {
+	rule, err := h.rules.Get(c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	before := *rule
+
+	var body pricingRuleBody
+	if err := c.BodyParser(&body); err != nil {
+		return badRequest("invalid request")
+	}
+	if err := body.apply(rule); err != nil {
+		return badRequest(err.Error())
+	}
+	if err := h.rules.Update(rule); err != nil {
+		return err
+	}
+	recordAudit(c, db.AuditPricingRule, rule.ID, before, rule)
+	return c.JSON(rule)
+}
+
+// Delete DELETE /api/admin/:adminUserID/pricing_rules/:id
+func (h *PricingRuleHandler) Delete(c *fiber.Ctx) error {
+	before, err := h.rules.Get(c.Params("id"))
+	if err != nil {
+		return err
+	}
+	if err := h.rules.Delete(before.ID); err != nil {
+		return err
+	}
+	recordAudit(c, db.AuditPricingRule, before.ID, before, nil)
+	return c.SendStatus(204)
+}
+
+// optionalString пустая строка — nil
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// optionalDate разбирает YYYY-MM-DD; пустая строка — nil
+func optionalDate(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
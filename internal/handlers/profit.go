@@ -6,6 +6,8 @@ import (
 
 	"github.com/WhoYa/subscription-manager/internal/repository/user"
 	"github.com/WhoYa/subscription-manager/internal/service"
+	"github.com/WhoYa/subscription-manager/pkg/apierr"
+	"github.com/WhoYa/subscription-manager/pkg/db"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
@@ -13,12 +15,14 @@ import (
 type ProfitHandler struct {
 	profitService service.ProfitAnalytics
 	userRepo      user.UserRepository
+	currencies    service.Currencies
 }
 
-func NewProfitHandler(profitService service.ProfitAnalytics, userRepo user.UserRepository) *ProfitHandler {
+func NewProfitHandler(profitService service.ProfitAnalytics, userRepo user.UserRepository, currencies service.Currencies) *ProfitHandler {
 	return &ProfitHandler{
 		profitService: profitService,
 		userRepo:      userRepo,
+		currencies:    currencies,
 	}
 }
 
@@ -26,43 +30,48 @@ func NewProfitHandler(profitService service.ProfitAnalytics, userRepo user.UserR
 func (h *ProfitHandler) CheckAdminAccess(c *fiber.Ctx) error {
 	userID := c.Params("adminUserID")
 	if userID == "" {
-		return c.Status(400).JSON(fiber.Map{"error": "admin user ID is required"})
+		return badRequest("admin user ID is required")
 	}
 
 	user, err := h.userRepo.FindByID(userID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return c.Status(404).JSON(fiber.Map{"error": "user not found"})
+			return notFound(apierr.CodeUserNotFound, "user not found")
 		}
-		return c.Status(500).JSON(fiber.Map{"error": "failed to check user"})
+		return err
 	}
 
 	if !user.IsAdmin {
-		return c.Status(403).JSON(fiber.Map{"error": "access denied: admin privileges required"})
+		return forbidden("access denied: admin privileges required")
 	}
 
 	return c.Next()
 }
 
 // GetMonthlyProfit возвращает прибыль за месяц
-// GET /api/admin/:adminUserID/profit/monthly/:year/:month
+// GET /api/admin/:adminUserID/profit/monthly/:year/:month?currency=RUB
 func (h *ProfitHandler) GetMonthlyProfit(c *fiber.Ctx) error {
 	yearStr := c.Params("year")
 	monthStr := c.Params("month")
 
 	year, err := strconv.Atoi(yearStr)
 	if err != nil || year < 2020 || year > 2030 {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid year"})
+		return badRequest("invalid year")
 	}
 
 	month, err := strconv.Atoi(monthStr)
 	if err != nil || month < 1 || month > 12 {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid month"})
+		return badRequest("invalid month")
 	}
 
-	stats, err := h.profitService.GetMonthlyProfit(year, month)
+	currency, err := h.reportCurrency(c)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
+	}
+
+	stats, err := h.profitService.GetMonthlyProfit(year, month, currency)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(stats)
@@ -75,62 +84,77 @@ func (h *ProfitHandler) GetUserProfitStats(c *fiber.Ctx) error {
 	toStr := c.Query("to")
 
 	if fromStr == "" || toStr == "" {
-		return c.Status(400).JSON(fiber.Map{"error": "from and to query parameters are required"})
+		return badRequest("from and to query parameters are required")
 	}
 
 	from, err := time.Parse(time.RFC3339, fromStr)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid from date format"})
+		return badRequest("invalid from date format")
 	}
 
 	to, err := time.Parse(time.RFC3339, toStr)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid to date format"})
+		return badRequest("invalid to date format")
 	}
 
 	stats, err := h.profitService.GetUserProfitStats(from, to)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
 	return c.JSON(stats)
 }
 
 // GetSubscriptionProfitStats возвращает статистику прибыли по подпискам
-// GET /api/admin/:adminUserID/profit/subscriptions?from=2024-01-01T00:00:00Z&to=2024-12-31T23:59:59Z
+// GET /api/admin/:adminUserID/profit/subscriptions?from=2024-01-01T00:00:00Z&to=2024-12-31T23:59:59Z&currency=RUB
 func (h *ProfitHandler) GetSubscriptionProfitStats(c *fiber.Ctx) error {
 	fromStr := c.Query("from")
 	toStr := c.Query("to")
 
 	if fromStr == "" || toStr == "" {
-		return c.Status(400).JSON(fiber.Map{"error": "from and to query parameters are required"})
+		return badRequest("from and to query parameters are required")
 	}
 
 	from, err := time.Parse(time.RFC3339, fromStr)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid from date format"})
+		return badRequest("invalid from date format")
 	}
 
 	to, err := time.Parse(time.RFC3339, toStr)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid to date format"})
+		return badRequest("invalid to date format")
+	}
+
+	currency, err := h.reportCurrency(c)
+	if err != nil {
+		return err
 	}
 
-	stats, err := h.profitService.GetSubscriptionProfitStats(from, to)
+	stats, err := h.profitService.GetSubscriptionProfitStats(from, to, currency)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
 	return c.JSON(stats)
 }
 
 // GetTotalProfit возвращает общую прибыль за все время
-// GET /api/admin/:adminUserID/profit/total
+// GET /api/admin/:adminUserID/profit/total?currency=RUB
 func (h *ProfitHandler) GetTotalProfit(c *fiber.Ctx) error {
-	stats, err := h.profitService.GetTotalProfit()
+	currency, err := h.reportCurrency(c)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
+	}
+
+	stats, err := h.profitService.GetTotalProfit(currency)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(stats)
 }
+
+// reportCurrency валюта отчёта из query-параметра currency, по умолчанию RUB
+func (h *ProfitHandler) reportCurrency(c *fiber.Ctx) (db.Currency, error) {
+	return h.currencies.Validate(c.Query("currency", string(db.RUB)))
+}
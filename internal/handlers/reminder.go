@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	userRepo "github.com/WhoYa/subscription-manager/internal/repository/user"
+	"github.com/WhoYa/subscription-manager/internal/service"
+	"github.com/WhoYa/subscription-manager/pkg/apierr"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// reminderHistoryLimit сколько последних напоминаний отдаётся вместе с настройками
+const reminderHistoryLimit = 20
+
+type ReminderHandler struct {
+	userRepo  userRepo.UserRepository
+	reminders service.Reminders // nil — напоминания выключены на сервере
+}
+
+func NewReminderHandler(uRepo userRepo.UserRepository, reminders service.Reminders) *ReminderHandler {
+	return &ReminderHandler{userRepo: uRepo, reminders: reminders}
+}
+
+// Get настройки напоминаний пользователя и последние отправленные напоминания
+// GET /api/users/:id/reminders
+func (h *ReminderHandler) Get(c *fiber.Ctx) error {
+	user, err := h.userRepo.FindByID(c.Params("id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFound(apierr.CodeUserNotFound, "user not found")
+	} else if err != nil {
+		return err
+	}
+
+	resp := fiber.Map{
+		"enabled":     !user.RemindersOff,
+		"quiet_hours": quietHours(user.QuietFrom, user.QuietTo),
+		"history":     []any{},
+	}
+	if h.reminders != nil {
+		history, err := h.reminders.History(user.ID, reminderHistoryLimit)
+		if err != nil {
+			return err
+		}
+		resp["history"] = history
+	}
+	return c.JSON(resp)
+}
+
+// Update включает/выключает напоминания и задаёт тихие часы
+// PUT /api/users/:id/reminders {"enabled": false, "quiet_hours": "23-8"}
+// пустая строка в quiet_hours возвращает общие тихие часы сервера
+func (h *ReminderHandler) Update(c *fiber.Ctx) error {
+	user, err := h.userRepo.FindByID(c.Params("id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFound(apierr.CodeUserNotFound, "user not found")
+	} else if err != nil {
+		return err
+	}
+
+	before := *user
+
+	var body struct {
+		Enabled    *bool   `json:"enabled"`
+		QuietHours *string `json:"quiet_hours"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return badRequest("invalid request")
+	}
+
+	if body.Enabled != nil {
+		user.RemindersOff = !*body.Enabled
+	}
+	if body.QuietHours != nil {
+		user.QuietFrom, user.QuietTo = nil, nil
+		if *body.QuietHours != "" {
+			from, to, err := service.ParseQuietHours(*body.QuietHours)
+			if err != nil {
+				return err
+			}
+			user.QuietFrom, user.QuietTo = &from, &to
+		}
+	}
+	if err := h.userRepo.Update(user); err != nil {
+		return err
+	}
+	recordAudit(c, db.AuditReminderSettings, user.ID, reminderSettings(&before), reminderSettings(user))
+
+	return c.JSON(reminderSettings(user))
+}
+
+// reminderSettings настройки напоминаний пользователя в виде ответа API
+func reminderSettings(user *db.User) fiber.Map {
+	return fiber.Map{
+		"enabled":     !user.RemindersOff,
+		"quiet_hours": quietHours(user.QuietFrom, user.QuietTo),
+	}
+}
+
+// quietHours тихие часы пользователя в виде "22-9"; пусто — общие
+func quietHours(from, to *int) string {
+	if from == nil || to == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d-%d", *from, *to)
+}
@@ -3,54 +3,61 @@ package handlers
 import (
 	"errors"
 	"log"
-	"strconv"
 
 	repo "github.com/WhoYa/subscription-manager/internal/repository/subscription"
+	"github.com/WhoYa/subscription-manager/internal/service"
+	"github.com/WhoYa/subscription-manager/pkg/apierr"
 	dbpkg "github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/WhoYa/subscription-manager/pkg/money"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type SubscriptionHandler struct {
-	repo repo.SubscriptionRepository
+	repo       repo.SubscriptionRepository
+	currencies service.Currencies
 }
 
-func NewSubscriptionHandler(r repo.SubscriptionRepository) *SubscriptionHandler {
-	return &SubscriptionHandler{repo: r}
+func NewSubscriptionHandler(r repo.SubscriptionRepository, currencies service.Currencies) *SubscriptionHandler {
+	return &SubscriptionHandler{repo: r, currencies: currencies}
 }
 
 func (h *SubscriptionHandler) Create(c *fiber.Ctx) error {
 	var body struct {
-		ServiceName  string  `json:"service_name"`
-		BasePrice    float64 `json:"base_price"`
-		BaseCurrency string  `json:"base_currency"`
-		PeriodDays   int     `json:"period_days"`
+		ServiceName  string        `json:"service_name"`
+		BasePrice    money.Decimal `json:"base_price"`
+		BaseCurrency string        `json:"base_currency"`
+		PeriodDays   int           `json:"period_days"`
+		// семейный тариф, по умолчанию none — каждый участник платит полную цену
+		SplitMode         string        `json:"split_mode"`
+		OwnerID           string        `json:"owner_id"`
+		OwnerSharePercent money.Decimal `json:"owner_share_percent"`
 	}
 	if err := c.BodyParser(&body); err != nil {
 		log.Printf("SUBSCRIPTION: Failed to parse request body: %v", err)
-		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+		return badRequest("invalid request")
 	}
 
-	log.Printf("SUBSCRIPTION: Creating subscription request - ServiceName: %s, BasePrice: %.2f, BaseCurrency: %s, PeriodDays: %d",
+	log.Printf("SUBSCRIPTION: Creating subscription request - ServiceName: %s, BasePrice: %s, BaseCurrency: %s, PeriodDays: %d",
 		body.ServiceName, body.BasePrice, body.BaseCurrency, body.PeriodDays)
 
 	if exist, err := h.repo.FindByServiceName(body.ServiceName); err == nil && exist != nil {
 		log.Printf("SUBSCRIPTION: Service with name '%s' already exists", body.ServiceName)
-		return c.Status(409).JSON(fiber.Map{"error": "subscription with this service_name already exists"})
+		return conflict(apierr.CodeDuplicateServiceName, "subscription with this service_name already exists")
 	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		log.Printf("SUBSCRIPTION: Error checking existing service: %v", err)
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
-	curr := dbpkg.Currency(body.BaseCurrency)
-	if curr != dbpkg.USD && curr != dbpkg.EUR {
+	curr, err := h.currencies.Validate(body.BaseCurrency)
+	if err != nil {
 		log.Printf("SUBSCRIPTION: Invalid currency provided: %s", body.BaseCurrency)
-		return c.Status(400).JSON(fiber.Map{"error": "unsupported currency, must be USD or EUR"})
+		return err
 	}
 	if body.PeriodDays <= 0 {
 		log.Printf("SUBSCRIPTION: Invalid period days: %d", body.PeriodDays)
-		return c.Status(400).JSON(fiber.Map{"error": "period_days must be > 0"})
+		return badRequest("period_days must be > 0")
 	}
 
 	s := dbpkg.Subscription{
@@ -59,19 +66,25 @@ func (h *SubscriptionHandler) Create(c *fiber.Ctx) error {
 		BaseCurrency: curr,
 		PeriodDays:   body.PeriodDays,
 		IsActive:     true,
+		SplitMode:    dbpkg.SplitMode(body.SplitMode),
+	}
+	if body.OwnerID != "" {
+		s.OwnerID = &body.OwnerID
+		s.OwnerSharePercent = body.OwnerSharePercent
+	}
+	if err := dbpkg.ValidateSplit(&s); err != nil {
+		return err
 	}
 
 	log.Printf("SUBSCRIPTION: Created subscription struct: %+v", s)
 
 	if err := h.repo.Create(&s); err != nil {
 		log.Printf("SUBSCRIPTION: Failed to create subscription in database: %v", err)
-		if errors.Is(err, repo.ErrDuplicateServiceName) {
-			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
-		}
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
 	log.Printf("SUBSCRIPTION: Subscription created successfully: %+v", s)
+	recordAudit(c, dbpkg.AuditSubscription, s.ID, nil, s)
 	return c.Status(201).JSON(s)
 }
 
@@ -79,56 +92,57 @@ func (h *SubscriptionHandler) Get(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	if _, err := uuid.Parse(id); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid subscription id"})
+		return badRequest("invalid subscription id")
 	}
 	s, err := h.repo.FindByID(id)
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return c.Status(404).JSON(fiber.Map{"error": "subscription not found"})
+		return notFound(apierr.CodeSubscriptionNotFound, "subscription not found")
 	} else if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 	return c.JSON(s)
 }
 
+// List GET /api/subscriptions?is_active=true&base_currency=USD&sort=service_name&limit=&cursor=
 func (h *SubscriptionHandler) List(c *fiber.Ctx) error {
-	limit, err := strconv.Atoi(c.Query("limit", "25"))
-	if err != nil || limit <= 0 {
-		limit = 25
-	}
-	offset, err := strconv.Atoi(c.Query("offset", "0"))
-	if err != nil || offset < 0 {
-		offset = 0
+	spec, err := listSpec(c, repo.Fields, 25)
+	if err != nil {
+		return err
 	}
-
-	subs, err := h.repo.List(limit, offset)
+	page, err := h.repo.List(spec)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
-	return c.JSON(subs)
+	return sendPage(c, page)
 }
 
 func (h *SubscriptionHandler) Update(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if _, err := uuid.Parse(id); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid subscription id"})
+		return badRequest("invalid subscription id")
 	}
 	s, err := h.repo.FindByID(id)
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return c.Status(404).JSON(fiber.Map{"error": "subscription not found"})
+		return notFound(apierr.CodeSubscriptionNotFound, "subscription not found")
 	} else if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
+	before := *s
 
 	var body struct {
-		ServiceName  *string  `json:"service_name"`
-		IconURL      *string  `json:"icon_url"`
-		BasePrice    *float64 `json:"base_price"`
-		BaseCurrency *string  `json:"base_currency"`
-		IsActive     *bool    `json:"is_active"`
-		PeriodDays   *int     `json:"period_days"`
+		ServiceName  *string        `json:"service_name"`
+		IconURL      *string        `json:"icon_url"`
+		BasePrice    *money.Decimal `json:"base_price"`
+		BaseCurrency *string        `json:"base_currency"`
+		IsActive     *bool          `json:"is_active"`
+		PeriodDays   *int           `json:"period_days"`
+		// пустой owner_id снимает владельца
+		SplitMode         *string        `json:"split_mode"`
+		OwnerID           *string        `json:"owner_id"`
+		OwnerSharePercent *money.Decimal `json:"owner_share_percent"`
 	}
 	if err := c.BodyParser(&body); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+		return badRequest("invalid request")
 	}
 
 	if body.ServiceName != nil {
@@ -141,9 +155,9 @@ func (h *SubscriptionHandler) Update(c *fiber.Ctx) error {
 		s.BasePrice = *body.BasePrice
 	}
 	if body.BaseCurrency != nil {
-		curr := dbpkg.Currency(*body.BaseCurrency)
-		if curr != dbpkg.USD && curr != dbpkg.EUR {
-			return c.Status(400).JSON(fiber.Map{"error": "unsupported currency"})
+		curr, err := h.currencies.Validate(*body.BaseCurrency)
+		if err != nil {
+			return err
 		}
 		s.BaseCurrency = curr
 	}
@@ -152,24 +166,45 @@ func (h *SubscriptionHandler) Update(c *fiber.Ctx) error {
 	}
 	if body.PeriodDays != nil {
 		if *body.PeriodDays <= 0 {
-			return c.Status(400).JSON(fiber.Map{"error": "period_days must be > 0"})
+			return badRequest("period_days must be > 0")
 		}
 		s.PeriodDays = *body.PeriodDays
 	}
+	if body.SplitMode != nil {
+		s.SplitMode = dbpkg.SplitMode(*body.SplitMode)
+	}
+	if body.OwnerID != nil {
+		s.OwnerID = nil
+		if *body.OwnerID != "" {
+			s.OwnerID = body.OwnerID
+		}
+	}
+	if body.OwnerSharePercent != nil {
+		s.OwnerSharePercent = *body.OwnerSharePercent
+	}
+	if err := dbpkg.ValidateSplit(s); err != nil {
+		return err
+	}
 
 	if err := h.repo.Update(s); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
+	recordAudit(c, dbpkg.AuditSubscription, s.ID, before, s)
 	return c.JSON(s)
 }
 
 func (h *SubscriptionHandler) Delete(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if _, err := uuid.Parse(id); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid subscription id"})
+		return badRequest("invalid subscription id")
+	}
+	before, err := h.repo.FindByID(id)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
 	}
 	if err := h.repo.Delete(id); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
+	recordAudit(c, dbpkg.AuditSubscription, id, before, nil)
 	return c.SendStatus(204)
 }
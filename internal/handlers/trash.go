@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+
+	crrepo "github.com/WhoYa/subscription-manager/internal/repository/currencyrate"
+	prrepo "github.com/WhoYa/subscription-manager/internal/repository/pricingrule"
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	subrepo "github.com/WhoYa/subscription-manager/internal/repository/subscription"
+	"github.com/WhoYa/subscription-manager/internal/repository/trash"
+	userrepo "github.com/WhoYa/subscription-manager/internal/repository/user"
+	"github.com/WhoYa/subscription-manager/pkg/apierr"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// TrashHandler корзина: удалённые записи и их восстановление
+type TrashHandler struct {
+	users         userrepo.UserRepository
+	subscriptions subrepo.SubscriptionRepository
+	rates         crrepo.CurrencyRateRepository
+	rules         prrepo.PricingRuleRepository
+}
+
+func NewTrashHandler(
+	users userrepo.UserRepository,
+	subscriptions subrepo.SubscriptionRepository,
+	rates crrepo.CurrencyRateRepository,
+	rules prrepo.PricingRuleRepository,
+) *TrashHandler {
+	return &TrashHandler{users: users, subscriptions: subscriptions, rates: rates, rules: rules}
+}
+
+// Users GET /api/admin/:adminUserID/trash/users?deleted_at[gte]=2025-07-01&limit=&cursor=
+func (h *TrashHandler) Users(c *fiber.Ctx) error {
+	return listTrash(c, userrepo.Fields, h.users.Trash)
+}
+
+// RestoreUser POST /api/admin/:adminUserID/trash/users/:id/restore
+func (h *TrashHandler) RestoreUser(c *fiber.Ctx) error {
+	return restoreFromTrash(c, db.AuditUser, h.users.Restore, h.users.FindByID,
+		notFound(apierr.CodeUserNotFound, "user not found in trash"))
+}
+
+// Subscriptions GET /api/admin/:adminUserID/trash/subscriptions?service_name[contains]=&limit=&cursor=
+func (h *TrashHandler) Subscriptions(c *fiber.Ctx) error {
+	return listTrash(c, subrepo.Fields, h.subscriptions.Trash)
+}
+
+// RestoreSubscription POST /api/admin/:adminUserID/trash/subscriptions/:id/restore
+func (h *TrashHandler) RestoreSubscription(c *fiber.Ctx) error {
+	return restoreFromTrash(c, db.AuditSubscription, h.subscriptions.Restore, h.subscriptions.FindByID,
+		notFound(apierr.CodeSubscriptionNotFound, "subscription not found in trash"))
+}
+
+// CurrencyRates GET /api/admin/:adminUserID/trash/currency_rates?currency=USD&limit=&cursor=
+func (h *TrashHandler) CurrencyRates(c *fiber.Ctx) error {
+	return listTrash(c, crrepo.Fields, h.rates.Trash)
+}
+
+// RestoreCurrencyRate POST /api/admin/:adminUserID/trash/currency_rates/:id/restore
+func (h *TrashHandler) RestoreCurrencyRate(c *fiber.Ctx) error {
+	return restoreFromTrash(c, db.AuditCurrencyRate, h.rates.Restore, h.rates.FindByID,
+		notFound(apierr.CodeCurrencyRateNotFound, "currency rate not found in trash"))
+}
+
+// PricingRules GET /api/admin/:adminUserID/trash/pricing_rules?scope=user&limit=&cursor=
+func (h *TrashHandler) PricingRules(c *fiber.Ctx) error {
+	return listTrash(c, prrepo.Fields, h.rules.Trash)
+}
+
+// RestorePricingRule POST /api/admin/:adminUserID/trash/pricing_rules/:id/restore
+func (h *TrashHandler) RestorePricingRule(c *fiber.Ctx) error {
+	return restoreFromTrash(c, db.AuditPricingRule, h.rules.Restore, h.rules.FindByID,
+		notFound(apierr.CodePricingRuleNotFound, "pricing rule not found in trash"))
+}
+
+// listTrash страница корзины: фильтры по полям списка и deleted_at, недавно удалённые первыми
+func listTrash[T any](c *fiber.Ctx, fields query.Fields, list func(query.Spec) (query.Page[T], error)) error {
+	spec, err := listSpec(c, trash.Fields(fields), 25)
+	if err != nil {
+		return err
+	}
+	page, err := list(spec)
+	if err != nil {
+		return err
+	}
+	return sendPage(c, page)
+}
+
+// restoreFromTrash восстанавливает запись :id, пишет восстановление в журнал
+// и отдаёт запись так же, как её отдаёт обычный GET
+func restoreFromTrash[T any](
+	c *fiber.Ctx,
+	entity db.AuditEntity,
+	restore func(id string) (*T, error),
+	find func(id string) (*T, error),
+	missing error,
+) error {
+	id := c.Params("id")
+	deleted, err := restore(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return missing
+	}
+	if err != nil {
+		return err
+	}
+
+	recordRestore(c, entity, id, deleted)
+
+	restored, err := find(id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(restored)
+}
@@ -2,10 +2,14 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"strconv"
+	"strings"
 
 	repo "github.com/WhoYa/subscription-manager/internal/repository/user"
+	"github.com/WhoYa/subscription-manager/internal/service"
+	"github.com/WhoYa/subscription-manager/pkg/apierr"
 	dbpkg "github.com/WhoYa/subscription-manager/pkg/db"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
@@ -14,11 +18,13 @@ import (
 func Healthz(c *fiber.Ctx) error { return c.SendString("ok") }
 
 type UserHandler struct {
-	repo repo.UserRepository
+	repo       repo.UserRepository
+	ledger     service.Ledger
+	currencies service.Currencies
 }
 
-func NewUserHandler(r repo.UserRepository) *UserHandler {
-	return &UserHandler{repo: r}
+func NewUserHandler(r repo.UserRepository, ledger service.Ledger, currencies service.Currencies) *UserHandler {
+	return &UserHandler{repo: r, ledger: ledger, currencies: currencies}
 }
 
 func (h *UserHandler) Create(c *fiber.Ctx) error {
@@ -27,20 +33,32 @@ func (h *UserHandler) Create(c *fiber.Ctx) error {
 		Username string `json:"username"`
 		Fullname string `json:"fullname"`
 		IsAdmin  bool   `json:"is_admin"`
+		// SettlementCurrency валюта счетов и баланса, RUB по умолчанию
+		SettlementCurrency string `json:"settlement_currency"`
 	}
 	if err := c.BodyParser(&body); err != nil {
 		log.Printf("USER: Failed to parse request body: %v", err)
-		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+		return badRequest("invalid request")
+	}
+
+	settlement := dbpkg.RUB
+	if body.SettlementCurrency != "" {
+		curr, err := h.currencies.Validate(body.SettlementCurrency)
+		if err != nil {
+			return fmt.Errorf("settlement_currency: %w", err)
+		}
+		settlement = curr
 	}
 
 	log.Printf("USER: Creating user request - TGID: %d, Username: %s, Fullname: %s, IsAdmin: %t",
 		body.TGID, body.Username, body.Fullname, body.IsAdmin)
 
 	user := dbpkg.User{
-		TGID:     body.TGID,
-		Username: body.Username,
-		Fullname: body.Fullname,
-		IsAdmin:  body.IsAdmin,
+		TGID:               body.TGID,
+		Username:           body.Username,
+		Fullname:           body.Fullname,
+		IsAdmin:            body.IsAdmin,
+		SettlementCurrency: settlement,
 	}
 
 	log.Printf("USER: Created user struct: %+v", user)
@@ -48,13 +66,11 @@ func (h *UserHandler) Create(c *fiber.Ctx) error {
 	if err := h.repo.Create(&user); err != nil {
 		log.Printf("USER: Failed to create user in database: %v", err)
 		// репозиторий уже переводит PG-ошибку дублирования в ErrDuplicateTGID
-		if errors.Is(err, repo.ErrDuplicateTGID) {
-			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
-		}
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
 	log.Printf("USER: User created successfully: %+v", user)
+	recordAudit(c, dbpkg.AuditUser, user.ID, nil, user)
 	return c.Status(201).JSON(user)
 }
 
@@ -62,45 +78,60 @@ func (h *UserHandler) Get(c *fiber.Ctx) error {
 	id := c.Params("id")
 	u, err := h.repo.FindByID(id)
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return c.Status(404).JSON(fiber.Map{"error": "user not found"})
+		return notFound(apierr.CodeUserNotFound, "user not found")
 	}
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 	return c.JSON(u)
 }
 
+// List GET /api/users?is_admin=false&sort=fullname&limit=&cursor=
 func (h *UserHandler) List(c *fiber.Ctx) error {
-	// дефолты
-	limit, err := strconv.Atoi(c.Query("limit", "25"))
-	if err != nil || limit <= 0 {
-		limit = 25
-	}
-	offset, err := strconv.Atoi(c.Query("offset", "0"))
-	if err != nil || offset < 0 {
-		offset = 0
+	spec, err := listSpec(c, repo.Fields, 25)
+	if err != nil {
+		return err
 	}
-
-	users, err := h.repo.List(limit, offset)
+	page, err := h.repo.List(spec)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
-	return c.JSON(users)
+	return sendPage(c, page)
 }
 
 func (h *UserHandler) Update(c *fiber.Ctx) error {
 	id := c.Params("id")
 	user, err := h.repo.FindByID(id)
-	if err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFound(apierr.CodeUserNotFound, "user not found")
+	} else if err != nil {
+		return err
 	}
+	before := *user
 	var body struct {
 		Username *string `json:"username"`
 		Fullname *string `json:"fullname"`
 		IsAdmin  *bool   `json:"is_admin"`
+
+		SettlementCurrency *string `json:"settlement_currency"`
 	}
 	if err := c.BodyParser(&body); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+		return badRequest("invalid request")
+	}
+	if body.SettlementCurrency != nil && !strings.EqualFold(*body.SettlementCurrency, string(user.SettlementCurrency)) {
+		curr, err := h.currencies.Validate(*body.SettlementCurrency)
+		if err != nil {
+			return fmt.Errorf("settlement_currency: %w", err)
+		}
+		// счета выставляются в валюте расчётов, поэтому сменить её можно только при нулевом балансе в ней
+		balance, err := h.ledger.Balance(user.ID, service.SettlementCurrency(user))
+		if err != nil {
+			return err
+		}
+		if balance != 0 {
+			return conflict(apierr.CodeBalanceNotZero, "settlement_currency can be changed only with zero balance")
+		}
+		user.SettlementCurrency = curr
 	}
 	if body.Username != nil {
 		user.Username = *body.Username
@@ -112,16 +143,22 @@ func (h *UserHandler) Update(c *fiber.Ctx) error {
 		user.IsAdmin = *body.IsAdmin
 	}
 	if err := h.repo.Update(user); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
+	recordAudit(c, dbpkg.AuditUser, user.ID, before, user)
 	return c.JSON(user)
 }
 
 func (h *UserHandler) Delete(c *fiber.Ctx) error {
 	id := c.Params("id")
+	before, err := h.repo.FindByID(id)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
 	if err := h.repo.Delete(id); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
+	recordAudit(c, dbpkg.AuditUser, id, before, nil)
 	return c.SendStatus(204)
 }
 
@@ -129,15 +166,15 @@ func (h *UserHandler) FindByTGID(c *fiber.Ctx) error {
 	tgidStr := c.Params("tgid")
 	tgid, err := strconv.ParseInt(tgidStr, 10, 64)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid tg_id"})
+		return badRequest("invalid tg_id")
 	}
 
 	u, err := h.repo.FindByTGID(tgid)
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return c.Status(404).JSON(fiber.Map{"error": "user not found"})
+		return notFound(apierr.CodeUserNotFound, "user not found")
 	}
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 	return c.JSON(u)
 }
@@ -2,19 +2,24 @@ package handlers
 
 import (
 	"errors"
-	"strconv"
+	"time"
 
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
 	usrepo "github.com/WhoYa/subscription-manager/internal/repository/usersubscription"
+	"github.com/WhoYa/subscription-manager/internal/service"
+	"github.com/WhoYa/subscription-manager/pkg/apierr"
 	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/WhoYa/subscription-manager/pkg/money"
 	"github.com/gofiber/fiber/v2"
 )
 
 type UserSubscriptionHandler struct {
-	repo usrepo.UserSubscriptionRepository
+	repo    usrepo.UserSubscriptionRepository
+	billing service.Billing
 }
 
-func NewUserSubscriptionHandler(r usrepo.UserSubscriptionRepository) *UserSubscriptionHandler {
-	return &UserSubscriptionHandler{repo: r}
+func NewUserSubscriptionHandler(r usrepo.UserSubscriptionRepository, billing service.Billing) *UserSubscriptionHandler {
+	return &UserSubscriptionHandler{repo: r, billing: billing}
 }
 
 var validPricingModes = map[db.PricingMode]struct{}{
@@ -27,63 +32,107 @@ func (h *UserSubscriptionHandler) Create(c *fiber.Ctx) error {
 	userID := c.Params("userID")
 
 	var body struct {
-		SubscriptionID string  `json:"subscription_id"`
-		PricingMode    string  `json:"pricing_mode"`
-		MarkupPercent  float64 `json:"markup_percent"`
-		FixedFee       float64 `json:"fixed_fee"`
+		SubscriptionID string        `json:"subscription_id"`
+		PricingMode    string        `json:"pricing_mode"`
+		MarkupPercent  money.Decimal `json:"markup_percent"`
+		FixedFee       money.Decimal `json:"fixed_fee"`
+		AnchorDate     string        `json:"anchor_date"`  // опционально, YYYY-MM-DD — дата первого списания
+		JoinedAt       string        `json:"joined_at"`    // опционально, YYYY-MM-DD — с какого дня участник в подписке, по умолчанию сегодня
+		ShareWeight    money.Decimal `json:"share_weight"` // вес для подписок с split_mode=weighted, по умолчанию 1
 	}
 	if err := c.BodyParser(&body); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+		return badRequest("invalid request")
+	}
+
+	var anchor time.Time
+	if body.AnchorDate != "" {
+		t, err := time.Parse("2006-01-02", body.AnchorDate)
+		if err != nil {
+			return badRequest("invalid anchor_date format, use YYYY-MM-DD")
+		}
+		anchor = t
+	}
+
+	var joined time.Time
+	if body.JoinedAt != "" {
+		t, err := time.Parse("2006-01-02", body.JoinedAt)
+		if err != nil {
+			return badRequest("invalid joined_at format, use YYYY-MM-DD")
+		}
+		joined = t
 	}
 
 	pm := db.PricingMode(body.PricingMode)
 	if _, ok := validPricingModes[pm]; !ok {
-		return c.Status(400).JSON(fiber.Map{"error": "pricing_mode must be one of none|percent|fixed"})
+		return badRequest("pricing_mode must be one of none|percent|fixed")
 	}
 	// для percent—>markup >0; для fixed—>fixed_fee >0
 	switch pm {
 	case db.Percent:
-		if body.MarkupPercent <= 0 {
-			return c.Status(400).JSON(fiber.Map{"error": "markup_percent must be > 0 for percent mode"})
+		if body.MarkupPercent.Sign() <= 0 {
+			return badRequest("markup_percent must be > 0 for percent mode")
 		}
 	case db.Fixed:
-		if body.FixedFee <= 0 {
-			return c.Status(400).JSON(fiber.Map{"error": "fixed_fee must be > 0 for fixed mode"})
+		if body.FixedFee.Sign() <= 0 {
+			return badRequest("fixed_fee must be > 0 for fixed mode")
 		}
-		if body.MarkupPercent != 0 {
-			return c.Status(400).JSON(fiber.Map{"error": "markup_percent must be 0 for fixed mode"})
+		if !body.MarkupPercent.IsZero() {
+			return badRequest("markup_percent must be 0 for fixed mode")
 		}
 	}
 
+	if body.ShareWeight.Sign() < 0 {
+		return badRequest("share_weight must be > 0")
+	}
+
 	us := db.UserSubscription{
 		UserID:         userID,
 		SubscriptionID: body.SubscriptionID,
 		PricingMode:    pm,
 		MarkupPercent:  body.MarkupPercent,
 		FixedFee:       body.FixedFee,
+		AnchorDate:     anchor,
+		JoinedAt:       joined,
+		ShareWeight:    body.ShareWeight, // 0 — значение по умолчанию из БД
 	}
 
 	if err := h.repo.Create(&us); err != nil {
 		if errors.Is(err, usrepo.ErrDuplicateUserSubscription) {
-			return c.Status(409).JSON(fiber.Map{"error": "user already subscribed to this service"})
+			return conflict(apierr.CodeDuplicateUserSubscription, "user already subscribed to this service")
 		}
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
 	full, err := h.repo.FindByID(us.ID)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
+	recordAudit(c, db.AuditUserSubscription, full.ID, nil, full)
 	return c.Status(201).JSON(full)
 }
 
+// ListByUser подписки пользователя
+// GET /api/users/:userID/subscriptions?subscription_id=&sort=-joined_at&limit=&cursor=
 func (h *UserSubscriptionHandler) ListByUser(c *fiber.Ctx) error {
-	userID := c.Params("userID")
-	limit, _ := strconv.Atoi(c.Query("limit", "25"))
-	offset, _ := strconv.Atoi(c.Query("offset", "0"))
-	list, err := h.repo.FindByUser(userID, limit, offset)
+	spec, err := listSpec(c, usrepo.Fields, 25)
+	if err != nil {
+		return err
+	}
+	spec.Where("user_id", query.Eq, c.Params("userID"))
+
+	page, err := h.repo.List(spec)
+	if err != nil {
+		return err
+	}
+	return sendPage(c, page)
+}
+
+// ListBySubscription участники подписки с их долями в цене
+// GET /api/subscriptions/:subID/members
+func (h *UserSubscriptionHandler) ListBySubscription(c *fiber.Ctx) error {
+	list, err := h.repo.FindBySubscription(c.Params("subID"))
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 	return c.JSON(list)
 }
@@ -93,17 +142,19 @@ func (h *UserSubscriptionHandler) UpdateSettings(c *fiber.Ctx) error {
 
 	us, err := h.repo.FindByID(id)
 	if err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": "subscription link not found"})
+		return notFound(apierr.CodeUserSubscriptionNotFound, "subscription link not found")
 	}
+	before := *us
 
 	var body struct {
-		PricingMode   *string  `json:"pricing_mode"`
-		MarkupPercent *float64 `json:"markup_percent"`
-		FixedFee      *float64 `json:"fixed_fee"`
+		PricingMode   *string        `json:"pricing_mode"`
+		MarkupPercent *money.Decimal `json:"markup_percent"`
+		FixedFee      *money.Decimal `json:"fixed_fee"`
+		ShareWeight   *money.Decimal `json:"share_weight"`
 	}
 
 	if err := c.BodyParser(&body); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+		return badRequest("invalid request")
 	}
 
 	if body.PricingMode != nil {
@@ -115,20 +166,62 @@ func (h *UserSubscriptionHandler) UpdateSettings(c *fiber.Ctx) error {
 	if body.FixedFee != nil {
 		us.FixedFee = *body.FixedFee
 	}
+	if body.ShareWeight != nil {
+		if body.ShareWeight.Sign() <= 0 {
+			return badRequest("share_weight must be > 0")
+		}
+		us.ShareWeight = *body.ShareWeight
+	}
 
 	if err := h.repo.UpdateSettings(us); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
+	recordAudit(c, db.AuditUserSubscription, us.ID, before, us)
 
 	return c.JSON(us)
 }
 
+// Leave отмечает выход участника из подписки; за оплаченные дни после выхода
+// на баланс начисляется возврат
+// POST /api/users/:userID/subscriptions/:id/leave
+func (h *UserSubscriptionHandler) Leave(c *fiber.Ctx) error {
+	us, err := h.repo.FindByID(c.Params("id"))
+	if err != nil || us.UserID != c.Params("userID") {
+		return notFound(apierr.CodeUserSubscriptionNotFound, "subscription link not found")
+	}
+
+	var body struct {
+		Date string `json:"date"` // YYYY-MM-DD, первый день без подписки; по умолчанию сегодня
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return badRequest("invalid request")
+	}
+	at := time.Now().UTC()
+	if body.Date != "" {
+		t, err := time.Parse("2006-01-02", body.Date)
+		if err != nil {
+			return badRequest("invalid date format, use YYYY-MM-DD")
+		}
+		at = t
+	}
+
+	result, err := h.billing.EndMembership(us.ID, at)
+	if err != nil {
+		return err
+	}
+	recordAudit(c, db.AuditUserSubscription, us.ID, us, result.UserSubscription)
+	return c.JSON(result)
+}
+
 func (h *UserSubscriptionHandler) Delete(c *fiber.Ctx) error {
 	id := c.Params("id")
 
+	// связь могла уже не существовать: тогда и записывать в журнал нечего
+	before, _ := h.repo.FindByID(id)
 	if err := h.repo.Delete(id); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
+	recordAudit(c, db.AuditUserSubscription, id, before, nil)
 
 	return c.SendStatus(204)
 }
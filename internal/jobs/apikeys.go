@@ -0,0 +1,33 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/internal/service"
+)
+
+// APIKeysJob удаляет ключи доступа, истёкшие больше retention назад: каждый вход
+// через Mini App выпускает новый ключ-сессию, и без очистки таблица только растёт
+type APIKeysJob struct {
+	auth      service.Auth
+	retention time.Duration
+	interval  time.Duration
+}
+
+// NewAPIKeysJob создаёт задачу очистки истёкших ключей доступа
+func NewAPIKeysJob(auth service.Auth, retention, interval time.Duration) *APIKeysJob {
+	return &APIKeysJob{auth: auth, retention: retention, interval: interval}
+}
+
+func (j *APIKeysJob) Name() string            { return "apikeys" }
+func (j *APIKeysJob) Interval() time.Duration { return j.interval }
+
+func (j *APIKeysJob) Run(_ context.Context) error {
+	purged, err := j.auth.PurgeExpired(time.Now().Add(-j.retention))
+	if purged > 0 {
+		log.Printf("AUTH: %d expired api key(s) purged", purged)
+	}
+	return err
+}
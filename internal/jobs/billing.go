@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/internal/service"
+)
+
+// BillingJob выставляет счета за сутки до даты списания и помечает просроченные
+type BillingJob struct {
+	billing   service.Billing
+	invoicing service.Invoicing
+	interval  time.Duration
+}
+
+// NewBillingJob создаёт задачу выставления счетов
+func NewBillingJob(billing service.Billing, invoicing service.Invoicing, interval time.Duration) *BillingJob {
+	return &BillingJob{billing: billing, invoicing: invoicing, interval: interval}
+}
+
+func (j *BillingJob) Name() string            { return "billing" }
+func (j *BillingJob) Interval() time.Duration { return j.interval }
+
+func (j *BillingJob) Run(_ context.Context) error {
+	now := time.Now().UTC()
+	created, err := j.billing.GenerateInvoices(now)
+	if created > 0 {
+		log.Printf("BILLING: %d invoice(s) created", created)
+	}
+
+	overdue, overdueErr := j.invoicing.MarkOverdue(now)
+	if overdue > 0 {
+		log.Printf("BILLING: %d invoice(s) marked overdue", overdue)
+	}
+	return errors.Join(err, overdueErr)
+}
@@ -0,0 +1,33 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/internal/service"
+)
+
+// IdempotencyJob удаляет ключи идемпотентности старше ttl: повтор запроса
+// ждать так долго не стоит, а таблица растёт с каждым POST
+type IdempotencyJob struct {
+	idempotency service.Idempotency
+	ttl         time.Duration
+	interval    time.Duration
+}
+
+// NewIdempotencyJob создаёт задачу очистки ключей идемпотентности
+func NewIdempotencyJob(idempotency service.Idempotency, ttl, interval time.Duration) *IdempotencyJob {
+	return &IdempotencyJob{idempotency: idempotency, ttl: ttl, interval: interval}
+}
+
+func (j *IdempotencyJob) Name() string            { return "idempotency" }
+func (j *IdempotencyJob) Interval() time.Duration { return j.interval }
+
+func (j *IdempotencyJob) Run(_ context.Context) error {
+	purged, err := j.idempotency.Purge(time.Now().Add(-j.ttl))
+	if purged > 0 {
+		log.Printf("IDEMPOTENCY: %d expired key(s) purged", purged)
+	}
+	return err
+}
@@ -0,0 +1,98 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/internal/rates"
+	crRepo "github.com/WhoYa/subscription-manager/internal/repository/currencyrate"
+	"github.com/WhoYa/subscription-manager/internal/service"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+// fetchTimeout сколько ждём ответа одного источника
+const fetchTimeout = 30 * time.Second
+
+// RatesJob загружает курсы из внешних источников и сохраняет их.
+// При ошибке источника новые курсы не пишутся, в расчётах остаётся последний известный.
+// Курсы валют, которых нет в справочнике или которые выключены, пропускаются.
+type RatesJob struct {
+	providers  []rates.RateProvider
+	repo       crRepo.CurrencyRateRepository
+	currencies service.Currencies
+	interval   time.Duration
+}
+
+// NewRatesJob создаёт задачу обновления курсов
+func NewRatesJob(
+	repo crRepo.CurrencyRateRepository,
+	currencies service.Currencies,
+	interval time.Duration,
+	providers ...rates.RateProvider,
+) *RatesJob {
+	return &RatesJob{providers: providers, repo: repo, currencies: currencies, interval: interval}
+}
+
+func (j *RatesJob) Name() string            { return "rates" }
+func (j *RatesJob) Interval() time.Duration { return j.interval }
+
+func (j *RatesJob) Run(ctx context.Context) error {
+	var errs []error
+	for _, p := range j.providers {
+		if err := j.update(ctx, p); err != nil {
+			log.Printf("RATES: %s fetch failed, keeping last known rates: %v", p.Source(), err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (j *RatesJob) update(ctx context.Context, p rates.RateProvider) error {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	fetched, err := p.Fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range fetched {
+		known, err := j.known(r.Currency, r.Quote)
+		if err != nil {
+			return err
+		}
+		if !known {
+			log.Printf("RATES: %s %s/%s skipped: currency is not enabled", r.Source, r.Currency, r.Quote)
+			continue
+		}
+
+		cr := db.CurrencyRate{
+			Currency:      r.Currency,
+			QuoteCurrency: r.Quote,
+			Value:         r.Value,
+			Source:        r.Source,
+			FetchedAt:     r.FetchedAt,
+		}
+		if err := j.repo.Create(&cr); err != nil {
+			return fmt.Errorf("failed to save %s rate: %w", r.Currency, err)
+		}
+		log.Printf("RATES: %s %s/%s = %s", r.Source, r.Currency, r.Quote, r.Value)
+	}
+	return nil
+}
+
+// known проверяет, что все валюты пары есть в справочнике и включены
+func (j *RatesJob) known(codes ...db.Currency) (bool, error) {
+	for _, code := range codes {
+		if _, err := j.currencies.Validate(string(code)); err != nil {
+			if errors.Is(err, service.ErrUnsupportedCurrency) {
+				return false, nil
+			}
+			return false, err
+		}
+	}
+	return true, nil
+}
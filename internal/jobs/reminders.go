@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/internal/service"
+)
+
+// RemindersJob рассылает участникам напоминания об оплате
+type RemindersJob struct {
+	reminders service.Reminders
+	interval  time.Duration
+}
+
+// NewRemindersJob создаёт задачу напоминаний
+func NewRemindersJob(reminders service.Reminders, interval time.Duration) *RemindersJob {
+	return &RemindersJob{reminders: reminders, interval: interval}
+}
+
+func (j *RemindersJob) Name() string            { return "reminders" }
+func (j *RemindersJob) Interval() time.Duration { return j.interval }
+
+func (j *RemindersJob) Run(_ context.Context) error {
+	sent, err := j.reminders.SendDue(time.Now())
+	if sent > 0 {
+		log.Printf("REMINDERS: %d reminder(s) sent", sent)
+	}
+	return err
+}
@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job периодическая фоновая задача
+type Job interface {
+	Name() string
+	Interval() time.Duration
+	Run(ctx context.Context) error
+}
+
+// Runner запускает зарегистрированные задачи по расписанию
+type Runner struct {
+	jobs []Job
+	wg   sync.WaitGroup
+}
+
+// NewRunner создаёт планировщик с набором задач
+func NewRunner(jobs ...Job) *Runner {
+	return &Runner{jobs: jobs}
+}
+
+// Add регистрирует задачу, вызывать до Start
+func (r *Runner) Add(job Job) {
+	r.jobs = append(r.jobs, job)
+}
+
+// Start запускает каждую задачу в отдельной горутине.
+// Первый запуск происходит сразу, дальше — раз в Interval, до отмены ctx.
+func (r *Runner) Start(ctx context.Context) {
+	for _, job := range r.jobs {
+		r.wg.Add(1)
+		go func(job Job) {
+			defer r.wg.Done()
+			r.loop(ctx, job)
+		}(job)
+	}
+}
+
+// Wait дожидается остановки всех задач
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+func (r *Runner) loop(ctx context.Context, job Job) {
+	log.Printf("JOBS: %s started, interval %s", job.Name(), job.Interval())
+
+	ticker := time.NewTicker(job.Interval())
+	defer ticker.Stop()
+
+	for {
+		if err := job.Run(ctx); err != nil {
+			log.Printf("JOBS: %s failed: %v", job.Name(), err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("JOBS: %s stopped", job.Name())
+			return
+		case <-ticker.C:
+		}
+	}
+}
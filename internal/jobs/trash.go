@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Purger окончательно удаляет записи, удалённые раньше before, и возвращает их число
+type Purger interface {
+	Purge(before time.Time) (int, error)
+}
+
+// TrashJob очищает корзину: записи, удалённые больше retention назад, удаляются
+// окончательно. Записи, на которые ссылается история платежей и счетов, остаются.
+type TrashJob struct {
+	purgers   map[string]Purger // по названию записей для лога
+	retention time.Duration
+	interval  time.Duration
+}
+
+// NewTrashJob создаёт задачу очистки корзины
+func NewTrashJob(retention, interval time.Duration, purgers map[string]Purger) *TrashJob {
+	return &TrashJob{purgers: purgers, retention: retention, interval: interval}
+}
+
+func (j *TrashJob) Name() string            { return "trash" }
+func (j *TrashJob) Interval() time.Duration { return j.interval }
+
+func (j *TrashJob) Run(_ context.Context) error {
+	before := time.Now().Add(-j.retention)
+	var errs []error
+	for name, p := range j.purgers {
+		purged, err := p.Purge(before)
+		if purged > 0 {
+			log.Printf("TRASH: %d %s purged", purged, name)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
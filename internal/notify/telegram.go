@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TelegramAPIURL адрес Bot API
+const TelegramAPIURL = "https://api.telegram.org"
+
+// Telegram отправляет сообщения от имени бота через Bot API
+type Telegram struct {
+	token  string
+	url    string
+	client *http.Client
+}
+
+// NewTelegram создаёт отправителя; пустой url — TelegramAPIURL, nil client — клиент с таймаутом 30 секунд
+func NewTelegram(token, url string, client *http.Client) *Telegram {
+	if url == "" {
+		url = TelegramAPIURL
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Telegram{token: token, url: url, client: client}
+}
+
+// Send отправляет текстовое сообщение в чат. Для личных чатов chatID совпадает с Telegram ID пользователя.
+func (t *Telegram) Send(chatID int64, text string) error {
+	body, err := json.Marshal(map[string]any{
+		"chat_id": chatID,
+		"text":    text,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.client.Post(fmt.Sprintf("%s/bot%s/sendMessage", t.url, t.token), "application/json", bytes.NewReader(body))
+	if err != nil {
+		// в ошибке net/http есть URL вместе с токеном
+		return fmt.Errorf("telegram: request failed: %w", redact(err, t.token))
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("telegram: status %d: %w", resp.StatusCode, err)
+	}
+	if !result.OK {
+		return fmt.Errorf("telegram: status %d: %s", resp.StatusCode, result.Description)
+	}
+	return nil
+}
+
+// redact убирает токен бота из текста ошибки
+func redact(err error, token string) error {
+	return errors.New(strings.ReplaceAll(err.Error(), token, "***"))
+}
@@ -0,0 +1,69 @@
+package rates
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+// CifraURL страница с курсами обмена Цифра банка
+const CifraURL = "https://cifra-bank.ru/"
+
+var (
+	htmlTag    = regexp.MustCompile(`(?s)<[^>]*>`)
+	htmlSpaces = regexp.MustCompile(`(\s|&nbsp;)+`)
+	// код валюты, затем курсы покупки и продажи
+	cifraRow = regexp.MustCompile(`\b(USD|EUR)\b\D{0,80}?(\d{1,4}[.,]\d{1,4})\D{1,80}?(\d{1,4}[.,]\d{1,4})`)
+)
+
+// cifraProvider разбирает HTML-страницу курсов Цифра банка
+type cifraProvider struct {
+	url    string
+	client *http.Client
+}
+
+// NewCifra создаёт провайдер курсов Цифра банка; пустой url — CifraURL
+func NewCifra(url string, client *http.Client) RateProvider {
+	if url == "" {
+		url = CifraURL
+	}
+	return &cifraProvider{url: url, client: orDefault(client)}
+}
+
+func (p *cifraProvider) Source() db.RateSource { return db.Cifra }
+
+// Fetch возвращает курс продажи банка: по нему покупается валюта для оплаты подписок
+func (p *cifraProvider) Fetch(ctx context.Context) ([]Rate, error) {
+	body, err := fetch(ctx, p.client, p.url)
+	if err != nil {
+		return nil, fmt.Errorf("cifra: %w", err)
+	}
+
+	text := htmlTag.ReplaceAllString(string(body), " ")
+	text = htmlSpaces.ReplaceAllString(text, " ")
+
+	now := time.Now().UTC()
+	seen := make(map[db.Currency]bool)
+	var result []Rate
+	for _, m := range cifraRow.FindAllStringSubmatch(text, -1) {
+		curr := db.Currency(m[1])
+		if seen[curr] {
+			continue // первая таблица на странице — курсы для физлиц
+		}
+		sell, err := parseNumber(m[3])
+		if err != nil {
+			return nil, fmt.Errorf("cifra: invalid %s rate: %w", curr, err)
+		}
+		seen[curr] = true
+		result = append(result, Rate{Currency: curr, Quote: db.RUB, Value: sell, Source: db.Cifra, FetchedAt: now})
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("cifra: %w", ErrRateNotFound)
+	}
+	return result, nil
+}
@@ -0,0 +1,89 @@
+package rates
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+// serveFixture отдаёт файл из testdata с кодом status
+func serveFixture(t *testing.T, name string, status int) *httptest.Server {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") == "" {
+			t.Error("request without User-Agent")
+		}
+		w.WriteHeader(status)
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// rateValues курсы по валютам в виде строк для сравнения
+func rateValues(t *testing.T, rates []Rate, quote db.Currency, source db.RateSource) map[db.Currency]string {
+	t.Helper()
+	values := make(map[db.Currency]string, len(rates))
+	for _, r := range rates {
+		if r.Quote != quote || r.Source != source || r.FetchedAt.IsZero() {
+			t.Errorf("rate %s: quote %s, source %s, fetched %v", r.Currency, r.Quote, r.Source, r.FetchedAt)
+		}
+		values[r.Currency] = r.Value.String()
+	}
+	return values
+}
+
+func TestCifraFetch(t *testing.T) {
+	srv := serveFixture(t, "cifra.html", http.StatusOK)
+
+	rates, err := NewCifra(srv.URL, srv.Client()).Fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// курс продажи из первой таблицы — для физлиц
+	want := map[db.Currency]string{db.USD: "81.95", db.EUR: "93.75"}
+	got := rateValues(t, rates, db.RUB, db.Cifra)
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for curr, v := range want {
+		if got[curr] != v {
+			t.Errorf("%s = %s, want %s", curr, got[curr], v)
+		}
+	}
+}
+
+func TestCifraFetchErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		status  int
+		wantErr error
+	}{
+		{"bad status", "cifra.html", http.StatusServiceUnavailable, nil},
+		{"no rates", "ff.json", http.StatusOK, ErrRateNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := serveFixture(t, tt.fixture, tt.status)
+			_, err := NewCifra(srv.URL, srv.Client()).Fetch(context.Background())
+			if err == nil {
+				t.Fatal("want error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
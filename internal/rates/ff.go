@@ -0,0 +1,89 @@
+package rates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+// FFURL JSON с курсами, из которого строится страница bankffin.kz/ru/exchange-rates
+const FFURL = "https://bankffin.kz/api/exchange-rates/getRates"
+
+// ffResponse курсы Freedom Finance в тенге
+type ffResponse struct {
+	Data []struct {
+		Currency string   `json:"currency"`
+		Buy      ffNumber `json:"buy"`  // банк покупает валюту
+		Sell     ffNumber `json:"sell"` // банк продаёт валюту
+	} `json:"data"`
+}
+
+// ffNumber курс, который приходит то числом, то строкой ("515,2")
+type ffNumber string
+
+func (n *ffNumber) UnmarshalJSON(b []byte) error {
+	*n = ffNumber(strings.Trim(string(b), `"`))
+	return nil
+}
+
+// ffProvider разбирает курсы Freedom Finance к тенге
+type ffProvider struct {
+	url    string
+	client *http.Client
+}
+
+// NewFF создаёт провайдер курсов Freedom Finance; пустой url — FFURL
+func NewFF(url string, client *http.Client) RateProvider {
+	if url == "" {
+		url = FFURL
+	}
+	return &ffProvider{url: url, client: orDefault(client)}
+}
+
+func (p *ffProvider) Source() db.RateSource { return db.FF }
+
+// Fetch возвращает курсы к тенге: для валют — курс продажи банка, для рубля — курс покупки
+// (рубли продаются банку за тенге, на тенге покупается валюта). Курс к рублю
+// получается кроссом через KZT в Converter.
+func (p *ffProvider) Fetch(ctx context.Context) ([]Rate, error) {
+	body, err := fetch(ctx, p.client, p.url)
+	if err != nil {
+		return nil, fmt.Errorf("ff: %w", err)
+	}
+
+	var resp ffResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("ff: invalid response: %w", err)
+	}
+
+	now := time.Now().UTC()
+	var result []Rate
+	for _, r := range resp.Data {
+		curr := db.Currency(strings.ToUpper(r.Currency))
+		var raw ffNumber
+		switch curr {
+		case db.USD, db.EUR:
+			raw = r.Sell
+		case db.RUB:
+			raw = r.Buy
+		default:
+			continue
+		}
+
+		v, err := parseNumber(string(raw))
+		if err != nil {
+			return nil, fmt.Errorf("ff: invalid %s rate: %w", curr, err)
+		}
+		result = append(result, Rate{Currency: curr, Quote: db.KZT, Value: v, Source: db.FF, FetchedAt: now})
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("ff: %w", ErrRateNotFound)
+	}
+	return result, nil
+}
@@ -0,0 +1,55 @@
+package rates
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+func TestFFFetch(t *testing.T) {
+	srv := serveFixture(t, "ff.json", http.StatusOK)
+
+	rates, err := NewFF(srv.URL, srv.Client()).Fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// валюты — по курсу продажи, рубль — по курсу покупки; числа приходят и строками, и числами
+	want := map[db.Currency]string{db.USD: "518.9", db.EUR: "604.45", db.RUB: "6.27"}
+	got := rateValues(t, rates, db.KZT, db.FF)
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for curr, v := range want {
+		if got[curr] != v {
+			t.Errorf("%s = %s, want %s", curr, got[curr], v)
+		}
+	}
+}
+
+func TestFFFetchErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		status  int
+		wantErr error
+	}{
+		{"bad status", "ff.json", http.StatusBadGateway, nil},
+		{"not json", "cifra.html", http.StatusOK, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := serveFixture(t, tt.fixture, tt.status)
+			_, err := NewFF(srv.URL, srv.Client()).Fetch(context.Background())
+			if err == nil {
+				t.Fatal("want error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
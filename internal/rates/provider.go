@@ -0,0 +1,78 @@
+package rates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/WhoYa/subscription-manager/pkg/money"
+)
+
+// maxBodySize ограничение размера страницы с курсами
+const maxBodySize = 2 << 20
+
+var ErrRateNotFound = errors.New("rate not found in response")
+
+// Rate курс валюты, полученный от источника: одна единица Currency стоит Value единиц Quote
+type Rate struct {
+	Currency  db.Currency
+	Quote     db.Currency
+	Value     money.Decimal
+	Source    db.RateSource
+	FetchedAt time.Time
+}
+
+// RateProvider источник курсов валют
+type RateProvider interface {
+	// Source возвращает источник, под которым сохраняются курсы
+	Source() db.RateSource
+
+	// Fetch загружает актуальные курсы
+	Fetch(ctx context.Context) ([]Rate, error)
+}
+
+// fetch загружает страницу источника
+func fetch(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "subscription-manager/1.0")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+}
+
+// parseNumber разбирает число в формате банков: "92,45", "92.45", "1 234,5"
+func parseNumber(s string) (money.Decimal, error) {
+	s = strings.NewReplacer(" ", "", "\u00a0", "", ",", ".").Replace(strings.TrimSpace(s))
+	v, err := money.Parse(s)
+	if err != nil {
+		return money.Zero, err
+	}
+	if v.Sign() <= 0 {
+		return money.Zero, fmt.Errorf("non-positive rate %q", s)
+	}
+	return v, nil
+}
+
+// orDefault возвращает клиент с таймаутом, если клиент не передан
+func orDefault(client *http.Client) *http.Client {
+	if client != nil {
+		return client
+	}
+	return &http.Client{Timeout: 30 * time.Second}
+}
@@ -0,0 +1,64 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type apiKeyGormRepo struct {
+	orm *gorm.DB
+}
+
+func NewAPIKeyRepo(db *gorm.DB) APIKeyRepository {
+	return &apiKeyGormRepo{orm: db}
+}
+
+func (r *apiKeyGormRepo) Create(key *db.APIKey) error {
+	// Генерируем UUID если он не установлен
+	if key.ID == "" {
+		key.ID = uuid.New().String()
+	}
+	return r.orm.Create(key).Error
+}
+
+func (r *apiKeyGormRepo) FindByID(id string) (*db.APIKey, error) {
+	var key db.APIKey
+	if err := r.orm.First(&key, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyGormRepo) FindByHash(hash string) (*db.APIKey, error) {
+	var key db.APIKey
+	if err := r.orm.First(&key, "key_hash = ?", hash).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyGormRepo) List(spec query.Spec) (query.Page[db.APIKey], error) {
+	return query.Find[db.APIKey](r.orm.Model(&db.APIKey{}), spec, Fields,
+		query.Sort{Field: "created_at", Desc: true})
+}
+
+func (r *apiKeyGormRepo) Revoke(id string, at time.Time) error {
+	return r.orm.Model(&db.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+func (r *apiKeyGormRepo) Touch(id string, at time.Time) error {
+	return r.orm.Model(&db.APIKey{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", at).Error
+}
+
+func (r *apiKeyGormRepo) PurgeExpired(before time.Time) (int, error) {
+	res := r.orm.Where("expires_at < ?", before).Delete(&db.APIKey{})
+	return int(res.RowsAffected), res.Error
+}
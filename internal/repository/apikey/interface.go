@@ -0,0 +1,31 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+// Fields поля для фильтрации и сортировки списка ключей
+var Fields = query.Fields{
+	"user_id": {Column: "user_id", Kind: query.UUID},
+	"name":    {Column: "name", Kind: query.String, Sortable: true},
+	"scope": {Column: "scope", Kind: query.String, Enum: []string{
+		string(db.KeyScopeAdmin), string(db.KeyScopeMember),
+	}},
+	"created_at": {Column: "created_at", Kind: query.Time, Sortable: true},
+}
+
+type APIKeyRepository interface {
+	Create(key *db.APIKey) error
+	FindByID(id string) (*db.APIKey, error)
+	FindByHash(hash string) (*db.APIKey, error)
+	// List ключи по Fields, по умолчанию новые первыми
+	List(spec query.Spec) (query.Page[db.APIKey], error)
+	Revoke(id string, at time.Time) error
+	// Touch отмечает использование ключа
+	Touch(id string, at time.Time) error
+	// PurgeExpired удаляет ключи, срок действия которых истёк раньше before; возвращает их число
+	PurgeExpired(before time.Time) (int, error)
+}
@@ -0,0 +1,29 @@
+package audit
+
+import (
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type auditGormRepo struct {
+	orm *gorm.DB
+}
+
+func NewAuditRepo(db *gorm.DB) AuditRepository {
+	return &auditGormRepo{orm: db}
+}
+
+func (r *auditGormRepo) Create(entry *db.AuditEntry) error {
+	// Генерируем UUID если он не установлен
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	return r.orm.Create(entry).Error
+}
+
+func (r *auditGormRepo) List(spec query.Spec) (query.Page[db.AuditEntry], error) {
+	return query.Find[db.AuditEntry](r.orm.Model(&db.AuditEntry{}), spec, Fields,
+		query.Sort{Field: "created_at", Desc: true})
+}
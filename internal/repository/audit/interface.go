@@ -0,0 +1,31 @@
+package audit
+
+import (
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+// Fields поля для фильтрации и сортировки журнала аудита
+var Fields = query.Fields{
+	"entity": {Column: "entity", Kind: query.String, Enum: []string{
+		string(db.AuditUser), string(db.AuditSubscription), string(db.AuditUserSubscription),
+		string(db.AuditPayment), string(db.AuditPaymentClaim), string(db.AuditInvoice),
+		string(db.AuditLedgerEntry), string(db.AuditGlobalSettings), string(db.AuditCurrency),
+		string(db.AuditCurrencyRate), string(db.AuditPricingRule), string(db.AuditAPIKey),
+		string(db.AuditReminderSettings),
+	}},
+	"entity_id": {Column: "entity_id", Kind: query.String},
+	"action": {Column: "action", Kind: query.String, Enum: []string{
+		string(db.AuditCreate), string(db.AuditUpdate), string(db.AuditDelete), string(db.AuditRestore),
+	}},
+	"actor_key_id":  {Column: "actor_key_id", Kind: query.UUID},
+	"actor_user_id": {Column: "actor_user_id", Kind: query.UUID},
+	"actor_tg_id":   {Column: "actor_tg_id", Kind: query.Int},
+	"created_at":    {Column: "created_at", Kind: query.Time, Sortable: true},
+}
+
+type AuditRepository interface {
+	Create(entry *db.AuditEntry) error
+	// List записи по Fields, по умолчанию новые первыми
+	List(spec query.Spec) (query.Page[db.AuditEntry], error)
+}
@@ -0,0 +1,55 @@
+package currency
+
+import (
+	"errors"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrDuplicateCurrency возвращается, когда валюта с таким кодом уже есть в справочнике
+	ErrDuplicateCurrency = errors.New("duplicate currency code")
+)
+
+type currencyGormRepo struct {
+	orm *gorm.DB
+}
+
+func NewCurrencyRepo(db *gorm.DB) CurrencyRepository {
+	return &currencyGormRepo{orm: db}
+}
+
+func (r *currencyGormRepo) Create(c *db.CurrencyInfo) error {
+	err := r.orm.Create(c).Error
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrDuplicateCurrency
+		}
+	}
+	return err
+}
+
+func (r *currencyGormRepo) FindByCode(code db.Currency) (*db.CurrencyInfo, error) {
+	var c db.CurrencyInfo
+	if err := r.orm.First(&c, "code = ?", code).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *currencyGormRepo) List(enabledOnly bool) ([]db.CurrencyInfo, error) {
+	var ary []db.CurrencyInfo
+	q := r.orm.Order("code")
+	if enabledOnly {
+		q = q.Where("enabled = ?", true)
+	}
+	err := q.Find(&ary).Error
+	return ary, err
+}
+
+func (r *currencyGormRepo) Update(c *db.CurrencyInfo) error {
+	return r.orm.Save(c).Error
+}
@@ -0,0 +1,11 @@
+package currency
+
+import "github.com/WhoYa/subscription-manager/pkg/db"
+
+type CurrencyRepository interface {
+	Create(c *db.CurrencyInfo) error
+	FindByCode(code db.Currency) (*db.CurrencyInfo, error)
+	// List возвращает справочник валют по коду; enabledOnly — только включённые
+	List(enabledOnly bool) ([]db.CurrencyInfo, error)
+	Update(c *db.CurrencyInfo) error
+}
@@ -1,6 +1,10 @@
 package currencyrate
 
 import (
+	"time"
+
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	"github.com/WhoYa/subscription-manager/internal/repository/trash"
 	"github.com/WhoYa/subscription-manager/pkg/db"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -29,20 +33,28 @@ func (r *currencyRateGormRepo) FindByID(id string) (*db.CurrencyRate, error) {
 	return &cr, nil
 }
 
-func (r *currencyRateGormRepo) List(limit, offset int) ([]db.CurrencyRate, error) {
-	var ary []db.CurrencyRate
+func (r *currencyRateGormRepo) List(spec query.Spec) (query.Page[db.CurrencyRate], error) {
+	return query.Find[db.CurrencyRate](r.orm.Model(&db.CurrencyRate{}), spec, Fields,
+		query.Sort{Field: "fetched_at", Desc: true})
+}
+
+func (r *currencyRateGormRepo) LatestByCurrency(currency db.Currency) (*db.CurrencyRate, error) {
+	var cr db.CurrencyRate
 	err := r.orm.
+		Where("currency = ? AND quote_currency = ?", currency, db.RUB).
 		Order("fetched_at DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&ary).Error
-	return ary, err
+		First(&cr).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &cr, nil
 }
 
-func (r *currencyRateGormRepo) LatestByCurrency(currency db.Currency) (*db.CurrencyRate, error) {
+func (r *currencyRateGormRepo) AsOf(currency db.Currency, t time.Time) (*db.CurrencyRate, error) {
 	var cr db.CurrencyRate
 	err := r.orm.
-		Where("currency = ?", currency).
+		Where("currency = ? AND quote_currency = ? AND fetched_at <= ?", currency, db.RUB, t).
 		Order("fetched_at DESC").
 		First(&cr).
 		Error
@@ -52,6 +64,25 @@ func (r *currencyRateGormRepo) LatestByCurrency(currency db.Currency) (*db.Curre
 	return &cr, nil
 }
 
+func (r *currencyRateGormRepo) RatesAsOf(t time.Time) ([]db.CurrencyRate, error) {
+	var ary []db.CurrencyRate
+	err := r.orm.
+		Select("DISTINCT ON (currency, quote_currency) *").
+		Where("fetched_at <= ?", t).
+		Order("currency, quote_currency, fetched_at DESC").
+		Find(&ary).Error
+	return ary, err
+}
+
+func (r *currencyRateGormRepo) History(currency db.Currency, from, to time.Time) ([]db.CurrencyRate, error) {
+	var ary []db.CurrencyRate
+	err := r.orm.
+		Where("currency = ? AND fetched_at BETWEEN ? AND ?", currency, from, to).
+		Order("fetched_at ASC").
+		Find(&ary).Error
+	return ary, err
+}
+
 func (r *currencyRateGormRepo) Update(cr *db.CurrencyRate) error {
 	return r.orm.Save(cr).Error
 }
@@ -59,3 +90,15 @@ func (r *currencyRateGormRepo) Update(cr *db.CurrencyRate) error {
 func (r *currencyRateGormRepo) Delete(id string) error {
 	return r.orm.Delete(&db.CurrencyRate{}, "id = ?", id).Error
 }
+
+func (r *currencyRateGormRepo) Trash(spec query.Spec) (query.Page[db.CurrencyRate], error) {
+	return trash.List[db.CurrencyRate](r.orm, spec, trash.Fields(Fields))
+}
+
+func (r *currencyRateGormRepo) Restore(id string) (*db.CurrencyRate, error) {
+	return trash.Restore[db.CurrencyRate](r.orm, id, nil)
+}
+
+func (r *currencyRateGormRepo) Purge(before time.Time) (int, error) {
+	return trash.Purge[db.CurrencyRate](r.orm, before, nil)
+}
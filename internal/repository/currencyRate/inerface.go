@@ -1,12 +1,44 @@
 package currencyrate
 
-import "github.com/WhoYa/subscription-manager/pkg/db"
+import (
+	"time"
+
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+// Fields поля для фильтрации и сортировки списка курсов
+var Fields = query.Fields{
+	"currency":       {Column: "currency", Kind: query.String, Sortable: true},
+	"quote_currency": {Column: "quote_currency", Kind: query.String},
+	"value":          {Column: "value", Kind: query.Decimal, Sortable: true},
+	"source": {Column: "source", Kind: query.String, Enum: []string{
+		string(db.Cifra), string(db.FF), string(db.Manual),
+	}},
+	"fetched_at": {Column: "fetched_at", Kind: query.Time, Sortable: true},
+	"created_at": {Column: "created_at", Kind: query.Time, Sortable: true},
+}
 
 type CurrencyRateRepository interface {
 	Create(cr *db.CurrencyRate) error
 	FindByID(id string) (*db.CurrencyRate, error)
-	List(limit, offset int) ([]db.CurrencyRate, error)
+	// List курсы по Fields, по умолчанию новые первыми
+	List(spec query.Spec) (query.Page[db.CurrencyRate], error)
+	// LatestByCurrency возвращает последний курс валюты к рублю
 	LatestByCurrency(currency db.Currency) (*db.CurrencyRate, error)
+	// AsOf возвращает курс к рублю, действовавший в момент t (последний полученный не позже t)
+	AsOf(currency db.Currency, t time.Time) (*db.CurrencyRate, error)
+	// RatesAsOf возвращает действовавшие в момент t курсы по каждой паре валют
+	RatesAsOf(t time.Time) ([]db.CurrencyRate, error)
+	// History возвращает курсы за период в хронологическом порядке
+	History(currency db.Currency, from, to time.Time) ([]db.CurrencyRate, error)
 	Update(cr *db.CurrencyRate) error
 	Delete(id string) error
+
+	// Trash удалённые курсы по Fields и deleted_at, недавно удалённые первыми
+	Trash(spec query.Spec) (query.Page[db.CurrencyRate], error)
+	// Restore возвращает курс из корзины и отдаёт его в том виде, в котором он лежал в корзине
+	Restore(id string) (*db.CurrencyRate, error)
+	// Purge окончательно удаляет курсы, удалённые раньше before. Возвращает число удалённых
+	Purge(before time.Time) (int, error)
 }
@@ -0,0 +1,77 @@
+package idempotency
+
+import (
+	"errors"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// ErrDuplicateKey ключ уже использован этим клиентом
+var ErrDuplicateKey = errors.New("duplicate idempotency key")
+
+type idempotencyGormRepo struct {
+	orm *gorm.DB
+}
+
+func NewIdempotencyRepo(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyGormRepo{orm: db}
+}
+
+func (r *idempotencyGormRepo) Create(k *db.IdempotencyKey) error {
+	// Генерируем UUID если он не установлен
+	if k.ID == "" {
+		k.ID = uuid.New().String()
+	}
+	err := r.orm.Create(k).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrDuplicateKey
+	}
+	return err
+}
+
+func (r *idempotencyGormRepo) Find(apiKeyID, key string) (*db.IdempotencyKey, error) {
+	var k db.IdempotencyKey
+	err := r.orm.First(&k, "api_key_id = ? AND key = ?", apiKeyID, key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *idempotencyGormRepo) Complete(k *db.IdempotencyKey) error {
+	return r.orm.Model(k).Updates(map[string]any{
+		"status":       k.Status,
+		"content_type": k.ContentType,
+		"response":     k.Response,
+	}).Error
+}
+
+func (r *idempotencyGormRepo) Reclaim(k *db.IdempotencyKey, before time.Time) (bool, error) {
+	now := time.Now().UTC()
+	res := r.orm.
+		Model(&db.IdempotencyKey{}).
+		Where("id = ? AND status = 0 AND created_at < ?", k.ID, before).
+		Update("created_at", now)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return false, nil
+	}
+	k.CreatedAt = now
+	return true, nil
+}
+
+func (r *idempotencyGormRepo) Delete(id string) error {
+	return r.orm.Delete(&db.IdempotencyKey{}, "id = ?", id).Error
+}
+
+func (r *idempotencyGormRepo) Purge(before time.Time) (int, error) {
+	res := r.orm.Where("created_at < ?", before).Delete(&db.IdempotencyKey{})
+	return int(res.RowsAffected), res.Error
+}
@@ -0,0 +1,21 @@
+package idempotency
+
+import (
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+type IdempotencyRepository interface {
+	// Create занимает ключ; ключ клиента уже занят — ErrDuplicateKey
+	Create(k *db.IdempotencyKey) error
+	Find(apiKeyID, key string) (*db.IdempotencyKey, error)
+	// Complete сохраняет статус и тело ответа
+	Complete(k *db.IdempotencyKey) error
+	// Reclaim заново занимает незавершённый ключ, занятый раньше before: запрос, который
+	// его занял, упал или завис. false — ключ уже завершён или его успели занять заново
+	Reclaim(k *db.IdempotencyKey, before time.Time) (bool, error)
+	Delete(id string) error
+	// Purge удаляет ключи, созданные раньше before; возвращает их число
+	Purge(before time.Time) (int, error)
+}
@@ -0,0 +1,127 @@
+package invoice
+
+import (
+	"errors"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrDuplicateInvoice = errors.New("duplicate invoice for cycle")
+
+type invoiceGormRepo struct {
+	orm *gorm.DB
+}
+
+func NewInvoiceRepo(db *gorm.DB) InvoiceRepository {
+	return &invoiceGormRepo{orm: db}
+}
+
+func (r *invoiceGormRepo) Create(inv *db.Invoice) error {
+	// Генерируем UUID если он не установлен
+	if inv.ID == "" {
+		inv.ID = uuid.New().String()
+	}
+
+	err := r.orm.Create(inv).Error
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrDuplicateInvoice
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *invoiceGormRepo) CreateForCycle(inv *db.Invoice) (bool, error) {
+	// Генерируем UUID если он не установлен
+	if inv.ID == "" {
+		inv.ID = uuid.New().String()
+	}
+
+	// уникальный индекс invoice_cycle_uq защищает от повторного выставления,
+	// в том числе если предыдущий запуск упал посередине
+	res := r.orm.
+		Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "user_subscription_id"}, {Name: "period_start"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Neq{Column: "status", Value: db.InvoiceVoided}}},
+			DoNothing:   true,
+		}).
+		Create(inv)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *invoiceGormRepo) FindByID(id string) (*db.Invoice, error) {
+	var inv db.Invoice
+	if err := r.orm.First(&inv, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+func (r *invoiceGormRepo) FindByUser(userID string) ([]db.Invoice, error) {
+	var list []db.Invoice
+	err := r.orm.
+		Where("user_id = ?", userID).
+		Order("due_date DESC").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *invoiceGormRepo) FindByUserSubscription(userSubID string) ([]db.Invoice, error) {
+	var list []db.Invoice
+	err := r.orm.
+		Where("user_subscription_id = ?", userSubID).
+		Order("period_start DESC").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *invoiceGormRepo) FindLatest(userSubID string) (*db.Invoice, error) {
+	var inv db.Invoice
+	err := r.orm.
+		Where("user_subscription_id = ? AND status <> ?", userSubID, db.InvoiceVoided).
+		Order("period_start DESC").
+		First(&inv).Error
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+func (r *invoiceGormRepo) FindOutstanding(userID, subscriptionID string) ([]db.Invoice, error) {
+	q := r.orm.Where("user_id = ? AND status IN ?", userID, db.OutstandingInvoiceStatuses)
+	if subscriptionID != "" {
+		q = q.Where("subscription_id = ?", subscriptionID)
+	}
+
+	var list []db.Invoice
+	err := q.Order("due_date ASC").Find(&list).Error
+	return list, err
+}
+
+func (r *invoiceGormRepo) List(spec query.Spec) (query.Page[db.Invoice], error) {
+	return query.Find[db.Invoice](r.orm.Model(&db.Invoice{}), spec, Fields,
+		query.Sort{Field: "due_date", Desc: true})
+}
+
+func (r *invoiceGormRepo) Update(inv *db.Invoice) error {
+	return r.orm.Save(inv).Error
+}
+
+func (r *invoiceGormRepo) MarkOverdue(before time.Time) (int64, error) {
+	res := r.orm.
+		Model(&db.Invoice{}).
+		Where("status IN ? AND due_date < ?", []db.InvoiceStatus{db.InvoiceIssued, db.InvoicePartiallyPaid}, before).
+		Update("status", db.InvoiceOverdue)
+	return res.RowsAffected, res.Error
+}
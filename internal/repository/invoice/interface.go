@@ -0,0 +1,46 @@
+package invoice
+
+import (
+	"time"
+
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+// Fields поля для фильтрации и сортировки списка счетов
+var Fields = query.Fields{
+	"user_id":              {Column: "user_id", Kind: query.UUID},
+	"subscription_id":      {Column: "subscription_id", Kind: query.UUID},
+	"user_subscription_id": {Column: "user_subscription_id", Kind: query.UUID},
+	"status": {Column: "status", Kind: query.String, Enum: []string{
+		string(db.InvoiceDraft), string(db.InvoiceIssued), string(db.InvoicePartiallyPaid),
+		string(db.InvoicePaid), string(db.InvoiceOverdue), string(db.InvoiceVoided),
+	}},
+	"currency":     {Column: "currency", Kind: query.String, Sortable: true},
+	"amount":       {Column: "amount", Kind: query.Int, Sortable: true},
+	"paid_amount":  {Column: "paid_amount", Kind: query.Int, Sortable: true},
+	"period_start": {Column: "period_start", Kind: query.Time, Sortable: true},
+	"due_date":     {Column: "due_date", Kind: query.Time, Sortable: true},
+	"created_at":   {Column: "created_at", Kind: query.Time, Sortable: true},
+}
+
+type InvoiceRepository interface {
+	Create(inv *db.Invoice) error
+	// CreateForCycle создаёт счёт, если за этот цикл он ещё не выставлен.
+	// Возвращает false, если счёт уже существовал.
+	CreateForCycle(inv *db.Invoice) (bool, error)
+	FindByID(id string) (*db.Invoice, error)
+	FindByUser(userID string) ([]db.Invoice, error)
+	FindByUserSubscription(userSubID string) ([]db.Invoice, error)
+	// FindLatest возвращает неаннулированный счёт участника за самый поздний цикл
+	// или gorm.ErrRecordNotFound, если счетов ещё не было
+	FindLatest(userSubID string) (*db.Invoice, error)
+	// FindOutstanding возвращает неоплаченные счета пользователя, старые первыми.
+	// Пустой subscriptionID — по всем подпискам.
+	FindOutstanding(userID, subscriptionID string) ([]db.Invoice, error)
+	// List счета по Fields, по умолчанию с поздней датой списания первыми
+	List(spec query.Spec) (query.Page[db.Invoice], error)
+	Update(inv *db.Invoice) error
+	// MarkOverdue переводит неоплаченные счета с датой списания раньше before в overdue
+	MarkOverdue(before time.Time) (int64, error)
+}
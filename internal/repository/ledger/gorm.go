@@ -0,0 +1,54 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ledgerGormRepo struct {
+	orm *gorm.DB
+}
+
+func NewLedgerRepo(db *gorm.DB) LedgerRepository {
+	return &ledgerGormRepo{orm: db}
+}
+
+func (r *ledgerGormRepo) Lock(userID string) error {
+	// транзакционная advisory-блокировка снимается сама при COMMIT или ROLLBACK
+	return r.orm.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "ledger:"+userID).Error
+}
+
+func (r *ledgerGormRepo) Create(e *db.LedgerEntry) error {
+	// Генерируем UUID если он не установлен
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+
+	return r.orm.Create(e).Error
+}
+
+func (r *ledgerGormRepo) Balance(userID string, currency db.Currency, before time.Time) (int64, error) {
+	q := r.orm.
+		Model(&db.LedgerEntry{}).
+		Select("COALESCE(SUM(credit - debit), 0)").
+		Where("user_id = ? AND currency = ?", userID, currency)
+	if !before.IsZero() {
+		q = q.Where("occurred_at < ?", before)
+	}
+
+	var balance int64
+	err := q.Scan(&balance).Error
+	return balance, err
+}
+
+func (r *ledgerGormRepo) FindByUser(userID string, currency db.Currency, from, to time.Time) ([]db.LedgerEntry, error) {
+	var entries []db.LedgerEntry
+	err := r.orm.
+		Where("user_id = ? AND currency = ? AND occurred_at BETWEEN ? AND ?", userID, currency, from, to).
+		Order("occurred_at ASC, created_at ASC").
+		Find(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,19 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+type LedgerRepository interface {
+	// Lock блокирует лицевой счёт участника до конца транзакции, чтобы проводки
+	// и погашение счетов по одному участнику шли по очереди
+	Lock(userID string) error
+	Create(e *db.LedgerEntry) error
+	// Balance возвращает сумму кредитов минус сумму дебетов по проводкам в валюте currency
+	// до момента before (нулевое значение — по всем проводкам)
+	Balance(userID string, currency db.Currency, before time.Time) (int64, error)
+	// FindByUser возвращает проводки в валюте currency за период в хронологическом порядке
+	FindByUser(userID string, currency db.Currency, from, to time.Time) ([]db.LedgerEntry, error)
+}
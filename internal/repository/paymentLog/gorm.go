@@ -3,6 +3,7 @@ package paymentlog
 import (
 	"time"
 
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
 	"github.com/WhoYa/subscription-manager/pkg/db"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -38,22 +39,9 @@ func (r *paymentLogGormRepo) FindByID(id string) (*db.PaymentLog, error) {
 	return &pl, err
 }
 
-func (r *paymentLogGormRepo) FindByUser(userID string, from, to time.Time) ([]db.PaymentLog, error) {
-	var logs []db.PaymentLog
-	err := r.orm.
-		Preload("Subscription").
-		Where("user_id = ? AND paid_at BETWEEN ? AND ?", userID, from, to).
-		Find(&logs).Error
-	return logs, err
-}
-
-func (r *paymentLogGormRepo) FindBySubscription(subID string, from, to time.Time) ([]db.PaymentLog, error) {
-	var logs []db.PaymentLog
-	err := r.orm.
-		Preload("User").
-		Where("subscription_id = ? AND paid_at BETWEEN ? AND ?", subID, from, to).
-		Find(&logs).Error
-	return logs, err
+func (r *paymentLogGormRepo) List(spec query.Spec) (query.Page[db.PaymentLog], error) {
+	return query.Find[db.PaymentLog](r.orm.Model(&db.PaymentLog{}), spec, Fields,
+		query.Sort{Field: "paid_at", Desc: true}, "User", "Subscription")
 }
 
 func (r *paymentLogGormRepo) FindAll(from, to time.Time) ([]db.PaymentLog, error) {
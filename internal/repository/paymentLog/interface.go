@@ -3,13 +3,28 @@ package paymentlog
 import (
 	"time"
 
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
 	"github.com/WhoYa/subscription-manager/pkg/db"
 )
 
+// Fields поля для фильтрации и сортировки списка платежей
+var Fields = query.Fields{
+	"user_id":         {Column: "user_id", Kind: query.UUID},
+	"subscription_id": {Column: "subscription_id", Kind: query.UUID},
+	"invoice_id":      {Column: "invoice_id", Kind: query.UUID},
+	"amount":          {Column: "amount", Kind: query.Int, Sortable: true},
+	"base_amount":     {Column: "base_amount", Kind: query.Int, Sortable: true},
+	"profit_amount":   {Column: "profit_amount", Kind: query.Int, Sortable: true},
+	"currency":        {Column: "currency", Kind: query.String, Sortable: true},
+	"rate_used":       {Column: "rate_used", Kind: query.Decimal, Sortable: true},
+	"paid_at":         {Column: "paid_at", Kind: query.Time, Sortable: true},
+	"created_at":      {Column: "created_at", Kind: query.Time, Sortable: true},
+}
+
 type PaymentLogRepository interface {
 	Create(pl *db.PaymentLog) error
 	FindByID(id string) (*db.PaymentLog, error)
-	FindByUser(userID string, from, to time.Time) ([]db.PaymentLog, error)
-	FindBySubscription(subID string, from, to time.Time) ([]db.PaymentLog, error)
+	// List платежи по Fields, по умолчанию новые первыми
+	List(spec query.Spec) (query.Page[db.PaymentLog], error)
 	FindAll(from, to time.Time) ([]db.PaymentLog, error)
 }
@@ -0,0 +1,62 @@
+package paymentclaim
+
+import (
+	"time"
+
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type paymentClaimGormRepo struct {
+	orm *gorm.DB
+}
+
+func NewPaymentClaimRepo(db *gorm.DB) PaymentClaimRepository {
+	return &paymentClaimGormRepo{orm: db}
+}
+
+func (r *paymentClaimGormRepo) Create(claim *db.PaymentClaim) error {
+	// Генерируем UUID если он не установлен
+	if claim.ID == "" {
+		claim.ID = uuid.New().String()
+	}
+	return r.orm.Create(claim).Error
+}
+
+func (r *paymentClaimGormRepo) FindByID(id string) (*db.PaymentClaim, error) {
+	var claim db.PaymentClaim
+	if err := r.orm.First(&claim, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+func (r *paymentClaimGormRepo) List(spec query.Spec) (query.Page[db.PaymentClaim], error) {
+	return query.Find[db.PaymentClaim](r.orm.Model(&db.PaymentClaim{}), spec, Fields,
+		query.Sort{Field: "created_at"})
+}
+
+func (r *paymentClaimGormRepo) Resolve(claim *db.PaymentClaim, from db.PaymentClaimStatus) (bool, error) {
+	// условие на статус не даёт двум администраторам провести одно сообщение дважды
+	res := r.orm.Model(&db.PaymentClaim{}).
+		Where("id = ? AND status = ?", claim.ID, from).
+		Updates(map[string]any{
+			"status":        claim.Status,
+			"reject_reason": claim.RejectReason,
+			"reviewed_by":   claim.ReviewedBy,
+			"reviewed_at":   claim.ReviewedAt,
+			"updated_at":    time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *paymentClaimGormRepo) SetPayment(id, paymentLogID string, at time.Time) error {
+	return r.orm.Model(&db.PaymentClaim{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"payment_log_id": paymentLogID, "updated_at": at}).Error
+}
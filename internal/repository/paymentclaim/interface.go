@@ -0,0 +1,37 @@
+package paymentclaim
+
+import (
+	"time"
+
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+// Fields поля для фильтрации и сортировки списка сообщений об оплате
+var Fields = query.Fields{
+	"user_id":         {Column: "user_id", Kind: query.UUID},
+	"subscription_id": {Column: "subscription_id", Kind: query.UUID},
+	"invoice_id":      {Column: "invoice_id", Kind: query.UUID},
+	"status": {Column: "status", Kind: query.String, Enum: []string{
+		string(db.ClaimPending), string(db.ClaimApproved), string(db.ClaimRejected),
+	}},
+	"method": {Column: "method", Kind: query.String, Enum: []string{
+		string(db.PaymentCard), string(db.PaymentTransfer), string(db.PaymentCash), string(db.PaymentOther),
+	}},
+	"currency":   {Column: "currency", Kind: query.String, Sortable: true},
+	"amount":     {Column: "amount", Kind: query.Int, Sortable: true},
+	"paid_at":    {Column: "paid_at", Kind: query.Time, Sortable: true},
+	"created_at": {Column: "created_at", Kind: query.Time, Sortable: true},
+}
+
+type PaymentClaimRepository interface {
+	Create(claim *db.PaymentClaim) error
+	FindByID(id string) (*db.PaymentClaim, error)
+	// List возвращает сообщения по Fields, по умолчанию старые первыми — в порядке очереди
+	List(spec query.Spec) (query.Page[db.PaymentClaim], error)
+	// Resolve переводит сообщение из статуса from в claim.Status вместе с решением администратора.
+	// Возвращает false, если статус уже не from: решение принял кто-то другой.
+	Resolve(claim *db.PaymentClaim, from db.PaymentClaimStatus) (bool, error)
+	// SetPayment привязывает зарегистрированный по сообщению платёж
+	SetPayment(id, paymentLogID string, at time.Time) error
+}
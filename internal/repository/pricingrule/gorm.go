@@ -0,0 +1,74 @@
+package pricingrule
+
+import (
+	"time"
+
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	"github.com/WhoYa/subscription-manager/internal/repository/trash"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type pricingRuleGormRepo struct {
+	orm *gorm.DB
+}
+
+func NewPricingRuleRepo(db *gorm.DB) PricingRuleRepository {
+	return &pricingRuleGormRepo{orm: db}
+}
+
+func (r *pricingRuleGormRepo) Create(rule *db.PricingRule) error {
+	// Генерируем UUID если он не установлен
+	if rule.ID == "" {
+		rule.ID = uuid.New().String()
+	}
+	return r.orm.Create(rule).Error
+}
+
+func (r *pricingRuleGormRepo) FindByID(id string) (*db.PricingRule, error) {
+	var rule db.PricingRule
+	if err := r.orm.First(&rule, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *pricingRuleGormRepo) List(spec query.Spec) (query.Page[db.PricingRule], error) {
+	return query.Find[db.PricingRule](r.orm.Model(&db.PricingRule{}), spec, Fields,
+		query.Sort{Field: "priority"})
+}
+
+func (r *pricingRuleGormRepo) Applicable(userID, subscriptionID string, at time.Time) ([]db.PricingRule, error) {
+	var list []db.PricingRule
+	err := r.orm.
+		Where("user_id IS NULL OR user_id = ?", userID).
+		Where("subscription_id IS NULL OR subscription_id = ?", subscriptionID).
+		Where("valid_from IS NULL OR valid_from <= ?", at).
+		Where("valid_to IS NULL OR valid_to > ?", at).
+		Order("priority").
+		Order("created_at").
+		Order("id").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *pricingRuleGormRepo) Update(rule *db.PricingRule) error {
+	return r.orm.Save(rule).Error
+}
+
+func (r *pricingRuleGormRepo) Delete(id string) error {
+	return r.orm.Delete(&db.PricingRule{}, "id = ?", id).Error
+}
+
+func (r *pricingRuleGormRepo) Trash(spec query.Spec) (query.Page[db.PricingRule], error) {
+	return trash.List[db.PricingRule](r.orm, spec, trash.Fields(Fields))
+}
+
+func (r *pricingRuleGormRepo) Restore(id string) (*db.PricingRule, error) {
+	return trash.Restore[db.PricingRule](r.orm, id, nil)
+}
+
+func (r *pricingRuleGormRepo) Purge(before time.Time) (int, error) {
+	return trash.Purge[db.PricingRule](r.orm, before, nil)
+}
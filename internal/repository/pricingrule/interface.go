@@ -0,0 +1,45 @@
+package pricingrule
+
+import (
+	"time"
+
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+// Fields поля для фильтрации и сортировки списка правил
+var Fields = query.Fields{
+	"name": {Column: "name", Kind: query.String, Sortable: true},
+	"kind": {Column: "kind", Kind: query.String, Enum: []string{
+		string(db.RuleMarkupPercent), string(db.RuleDiscountPercent), string(db.RuleFixedPrice),
+		string(db.RuleMinAmount), string(db.RuleRoundUp),
+	}},
+	"scope": {Column: "scope", Kind: query.String, Enum: []string{
+		string(db.ScopeGlobal), string(db.ScopeSubscription), string(db.ScopeUser), string(db.ScopeUserSubscription),
+	}},
+	"user_id":         {Column: "user_id", Kind: query.UUID},
+	"subscription_id": {Column: "subscription_id", Kind: query.UUID},
+	"currency":        {Column: "currency", Kind: query.String},
+	"value":           {Column: "value", Kind: query.Decimal, Sortable: true},
+	"priority":        {Column: "priority", Kind: query.Int, Sortable: true},
+	"created_at":      {Column: "created_at", Kind: query.Time, Sortable: true},
+}
+
+type PricingRuleRepository interface {
+	Create(rule *db.PricingRule) error
+	FindByID(id string) (*db.PricingRule, error)
+	// List правила по Fields, по умолчанию в порядке применения
+	List(spec query.Spec) (query.Page[db.PricingRule], error)
+	// Applicable возвращает правила, которые относятся к участнику в подписке
+	// и действуют в момент at, в порядке применения
+	Applicable(userID, subscriptionID string, at time.Time) ([]db.PricingRule, error)
+	Update(rule *db.PricingRule) error
+	Delete(id string) error
+
+	// Trash удалённые правила по Fields и deleted_at, недавно удалённые первыми
+	Trash(spec query.Spec) (query.Page[db.PricingRule], error)
+	// Restore возвращает правило из корзины и отдаёт его в том виде, в котором оно лежало в корзине
+	Restore(id string) (*db.PricingRule, error)
+	// Purge окончательно удаляет правила, удалённые раньше before. Возвращает число удалённых
+	Purge(before time.Time) (int, error)
+}
@@ -0,0 +1,167 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// cursor позиция в выборке: значение поля сортировки и id последней отданной записи.
+// Сортировка хранится в курсоре, чтобы курсор от другого порядка не дал пропусков.
+type cursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d,omitempty"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// Find выполняет выборку страницы. q — запрос к модели T с ограничениями репозитория
+// (Model уже задана); def — порядок, если клиент его не задал; preload — связи для Preload.
+func Find[T any](q *gorm.DB, spec Spec, fields Fields, def Sort, preload ...string) (Page[T], error) {
+	sort := spec.Sort
+	if sort.Field == "" {
+		sort = def
+	}
+	sortField, ok := fields[sort.Field]
+	if !ok || !sortField.Sortable {
+		return Page[T]{}, fmt.Errorf("%w: cannot sort by %q", ErrInvalidSpec, sort.Field)
+	}
+
+	for _, c := range spec.Conditions {
+		field, ok := fields[c.Field]
+		if !ok {
+			return Page[T]{}, fmt.Errorf("%w: unknown field %q", ErrInvalidSpec, c.Field)
+		}
+		q = where(q, field.Column, c)
+	}
+
+	// Session делает запрос переиспользуемым: count и выборка не делят условия
+	base := q.Session(&gorm.Session{})
+	var page Page[T]
+	if err := base.Count(&page.Total).Error; err != nil {
+		return Page[T]{}, err
+	}
+
+	dir := "ASC"
+	if sort.Desc {
+		dir = "DESC"
+	}
+	find := base
+	if spec.Cursor != "" {
+		c, err := decodeCursor(spec.Cursor, sort)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		value, err := sortField.parse(sort.Field, c.Value)
+		if err != nil {
+			return Page[T]{}, fmt.Errorf("%w: invalid cursor", ErrInvalidSpec)
+		}
+		cmp := ">"
+		if sort.Desc {
+			cmp = "<"
+		}
+		find = find.Where(fmt.Sprintf("(%s, id) %s (?, ?)", sortField.Column, cmp), value, c.ID)
+	} else if spec.Offset > 0 {
+		find = find.Offset(spec.Offset)
+	}
+	find = find.Order(sortField.Column + " " + dir).Order("id " + dir)
+	if spec.Limit > 0 {
+		// лишняя запись показывает, есть ли следующая страница
+		find = find.Limit(spec.Limit + 1)
+	}
+	for _, p := range preload {
+		find = find.Preload(p)
+	}
+
+	var items []T
+	if err := find.Find(&items).Error; err != nil {
+		return Page[T]{}, err
+	}
+	page.Items = items
+	if spec.Limit > 0 && len(items) > spec.Limit {
+		page.Items = items[:spec.Limit]
+		next, err := encodeCursor(base, page.Items[spec.Limit-1], sort, sortField.Column)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		page.NextCursor = next
+	}
+	return page, nil
+}
+
+func where(q *gorm.DB, column string, c Condition) *gorm.DB {
+	switch c.Op {
+	case Ne:
+		return q.Where(column+" <> ?", c.Value)
+	case Gt:
+		return q.Where(column+" > ?", c.Value)
+	case Gte:
+		return q.Where(column+" >= ?", c.Value)
+	case Lt:
+		return q.Where(column+" < ?", c.Value)
+	case Lte:
+		return q.Where(column+" <= ?", c.Value)
+	case In:
+		return q.Where(column+" IN ?", c.Value)
+	case Contains:
+		pattern := "%" + likeEscaper.Replace(fmt.Sprint(c.Value)) + "%"
+		return q.Where(column+" ILIKE ?", pattern)
+	}
+	return q.Where(column+" = ?", c.Value)
+}
+
+// likeEscaper экранирует спецсимволы LIKE, чтобы они искались как есть
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// encodeCursor курсор после записи item: значения колонок берутся из схемы модели GORM
+func encodeCursor[T any](q *gorm.DB, item T, sort Sort, column string) (string, error) {
+	stmt := &gorm.Statement{DB: q}
+	if err := stmt.Parse(&item); err != nil {
+		return "", err
+	}
+	sortField, idField := stmt.Schema.LookUpField(column), stmt.Schema.LookUpField("id")
+	if sortField == nil || idField == nil {
+		return "", fmt.Errorf("cursor: model has no %s or id column", column)
+	}
+
+	rv := reflect.ValueOf(item)
+	value, _ := sortField.ValueOf(q.Statement.Context, rv)
+	id, _ := idField.ValueOf(q.Statement.Context, rv)
+
+	raw, err := json.Marshal(cursor{Sort: sort.Field, Desc: sort.Desc, Value: formatValue(value), ID: fmt.Sprint(id)})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeCursor(s string, sort Sort) (cursor, error) {
+	var c cursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(raw, &c)
+	}
+	if err != nil || c.ID == "" {
+		return cursor{}, fmt.Errorf("%w: invalid cursor", ErrInvalidSpec)
+	}
+	if c.Sort != sort.Field || c.Desc != sort.Desc {
+		return cursor{}, fmt.Errorf("%w: cursor belongs to another sort order", ErrInvalidSpec)
+	}
+	return c, nil
+}
+
+// formatValue значение колонки в виде, который разбирает Field.parse
+func formatValue(v any) string {
+	switch x := v.(type) {
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return x.String()
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,137 @@
+package query
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/money"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type cursorItem struct {
+	ID        string
+	Name      string
+	Amount    money.Decimal
+	Count     int64
+	CreatedAt time.Time
+}
+
+// dryRun GORM без подключения к базе: курсору нужна только схема модели
+func dryRun(t *testing.T) *gorm.DB {
+	t.Helper()
+	orm, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return orm
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	item := cursorItem{
+		ID:        "3f1c2a8e-6b1d-4d47-9a57-0e5c1d9b7a10",
+		Name:      "Netflix, 4K",
+		Amount:    money.MustParse("1234.5"),
+		Count:     -42,
+		CreatedAt: time.Date(2025, 7, 27, 10, 30, 15, 123456789, time.UTC),
+	}
+	tests := []struct {
+		name  string
+		sort  Sort
+		field Field
+		want  any
+	}{
+		{"string asc", Sort{Field: "name"}, Field{Column: "name", Kind: String}, item.Name},
+		{"decimal desc", Sort{Field: "amount", Desc: true}, Field{Column: "amount", Kind: Decimal}, item.Amount},
+		{"int", Sort{Field: "count"}, Field{Column: "count", Kind: Int}, item.Count},
+		{"time keeps nanoseconds", Sort{Field: "created_at", Desc: true}, Field{Column: "created_at", Kind: Time}, item.CreatedAt},
+		{"uuid", Sort{Field: "id"}, Field{Column: "id", Kind: UUID}, item.ID},
+	}
+	orm := dryRun(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := encodeCursor(orm, item, tt.sort, tt.field.Column)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c, err := decodeCursor(s, tt.sort)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.ID != item.ID {
+				t.Errorf("id = %q, want %q", c.ID, item.ID)
+			}
+			got, err := tt.field.parse(tt.sort.Field, c.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch want := tt.want.(type) {
+			case time.Time:
+				if !got.(time.Time).Equal(want) {
+					t.Errorf("value = %v, want %v", got, want)
+				}
+			case money.Decimal:
+				if got.(money.Decimal).Cmp(want) != 0 {
+					t.Errorf("value = %v, want %v", got, want)
+				}
+			default:
+				if got != want {
+					t.Errorf("value = %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	valid, err := encodeCursor(dryRun(t), cursorItem{ID: "1", Name: "a"}, Sort{Field: "name"}, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		cursor string
+		sort   Sort
+	}{
+		{"not base64", "!!!", Sort{Field: "name"}},
+		{"not json", "bm90IGpzb24", Sort{Field: "name"}},
+		{"no id", "eyJzIjoibmFtZSIsInYiOiJhIn0", Sort{Field: "name"}},
+		{"another field", valid, Sort{Field: "created_at"}},
+		{"another direction", valid, Sort{Field: "name", Desc: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.cursor, tt.sort); !errors.Is(err, ErrInvalidSpec) {
+				t.Errorf("err = %v, want ErrInvalidSpec", err)
+			}
+		})
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{"25", 25},
+		{"100", 100},
+		{"101", MaxLimit},
+		{"1000000000", MaxLimit},
+		{"0", 0},
+		{"-5", 0},
+		{"abc", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			spec, err := Parse(map[string]string{ParamLimit: tt.value}, Fields{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if spec.Limit != tt.want {
+				t.Errorf("limit = %d, want %d", spec.Limit, tt.want)
+			}
+		})
+	}
+}
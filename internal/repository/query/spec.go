@@ -0,0 +1,248 @@
+// Package query выборка списков: фильтры, сортировка и курсорная пагинация.
+// Spec разбирается из параметров запроса по описанию полей репозитория (Fields)
+// и выполняется функцией Find в GORM-репозитории.
+//
+// Параметры запроса:
+//
+//	limit=25           размер страницы, не больше MaxLimit
+//	cursor=...         курсор следующей страницы из ответа; offset с курсором не используется
+//	offset=50          смещение, если курсора нет
+//	sort=-base_price   поле сортировки, "-" — по убыванию
+//	base_currency=USD  фильтр по равенству; через запятую — любое из значений
+//	amount[gte]=100000 фильтр с оператором: ne, gt, gte, lt, lte, in, contains
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/money"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidSpec неизвестное поле, оператор, значение или курсор
+var ErrInvalidSpec = errors.New("invalid list query")
+
+// Служебные параметры запроса
+const (
+	ParamLimit  = "limit"
+	ParamOffset = "offset"
+	ParamCursor = "cursor"
+	ParamSort   = "sort"
+)
+
+// MaxLimit наибольший размер страницы: больший limit уменьшается до него
+const MaxLimit = 100
+
+// Kind тип значения поля: по нему разбираются значения фильтров и курсора
+type Kind int
+
+const (
+	String Kind = iota
+	Int
+	Decimal
+	Time // RFC 3339 или дата YYYY-MM-DD
+	Bool
+	UUID
+)
+
+// Field поле, доступное для фильтрации
+type Field struct {
+	Column string
+	Kind   Kind
+	// Sortable по полю можно сортировать. Только для колонок без NULL:
+	// курсор сравнивает значения, а NULL ни с чем не сравнивается.
+	Sortable bool
+	Enum     []string // допустимые значения; пусто — любые
+}
+
+// Fields поля выборки по именам в API (совпадают с JSON-полями модели)
+type Fields map[string]Field
+
+// Op оператор фильтра
+type Op string
+
+const (
+	Eq       Op = "eq"
+	Ne       Op = "ne"
+	Gt       Op = "gt"
+	Gte      Op = "gte"
+	Lt       Op = "lt"
+	Lte      Op = "lte"
+	In       Op = "in"
+	Contains Op = "contains" // подстрока без учёта регистра, только для строк
+)
+
+// Condition условие фильтра; для In значение — []any
+type Condition struct {
+	Field string
+	Op    Op
+	Value any
+}
+
+// Sort порядок выборки; от равных значений порядок задаёт id в том же направлении
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Spec параметры выборки списка
+type Spec struct {
+	Conditions []Condition
+	Sort       Sort // пустое Field — порядок репозитория по умолчанию
+	Cursor     string
+	Limit      int // 0 — без ограничения
+	Offset     int
+}
+
+// Page страница списка
+type Page[T any] struct {
+	Items      []T
+	Total      int64  // всего записей по фильтрам, без учёта страницы
+	NextCursor string // пусто — страница последняя
+}
+
+// Where добавляет условие, которое не задаётся клиентом: например, пользователь из пути
+func (s *Spec) Where(field string, op Op, value any) {
+	s.Conditions = append(s.Conditions, Condition{Field: field, Op: op, Value: value})
+}
+
+// Has задано ли клиентом условие на поле
+func (s Spec) Has(field string) bool {
+	for _, c := range s.Conditions {
+		if c.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse разбирает параметры запроса в Spec. Параметры из own вызывающий разбирает сам,
+// остальные неизвестные — ошибка, чтобы опечатка в фильтре не возвращала весь список.
+func Parse(params map[string]string, fields Fields, own ...string) (Spec, error) {
+	var spec Spec
+	for key, value := range params {
+		if contains(own, key) {
+			continue
+		}
+		switch key {
+		case ParamLimit:
+			// неверное значение — размер страницы по умолчанию, как и раньше
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				spec.Limit = min(n, MaxLimit)
+			}
+		case ParamOffset:
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				spec.Offset = n
+			}
+		case ParamCursor:
+			spec.Cursor = value
+		case ParamSort:
+			sort, err := fields.parseSort(value)
+			if err != nil {
+				return Spec{}, err
+			}
+			spec.Sort = sort
+		default:
+			cond, err := fields.parseCondition(key, value)
+			if err != nil {
+				return Spec{}, err
+			}
+			spec.Conditions = append(spec.Conditions, cond)
+		}
+	}
+	return spec, nil
+}
+
+func (f Fields) parseSort(value string) (Sort, error) {
+	sort := Sort{Field: strings.TrimPrefix(value, "-"), Desc: strings.HasPrefix(value, "-")}
+	field, ok := f[sort.Field]
+	if !ok || !field.Sortable {
+		return Sort{}, fmt.Errorf("%w: cannot sort by %q", ErrInvalidSpec, sort.Field)
+	}
+	return sort, nil
+}
+
+// parseCondition разбирает параметр name=value или name[op]=value
+func (f Fields) parseCondition(key, value string) (Condition, error) {
+	name, op := key, Eq
+	if i := strings.IndexByte(key, '['); i > 0 && strings.HasSuffix(key, "]") {
+		name, op = key[:i], Op(key[i+1:len(key)-1])
+	}
+	field, ok := f[name]
+	if !ok {
+		return Condition{}, fmt.Errorf("%w: unknown parameter %q", ErrInvalidSpec, key)
+	}
+
+	switch op {
+	case Eq, In:
+		parts := strings.Split(value, ",")
+		if op == Eq && len(parts) == 1 {
+			v, err := field.parse(name, value)
+			return Condition{Field: name, Op: Eq, Value: v}, err
+		}
+		values := make([]any, 0, len(parts))
+		for _, p := range parts {
+			v, err := field.parse(name, strings.TrimSpace(p))
+			if err != nil {
+				return Condition{}, err
+			}
+			values = append(values, v)
+		}
+		return Condition{Field: name, Op: In, Value: values}, nil
+	case Ne, Gt, Gte, Lt, Lte:
+		v, err := field.parse(name, value)
+		return Condition{Field: name, Op: op, Value: v}, err
+	case Contains:
+		if field.Kind != String || len(field.Enum) > 0 {
+			return Condition{}, fmt.Errorf("%w: %s does not support contains", ErrInvalidSpec, name)
+		}
+		return Condition{Field: name, Op: Contains, Value: value}, nil
+	}
+	return Condition{}, fmt.Errorf("%w: unknown operator %q", ErrInvalidSpec, op)
+}
+
+// parse переводит строку из запроса или курсора в значение для SQL
+func (f Field) parse(name, s string) (any, error) {
+	var (
+		v   any
+		err error
+	)
+	switch f.Kind {
+	case String:
+		if len(f.Enum) > 0 && !contains(f.Enum, s) {
+			return nil, fmt.Errorf("%w: %s must be one of %s", ErrInvalidSpec, name, strings.Join(f.Enum, ", "))
+		}
+		v = s
+	case Int:
+		v, err = strconv.ParseInt(s, 10, 64)
+	case Decimal:
+		v, err = money.Parse(s)
+	case Time:
+		v, err = time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			v, err = time.Parse("2006-01-02", s)
+		}
+	case Bool:
+		v, err = strconv.ParseBool(s)
+	case UUID:
+		_, err = uuid.Parse(s)
+		v = s
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid %s value %q", ErrInvalidSpec, name, s)
+	}
+	return v, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
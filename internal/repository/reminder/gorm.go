@@ -0,0 +1,63 @@
+package reminder
+
+import (
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type reminderGormRepo struct {
+	orm *gorm.DB
+}
+
+func NewReminderRepo(db *gorm.DB) ReminderRepository {
+	return &reminderGormRepo{orm: db}
+}
+
+func (r *reminderGormRepo) Claim(d *db.ReminderDelivery) (bool, error) {
+	// Генерируем UUID если он не установлен
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+
+	// уникальный индекс reminder_delivery_uq не даёт отправить напоминание дважды,
+	// даже если два процесса или перезапуск наложились друг на друга
+	res := r.orm.
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_subscription_id"}, {Name: "due_date"}, {Name: "offset_days"}},
+			DoNothing: true,
+		}).
+		Create(d)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *reminderGormRepo) FindByKey(userSubID string, dueDate time.Time, offsetDays int) (*db.ReminderDelivery, error) {
+	var d db.ReminderDelivery
+	err := r.orm.
+		Where("user_subscription_id = ? AND due_date = ? AND offset_days = ?", userSubID, dueDate, offsetDays).
+		First(&d).Error
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *reminderGormRepo) ListByUser(userID string, limit int) ([]db.ReminderDelivery, error) {
+	var list []db.ReminderDelivery
+	err := r.orm.
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&list).Error
+	return list, err
+}
+
+func (r *reminderGormRepo) Update(d *db.ReminderDelivery) error {
+	return r.orm.Save(d).Error
+}
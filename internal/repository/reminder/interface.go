@@ -0,0 +1,17 @@
+package reminder
+
+import (
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+type ReminderRepository interface {
+	// Claim создаёт запись о напоминании, если за этот цикл и смещение её ещё нет.
+	// Возвращает false, если запись уже существовала.
+	Claim(d *db.ReminderDelivery) (bool, error)
+	FindByKey(userSubID string, dueDate time.Time, offsetDays int) (*db.ReminderDelivery, error)
+	// ListByUser последние напоминания пользователя, новые первыми
+	ListByUser(userID string, limit int) ([]db.ReminderDelivery, error)
+	Update(d *db.ReminderDelivery) error
+}
@@ -3,7 +3,10 @@ package subscription
 import (
 	"errors"
 	"strings"
+	"time"
 
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	"github.com/WhoYa/subscription-manager/internal/repository/trash"
 	"github.com/WhoYa/subscription-manager/pkg/db"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -43,14 +46,9 @@ func (r *subscriptionGormRepo) Create(s *db.Subscription) error {
 	return err
 }
 
-func (r *subscriptionGormRepo) List(limit, offset int) ([]db.Subscription, error) {
-	var subscriptions []db.Subscription
-	err := r.orm.
-		Preload("Users").
-		Limit(limit).
-		Offset(offset).
-		Find(&subscriptions).Error
-	return subscriptions, err
+func (r *subscriptionGormRepo) List(spec query.Spec) (query.Page[db.Subscription], error) {
+	return query.Find[db.Subscription](r.orm.Model(&db.Subscription{}), spec, Fields,
+		query.Sort{Field: "created_at"}, "Users")
 }
 
 func (r *subscriptionGormRepo) FindByID(id string) (*db.Subscription, error) {
@@ -82,3 +80,30 @@ func (r *subscriptionGormRepo) Update(s *db.Subscription) error {
 func (r *subscriptionGormRepo) Delete(id string) error {
 	return r.orm.Delete(&db.Subscription{}, "id = ?", id).Error
 }
+
+func (r *subscriptionGormRepo) Trash(spec query.Spec) (query.Page[db.Subscription], error) {
+	return trash.List[db.Subscription](r.orm, spec, trash.Fields(Fields))
+}
+
+func (r *subscriptionGormRepo) Restore(id string) (*db.Subscription, error) {
+	return trash.Restore(r.orm, id, func(tx *gorm.DB, deleted *db.Subscription) error {
+		// service_name уникален только на уровне API, поэтому проверяем здесь
+		var count int64
+		err := tx.Model(&db.Subscription{}).
+			Where("service_name = ? AND id <> ?", deleted.ServiceName, deleted.ID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrDuplicateServiceName
+		}
+		return nil
+	})
+}
+
+func (r *subscriptionGormRepo) Purge(before time.Time) (int, error) {
+	return trash.Purge[db.Subscription](r.orm, before, func(tx *gorm.DB, id string) error {
+		return tx.Where("subscription_id = ?", id).Delete(&db.UserSubscription{}).Error
+	})
+}
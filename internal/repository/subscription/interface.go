@@ -1,12 +1,41 @@
 package subscription
 
-import "github.com/WhoYa/subscription-manager/pkg/db"
+import (
+	"time"
+
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+// Fields поля для фильтрации и сортировки списка подписок
+var Fields = query.Fields{
+	"id":            {Column: "id", Kind: query.UUID},
+	"service_name":  {Column: "service_name", Kind: query.String, Sortable: true},
+	"base_price":    {Column: "base_price", Kind: query.Decimal, Sortable: true},
+	"base_currency": {Column: "base_currency", Kind: query.String, Sortable: true},
+	"is_active":     {Column: "is_active", Kind: query.Bool},
+	"period_days":   {Column: "period_days", Kind: query.Int, Sortable: true},
+	"split_mode": {Column: "split_mode", Kind: query.String, Enum: []string{
+		string(db.SplitNone), string(db.SplitEqual), string(db.SplitWeighted), string(db.SplitOwnerFixed),
+	}},
+	"owner_id":   {Column: "owner_id", Kind: query.UUID},
+	"created_at": {Column: "created_at", Kind: query.Time, Sortable: true},
+	"updated_at": {Column: "updated_at", Kind: query.Time, Sortable: true},
+}
 
 type SubscriptionRepository interface {
 	Create(s *db.Subscription) error
-	List(limit, offset int) ([]db.Subscription, error)
+	// List подписки по Fields, по умолчанию в порядке создания
+	List(spec query.Spec) (query.Page[db.Subscription], error)
 	FindByID(id string) (*db.Subscription, error)
 	FindByServiceName(name string) (*db.Subscription, error)
 	Update(s *db.Subscription) error
 	Delete(id string) error
+
+	// Trash удалённые подписки по Fields и deleted_at, недавно удалённые первыми
+	Trash(spec query.Spec) (query.Page[db.Subscription], error)
+	// Restore возвращает подписку из корзины и отдаёт её в том виде, в котором она лежала в корзине; ErrDuplicateServiceName — уже есть подписка с тем же service_name
+	Restore(id string) (*db.Subscription, error)
+	// Purge окончательно удаляет подписки, удалённые раньше before; подписки с историей платежей и счетов остаются в корзине. Возвращает число удалённых
+	Purge(before time.Time) (int, error)
 }
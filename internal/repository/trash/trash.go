@@ -0,0 +1,103 @@
+// Package trash корзина для моделей с мягким удалением (gorm.DeletedAt):
+// список удалённых записей, восстановление и окончательное удаление старых.
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// DeletedAt поле корзины: когда запись удалена
+const DeletedAt = "deleted_at"
+
+// Fields поля репозитория и время удаления
+func Fields(fields query.Fields) query.Fields {
+	out := make(query.Fields, len(fields)+1)
+	for name, f := range fields {
+		out[name] = f
+	}
+	out[DeletedAt] = query.Field{Column: DeletedAt, Kind: query.Time, Sortable: true}
+	return out
+}
+
+// List страница удалённых записей модели T, по умолчанию недавно удалённые первыми
+func List[T any](orm *gorm.DB, spec query.Spec, fields query.Fields, preload ...string) (query.Page[T], error) {
+	q := orm.Unscoped().Model(new(T)).Where(DeletedAt + " IS NOT NULL")
+	return query.Find[T](q, spec, fields, query.Sort{Field: DeletedAt, Desc: true}, preload...)
+}
+
+// Restore снимает отметку об удалении и возвращает запись в том виде, в котором она лежала
+// в корзине. Записи нет в корзине — gorm.ErrRecordNotFound; ошибку уникального индекса
+// (запись с тем же ключом уже создана заново) разбирает вызывающий. check (может быть nil)
+// проверяет удалённую запись перед восстановлением в той же транзакции.
+func Restore[T any](orm *gorm.DB, id string, check func(tx *gorm.DB, deleted *T) error) (*T, error) {
+	deleted := new(T)
+	err := orm.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Where("id = ? AND "+DeletedAt+" IS NOT NULL", id).
+			First(deleted).Error
+		if err != nil {
+			return err
+		}
+		if check != nil {
+			if err := check(tx, deleted); err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Model(new(T)).Where("id = ?", id).Update(DeletedAt, nil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// Purge окончательно удаляет записи модели T, удалённые раньше before. Каждая запись удаляется
+// в своей транзакции вместе с зависимыми записями из cleanup (может быть nil). Запись,
+// на которую ссылается история (платежи, счета), остаётся в корзине и не считается ошибкой.
+func Purge[T any](orm *gorm.DB, before time.Time, cleanup func(tx *gorm.DB, id string) error) (int, error) {
+	var ids []string
+	err := orm.Unscoped().Model(new(T)).
+		Where(DeletedAt+" < ?", before).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, id := range ids {
+		err := orm.Transaction(func(tx *gorm.DB) error {
+			if cleanup != nil {
+				if err := cleanup(tx, id); err != nil {
+					return err
+				}
+			}
+			return tx.Unscoped().Delete(new(T), "id = ?", id).Error
+		})
+		if IsReferenced(err) {
+			continue
+		}
+		if err != nil {
+			return purged, fmt.Errorf("purge %s: %w", id, err)
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// IsUniqueViolation нарушен уникальный индекс
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// IsReferenced на запись ссылается внешний ключ другой таблицы
+func IsReferenced(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
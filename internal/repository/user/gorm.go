@@ -3,7 +3,10 @@ package user
 import (
 	"errors"
 	"strings"
+	"time"
 
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	"github.com/WhoYa/subscription-manager/internal/repository/trash"
 	"github.com/WhoYa/subscription-manager/pkg/db"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -42,15 +45,9 @@ func (r *userGormRepo) Create(u *db.User) error {
 	return err
 }
 
-func (r *userGormRepo) List(limit, offset int) ([]db.User, error) {
-	var users []db.User
-	err := r.orm.
-		Preload("Subscriptions").
-		Preload("Payments").
-		Limit(limit).
-		Offset(offset).
-		Find(&users).Error
-	return users, err
+func (r *userGormRepo) List(spec query.Spec) (query.Page[db.User], error) {
+	return query.Find[db.User](r.orm.Model(&db.User{}), spec, Fields,
+		query.Sort{Field: "created_at"}, "Subscriptions", "Payments")
 }
 
 func (r *userGormRepo) FindByID(id string) (*db.User, error) {
@@ -85,3 +82,21 @@ func (r *userGormRepo) Update(u *db.User) error {
 func (r *userGormRepo) Delete(id string) error {
 	return r.orm.Delete(&db.User{}, "id = ?", id).Error
 }
+
+func (r *userGormRepo) Trash(spec query.Spec) (query.Page[db.User], error) {
+	return trash.List[db.User](r.orm, spec, trash.Fields(Fields))
+}
+
+func (r *userGormRepo) Restore(id string) (*db.User, error) {
+	u, err := trash.Restore[db.User](r.orm, id, nil)
+	if trash.IsUniqueViolation(err) {
+		return nil, ErrDuplicateTGID
+	}
+	return u, err
+}
+
+func (r *userGormRepo) Purge(before time.Time) (int, error) {
+	return trash.Purge[db.User](r.orm, before, func(tx *gorm.DB, id string) error {
+		return tx.Where("user_id = ?", id).Delete(&db.UserSubscription{}).Error
+	})
+}
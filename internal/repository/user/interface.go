@@ -1,12 +1,38 @@
 package user
 
-import "github.com/WhoYa/subscription-manager/pkg/db"
+import (
+	"time"
+
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+// Fields поля для фильтрации и сортировки списка пользователей
+var Fields = query.Fields{
+	"id":                  {Column: "id", Kind: query.UUID},
+	"tg_id":               {Column: "tg_id", Kind: query.Int, Sortable: true},
+	"username":            {Column: "username", Kind: query.String, Sortable: true},
+	"fullname":            {Column: "fullname", Kind: query.String, Sortable: true},
+	"is_admin":            {Column: "is_admin", Kind: query.Bool},
+	"settlement_currency": {Column: "settlement_currency", Kind: query.String},
+	"reminders_off":       {Column: "reminders_off", Kind: query.Bool},
+	"created_at":          {Column: "created_at", Kind: query.Time, Sortable: true},
+	"updated_at":          {Column: "updated_at", Kind: query.Time, Sortable: true},
+}
 
 type UserRepository interface {
 	Create(u *db.User) error
-	List(limit, offset int) ([]db.User, error)
+	// List пользователи по Fields, по умолчанию в порядке регистрации
+	List(spec query.Spec) (query.Page[db.User], error)
 	FindByID(id string) (*db.User, error)
 	FindByTGID(tgID int64) (*db.User, error)
 	Update(u *db.User) error
 	Delete(id string) error
+
+	// Trash удалённые пользователи по Fields и deleted_at, недавно удалённые первыми
+	Trash(spec query.Spec) (query.Page[db.User], error)
+	// Restore возвращает пользователя из корзины и отдаёт его в том виде, в котором он лежал в корзине; ErrDuplicateTGID — с тем же Telegram ID уже зарегистрирован новый пользователь
+	Restore(id string) (*db.User, error)
+	// Purge окончательно удаляет пользователей, удалённые раньше before; пользователи с историей платежей и счетов остаются в корзине. Возвращает число удалённых
+	Purge(before time.Time) (int, error)
 }
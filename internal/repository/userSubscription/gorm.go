@@ -3,7 +3,9 @@ package usersubscription
 import (
 	"errors"
 	"strings"
+	"time"
 
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
 	"github.com/WhoYa/subscription-manager/pkg/db"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -25,6 +27,14 @@ func (r *userSubscriptionGormRepo) Create(us *db.UserSubscription) error {
 	if us.ID == "" {
 		us.ID = uuid.New().String()
 	}
+	// По умолчанию циклы списания отсчитываются от даты привязки
+	if us.AnchorDate.IsZero() {
+		us.AnchorDate = time.Now().UTC()
+	}
+	// По умолчанию участник состоит в подписке с сегодняшнего дня
+	if us.JoinedAt.IsZero() {
+		us.JoinedAt = today()
+	}
 
 	err := r.orm.Create(us).Error
 	if err != nil {
@@ -54,7 +64,7 @@ func (r *userSubscriptionGormRepo) FindByID(id string) (*db.UserSubscription, er
 	return &us, err
 }
 
-func (r *userSubscriptionGormRepo) FindByUser(userID string, limit, offset int) ([]db.UserSubscription, error) {
+func (r *userSubscriptionGormRepo) FindByUser(userID string) ([]db.UserSubscription, error) {
 	var list []db.UserSubscription
 	err := r.orm.
 		Preload("User").
@@ -64,18 +74,58 @@ func (r *userSubscriptionGormRepo) FindByUser(userID string, limit, offset int)
 	return list, err
 }
 
+func (r *userSubscriptionGormRepo) List(spec query.Spec) (query.Page[db.UserSubscription], error) {
+	return query.Find[db.UserSubscription](r.orm.Model(&db.UserSubscription{}), spec, Fields,
+		query.Sort{Field: "joined_at"}, "User", "Subscription")
+}
+
 func (r *userSubscriptionGormRepo) FindBySubscription(subID string) ([]db.UserSubscription, error) {
 	var list []db.UserSubscription
 	err := r.orm.
 		Preload("User").
 		Preload("Subscription").
 		Where("subscription_id = ?", subID).
+		Order("created_at").
+		Order("id").
+		Find(&list).Error
+	if err != nil || len(list) == 0 {
+		return list, err
+	}
+
+	// доли участников зависят от всего состава, поэтому считаются здесь, а не хранятся
+	from := today()
+	if err := db.SplitShares(&list[0].Subscription, list, from, from.AddDate(0, 0, 1)); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ListActive возвращает привязки к активным подпискам
+func (r *userSubscriptionGormRepo) ListActive() ([]db.UserSubscription, error) {
+	var list []db.UserSubscription
+	err := r.orm.
+		Preload("Subscription").
+		Joins("JOIN subscriptions ON subscriptions.id = user_subscriptions.subscription_id").
+		Where("subscriptions.is_active = ? AND subscriptions.deleted_at IS NULL", true).
 		Find(&list).Error
 	return list, err
 }
+
 func (r *userSubscriptionGormRepo) UpdateSettings(us *db.UserSubscription) error {
-	return r.orm.Model(us).Select("PricingMode", "MarkupPercent", "FixedFee").Updates(us).Error
+	return r.orm.Model(us).Select("PricingMode", "MarkupPercent", "FixedFee", "ShareWeight").Updates(us).Error
 }
+
+// UpdateMembership сохраняет даты входа и выхода участника
+func (r *userSubscriptionGormRepo) UpdateMembership(us *db.UserSubscription) error {
+	return r.orm.Model(us).Select("JoinedAt", "LeftAt").Updates(us).Error
+}
+
 func (r *userSubscriptionGormRepo) Delete(id string) error {
 	return r.orm.Delete(&db.UserSubscription{}, "id = ?", id).Error
 }
+
+// today начало текущих суток в UTC
+func today() time.Time {
+	y, m, d := time.Now().UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -1,12 +1,34 @@
 package usersubscription
 
-import "github.com/WhoYa/subscription-manager/pkg/db"
+import (
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+// Fields поля для фильтрации и сортировки списка участий в подписках
+var Fields = query.Fields{
+	"user_id":         {Column: "user_id", Kind: query.UUID},
+	"subscription_id": {Column: "subscription_id", Kind: query.UUID},
+	"pricing_mode": {Column: "pricing_mode", Kind: query.String, Enum: []string{
+		string(db.None), string(db.Percent), string(db.Fixed),
+	}},
+	"joined_at":   {Column: "joined_at", Kind: query.Time, Sortable: true},
+	"anchor_date": {Column: "anchor_date", Kind: query.Time, Sortable: true},
+	"created_at":  {Column: "created_at", Kind: query.Time, Sortable: true},
+}
 
 type UserSubscriptionRepository interface {
 	Create(us *db.UserSubscription) error
 	FindByID(id string) (*db.UserSubscription, error)
-	FindByUser(userID string, limit, offset int) ([]db.UserSubscription, error)
+	// FindByUser возвращает все участия пользователя, без постраничной выборки
+	FindByUser(userID string) ([]db.UserSubscription, error)
+	// List участия по Fields, по умолчанию в порядке вступления
+	List(spec query.Spec) (query.Page[db.UserSubscription], error)
+	// FindBySubscription возвращает участников подписки с их долями в цене на сегодня (SharePercent, ShareAmount)
 	FindBySubscription(subID string) ([]db.UserSubscription, error)
+	ListActive() ([]db.UserSubscription, error)
 	UpdateSettings(us *db.UserSubscription) error
+	// UpdateMembership сохраняет JoinedAt и LeftAt
+	UpdateMembership(us *db.UserSubscription) error
 	Delete(id string) error
 }
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	auditRepo "github.com/WhoYa/subscription-manager/internal/repository/audit"
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	userRepo "github.com/WhoYa/subscription-manager/internal/repository/user"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"gorm.io/gorm"
+)
+
+// auditIgnoredFields меняются при каждом сохранении и ничего не говорят об изменении
+var auditIgnoredFields = map[string]struct{}{
+	"updated_at": {},
+}
+
+// auditService реализация Audit
+type auditService struct {
+	auditRepo auditRepo.AuditRepository
+	userRepo  userRepo.UserRepository
+}
+
+// NewAudit создаёт сервис журнала аудита
+func NewAudit(auditRepo auditRepo.AuditRepository, userRepo userRepo.UserRepository) Audit {
+	return &auditService{auditRepo: auditRepo, userRepo: userRepo}
+}
+
+// Record вычисляет разницу состояний и сохраняет запись журнала
+func (s *auditService) Record(actor Actor, entity db.AuditEntity, entityID string, before, after any) error {
+	from, err := auditFields(before)
+	if err != nil {
+		return fmt.Errorf("audit: before: %w", err)
+	}
+	to, err := auditFields(after)
+	if err != nil {
+		return fmt.Errorf("audit: after: %w", err)
+	}
+
+	action := db.AuditUpdate
+	switch {
+	case from == nil && to == nil:
+		return nil
+	case from == nil:
+		action = db.AuditCreate
+	case to == nil:
+		action = db.AuditDelete
+	}
+
+	changes := auditDiff(from, to)
+	if action == db.AuditUpdate && len(changes) == 0 {
+		return nil
+	}
+	return s.save(actor, entity, entityID, action, changes)
+}
+
+// Restored сохраняет запись о восстановлении из корзины: изменилось только время удаления
+func (s *auditService) Restored(actor Actor, entity db.AuditEntity, entityID string, deleted any) error {
+	from, err := auditFields(deleted)
+	if err != nil {
+		return fmt.Errorf("audit: deleted: %w", err)
+	}
+	changes := db.AuditChanges{"deleted_at": {Before: from["deleted_at"], After: nil}}
+	return s.save(actor, entity, entityID, db.AuditRestore, changes)
+}
+
+// save дополняет запись журнала данными об авторе и сохраняет её
+func (s *auditService) save(actor Actor, entity db.AuditEntity, entityID string, action db.AuditAction, changes db.AuditChanges) error {
+	entry := db.AuditEntry{
+		ActorName: actor.Name,
+		Entity:    entity,
+		EntityID:  entityID,
+		Action:    action,
+		Changes:   changes,
+	}
+	if actor.KeyID != "" {
+		entry.ActorKeyID = &actor.KeyID
+	}
+	if actor.TGID != 0 {
+		entry.ActorTGID = &actor.TGID
+		if actor.UserID == "" {
+			// бот знает только Telegram ID администратора
+			user, err := s.userRepo.FindByTGID(actor.TGID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("audit: failed to get actor: %w", err)
+			}
+			if user != nil {
+				actor.UserID = user.ID
+			}
+		}
+	}
+	if actor.UserID != "" {
+		entry.ActorUserID = &actor.UserID
+	}
+
+	if err := s.auditRepo.Create(&entry); err != nil {
+		return fmt.Errorf("failed to save audit entry: %w", err)
+	}
+	return nil
+}
+
+// List возвращает страницу журнала
+func (s *auditService) List(spec query.Spec) (query.Page[db.AuditEntry], error) {
+	page, err := s.auditRepo.List(spec)
+	if err != nil {
+		return page, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	return page, nil
+}
+
+// auditFields поля записи в том виде, в котором их отдаёт API; nil — записи нет
+func auditFields(v any) (map[string]any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// auditDiff поля, значения которых различаются в from и to
+func auditDiff(from, to map[string]any) db.AuditChanges {
+	changes := db.AuditChanges{}
+	add := func(name string) {
+		if _, ok := auditIgnoredFields[name]; ok {
+			return
+		}
+		if _, ok := changes[name]; ok {
+			return
+		}
+		before, after := from[name], to[name]
+		if !reflect.DeepEqual(before, after) {
+			changes[name] = db.AuditChange{Before: before, After: after}
+		}
+	}
+	for name := range from {
+		add(name)
+	}
+	for name := range to {
+		add(name)
+	}
+	return changes
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	keyRepo "github.com/WhoYa/subscription-manager/internal/repository/apikey"
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	userRepo "github.com/WhoYa/subscription-manager/internal/repository/user"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnauthorized   = errors.New("unauthorized")
+	ErrInvalidAPIKey  = errors.New("invalid api key")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+)
+
+const (
+	// apiKeyPrefix начало всех выпущенных ключей, чтобы их было легко найти в логах и конфигах
+	apiKeyPrefix = "smk_"
+	// minServiceKeyLength ключ сервиса задаётся вручную, короткий легко подобрать
+	minServiceKeyLength = 32
+	// touchInterval не чаще этого обновляется время последнего использования ключа
+	touchInterval = time.Minute
+)
+
+// authService реализация Auth
+type authService struct {
+	keyRepo  keyRepo.APIKeyRepository
+	userRepo userRepo.UserRepository
+}
+
+// NewAuth создаёт сервис ключей доступа
+func NewAuth(keyRepo keyRepo.APIKeyRepository, userRepo userRepo.UserRepository) Auth {
+	return &authService{keyRepo: keyRepo, userRepo: userRepo}
+}
+
+// Issue выпускает новый ключ
+func (s *authService) Issue(key *db.APIKey) (string, error) {
+	key.Name = strings.TrimSpace(key.Name)
+	if key.Name == "" {
+		return "", fmt.Errorf("%w: name is required", ErrInvalidAPIKey)
+	}
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(time.Now()) {
+		return "", fmt.Errorf("%w: expires_at must be in the future", ErrInvalidAPIKey)
+	}
+
+	switch key.Scope {
+	case db.KeyScopeAdmin:
+	case db.KeyScopeMember:
+		if key.UserID == nil {
+			return "", fmt.Errorf("%w: user_id is required for scope %s", ErrInvalidAPIKey, key.Scope)
+		}
+	default:
+		return "", fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIKey, key.Scope)
+	}
+
+	if key.UserID != nil {
+		user, err := s.userRepo.FindByID(*key.UserID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return "", fmt.Errorf("%w: %s", ErrUserNotFound, *key.UserID)
+			}
+			return "", fmt.Errorf("failed to get user: %w", err)
+		}
+		if key.Scope == db.KeyScopeAdmin && !user.IsAdmin {
+			return "", fmt.Errorf("%w: user %s is not an admin", ErrInvalidAPIKey, user.ID)
+		}
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return "", err
+	}
+	key.Prefix = token[:len(apiKeyPrefix)+6]
+	key.KeyHash = hashToken(token)
+	if err := s.keyRepo.Create(key); err != nil {
+		return "", fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	log.Printf("AUTH: Issued %s key %s %q", key.Scope, key.ID, key.Name)
+	return token, nil
+}
+
+// EnsureServiceKey регистрирует ключ сервиса из окружения. Отозванный ключ
+// повторно не включается: его нужно заменить в окружении.
+func (s *authService) EnsureServiceKey(name, token string) error {
+	if len(token) < minServiceKeyLength {
+		return fmt.Errorf("%w: service key must be at least %d characters", ErrInvalidAPIKey, minServiceKeyLength)
+	}
+
+	hash := hashToken(token)
+	existing, err := s.keyRepo.FindByHash(hash)
+	if err == nil {
+		if existing.RevokedAt != nil {
+			log.Printf("AUTH: Service key %q was revoked, replace it in the environment", existing.Name)
+		}
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	key := &db.APIKey{
+		Name:    name,
+		Scope:   db.KeyScopeAdmin,
+		Prefix:  token[:min(len(token), 10)],
+		KeyHash: hash,
+	}
+	if err := s.keyRepo.Create(key); err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	log.Printf("AUTH: Registered service key %s %q", key.ID, key.Name)
+	return nil
+}
+
+// Authenticate находит действующий ключ
+func (s *authService) Authenticate(token string) (*Principal, error) {
+	if token == "" {
+		return nil, fmt.Errorf("%w: api key required", ErrUnauthorized)
+	}
+
+	key, err := s.keyRepo.FindByHash(hashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: unknown api key", ErrUnauthorized)
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil {
+		return nil, fmt.Errorf("%w: api key revoked", ErrUnauthorized)
+	}
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("%w: api key expired", ErrUnauthorized)
+	}
+
+	principal := &Principal{KeyID: key.ID, Name: key.Name, Scope: key.Scope}
+	if key.UserID != nil {
+		// ключи удалённого пользователя и администратора, лишённого прав, перестают действовать
+		user, err := s.userRepo.FindByID(*key.UserID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: api key owner not found", ErrUnauthorized)
+			}
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if key.Scope == db.KeyScopeAdmin && !user.IsAdmin {
+			return nil, fmt.Errorf("%w: api key owner is not an admin", ErrUnauthorized)
+		}
+		principal.UserID = user.ID
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= touchInterval {
+		if err := s.keyRepo.Touch(key.ID, now); err != nil {
+			log.Printf("AUTH: Failed to touch key %s: %v", key.ID, err)
+		}
+	}
+	return principal, nil
+}
+
+// List возвращает страницу ключей без их значений
+func (s *authService) List(spec query.Spec) (query.Page[db.APIKey], error) {
+	page, err := s.keyRepo.List(spec)
+	if err != nil {
+		return page, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return page, nil
+}
+
+// Revoke отзывает ключ; повторный отзыв ничего не меняет
+func (s *authService) Revoke(id string) (*db.APIKey, error) {
+	key, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := s.keyRepo.Revoke(id, now); err != nil {
+		return nil, fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	key.RevokedAt = &now
+	log.Printf("AUTH: Revoked key %s", id)
+	return key, nil
+}
+
+// PurgeExpired удаляет истёкшие ключи: войти по ним уже нельзя
+func (s *authService) PurgeExpired(before time.Time) (int, error) {
+	purged, err := s.keyRepo.PurgeExpired(before)
+	if err != nil {
+		return purged, fmt.Errorf("failed to purge expired api keys: %w", err)
+	}
+	return purged, nil
+}
+
+// Get возвращает ключ по ID
+func (s *authService) Get(id string) (*db.APIKey, error) {
+	key, err := s.keyRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrAPIKeyNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	return key, nil
+}
+
+// generateToken 32 случайных байта в hex с префиксом
+func generateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	return apiKeyPrefix + hex.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
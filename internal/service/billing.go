@@ -0,0 +1,292 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	invRepo "github.com/WhoYa/subscription-manager/internal/repository/invoice"
+	usRepo "github.com/WhoYa/subscription-manager/internal/repository/usersubscription"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/WhoYa/subscription-manager/pkg/money"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMembershipEnded  = errors.New("membership already ended")
+	ErrInvalidLeaveDate = errors.New("invalid leave date")
+)
+
+// billingLeadTime за сколько до даты списания выставляется счёт
+const billingLeadTime = 24 * time.Hour
+
+// billingService реализация Billing
+type billingService struct {
+	userSubRepo    usRepo.UserSubscriptionRepository
+	invoiceRepo    invRepo.InvoiceRepository
+	paymentService Service
+	tx             Transactor
+}
+
+// NewBilling создаёт сервис циклов списания
+func NewBilling(
+	userSubRepo usRepo.UserSubscriptionRepository,
+	invoiceRepo invRepo.InvoiceRepository,
+	paymentService Service,
+	tx Transactor,
+) Billing {
+	return &billingService{
+		userSubRepo:    userSubRepo,
+		invoiceRepo:    invoiceRepo,
+		paymentService: paymentService,
+		tx:             tx,
+	}
+}
+
+// NextDueDate возвращает ближайшую дату списания, не раньше after
+func (s *billingService) NextDueDate(userSub *db.UserSubscription, after time.Time) time.Time {
+	start, end := cycleAt(userSub.AnchorDate, userSub.Subscription.PeriodDays, after)
+	if start.Before(truncateDay(after)) {
+		return end
+	}
+	return start
+}
+
+// GenerateInvoices выставляет счета за ближайшие циклы всех активных подписок
+func (s *billingService) GenerateInvoices(now time.Time) (int, error) {
+	userSubs, err := s.userSubRepo.ListActive()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list active user subscriptions: %w", err)
+	}
+
+	created := 0
+	var errs []error
+	for i := range userSubs {
+		us := &userSubs[i]
+		n, err := s.invoiceDueCycles(us, now)
+		created += n
+		if err != nil {
+			// одна ошибка (например, нет курса) не должна останавливать остальных
+			errs = append(errs, fmt.Errorf("user subscription %s: %w", us.ID, err))
+		}
+	}
+
+	return created, errors.Join(errs...)
+}
+
+// invoiceDueCycles выставляет счета за все циклы от следующего после последнего
+// выставленного счёта до цикла, который начнётся в ближайшие billingLeadTime.
+// Без выставленных счетов — только за ближайший цикл: прошлые циклы до включения
+// автоматических счетов оплачивались без них.
+func (s *billingService) invoiceDueCycles(us *db.UserSubscription, now time.Time) (int, error) {
+	periodDays := us.Subscription.PeriodDays
+	if periodDays <= 0 {
+		return 0, fmt.Errorf("invalid period_days %d", periodDays)
+	}
+
+	target := now.Add(billingLeadTime)
+	if target.Before(truncateDay(us.AnchorDate)) {
+		return 0, nil // первый цикл ещё не скоро
+	}
+	lastStart, _ := cycleAt(us.AnchorDate, periodDays, target)
+
+	start := lastStart
+	latest, err := s.invoiceRepo.FindLatest(us.ID)
+	switch {
+	case err == nil:
+		// цикл сразу после последнего счёта; если anchor_date меняли и этот цикл
+		// пересекается с уже выставленным, начинаем со следующего
+		next, end := cycleAt(us.AnchorDate, periodDays, latest.PeriodEnd)
+		if next.Before(latest.PeriodEnd) {
+			next = end
+		}
+		start = next
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return 0, fmt.Errorf("failed to get latest invoice: %w", err)
+	}
+
+	created := 0
+	for periodStart := start; !periodStart.After(lastStart); periodStart = periodStart.AddDate(0, 0, periodDays) {
+		ok, err := s.invoiceCycle(us, periodStart, periodStart.AddDate(0, 0, periodDays), now)
+		if err != nil {
+			return created, err
+		}
+		if ok {
+			created++
+		}
+	}
+	return created, nil
+}
+
+// invoiceCycle выставляет счёт за цикл [periodStart, periodEnd)
+func (s *billingService) invoiceCycle(us *db.UserSubscription, periodStart, periodEnd, now time.Time) (bool, error) {
+	if !us.ActiveDuring(periodStart, periodEnd) {
+		return false, nil // участник ещё не вступил или уже вышел
+	}
+
+	calc, err := s.paymentService.CalculateUserPayment(us.UserID, us.SubscriptionID, periodStart)
+	if err != nil {
+		return false, err
+	}
+
+	// счёт выставляется сразу, минуя черновик
+	issuedAt := now.UTC()
+	inv := &db.Invoice{
+		UserSubscriptionID: us.ID,
+		UserID:             us.UserID,
+		SubscriptionID:     us.SubscriptionID,
+		PeriodStart:        periodStart,
+		PeriodEnd:          periodEnd,
+		DueDate:            periodStart,
+		Status:             db.InvoiceIssued,
+		IssuedAt:           &issuedAt,
+	}
+	freezeAmounts(inv, calc)
+
+	// счёт и начисление по нему сохраняются вместе: упавшее начисление откатывает счёт,
+	// и следующий запуск выставит его заново
+	created := false
+	err = s.tx.Transaction(func(st Store) error {
+		var err error
+		created, err = st.Invoices.CreateForCycle(inv)
+		if err != nil {
+			return fmt.Errorf("failed to create invoice: %w", err)
+		}
+		if !created {
+			return nil
+		}
+		if err := chargeInvoice(st, inv); err != nil {
+			return fmt.Errorf("failed to charge invoice %s: %w", inv.ID, err)
+		}
+		return nil
+	})
+	if err != nil || !created {
+		return false, err
+	}
+	log.Printf("BILLING: Invoice %s created for user %s, subscription %s, due %s",
+		inv.ID, us.UserID, us.SubscriptionID, periodStart.Format("2006-01-02"))
+	return true, nil
+}
+
+// EndMembership отмечает выход участника и возвращает на баланс оплату неиспользованных дней
+func (s *billingService) EndMembership(userSubID string, at time.Time) (*MembershipEnd, error) {
+	us, err := s.userSubRepo.FindByID(userSubID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrUserSubscriptionNotFound, userSubID)
+		}
+		return nil, fmt.Errorf("failed to get user subscription: %w", err)
+	}
+	if us.LeftAt != nil {
+		return nil, fmt.Errorf("%w: left at %s", ErrMembershipEnded, us.LeftAt.Format("2006-01-02"))
+	}
+
+	leftAt := truncateDay(at)
+	if leftAt.Before(truncateDay(us.JoinedAt)) {
+		return nil, fmt.Errorf("%w: before joined_at %s", ErrInvalidLeaveDate, us.JoinedAt.Format("2006-01-02"))
+	}
+
+	// дни каждого выставленного счёта, оплаченные до выхода, считаем до изменения LeftAt
+	invoices, err := s.invoiceRepo.FindByUserSubscription(us.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoices: %w", err)
+	}
+	type refund struct {
+		inv            *db.Invoice
+		billed, unused int
+	}
+	var refunds []refund
+	for i := range invoices {
+		inv := &invoices[i]
+		if inv.Status == db.InvoiceDraft || inv.Status == db.InvoiceVoided || !inv.PeriodEnd.After(leftAt) {
+			continue
+		}
+		from, to := us.BilledPeriod(inv.PeriodStart, inv.PeriodEnd)
+		billed := db.DaysBetween(from, to)
+		unused := db.DaysBetween(maxTime(from, leftAt), to)
+		if billed > 0 && unused > 0 {
+			refunds = append(refunds, refund{inv: inv, billed: billed, unused: unused})
+		}
+	}
+
+	// возврат пропорционален неиспользованным дням от суммы, зафиксированной в счёте
+	us.LeftAt = &leftAt
+	result := &MembershipEnd{UserSubscription: us, InvoiceIDs: []string{}}
+	type credit struct {
+		invoiceID   string
+		amount      int64
+		currency    db.Currency
+		description string
+	}
+	var credits []credit
+	for _, r := range refunds {
+		share, err := money.NewFromInt(r.inv.Amount).
+			MulDiv(money.NewFromInt(int64(r.unused)), money.NewFromInt(int64(r.billed)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to credit invoice %s: %w", r.inv.ID, err)
+		}
+		amount := share.Minor(0, pricingRounding)
+		if amount <= 0 {
+			continue
+		}
+		credits = append(credits, credit{
+			invoiceID: r.inv.ID,
+			amount:    amount,
+			currency:  r.inv.Currency,
+			description: fmt.Sprintf("Перерасчёт за %d из %d дн. по счёту %s: выход из подписки %s",
+				r.unused, r.billed, r.inv.ID, leftAt.Format("2006-01-02")),
+		})
+	}
+
+	// выход и возвраты проводятся вместе, чтобы участник не вышел без перерасчёта
+	err = s.tx.Transaction(func(st Store) error {
+		if err := st.UserSubscriptions.UpdateMembership(us); err != nil {
+			return fmt.Errorf("failed to update user subscription: %w", err)
+		}
+		for _, c := range credits {
+			if _, err := adjustBalance(st, us.UserID, c.amount, c.currency, c.description, ""); err != nil {
+				return fmt.Errorf("failed to credit invoice %s: %w", c.invoiceID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		us.LeftAt = nil
+		return nil, err
+	}
+	log.Printf("BILLING: User %s left subscription %s on %s", us.UserID, us.SubscriptionID, leftAt.Format("2006-01-02"))
+
+	for _, c := range credits {
+		result.Credit += c.amount
+		result.InvoiceIDs = append(result.InvoiceIDs, c.invoiceID)
+	}
+	return result, nil
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// cycleAt возвращает границы цикла списания, в который попадает момент t.
+// Циклы длиной periodDays дней отсчитываются от даты anchor (в UTC).
+func cycleAt(anchor time.Time, periodDays int, t time.Time) (time.Time, time.Time) {
+	anchor = truncateDay(anchor)
+	day := truncateDay(t)
+	if periodDays <= 0 || day.Before(anchor) {
+		return anchor, anchor.AddDate(0, 0, periodDays)
+	}
+
+	days := int(day.Sub(anchor).Hours() / 24)
+	start := anchor.AddDate(0, 0, days/periodDays*periodDays)
+	return start, start.AddDate(0, 0, periodDays)
+}
+
+// truncateDay отбрасывает время суток
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	invRepo "github.com/WhoYa/subscription-manager/internal/repository/invoice"
+	ledgerRepo "github.com/WhoYa/subscription-manager/internal/repository/ledger"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"gorm.io/gorm"
+)
+
+// memInvoices счета в памяти; методы, которые не нужны тесту, не реализованы
+type memInvoices struct {
+	invRepo.InvoiceRepository
+	list []db.Invoice
+}
+
+func (r *memInvoices) CreateForCycle(inv *db.Invoice) (bool, error) {
+	for _, existing := range r.list {
+		if existing.UserSubscriptionID == inv.UserSubscriptionID && existing.PeriodStart.Equal(inv.PeriodStart) &&
+			existing.Status != db.InvoiceVoided {
+			return false, nil
+		}
+	}
+	inv.ID = inv.PeriodStart.Format(time.DateOnly)
+	r.list = append(r.list, *inv)
+	return true, nil
+}
+
+func (r *memInvoices) FindLatest(userSubID string) (*db.Invoice, error) {
+	var latest *db.Invoice
+	for i, inv := range r.list {
+		if inv.UserSubscriptionID == userSubID && inv.Status != db.InvoiceVoided &&
+			(latest == nil || inv.PeriodStart.After(latest.PeriodStart)) {
+			latest = &r.list[i]
+		}
+	}
+	if latest == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return latest, nil
+}
+
+func (r *memInvoices) FindOutstanding(userID, _ string) ([]db.Invoice, error) {
+	var list []db.Invoice
+	for _, inv := range r.list {
+		if inv.UserID == userID && slices.Contains(db.OutstandingInvoiceStatuses, inv.Status) {
+			list = append(list, inv)
+		}
+	}
+	return list, nil
+}
+
+func (r *memInvoices) Update(inv *db.Invoice) error {
+	for i := range r.list {
+		if r.list[i].ID == inv.ID {
+			r.list[i] = *inv
+		}
+	}
+	return nil
+}
+
+// memLedger проводки в памяти
+type memLedger struct {
+	ledgerRepo.LedgerRepository
+	entries []db.LedgerEntry
+}
+
+func (l *memLedger) Lock(string) error { return nil }
+
+func (l *memLedger) Create(e *db.LedgerEntry) error {
+	l.entries = append(l.entries, *e)
+	return nil
+}
+
+func (l *memLedger) Balance(userID string, currency db.Currency, _ time.Time) (int64, error) {
+	var balance int64
+	for _, e := range l.entries {
+		if e.UserID == userID && e.Currency == currency {
+			balance += e.Credit - e.Debit
+		}
+	}
+	return balance, nil
+}
+
+// memTx транзакция над репозиториями в памяти без отката
+type memTx struct{ st Store }
+
+func (t memTx) Transaction(fn func(st Store) error) error { return fn(t.st) }
+
+// fixedPrice расчёт с одной и той же суммой за любой цикл
+type fixedPrice struct{ Service }
+
+func (fixedPrice) CalculateUserPayment(userID, subscriptionID string, dueDate time.Time) (*PaymentAmount, error) {
+	return &PaymentAmount{Amount: 10000, BaseKopecks: 9000, ProfitKopecks: 1000, Currency: db.RUB, DueDate: dueDate}, nil
+}
+
+func TestGenerateInvoicesCatchesUpMissedCycles(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, time.UTC) }
+	anchor := day(1, 1)
+	at := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name     string
+		joined   time.Time
+		left     *time.Time
+		existing []db.Invoice
+		now      time.Time
+		want     []string // начала циклов новых счетов
+	}{
+		{
+			name:   "no invoices yet: only the upcoming cycle",
+			joined: anchor,
+			now:    day(3, 1).Add(12 * time.Hour),
+			want:   []string{"2025-03-02"},
+		},
+		{
+			name:     "job was down for two cycles",
+			joined:   anchor,
+			existing: []db.Invoice{{PeriodStart: anchor, PeriodEnd: day(1, 31)}},
+			now:      day(3, 1).Add(12 * time.Hour),
+			want:     []string{"2025-01-31", "2025-03-02"},
+		},
+		{
+			name:     "up to date",
+			joined:   anchor,
+			existing: []db.Invoice{{PeriodStart: day(3, 2), PeriodEnd: day(4, 1)}},
+			now:      day(3, 1).Add(12 * time.Hour),
+			want:     nil,
+		},
+		{
+			name:   "voided invoice does not count as invoiced",
+			joined: anchor,
+			existing: []db.Invoice{
+				{PeriodStart: anchor, PeriodEnd: day(1, 31)},
+				{PeriodStart: day(1, 31), PeriodEnd: day(3, 2), Status: db.InvoiceVoided},
+			},
+			now:  day(3, 1).Add(12 * time.Hour),
+			want: []string{"2025-01-31", "2025-03-02"},
+		},
+		{
+			name:     "member left: no cycles after leaving",
+			joined:   anchor,
+			left:     at(day(2, 10)),
+			existing: []db.Invoice{{PeriodStart: anchor, PeriodEnd: day(1, 31)}},
+			now:      day(3, 1).Add(12 * time.Hour),
+			want:     []string{"2025-01-31"},
+		},
+		{
+			name:     "anchor moved: overlapping cycle is skipped",
+			joined:   anchor,
+			existing: []db.Invoice{{PeriodStart: day(1, 1).AddDate(0, 0, -10), PeriodEnd: day(1, 21)}},
+			now:      day(3, 1).Add(12 * time.Hour),
+			want:     []string{"2025-01-31", "2025-03-02"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			us := db.UserSubscription{
+				ID: "us", UserID: "u", SubscriptionID: "s",
+				AnchorDate: anchor, JoinedAt: tt.joined, LeftAt: tt.left,
+				Subscription: db.Subscription{PeriodDays: 30},
+			}
+			invoices := &memInvoices{}
+			for _, inv := range tt.existing {
+				inv.UserSubscriptionID = us.ID
+				if inv.Status == "" {
+					inv.Status = db.InvoiceIssued
+				}
+				invoices.list = append(invoices.list, inv)
+			}
+			ledger := &memLedger{}
+			s := &billingService{
+				invoiceRepo:    invoices,
+				paymentService: fixedPrice{},
+				tx:             memTx{st: Store{Invoices: invoices, Ledger: ledger}},
+			}
+
+			n, err := s.invoiceDueCycles(&us, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, inv := range invoices.list[len(tt.existing):] {
+				got = append(got, inv.PeriodStart.Format(time.DateOnly))
+			}
+			if n != len(tt.want) || len(got) != len(tt.want) {
+				t.Fatalf("created %d invoices %v, want %v", n, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("invoice %d for cycle %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+			// каждый новый счёт начислен на лицевой счёт
+			if len(ledger.entries) != len(tt.want) {
+				t.Errorf("ledger has %d charges, want %d", len(ledger.entries), len(tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	claimRepo "github.com/WhoYa/subscription-manager/internal/repository/paymentclaim"
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	userRepo "github.com/WhoYa/subscription-manager/internal/repository/user"
+	usRepo "github.com/WhoYa/subscription-manager/internal/repository/usersubscription"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidPaymentClaim  = errors.New("invalid payment claim")
+	ErrPaymentClaimNotFound = errors.New("payment claim not found")
+	ErrPaymentClaimResolved = errors.New("payment claim already resolved")
+)
+
+// paymentClaimService реализация PaymentClaims
+type paymentClaimService struct {
+	repo        claimRepo.PaymentClaimRepository
+	userRepo    userRepo.UserRepository
+	userSubRepo usRepo.UserSubscriptionRepository
+	invoicing   Invoicing
+	payments    Payments
+	currencies  Currencies
+	tx          Transactor
+}
+
+// NewPaymentClaims создаёт сервис сообщений участников об оплате
+func NewPaymentClaims(
+	repo claimRepo.PaymentClaimRepository,
+	userRepo userRepo.UserRepository,
+	userSubRepo usRepo.UserSubscriptionRepository,
+	invoicing Invoicing,
+	payments Payments,
+	currencies Currencies,
+	tx Transactor,
+) PaymentClaims {
+	return &paymentClaimService{
+		repo:        repo,
+		userRepo:    userRepo,
+		userSubRepo: userSubRepo,
+		invoicing:   invoicing,
+		payments:    payments,
+		currencies:  currencies,
+		tx:          tx,
+	}
+}
+
+// Submit проверяет и сохраняет сообщение об оплате в статусе pending
+func (s *paymentClaimService) Submit(claim *db.PaymentClaim) error {
+	if claim.Amount <= 0 {
+		return fmt.Errorf("%w: amount must be positive", ErrInvalidPaymentClaim)
+	}
+	switch claim.Method {
+	case db.PaymentCard, db.PaymentTransfer, db.PaymentCash, db.PaymentOther:
+	default:
+		return fmt.Errorf("%w: unknown method %q", ErrInvalidPaymentClaim, claim.Method)
+	}
+	if len(claim.ReceiptFileID) > 255 {
+		return fmt.Errorf("%w: receipt_file_id is too long", ErrInvalidPaymentClaim)
+	}
+
+	user, err := s.userRepo.FindByID(claim.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrUserNotFound
+	} else if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// счёт задаёт подписку и валюту
+	if claim.InvoiceID != nil {
+		inv, err := s.invoicing.Get(*claim.InvoiceID)
+		if err != nil {
+			return err
+		}
+		if inv.UserID != claim.UserID {
+			return ErrInvoiceNotFound
+		}
+		claim.SubscriptionID = inv.SubscriptionID
+		if claim.Currency == "" {
+			claim.Currency = inv.Currency
+		}
+	}
+	if claim.SubscriptionID == "" {
+		return fmt.Errorf("%w: subscription_id is required", ErrInvalidPaymentClaim)
+	}
+	if err := s.checkMember(claim.UserID, claim.SubscriptionID); err != nil {
+		return err
+	}
+
+	if claim.Currency == "" {
+		claim.Currency = user.SettlementCurrency
+	} else if claim.Currency, err = s.currencies.Validate(string(claim.Currency)); err != nil {
+		return err
+	}
+
+	if claim.PaidAt.IsZero() {
+		claim.PaidAt = time.Now().UTC()
+	}
+	claim.Status = db.ClaimPending
+	claim.RejectReason = ""
+	claim.ReviewedBy, claim.ReviewedAt, claim.PaymentLogID = nil, nil, nil
+
+	if err := s.repo.Create(claim); err != nil {
+		return fmt.Errorf("failed to save payment claim: %w", err)
+	}
+	log.Printf("CLAIMS: User %s reported payment %d %s for subscription %s (claim %s)",
+		claim.UserID, claim.Amount, claim.Currency, claim.SubscriptionID, claim.ID)
+	return nil
+}
+
+// Get возвращает сообщение об оплате по ID
+func (s *paymentClaimService) Get(id string) (*db.PaymentClaim, error) {
+	claim, err := s.repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPaymentClaimNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get payment claim: %w", err)
+	}
+	return claim, nil
+}
+
+// List возвращает страницу сообщений, по умолчанию в порядке очереди
+func (s *paymentClaimService) List(spec query.Spec) (query.Page[db.PaymentClaim], error) {
+	page, err := s.repo.List(spec)
+	if err != nil {
+		return page, fmt.Errorf("failed to list payment claims: %w", err)
+	}
+	return page, nil
+}
+
+// Approve подтверждает сообщение: регистрирует платёж, который гасит счёт. Решение, платёж
+// и ссылка на него сохраняются в одной транзакции: при сбое сообщение остаётся в очереди.
+func (s *paymentClaimService) Approve(id, reviewerID string) (*db.PaymentClaim, error) {
+	claim, err := s.pending(id)
+	if err != nil {
+		return nil, err
+	}
+
+	req := PaymentRequest{
+		UserID:         claim.UserID,
+		SubscriptionID: claim.SubscriptionID,
+		Amount:         claim.Amount,
+		Currency:       claim.Currency,
+		PaidAt:         claim.PaidAt,
+	}
+	if claim.InvoiceID != nil {
+		req.InvoiceID = *claim.InvoiceID
+	}
+	var pl *db.PaymentLog
+	err = s.tx.Transaction(func(st Store) error {
+		if err := resolveClaim(st.Claims, claim, reviewerID, db.ClaimApproved, ""); err != nil {
+			return err
+		}
+		var err error
+		if pl, err = s.payments.RecordIn(st, req); err != nil {
+			return err
+		}
+		if err := st.Claims.SetPayment(claim.ID, pl.ID, time.Now()); err != nil {
+			return fmt.Errorf("failed to link payment to claim: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	claim.PaymentLogID = &pl.ID
+	log.Printf("CLAIMS: Claim %s approved by %s, payment %s", claim.ID, reviewerID, pl.ID)
+	return claim, nil
+}
+
+// Reject отклоняет сообщение с причиной, которую увидит участник
+func (s *paymentClaimService) Reject(id, reviewerID, reason string) (*db.PaymentClaim, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidPaymentClaim)
+	}
+	if len(reason) > 500 {
+		return nil, fmt.Errorf("%w: reason is too long", ErrInvalidPaymentClaim)
+	}
+
+	claim, err := s.pending(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := resolveClaim(s.repo, claim, reviewerID, db.ClaimRejected, reason); err != nil {
+		return nil, err
+	}
+	log.Printf("CLAIMS: Claim %s rejected by %s: %s", claim.ID, reviewerID, reason)
+	return claim, nil
+}
+
+// pending возвращает сообщение, по которому ещё не принято решение
+func (s *paymentClaimService) pending(id string) (*db.PaymentClaim, error) {
+	claim, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if claim.Status != db.ClaimPending {
+		return nil, ErrPaymentClaimResolved
+	}
+	return claim, nil
+}
+
+// resolveClaim переводит ожидающее сообщение в status; решение принимается один раз
+func resolveClaim(repo claimRepo.PaymentClaimRepository, claim *db.PaymentClaim, reviewerID string, status db.PaymentClaimStatus, reason string) error {
+	now := time.Now().UTC()
+	claim.Status = status
+	claim.RejectReason = reason
+	claim.ReviewedAt = &now
+	if reviewerID != "" {
+		claim.ReviewedBy = &reviewerID
+	}
+	ok, err := repo.Resolve(claim, db.ClaimPending)
+	if err != nil {
+		return fmt.Errorf("failed to save payment claim: %w", err)
+	}
+	if !ok {
+		return ErrPaymentClaimResolved
+	}
+	return nil
+}
+
+// checkMember проверяет, что пользователь участвует в подписке
+func (s *paymentClaimService) checkMember(userID, subscriptionID string) error {
+	members, err := s.userSubRepo.FindBySubscription(subscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to get subscription members: %w", err)
+	}
+	for i := range members {
+		if members[i].UserID == userID {
+			return nil
+		}
+	}
+	return ErrUserSubscriptionNotFound
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"errors"
+	"maps"
+	"testing"
+	"time"
+
+	claimRepo "github.com/WhoYa/subscription-manager/internal/repository/paymentclaim"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"gorm.io/gorm"
+)
+
+// memClaims сообщения об оплате в памяти
+type memClaims struct {
+	claimRepo.PaymentClaimRepository
+	claims     map[string]db.PaymentClaim
+	setPayment error
+}
+
+func (r *memClaims) FindByID(id string) (*db.PaymentClaim, error) {
+	c, ok := r.claims[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &c, nil
+}
+
+func (r *memClaims) Resolve(claim *db.PaymentClaim, from db.PaymentClaimStatus) (bool, error) {
+	if r.claims[claim.ID].Status != from {
+		return false, nil
+	}
+	r.claims[claim.ID] = *claim
+	return true, nil
+}
+
+func (r *memClaims) SetPayment(id, paymentLogID string, _ time.Time) error {
+	if r.setPayment != nil {
+		return r.setPayment
+	}
+	c := r.claims[id]
+	c.PaymentLogID = &paymentLogID
+	r.claims[id] = c
+	return nil
+}
+
+// claimsTx транзакция над memClaims: при ошибке сообщения возвращаются к состоянию до неё
+type claimsTx struct {
+	claims   *memClaims
+	payments *int
+}
+
+func (t claimsTx) Transaction(fn func(st Store) error) error {
+	saved, payments := maps.Clone(t.claims.claims), *t.payments
+	if err := fn(Store{Claims: t.claims}); err != nil {
+		t.claims.claims, *t.payments = saved, payments
+		return err
+	}
+	return nil
+}
+
+// txPayments регистрирует платежи только через транзакцию вызывающего
+type txPayments struct {
+	Payments
+	recorded *int
+	err      error
+}
+
+func (p txPayments) RecordIn(st Store, req PaymentRequest) (*db.PaymentLog, error) {
+	if st.Claims == nil {
+		return nil, errors.New("payment recorded outside the claim transaction")
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	*p.recorded++
+	return &db.PaymentLog{ID: "pl1", UserID: req.UserID, Amount: req.Amount, Currency: req.Currency}, nil
+}
+
+func TestApproveClaim(t *testing.T) {
+	errDB := errors.New("connection reset")
+	tests := []struct {
+		name       string
+		recordErr  error
+		setPayment error
+		wantStatus db.PaymentClaimStatus
+	}{
+		{"approved", nil, nil, db.ClaimApproved},
+		{"payment fails", errDB, nil, db.ClaimPending},
+		{"link fails", nil, errDB, db.ClaimPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &memClaims{
+				claims: map[string]db.PaymentClaim{"c1": {
+					ID: "c1", UserID: "u1", SubscriptionID: "s1", Amount: 49900, Currency: db.RUB, Status: db.ClaimPending,
+				}},
+				setPayment: tt.setPayment,
+			}
+			recorded := 0
+			s := NewPaymentClaims(claims, nil, nil, nil,
+				txPayments{recorded: &recorded, err: tt.recordErr}, nil, claimsTx{claims: claims, payments: &recorded})
+
+			claim, err := s.Approve("c1", "admin")
+			stored := claims.claims["c1"]
+			if stored.Status != tt.wantStatus {
+				t.Errorf("claim status = %s, want %s", stored.Status, tt.wantStatus)
+			}
+
+			if tt.wantStatus == db.ClaimPending {
+				// сбой откатывает и решение, и платёж: сообщение можно подтвердить ещё раз
+				if err == nil {
+					t.Fatal("Approve() error = nil")
+				}
+				if recorded != 0 || stored.PaymentLogID != nil || stored.ReviewedAt != nil {
+					t.Errorf("after failure: %d payment(s), claim %+v", recorded, stored)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if recorded != 1 || stored.PaymentLogID == nil || *stored.PaymentLogID != "pl1" ||
+				claim.PaymentLogID == nil || *claim.PaymentLogID != "pl1" {
+				t.Errorf("%d payment(s), stored %+v, returned %+v", recorded, stored, claim)
+			}
+			if _, err := s.Approve("c1", "admin"); !errors.Is(err, ErrPaymentClaimResolved) {
+				t.Errorf("second Approve() error = %v, want %v", err, ErrPaymentClaimResolved)
+			}
+		})
+	}
+}
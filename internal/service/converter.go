@@ -0,0 +1,126 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	crRepo "github.com/WhoYa/subscription-manager/internal/repository/currencyrate"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/WhoYa/subscription-manager/pkg/money"
+)
+
+// rateConverter реализация Converter: валюты — вершины графа, курсы — рёбра в обе стороны
+type rateConverter struct {
+	currencyRepo crRepo.CurrencyRateRepository
+}
+
+// NewConverter создаёт сервис конвертации валют
+func NewConverter(currencyRepo crRepo.CurrencyRateRepository) Converter {
+	return &rateConverter{currencyRepo: currencyRepo}
+}
+
+// rateEdge ребро графа курсов: одна единица исходной валюты стоит num/den единиц to
+type rateEdge struct {
+	to       db.Currency
+	num, den money.Decimal
+}
+
+// Rate ищет путь с наименьшим числом пересчётов: прямой курс, обратный или кросс через другие валюты
+func (c *rateConverter) Rate(from, to db.Currency, t time.Time) (*Conversion, error) {
+	one := money.NewFromInt(1)
+	if from == to {
+		return &Conversion{From: from, To: to, Rate: one, Path: []db.Currency{from}, AsOf: t, num: one, den: one}, nil
+	}
+
+	rates, err := c.currencyRepo.RatesAsOf(t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rates: %w", err)
+	}
+
+	graph := make(map[db.Currency][]rateEdge)
+	for _, r := range rates {
+		if r.Value.Sign() <= 0 || r.Currency == r.QuoteCurrency {
+			continue
+		}
+		// обратный курс не делим заранее, чтобы не терять точность на малых значениях
+		graph[r.Currency] = append(graph[r.Currency], rateEdge{to: r.QuoteCurrency, num: r.Value, den: one})
+		graph[r.QuoteCurrency] = append(graph[r.QuoteCurrency], rateEdge{to: r.Currency, num: one, den: r.Value})
+	}
+	// порядок обхода не должен зависеть от порядка строк в выборке
+	for _, edges := range graph {
+		sort.Slice(edges, func(i, j int) bool { return edges[i].to < edges[j].to })
+	}
+
+	// поиск в ширину: найденный путь — кратчайший по числу пересчётов
+	type step struct {
+		prev     db.Currency
+		num, den money.Decimal
+	}
+	visited := map[db.Currency]step{from: {num: one, den: one}}
+	queue := []db.Currency{from}
+	for len(queue) > 0 && !hasKey(visited, to) {
+		curr := queue[0]
+		queue = queue[1:]
+		for _, e := range graph[curr] {
+			if hasKey(visited, e.to) {
+				continue
+			}
+			num, err := visited[curr].num.Mul(e.num)
+			if err != nil {
+				return nil, err
+			}
+			den, err := visited[curr].den.Mul(e.den)
+			if err != nil {
+				return nil, err
+			}
+			visited[e.to] = step{prev: curr, num: num, den: den}
+			queue = append(queue, e.to)
+		}
+	}
+
+	last, ok := visited[to]
+	if !ok {
+		return nil, fmt.Errorf("%w: no path from %s to %s as of %s",
+			ErrExchangeRateNotFound, from, to, t.Format(time.RFC3339))
+	}
+
+	path := []db.Currency{to}
+	for curr := to; curr != from; curr = visited[curr].prev {
+		path = append([]db.Currency{visited[curr].prev}, path...)
+	}
+
+	rate, err := last.num.Div(last.den)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Conversion{
+		From: from,
+		To:   to,
+		Rate: rate,
+		Path: path,
+		AsOf: t,
+		num:  last.num,
+		den:  last.den,
+	}, nil
+}
+
+// Convert пересчитывает сумму из одной валюты в другую по курсу на момент t.
+// Результат не округляется до копеек — это решает вызывающий.
+func (c *rateConverter) Convert(amount money.Decimal, from, to db.Currency, t time.Time) (money.Decimal, *Conversion, error) {
+	conv, err := c.Rate(from, to, t)
+	if err != nil {
+		return money.Zero, nil, err
+	}
+	converted, err := amount.MulDiv(conv.num, conv.den)
+	if err != nil {
+		return money.Zero, nil, err
+	}
+	return converted, conv, nil
+}
+
+func hasKey[K comparable, V any](m map[K]V, k K) bool {
+	_, ok := m[k]
+	return ok
+}
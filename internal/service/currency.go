@@ -0,0 +1,120 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	curRepo "github.com/WhoYa/subscription-manager/internal/repository/currency"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrInvalidCurrency     = errors.New("invalid currency")
+	ErrCurrencyExists      = errors.New("currency already exists")
+	ErrCurrencyNotFound    = errors.New("currency not found")
+)
+
+// maxMinorDigits больше знаков после запятой у валют ISO 4217 не бывает
+const maxMinorDigits = 4
+
+var currencyCodeRe = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// currencyService реализация Currencies
+type currencyService struct {
+	currencyRepo curRepo.CurrencyRepository
+}
+
+// NewCurrencies создаёт сервис справочника валют
+func NewCurrencies(currencyRepo curRepo.CurrencyRepository) Currencies {
+	return &currencyService{currencyRepo: currencyRepo}
+}
+
+// Validate проверяет код валюты по справочнику
+func (s *currencyService) Validate(code string) (db.Currency, error) {
+	curr := db.Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if !currencyCodeRe.MatchString(string(curr)) {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedCurrency, code)
+	}
+
+	info, err := s.currencyRepo.FindByCode(curr)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", fmt.Errorf("%w: %s", ErrUnsupportedCurrency, curr)
+		}
+		return "", fmt.Errorf("failed to get currency: %w", err)
+	}
+	if !info.Enabled {
+		return "", fmt.Errorf("%w: %s is disabled", ErrUnsupportedCurrency, curr)
+	}
+	return curr, nil
+}
+
+// Get возвращает валюту по коду
+func (s *currencyService) Get(code db.Currency) (*db.CurrencyInfo, error) {
+	info, err := s.currencyRepo.FindByCode(db.Currency(strings.ToUpper(string(code))))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrCurrencyNotFound, code)
+		}
+		return nil, fmt.Errorf("failed to get currency: %w", err)
+	}
+	return info, nil
+}
+
+// List возвращает справочник валют
+func (s *currencyService) List(enabledOnly bool) ([]db.CurrencyInfo, error) {
+	list, err := s.currencyRepo.List(enabledOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list currencies: %w", err)
+	}
+	return list, nil
+}
+
+// Create добавляет валюту в справочник
+func (s *currencyService) Create(c *db.CurrencyInfo) error {
+	c.Code = db.Currency(strings.ToUpper(strings.TrimSpace(string(c.Code))))
+	if err := validateCurrencyInfo(c); err != nil {
+		return err
+	}
+
+	if err := s.currencyRepo.Create(c); err != nil {
+		if errors.Is(err, curRepo.ErrDuplicateCurrency) {
+			return fmt.Errorf("%w: %s", ErrCurrencyExists, c.Code)
+		}
+		return fmt.Errorf("failed to create currency: %w", err)
+	}
+	log.Printf("CURRENCY: Added %s (%s), enabled: %t", c.Code, c.Name, c.Enabled)
+	return nil
+}
+
+// Update сохраняет изменения валюты; базовую валюту выключить нельзя
+func (s *currencyService) Update(c *db.CurrencyInfo) error {
+	if err := validateCurrencyInfo(c); err != nil {
+		return err
+	}
+	if c.Code == db.RUB && !c.Enabled {
+		return fmt.Errorf("%w: base currency %s cannot be disabled", ErrInvalidCurrency, db.RUB)
+	}
+
+	if err := s.currencyRepo.Update(c); err != nil {
+		return fmt.Errorf("failed to update currency: %w", err)
+	}
+	log.Printf("CURRENCY: Updated %s, enabled: %t", c.Code, c.Enabled)
+	return nil
+}
+
+// validateCurrencyInfo проверяет поля записи справочника
+func validateCurrencyInfo(c *db.CurrencyInfo) error {
+	if !currencyCodeRe.MatchString(string(c.Code)) {
+		return fmt.Errorf("%w: code must be a 3-letter ISO 4217 code", ErrInvalidCurrency)
+	}
+	if c.MinorDigits < 0 || c.MinorDigits > maxMinorDigits {
+		return fmt.Errorf("%w: minor_digits must be between 0 and %d", ErrInvalidCurrency, maxMinorDigits)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	idemRepo "github.com/WhoYa/subscription-manager/internal/repository/idempotency"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was used with another request")
+	ErrIdempotencyKeyInProgress = errors.New("request with this idempotency key is in progress")
+)
+
+// idempotencyService реализация Idempotency
+type idempotencyService struct {
+	repo  idemRepo.IdempotencyRepository
+	lease time.Duration
+}
+
+// NewIdempotency создаёт сервис ключей идемпотентности. Ключ незавершённого запроса
+// держится lease: если запрос за это время не завершился (процесс упал или перезапустился),
+// повтор занимает ключ и выполняется заново.
+func NewIdempotency(repo idemRepo.IdempotencyRepository, lease time.Duration) Idempotency {
+	return &idempotencyService{repo: repo, lease: lease}
+}
+
+func (s *idempotencyService) Begin(apiKeyID, key, hash string) (*db.IdempotencyKey, error) {
+	// вторая попытка нужна, если ключ освободили между Create и Find
+	for attempt := 0; attempt < 2; attempt++ {
+		record := &db.IdempotencyKey{APIKeyID: apiKeyID, Key: key, RequestHash: hash}
+		err := s.repo.Create(record)
+		if err == nil {
+			return record, nil
+		}
+		if !errors.Is(err, idemRepo.ErrDuplicateKey) {
+			return nil, fmt.Errorf("failed to save idempotency key: %w", err)
+		}
+
+		existing, err := s.repo.Find(apiKeyID, key)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+		}
+		switch {
+		case existing.RequestHash != hash:
+			return nil, ErrIdempotencyKeyReused
+		case existing.Status == 0:
+			return s.reclaim(existing)
+		}
+		return existing, nil
+	}
+	return nil, ErrIdempotencyKeyInProgress
+}
+
+// reclaim занимает ключ запроса, который не завершился за lease; иначе запрос ещё выполняется
+func (s *idempotencyService) reclaim(record *db.IdempotencyKey) (*db.IdempotencyKey, error) {
+	if time.Since(record.CreatedAt) < s.lease {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	ok, err := s.repo.Reclaim(record, time.Now().UTC().Add(-s.lease))
+	if err != nil {
+		return nil, fmt.Errorf("failed to reclaim idempotency key: %w", err)
+	}
+	if !ok {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	return record, nil
+}
+
+func (s *idempotencyService) Complete(record *db.IdempotencyKey, status int, contentType string, body []byte) error {
+	record.Status = status
+	record.ContentType = contentType
+	record.Response = body
+	if err := s.repo.Complete(record); err != nil {
+		return fmt.Errorf("failed to save idempotent response: %w", err)
+	}
+	return nil
+}
+
+func (s *idempotencyService) Release(record *db.IdempotencyKey) error {
+	if err := s.repo.Delete(record.ID); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (s *idempotencyService) Purge(before time.Time) (int, error) {
+	purged, err := s.repo.Purge(before)
+	if err != nil {
+		return purged, fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+	return purged, nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	idemRepo "github.com/WhoYa/subscription-manager/internal/repository/idempotency"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"gorm.io/gorm"
+)
+
+// memIdempotency ключи идемпотентности в памяти
+type memIdempotency struct {
+	idemRepo.IdempotencyRepository
+	keys map[string]*db.IdempotencyKey
+}
+
+func (r *memIdempotency) Create(k *db.IdempotencyKey) error {
+	if _, ok := r.keys[k.Key]; ok {
+		return idemRepo.ErrDuplicateKey
+	}
+	k.ID = k.Key
+	k.CreatedAt = time.Now()
+	stored := *k
+	r.keys[k.Key] = &stored
+	return nil
+}
+
+func (r *memIdempotency) Find(_, key string) (*db.IdempotencyKey, error) {
+	k, ok := r.keys[key]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *k
+	return &found, nil
+}
+
+func (r *memIdempotency) Reclaim(k *db.IdempotencyKey, before time.Time) (bool, error) {
+	stored := r.keys[k.Key]
+	if stored.Status != 0 || !stored.CreatedAt.Before(before) {
+		return false, nil
+	}
+	stored.CreatedAt = time.Now()
+	k.CreatedAt = stored.CreatedAt
+	return true, nil
+}
+
+func TestIdempotencyBegin(t *testing.T) {
+	tests := []struct {
+		name    string
+		stored  db.IdempotencyKey
+		hash    string
+		wantErr error
+	}{
+		{"completed", db.IdempotencyKey{RequestHash: "h", Status: 201, CreatedAt: time.Now()}, "h", nil},
+		{"other request", db.IdempotencyKey{RequestHash: "h", Status: 201, CreatedAt: time.Now()}, "other", ErrIdempotencyKeyReused},
+		{"in progress", db.IdempotencyKey{RequestHash: "h", CreatedAt: time.Now().Add(-30 * time.Second)}, "h", ErrIdempotencyKeyInProgress},
+		{"lease expired", db.IdempotencyKey{RequestHash: "h", CreatedAt: time.Now().Add(-2 * time.Minute)}, "h", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := tt.stored
+			stored.ID, stored.Key = "k", "k"
+			repo := &memIdempotency{keys: map[string]*db.IdempotencyKey{"k": &stored}}
+			s := NewIdempotency(repo, time.Minute)
+
+			record, err := s.Begin("api-key", "k", tt.hash)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Begin() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if record.Status != tt.stored.Status {
+				t.Errorf("Begin() status = %d, want %d", record.Status, tt.stored.Status)
+			}
+			if record.Status == 0 && time.Since(stored.CreatedAt) > time.Second {
+				t.Error("expired key was not reclaimed")
+			}
+		})
+	}
+}
+
+func TestIdempotencyBeginNewKey(t *testing.T) {
+	repo := &memIdempotency{keys: map[string]*db.IdempotencyKey{}}
+	s := NewIdempotency(repo, time.Minute)
+
+	record, err := s.Begin("api-key", "k", "h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Status != 0 {
+		t.Errorf("new key status = %d, want 0", record.Status)
+	}
+	if _, err := s.Begin("api-key", "k", "h"); !errors.Is(err, ErrIdempotencyKeyInProgress) {
+		t.Errorf("second Begin() error = %v, want %v", err, ErrIdempotencyKeyInProgress)
+	}
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	invRepo "github.com/WhoYa/subscription-manager/internal/repository/invoice"
+	usRepo "github.com/WhoYa/subscription-manager/internal/repository/usersubscription"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvoiceNotFound          = errors.New("invoice not found")
+	ErrInvoiceExists            = errors.New("invoice for this cycle already exists")
+	ErrInvalidInvoiceTransition = errors.New("invalid invoice status transition")
+)
+
+// invoiceService реализация Invoicing
+type invoiceService struct {
+	invoiceRepo    invRepo.InvoiceRepository
+	userSubRepo    usRepo.UserSubscriptionRepository
+	paymentService Service
+	tx             Transactor
+}
+
+// NewInvoicing создаёт сервис жизненного цикла счетов
+func NewInvoicing(
+	invoiceRepo invRepo.InvoiceRepository,
+	userSubRepo usRepo.UserSubscriptionRepository,
+	paymentService Service,
+	tx Transactor,
+) Invoicing {
+	return &invoiceService{
+		invoiceRepo:    invoiceRepo,
+		userSubRepo:    userSubRepo,
+		paymentService: paymentService,
+		tx:             tx,
+	}
+}
+
+// CreateDraft создаёт черновик счёта за цикл, в который попадает dueDate
+func (s *invoiceService) CreateDraft(userID, subscriptionID string, dueDate time.Time) (*db.Invoice, error) {
+	userSubs, err := s.userSubRepo.FindByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user subscriptions: %w", err)
+	}
+
+	var userSub *db.UserSubscription
+	for i := range userSubs {
+		if userSubs[i].SubscriptionID == subscriptionID {
+			userSub = &userSubs[i]
+			break
+		}
+	}
+	if userSub == nil {
+		return nil, fmt.Errorf("%w: user %s not subscribed to %s", ErrUserSubscriptionNotFound, userID, subscriptionID)
+	}
+
+	periodStart, periodEnd := cycleAt(userSub.AnchorDate, userSub.Subscription.PeriodDays, dueDate)
+
+	calc, err := s.paymentService.CalculateUserPayment(userID, subscriptionID, periodStart)
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &db.Invoice{
+		UserSubscriptionID: userSub.ID,
+		UserID:             userID,
+		SubscriptionID:     subscriptionID,
+		PeriodStart:        periodStart,
+		PeriodEnd:          periodEnd,
+		DueDate:            periodStart,
+		Status:             db.InvoiceDraft,
+	}
+	freezeAmounts(inv, calc)
+
+	if err := s.invoiceRepo.Create(inv); err != nil {
+		if errors.Is(err, invRepo.ErrDuplicateInvoice) {
+			return nil, fmt.Errorf("%w: period %s", ErrInvoiceExists, periodStart.Format("2006-01-02"))
+		}
+		return nil, fmt.Errorf("failed to create invoice: %w", err)
+	}
+	return inv, nil
+}
+
+// Issue выставляет черновик; суммы пересчитываются по текущему курсу и больше не меняются
+func (s *invoiceService) Issue(id string) (*db.Invoice, error) {
+	inv, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if inv.Status != db.InvoiceDraft {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidInvoiceTransition, inv.Status, db.InvoiceIssued)
+	}
+
+	calc, err := s.paymentService.CalculateUserPayment(inv.UserID, inv.SubscriptionID, inv.DueDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// счёт выставляется вместе с начислением; статус перечитывается под блокировкой
+	// лицевого счёта, чтобы параллельный запрос не выставил его второй раз
+	err = s.tx.Transaction(func(st Store) error {
+		if err := lockLedger(st, inv.UserID); err != nil {
+			return err
+		}
+		if inv, err = findInvoice(st.Invoices, id); err != nil {
+			return err
+		}
+		if inv.Status != db.InvoiceDraft {
+			return fmt.Errorf("%w: %s -> %s", ErrInvalidInvoiceTransition, inv.Status, db.InvoiceIssued)
+		}
+
+		freezeAmounts(inv, calc)
+		now := time.Now().UTC()
+		inv.Status = db.InvoiceIssued
+		inv.IssuedAt = &now
+		if err := st.Invoices.Update(inv); err != nil {
+			return fmt.Errorf("failed to update invoice: %w", err)
+		}
+		return chargeInvoice(st, inv)
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("INVOICE: Invoice %s issued for user %s, amount %d", inv.ID, inv.UserID, inv.Amount)
+
+	// переплата могла погасить счёт сразу
+	return s.find(inv.ID)
+}
+
+// Void аннулирует счёт, по которому ещё не было оплаты
+func (s *invoiceService) Void(id, reason string) (*db.Invoice, error) {
+	inv, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// аннулирование и сторно проводятся вместе; оплата могла прийти после чтения счёта,
+	// поэтому состояние проверяется ещё раз под блокировкой лицевого счёта
+	err = s.tx.Transaction(func(st Store) error {
+		if err := lockLedger(st, inv.UserID); err != nil {
+			return err
+		}
+		if inv, err = findInvoice(st.Invoices, id); err != nil {
+			return err
+		}
+		if inv.Status == db.InvoicePaid || inv.Status == db.InvoiceVoided || inv.PaidAmount > 0 {
+			return fmt.Errorf("%w: %s -> %s", ErrInvalidInvoiceTransition, inv.Status, db.InvoiceVoided)
+		}
+
+		now := time.Now().UTC()
+		inv.Status = db.InvoiceVoided
+		inv.VoidedAt = &now
+		inv.VoidReason = reason
+		if err := st.Invoices.Update(inv); err != nil {
+			return fmt.Errorf("failed to update invoice: %w", err)
+		}
+		return reverseCharge(st, inv)
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("INVOICE: Invoice %s voided: %s", inv.ID, reason)
+	return inv, nil
+}
+
+// Get возвращает счёт по ID
+func (s *invoiceService) Get(id string) (*db.Invoice, error) {
+	return s.find(id)
+}
+
+// OutstandingFor возвращает самый старый неоплаченный счёт пользователя по подписке
+func (s *invoiceService) OutstandingFor(userID, subscriptionID string) (*db.Invoice, error) {
+	list, err := s.invoiceRepo.FindOutstanding(userID, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outstanding invoices: %w", err)
+	}
+	if len(list) == 0 {
+		return nil, ErrInvoiceNotFound
+	}
+	return &list[0], nil
+}
+
+// ApplyPayment засчитывает платёж в счёт в пределах остатка; переплата остаётся на балансе участника
+func (s *invoiceService) ApplyPayment(id string, amount int64, paidAt time.Time) (*db.Invoice, error) {
+	var inv *db.Invoice
+	err := s.tx.Transaction(func(st Store) error {
+		var err error
+		inv, err = applyInvoicePayment(st, id, amount, paidAt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// MarkOverdue переводит в overdue счета, дата списания которых уже прошла
+func (s *invoiceService) MarkOverdue(now time.Time) (int64, error) {
+	n, err := s.invoiceRepo.MarkOverdue(truncateDay(now))
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark overdue invoices: %w", err)
+	}
+	return n, nil
+}
+
+func (s *invoiceService) find(id string) (*db.Invoice, error) {
+	return findInvoice(s.invoiceRepo, id)
+}
+
+// findInvoice счёт по ID; отсутствующий — ErrInvoiceNotFound
+func findInvoice(repo invRepo.InvoiceRepository, id string) (*db.Invoice, error) {
+	inv, err := repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrInvoiceNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+	return inv, nil
+}
+
+// applyInvoicePayment засчитывает платёж в неоплаченный счёт; вызывается в транзакции
+// вместе с сохранением платежа и проводкой по лицевому счёту
+func applyInvoicePayment(st Store, id string, amount int64, paidAt time.Time) (*db.Invoice, error) {
+	inv, err := findInvoice(st.Invoices, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockLedger(st, inv.UserID); err != nil {
+		return nil, err
+	}
+	// счёт перечитывается под блокировкой: параллельный платёж мог его уже погасить
+	if inv, err = findInvoice(st.Invoices, id); err != nil {
+		return nil, err
+	}
+	if !isOutstanding(inv.Status) {
+		return nil, fmt.Errorf("%w: cannot pay %s invoice", ErrInvalidInvoiceTransition, inv.Status)
+	}
+
+	applyToInvoice(inv, amount, paidAt)
+	if err := st.Invoices.Update(inv); err != nil {
+		return nil, fmt.Errorf("failed to update invoice: %w", err)
+	}
+	return inv, nil
+}
+
+// isOutstanding проверяет, ожидает ли счёт оплаты
+func isOutstanding(status db.InvoiceStatus) bool {
+	for _, st := range db.OutstandingInvoiceStatuses {
+		if st == status {
+			return true
+		}
+	}
+	return false
+}
+
+// applyToInvoice засчитывает в счёт не больше его остатка и возвращает засчитанную сумму.
+// Частичная оплата → partially_paid, полная → paid.
+func applyToInvoice(inv *db.Invoice, amount int64, paidAt time.Time) int64 {
+	applied := min(amount, inv.Amount-inv.PaidAmount)
+	if applied <= 0 {
+		return 0
+	}
+
+	inv.PaidAmount += applied
+	if inv.PaidAmount >= inv.Amount {
+		inv.Status = db.InvoicePaid
+		inv.PaidAt = &paidAt
+	} else if inv.Status != db.InvoiceOverdue {
+		// просроченный счёт остаётся просроченным до полной оплаты
+		inv.Status = db.InvoicePartiallyPaid
+	}
+	return applied
+}
+
+// freezeAmounts переносит рассчитанные суммы, курс и надбавку в счёт
+func freezeAmounts(inv *db.Invoice, calc *PaymentAmount) {
+	inv.Amount = calc.Amount
+	inv.BaseAmount = calc.BaseKopecks
+	inv.ProfitAmount = calc.ProfitKopecks
+	inv.Currency = calc.Currency
+	inv.RateUsed = calc.ExchangeRate
+	inv.MarkupPercent = calc.MarkupPercent
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	ledgerRepo "github.com/WhoYa/subscription-manager/internal/repository/ledger"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+var ErrInvalidAdjustment = errors.New("invalid adjustment")
+
+// ledgerService реализация Ledger
+type ledgerService struct {
+	ledgerRepo ledgerRepo.LedgerRepository
+	tx         Transactor
+}
+
+// NewLedger создаёт сервис лицевых счетов участников
+func NewLedger(ledgerRepo ledgerRepo.LedgerRepository, tx Transactor) Ledger {
+	return &ledgerService{
+		ledgerRepo: ledgerRepo,
+		tx:         tx,
+	}
+}
+
+// Charge проводит дебет по выставленному счёту и гасит его накопленной переплатой
+func (s *ledgerService) Charge(inv *db.Invoice) error {
+	return s.tx.Transaction(func(st Store) error { return chargeInvoice(st, inv) })
+}
+
+// ReverseCharge сторнирует начисление по аннулированному счёту
+func (s *ledgerService) ReverseCharge(inv *db.Invoice) error {
+	return s.tx.Transaction(func(st Store) error { return reverseCharge(st, inv) })
+}
+
+// RecordPayment проводит кредит по платежу; переплата гасит другие неоплаченные счета
+func (s *ledgerService) RecordPayment(pl *db.PaymentLog) error {
+	return s.tx.Transaction(func(st Store) error { return recordPayment(st, pl) })
+}
+
+// Adjust проводит ручную корректировку: amount > 0 — кредит участнику, amount < 0 — дебет
+func (s *ledgerService) Adjust(userID string, amount int64, currency db.Currency, description, createdBy string) (*db.LedgerEntry, error) {
+	var entry *db.LedgerEntry
+	err := s.tx.Transaction(func(st Store) error {
+		var err error
+		entry, err = adjustBalance(st, userID, amount, currency, description, createdBy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Balance возвращает текущий баланс участника в валюте currency
+func (s *ledgerService) Balance(userID string, currency db.Currency) (int64, error) {
+	balance, err := s.ledgerRepo.Balance(userID, currency, time.Time{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get balance: %w", err)
+	}
+	return balance, nil
+}
+
+// Statement возвращает текущий баланс и выписку за период с нарастающим итогом в валюте currency
+func (s *ledgerService) Statement(userID string, currency db.Currency, from, to time.Time) (*Statement, error) {
+	balance, err := s.ledgerRepo.Balance(userID, currency, time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance: %w", err)
+	}
+	opening, err := s.ledgerRepo.Balance(userID, currency, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get opening balance: %w", err)
+	}
+	entries, err := s.ledgerRepo.FindByUser(userID, currency, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger entries: %w", err)
+	}
+
+	st := &Statement{
+		UserID:         userID,
+		Currency:       currency,
+		From:           from,
+		To:             to,
+		Balance:        balance,
+		OpeningBalance: opening,
+		Entries:        make([]StatementLine, 0, len(entries)),
+	}
+
+	running := opening
+	for _, e := range entries {
+		running += e.Credit - e.Debit
+		st.TotalDebit += e.Debit
+		st.TotalCredit += e.Credit
+		st.Entries = append(st.Entries, StatementLine{LedgerEntry: e, Balance: running})
+	}
+	st.ClosingBalance = running
+	return st, nil
+}
+
+// chargeInvoice проводит дебет по счёту и гасит его переплатой; вызывается в транзакции
+// вместе с сохранением счёта
+func chargeInvoice(st Store, inv *db.Invoice) error {
+	if err := lockLedger(st, inv.UserID); err != nil {
+		return err
+	}
+
+	occurredAt := inv.CreatedAt
+	if inv.IssuedAt != nil {
+		occurredAt = *inv.IssuedAt
+	}
+
+	invoiceID := inv.ID
+	entry := &db.LedgerEntry{
+		UserID:      inv.UserID,
+		Type:        db.LedgerCharge,
+		Debit:       inv.Amount,
+		Currency:    inv.Currency,
+		InvoiceID:   &invoiceID,
+		Description: fmt.Sprintf("Счёт за %s — %s", inv.PeriodStart.Format("02.01.2006"), inv.PeriodEnd.Format("02.01.2006")),
+		OccurredAt:  occurredAt,
+	}
+	if err := st.Ledger.Create(entry); err != nil {
+		return fmt.Errorf("failed to create charge entry: %w", err)
+	}
+	return settle(st, inv.UserID, inv.Currency, occurredAt)
+}
+
+// reverseCharge сторнирует начисление по счёту; вызывается в транзакции вместе с аннулированием
+func reverseCharge(st Store, inv *db.Invoice) error {
+	if inv.IssuedAt == nil {
+		return nil // черновик не начислялся
+	}
+	if err := lockLedger(st, inv.UserID); err != nil {
+		return err
+	}
+
+	occurredAt := time.Now().UTC()
+	if inv.VoidedAt != nil {
+		occurredAt = *inv.VoidedAt
+	}
+
+	invoiceID := inv.ID
+	entry := &db.LedgerEntry{
+		UserID:      inv.UserID,
+		Type:        db.LedgerReversal,
+		Credit:      inv.Amount,
+		Currency:    inv.Currency,
+		InvoiceID:   &invoiceID,
+		Description: inv.VoidReason,
+		OccurredAt:  occurredAt,
+	}
+	if err := st.Ledger.Create(entry); err != nil {
+		return fmt.Errorf("failed to create reversal entry: %w", err)
+	}
+	return nil
+}
+
+// recordPayment проводит кредит по сохранённому платежу и гасит переплатой другие счета
+func recordPayment(st Store, pl *db.PaymentLog) error {
+	if err := lockLedger(st, pl.UserID); err != nil {
+		return err
+	}
+
+	paymentID := pl.ID
+	entry := &db.LedgerEntry{
+		UserID:       pl.UserID,
+		Type:         db.LedgerPayment,
+		Credit:       pl.Amount,
+		Currency:     pl.Currency,
+		InvoiceID:    pl.InvoiceID,
+		PaymentLogID: &paymentID,
+		OccurredAt:   pl.PaidAt,
+	}
+	if err := st.Ledger.Create(entry); err != nil {
+		return fmt.Errorf("failed to create payment entry: %w", err)
+	}
+	return settle(st, pl.UserID, pl.Currency, pl.PaidAt)
+}
+
+// adjustBalance проводит ручную корректировку; кредит сразу гасит неоплаченные счета
+func adjustBalance(st Store, userID string, amount int64, currency db.Currency, description, createdBy string) (*db.LedgerEntry, error) {
+	if amount == 0 {
+		return nil, fmt.Errorf("%w: amount must not be zero", ErrInvalidAdjustment)
+	}
+	if currency == "" {
+		return nil, fmt.Errorf("%w: currency is required", ErrInvalidAdjustment)
+	}
+	if description == "" {
+		return nil, fmt.Errorf("%w: description is required", ErrInvalidAdjustment)
+	}
+	if err := lockLedger(st, userID); err != nil {
+		return nil, err
+	}
+
+	entry := &db.LedgerEntry{
+		UserID:      userID,
+		Type:        db.LedgerAdjustment,
+		Currency:    currency,
+		Description: description,
+		OccurredAt:  time.Now().UTC(),
+	}
+	if amount > 0 {
+		entry.Credit = amount
+	} else {
+		entry.Debit = -amount
+	}
+	if createdBy != "" {
+		entry.CreatedBy = &createdBy
+	}
+
+	if err := st.Ledger.Create(entry); err != nil {
+		return nil, fmt.Errorf("failed to create adjustment entry: %w", err)
+	}
+	log.Printf("LEDGER: Adjustment %d %s for user %s by %s: %s", amount, currency, userID, createdBy, description)
+
+	if amount > 0 {
+		if err := settle(st, userID, currency, entry.OccurredAt); err != nil {
+			return nil, err
+		}
+	}
+	return entry, nil
+}
+
+// lockLedger блокирует лицевой счёт участника до конца транзакции: settle читает баланс
+// и остатки счетов, и параллельная проводка не должна погасить те же счета второй раз
+func lockLedger(st Store, userID string) error {
+	if err := st.Ledger.Lock(userID); err != nil {
+		return fmt.Errorf("failed to lock ledger: %w", err)
+	}
+	return nil
+}
+
+// settle гасит неоплаченные счета участника в валюте currency свободной переплатой
+// в той же валюте, старые первыми.
+// Свободная переплата = баланс + остатки неоплаченных счетов (их начисления уже в балансе).
+func settle(st Store, userID string, currency db.Currency, at time.Time) error {
+	balance, err := st.Ledger.Balance(userID, currency, time.Time{})
+	if err != nil {
+		return fmt.Errorf("failed to get balance: %w", err)
+	}
+
+	all, err := st.Invoices.FindOutstanding(userID, "")
+	if err != nil {
+		return fmt.Errorf("failed to get outstanding invoices: %w", err)
+	}
+	var outstanding []db.Invoice
+	for _, inv := range all {
+		if inv.Currency == currency {
+			outstanding = append(outstanding, inv)
+		}
+	}
+
+	available := balance
+	for _, inv := range outstanding {
+		available += inv.Amount - inv.PaidAmount
+	}
+
+	for i := range outstanding {
+		if available <= 0 {
+			break
+		}
+		inv := &outstanding[i]
+		applied := applyToInvoice(inv, available, at)
+		if applied == 0 {
+			continue
+		}
+		if err := st.Invoices.Update(inv); err != nil {
+			return fmt.Errorf("failed to update invoice: %w", err)
+		}
+		available -= applied
+		log.Printf("LEDGER: Applied %d to invoice %s for user %s", applied, inv.ID, userID)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+func TestRecordPaymentSettlesSameCurrency(t *testing.T) {
+	issued := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	invoices := &memInvoices{list: []db.Invoice{
+		{ID: "rub", UserID: "u", Amount: 10000, Currency: db.RUB, Status: db.InvoiceIssued, IssuedAt: &issued},
+		{ID: "usd", UserID: "u", Amount: 500, Currency: db.USD, Status: db.InvoiceIssued, IssuedAt: &issued},
+	}}
+	ledger := &memLedger{}
+	st := Store{Invoices: invoices, Ledger: ledger}
+	for i := range invoices.list {
+		if err := chargeInvoice(st, &invoices.list[i]); err != nil {
+			t.Fatalf("chargeInvoice: %v", err)
+		}
+	}
+
+	// переплата в долларах не должна гасить рублёвый счёт
+	pl := &db.PaymentLog{ID: "p", UserID: "u", Amount: 20000, Currency: db.USD, PaidAt: issued.AddDate(0, 0, 1)}
+	if err := recordPayment(st, pl); err != nil {
+		t.Fatalf("recordPayment: %v", err)
+	}
+
+	want := map[string]db.InvoiceStatus{"rub": db.InvoiceIssued, "usd": db.InvoicePaid}
+	for _, inv := range invoices.list {
+		if inv.Status != want[inv.ID] {
+			t.Errorf("invoice %s status = %s, want %s", inv.ID, inv.Status, want[inv.ID])
+		}
+	}
+
+	balances := map[db.Currency]int64{db.RUB: -10000, db.USD: 19500}
+	for currency, want := range balances {
+		got, err := ledger.Balance("u", currency, time.Time{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("%s balance = %d, want %d", currency, got, want)
+		}
+	}
+	for _, e := range ledger.entries {
+		if e.Currency == "" {
+			t.Errorf("entry %s %+v has no currency", e.Type, e)
+		}
+	}
+}
+
+func TestAdjustBalanceRequiresCurrency(t *testing.T) {
+	st := Store{Invoices: &memInvoices{}, Ledger: &memLedger{}}
+	if _, err := adjustBalance(st, "u", 100, "", "bonus", ""); err == nil {
+		t.Fatal("adjustBalance without currency: want error")
+	}
+}
@@ -3,14 +3,15 @@ package service
 import (
 	"errors"
 	"fmt"
-	"math"
 	"time"
 
-	crRepo "github.com/WhoYa/subscription-manager/internal/repository/currencyrate"
 	gsRepo "github.com/WhoYa/subscription-manager/internal/repository/globalsettings"
+	prRepo "github.com/WhoYa/subscription-manager/internal/repository/pricingrule"
 	subRepo "github.com/WhoYa/subscription-manager/internal/repository/subscription"
+	userRepo "github.com/WhoYa/subscription-manager/internal/repository/user"
 	usRepo "github.com/WhoYa/subscription-manager/internal/repository/usersubscription"
 	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/WhoYa/subscription-manager/pkg/money"
 	"gorm.io/gorm"
 )
 
@@ -18,44 +19,57 @@ var (
 	ErrUserSubscriptionNotFound = errors.New("user subscription not found")
 	ErrSubscriptionNotFound     = errors.New("subscription not found")
 	ErrExchangeRateNotFound     = errors.New("exchange rate not found")
+	ErrUserNotFound             = errors.New("user not found")
 )
 
+// pricingRounding режим округления сумм к оплате: коммерческий, половина копейки — вверх
+const pricingRounding = money.HalfUp
+
 // paymentService простая реализация Service
 type paymentService struct {
+	userRepo     userRepo.UserRepository
 	userSubRepo  usRepo.UserSubscriptionRepository
 	subRepo      subRepo.SubscriptionRepository
-	currencyRepo crRepo.CurrencyRateRepository
 	settingsRepo gsRepo.GlobalSettingsRepository
+	ruleRepo     prRepo.PricingRuleRepository
+	converter    Converter
+	currencies   Currencies
 }
 
 // NewService создаёт новый экземпляр сервиса
 func NewService(
+	userRepo userRepo.UserRepository,
 	userSubRepo usRepo.UserSubscriptionRepository,
 	subRepo subRepo.SubscriptionRepository,
-	currencyRepo crRepo.CurrencyRateRepository,
 	settingsRepo gsRepo.GlobalSettingsRepository,
+	ruleRepo prRepo.PricingRuleRepository,
+	converter Converter,
+	currencies Currencies,
 ) Service {
 	return &paymentService{
+		userRepo:     userRepo,
 		userSubRepo:  userSubRepo,
 		subRepo:      subRepo,
-		currencyRepo: currencyRepo,
 		settingsRepo: settingsRepo,
+		ruleRepo:     ruleRepo,
+		converter:    converter,
+		currencies:   currencies,
 	}
 }
 
 // CalculateUserPayment рассчитывает сумму к оплате для пользователя
 func (s *paymentService) CalculateUserPayment(userID, subscriptionID string, dueDate time.Time) (*PaymentAmount, error) {
-	// Получаем настройки пользователя для подписки
-	userSubs, err := s.userSubRepo.FindByUser(userID, 100, 0)
+	// Получаем участников подписки: доля пользователя в цене зависит от всего состава
+	members, err := s.userSubRepo.FindBySubscription(subscriptionID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user subscriptions: %w", err)
+		return nil, fmt.Errorf("failed to get subscription members: %w", err)
 	}
 
-	// Ищем нужную подписку
+	// Ищем настройки пользователя
 	var userSub *db.UserSubscription
-	for _, us := range userSubs {
-		if us.SubscriptionID == subscriptionID {
-			userSub = &us
+	for i := range members {
+		if members[i].UserID == userID {
+			userSub = &members[i]
 			break
 		}
 	}
@@ -73,80 +87,143 @@ func (s *paymentService) CalculateUserPayment(userID, subscriptionID string, due
 		return nil, fmt.Errorf("failed to get subscription: %w", err)
 	}
 
-	// Рассчитываем базовую цену
-	basePrice := subscription.BasePrice
-	baseCurrency := subscription.BaseCurrency
-
-	// Получаем курс валюты (если нужна конвертация)
-	exchangeRate := 1.0
-	if baseCurrency != db.RUB {
-		rate, err := s.currencyRepo.LatestByCurrency(baseCurrency)
-		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, fmt.Errorf("%w: no rate for %s", ErrExchangeRateNotFound, baseCurrency)
-			}
-			return nil, fmt.Errorf("failed to get exchange rate for %s: %w", baseCurrency, err)
+	// Счёт выставляется в валюте расчётов участника
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrUserNotFound, userID)
 		}
-		exchangeRate = rate.Value
+		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
+	currency := SettlementCurrency(user)
 
-	// Конвертируем базовую цену в рубли (это "чистая" сумма)
-	baseAmountRub := basePrice * exchangeRate
+	// Цикл, за который платим, и доли участников за дни, которые они состояли в подписке
+	// в этом цикле: неполный цикл уже учтён в доле и второй раз не пересчитывается
+	periodStart, periodEnd := cycleAt(userSub.AnchorDate, subscription.PeriodDays, dueDate)
+	if err := db.SplitShares(subscription, members, periodStart, periodEnd); err != nil {
+		return nil, err
+	}
 
-	// Применяем пользовательские настройки цены
-	finalPrice := s.applyPricingMode(baseAmountRub, userSub)
+	// Конвертируем долю пользователя в цене подписки по курсу, действовавшему на дату списания
+	// (это "чистая" сумма)
+	share := money.NewFromMinor(userSub.ShareAmount, db.AmountDigits)
+	baseAmount, conv, err := s.converter.Convert(share, subscription.BaseCurrency, currency, dueDate)
+	if err != nil {
+		return nil, err
+	}
 
-	// Применяем глобальную надбавку, если нет пользовательских настроек
-	if userSub.PricingMode == db.None {
-		finalPrice = s.applyGlobalMarkup(finalPrice)
+	// Правила ценообразования: настройки участника, глобальная надбавка и правила,
+	// действующие на дату списания
+	rules, err := s.pricingRules(userSub, dueDate)
+	if err != nil {
+		return nil, err
 	}
 
-	// Вычисляем прибыль
-	profitAmount := finalPrice - baseAmountRub
+	digits, err := s.minorDigits(currency)
+	if err != nil {
+		return nil, err
+	}
+	finalPrice, breakdown, err := applyPricingRules(baseAmount, rules, currency, digits)
+	if err != nil {
+		return nil, err
+	}
 
-	// Конвертируем в копейки для точности
-	amountKopecks := int64(math.Round(finalPrice * 100))
+	// Округляем итог и базу до знаков валюты, прибыль — их разность,
+	// поэтому база + прибыль всегда равны итоговой сумме до копейки
+	amountKopecks := toKopecks(finalPrice, digits)
+	baseKopecks := toKopecks(baseAmount, digits)
+	profitKopecks := amountKopecks - baseKopecks
 
-	// Округляем рубли до 2 знаков после запятой
-	amountRubles := math.Round(finalPrice*100) / 100
-	baseAmountRubles := math.Round(baseAmountRub*100) / 100
-	profitAmountRubles := math.Round(profitAmount*100) / 100
+	// Фактическая надбавка в процентах (для fixed считается от базовой суммы)
+	markupPercent := money.Zero
+	if baseKopecks > 0 {
+		percent, err := money.NewFromInt(profitKopecks).MulDiv(money.NewFromInt(100), money.NewFromInt(baseKopecks))
+		if err != nil {
+			return nil, err
+		}
+		markupPercent = percent.Round(2, pricingRounding)
+	}
 
 	return &PaymentAmount{
 		UserID:         userID,
 		SubscriptionID: subscriptionID,
 		Amount:         amountKopecks,
-		AmountRubles:   amountRubles,
-		BaseAmount:     baseAmountRubles,
-		ProfitAmount:   profitAmountRubles,
-		Currency:       db.RUB,
-		ExchangeRate:   exchangeRate,
+		BaseKopecks:    baseKopecks,
+		ProfitKopecks:  profitKopecks,
+		AmountRubles:   money.NewFromMinor(amountKopecks, db.AmountDigits),
+		BaseAmount:     money.NewFromMinor(baseKopecks, db.AmountDigits),
+		ProfitAmount:   money.NewFromMinor(profitKopecks, db.AmountDigits),
+		Currency:       currency,
+		SourceCurrency: subscription.BaseCurrency,
+		ExchangeRate:   conv.Rate,
+		RatePath:       conv.Path,
+		MarkupPercent:  markupPercent,
+		SplitMode:      splitMode(subscription),
+		SharePercent:   userSub.SharePercent,
 		DueDate:        dueDate,
+		Breakdown:      breakdown,
+		Proration:      prorate(userSub, periodStart, periodEnd),
 	}, nil
 }
 
-// applyPricingMode применяет пользовательские настройки цены
-func (s *paymentService) applyPricingMode(basePrice float64, userSub *db.UserSubscription) float64 {
-	switch userSub.PricingMode {
-	case db.Percent:
-		return basePrice * (1 + userSub.MarkupPercent/100)
-	case db.Fixed:
-		return userSub.FixedFee
-	default: // db.None
-		return basePrice
+// prorate возвращает неполный цикл участника или nil, если он состоял в подписке весь цикл
+func prorate(userSub *db.UserSubscription, periodStart, periodEnd time.Time) *Proration {
+	from, to := userSub.BilledPeriod(periodStart, periodEnd)
+	if from.Equal(periodStart) && to.Equal(periodEnd) {
+		return nil
+	}
+	return &Proration{
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		PeriodDays:  db.DaysBetween(periodStart, periodEnd),
+		BilledFrom:  from,
+		BilledTo:    to,
+		BilledDays:  db.DaysBetween(from, to),
 	}
 }
 
-// applyGlobalMarkup применяет глобальную надбавку
-func (s *paymentService) applyGlobalMarkup(price float64) float64 {
-	settings, err := s.settingsRepo.Get()
-	if err != nil || settings == nil {
-		return price // Нет глобальных настроек
+// SettlementCurrency валюта расчётов участника; по умолчанию рубли
+func SettlementCurrency(user *db.User) db.Currency {
+	if user.SettlementCurrency == "" {
+		return db.RUB
+	}
+	return user.SettlementCurrency
+}
+
+// splitMode режим разделения цены подписки; пустой — none
+func splitMode(sub *db.Subscription) db.SplitMode {
+	if sub.SplitMode == "" {
+		return db.SplitNone
+	}
+	return sub.SplitMode
+}
+
+// minorDigits сколько знаков после запятой у валюты; суммы хранятся в сотых долях,
+// поэтому больше двух знаков не учитываем
+func (s *paymentService) minorDigits(currency db.Currency) (int, error) {
+	info, err := s.currencies.Get(currency)
+	if err != nil {
+		return 0, err
 	}
+	return min(info.MinorDigits, db.AmountDigits), nil
+}
+
+// toKopecks округляет сумму до digits знаков и переводит в сотые доли валюты
+func toKopecks(amount money.Decimal, digits int) int64 {
+	return amount.Round(digits, pricingRounding).Minor(db.AmountDigits, pricingRounding)
+}
 
-	if settings.GlobalMarkupPercent > 0 {
-		return price * (1 + settings.GlobalMarkupPercent/100)
+// pricingRules собирает правила для участника в подписке: сначала прежние настройки цены
+// (см. legacyRules), затем правила из таблицы в порядке применения
+func (s *paymentService) pricingRules(userSub *db.UserSubscription, at time.Time) ([]db.PricingRule, error) {
+	settings, err := s.settingsRepo.Get()
+	if err != nil {
+		settings = nil // Нет глобальных настроек
 	}
 
-	return price
+	stored, err := s.ruleRepo.Applicable(userSub.UserID, userSub.SubscriptionID, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pricing rules: %w", err)
+	}
+	return append(legacyRules(userSub, settings), stored...), nil
 }
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/WhoYa/subscription-manager/pkg/money"
+)
+
+var ErrPaymentNotCalculated = errors.New("failed to calculate payment")
+
+// paymentRecorder реализация Payments
+type paymentRecorder struct {
+	paymentService Service
+	invoicing      Invoicing
+	converter      Converter
+	tx             Transactor
+}
+
+// NewPayments создаёт сервис регистрации платежей
+func NewPayments(
+	paymentService Service,
+	invoicing Invoicing,
+	converter Converter,
+	tx Transactor,
+) Payments {
+	return &paymentRecorder{
+		paymentService: paymentService,
+		invoicing:      invoicing,
+		converter:      converter,
+		tx:             tx,
+	}
+}
+
+// Record регистрирует платёж: гасит счёт, сохраняет PaymentLog и проводит его по лицевому счёту
+func (s *paymentRecorder) Record(req PaymentRequest) (*db.PaymentLog, error) {
+	pl, invoiceID, err := s.prepare(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tx.Transaction(func(st Store) error { return savePayment(st, pl, invoiceID) }); err != nil {
+		return nil, err
+	}
+	return pl, nil
+}
+
+// RecordIn регистрирует платёж в транзакции вызывающего
+func (s *paymentRecorder) RecordIn(st Store, req PaymentRequest) (*db.PaymentLog, error) {
+	pl, invoiceID, err := s.prepare(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := savePayment(st, pl, invoiceID); err != nil {
+		return nil, err
+	}
+	return pl, nil
+}
+
+// prepare определяет счёт, который гасит платёж, и собирает PaymentLog в валюте расчётов;
+// пустой invoiceID — платёж без счёта
+func (s *paymentRecorder) prepare(req PaymentRequest) (*db.PaymentLog, string, error) {
+	// Определяем счёт, который гасит платёж
+	var inv *db.Invoice
+	var err error
+	if req.InvoiceID != "" {
+		inv, err = s.invoicing.Get(req.InvoiceID)
+		if err != nil {
+			return nil, "", err
+		}
+		if inv.UserID != req.UserID {
+			return nil, "", ErrInvoiceNotFound
+		}
+		req.SubscriptionID = inv.SubscriptionID
+	} else {
+		inv, err = s.invoicing.OutstandingFor(req.UserID, req.SubscriptionID)
+		if err != nil && !errors.Is(err, ErrInvoiceNotFound) {
+			return nil, "", err
+		}
+	}
+
+	// Платёж учитывается в валюте расчётов участника: валюте счёта или расчёта
+	var paymentCalc *PaymentAmount
+	var settlement db.Currency
+	if inv != nil {
+		settlement = inv.Currency
+	} else {
+		// Счёта нет - рассчитываем платеж чтобы получить базовую сумму и прибыль
+		paymentCalc, err = s.paymentService.CalculateUserPayment(req.UserID, req.SubscriptionID, req.PaidAt)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %w", ErrPaymentNotCalculated, err)
+		}
+		settlement = paymentCalc.Currency
+	}
+
+	// Сумма в другой валюте пересчитывается по курсу на дату оплаты
+	amount := req.Amount
+	if amount > 0 && req.Currency != "" && req.Currency != settlement {
+		converted, _, err := s.converter.Convert(money.NewFromInt(amount), req.Currency, settlement, req.PaidAt)
+		if err != nil {
+			return nil, "", err
+		}
+		amount = converted.Minor(0, money.HalfUp)
+	}
+
+	var pl db.PaymentLog
+	if inv != nil {
+		if pl, err = invoicePayment(inv, amount); err != nil {
+			return nil, "", err
+		}
+	} else {
+		// Используем рассчитанные значения или переданные пользователем
+		pl.Amount = paymentCalc.Amount // копейки
+		pl.BaseAmount = paymentCalc.BaseKopecks
+		pl.ProfitAmount = paymentCalc.ProfitKopecks
+		if amount > 0 {
+			// своя сумма пользователя: база и прибыль делятся в той же пропорции
+			pl.Amount = amount
+			pl.BaseAmount, pl.ProfitAmount, err = splitPayment(amount, paymentCalc.BaseKopecks, paymentCalc.Amount)
+			if err != nil {
+				return nil, "", err
+			}
+		}
+
+		pl.RateUsed = paymentCalc.ExchangeRate
+	}
+
+	if req.RateUsed.Sign() > 0 {
+		pl.RateUsed = req.RateUsed // если пользователь передал свой курс
+	}
+	pl.UserID = req.UserID
+	pl.SubscriptionID = req.SubscriptionID
+	pl.Currency = settlement
+	pl.PaidAt = req.PaidAt
+
+	if inv != nil {
+		return &pl, inv.ID, nil
+	}
+	return &pl, "", nil
+}
+
+// savePayment гасит счёт invoiceID, сохраняет платёж и проводит его по лицевому счёту.
+// Вызывается в транзакции: иначе сбой посередине оставил бы счёт оплаченным без платежа
+// или платёж без проводки.
+func savePayment(st Store, pl *db.PaymentLog, invoiceID string) error {
+	if invoiceID != "" {
+		if _, err := applyInvoicePayment(st, invoiceID, pl.Amount, pl.PaidAt); err != nil {
+			return err
+		}
+	}
+	if err := st.Payments.Create(pl); err != nil {
+		return fmt.Errorf("failed to save payment: %w", err)
+	}
+	// переплата остаётся на балансе и гасит следующие счета
+	return recordPayment(st, pl)
+}
+
+// invoicePayment собирает платёж по зафиксированным в счёте суммам.
+// Без явной суммы гасится остаток счёта. База платежа — прирост базы, приходящейся
+// на оплаченную часть счёта, поэтому частичные платежи в сумме дают ровно базу и прибыль счёта.
+func invoicePayment(inv *db.Invoice, amount int64) (db.PaymentLog, error) {
+	if amount <= 0 {
+		amount = inv.Amount - inv.PaidAmount
+	}
+
+	paidBase, _, err := splitPayment(inv.PaidAmount, inv.BaseAmount, inv.Amount)
+	if err != nil {
+		return db.PaymentLog{}, err
+	}
+	base, _, err := splitPayment(inv.PaidAmount+amount, inv.BaseAmount, inv.Amount)
+	if err != nil {
+		return db.PaymentLog{}, err
+	}
+	base -= paidBase
+	profit := amount - base
+
+	invoiceID := inv.ID
+	return db.PaymentLog{
+		Amount:       amount,
+		BaseAmount:   base,
+		ProfitAmount: profit,
+		RateUsed:     inv.RateUsed,
+		InvoiceID:    &invoiceID,
+	}, nil
+}
+
+// splitPayment делит платёж на базу и прибыль в пропорции base/total (в копейках).
+// База округляется коммерчески, прибыль — остаток, так что база + прибыль == amount.
+func splitPayment(amount, base, total int64) (int64, int64, error) {
+	if total <= 0 {
+		return base, amount - base, nil
+	}
+	share, err := money.NewFromInt(amount).MulDiv(money.NewFromInt(base), money.NewFromInt(total))
+	if err != nil {
+		return 0, 0, err
+	}
+	b := share.Minor(0, money.HalfUp)
+	return b, amount - b, nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"testing/quick"
+
+	"github.com/WhoYa/subscription-manager/pkg/money"
+)
+
+// maxKopecks верхняя граница сумм в тестах: 100 млн рублей, с запасом до предела Decimal
+const maxKopecks = 10_000_000_000
+
+func TestSplitPaymentSumsToAmount(t *testing.T) {
+	// база + прибыль == сумма платежа для любой пропорции base/total
+	prop := func(amount, base, total uint64) bool {
+		a := int64(amount % maxKopecks)
+		tot := int64(total%maxKopecks) + 1
+		b := int64(base % uint64(tot+1))
+
+		gotBase, gotProfit, err := splitPayment(a, b, tot)
+		if err != nil {
+			t.Logf("splitPayment(%d, %d, %d): %v", a, b, tot, err)
+			return false
+		}
+		if gotBase+gotProfit != a {
+			t.Logf("splitPayment(%d, %d, %d) = %d + %d", a, b, tot, gotBase, gotProfit)
+			return false
+		}
+		// доля базы не больше платежа: база счёта не больше его суммы
+		return gotBase >= 0 && gotBase <= a
+	}
+	if err := quick.Check(prop, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSplitPayment(t *testing.T) {
+	tests := []struct {
+		name                 string
+		amount, base, total  int64
+		wantBase, wantProfit int64
+	}{
+		{"full invoice", 11000, 10000, 11000, 10000, 1000},
+		{"half invoice", 5500, 10000, 11000, 5000, 500},
+		{"rounds base half up", 1, 1, 2, 1, 0},
+		{"no profit", 700, 700, 700, 700, 0},
+		{"no total keeps base", 500, 300, 0, 300, 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, profit, err := splitPayment(tt.amount, tt.base, tt.total)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if base != tt.wantBase || profit != tt.wantProfit {
+				t.Errorf("splitPayment(%d, %d, %d) = %d, %d; want %d, %d",
+					tt.amount, tt.base, tt.total, base, profit, tt.wantBase, tt.wantProfit)
+			}
+		})
+	}
+}
+
+func TestSplitPaymentOverflow(t *testing.T) {
+	_, _, err := splitPayment(90_000_000_000, 90_000_000_000, 1)
+	if !errors.Is(err, money.ErrOverflow) {
+		t.Fatalf("err = %v, want ErrOverflow", err)
+	}
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	prRepo "github.com/WhoYa/subscription-manager/internal/repository/pricingrule"
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
+	subRepo "github.com/WhoYa/subscription-manager/internal/repository/subscription"
+	userRepo "github.com/WhoYa/subscription-manager/internal/repository/user"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/WhoYa/subscription-manager/pkg/money"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidPricingRule  = errors.New("invalid pricing rule")
+	ErrPricingRuleNotFound = errors.New("pricing rule not found")
+)
+
+var (
+	// maxMarkupPercent верхняя граница надбавки: защита от опечатки в лишний ноль
+	maxMarkupPercent = money.NewFromInt(1000)
+	hundred          = money.NewFromInt(100)
+)
+
+// pricingRuleService реализация PricingRules
+type pricingRuleService struct {
+	ruleRepo   prRepo.PricingRuleRepository
+	userRepo   userRepo.UserRepository
+	subRepo    subRepo.SubscriptionRepository
+	currencies Currencies
+}
+
+// NewPricingRules создаёт сервис правил ценообразования
+func NewPricingRules(
+	ruleRepo prRepo.PricingRuleRepository,
+	userRepo userRepo.UserRepository,
+	subRepo subRepo.SubscriptionRepository,
+	currencies Currencies,
+) PricingRules {
+	return &pricingRuleService{
+		ruleRepo:   ruleRepo,
+		userRepo:   userRepo,
+		subRepo:    subRepo,
+		currencies: currencies,
+	}
+}
+
+// Get возвращает правило по ID
+func (s *pricingRuleService) Get(id string) (*db.PricingRule, error) {
+	rule, err := s.ruleRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrPricingRuleNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get pricing rule: %w", err)
+	}
+	return rule, nil
+}
+
+// List возвращает страницу правил
+func (s *pricingRuleService) List(spec query.Spec) (query.Page[db.PricingRule], error) {
+	page, err := s.ruleRepo.List(spec)
+	if err != nil {
+		return page, fmt.Errorf("failed to list pricing rules: %w", err)
+	}
+	return page, nil
+}
+
+// Create проверяет и сохраняет новое правило
+func (s *pricingRuleService) Create(rule *db.PricingRule) error {
+	if err := s.validate(rule); err != nil {
+		return err
+	}
+	if err := s.ruleRepo.Create(rule); err != nil {
+		return fmt.Errorf("failed to create pricing rule: %w", err)
+	}
+	log.Printf("PRICING: Added rule %s %q (%s %s, scope %s, priority %d)",
+		rule.ID, rule.Name, rule.Kind, rule.Value, rule.Scope, rule.Priority)
+	return nil
+}
+
+// Update проверяет и сохраняет изменения правила
+func (s *pricingRuleService) Update(rule *db.PricingRule) error {
+	if err := s.validate(rule); err != nil {
+		return err
+	}
+	if err := s.ruleRepo.Update(rule); err != nil {
+		return fmt.Errorf("failed to update pricing rule: %w", err)
+	}
+	log.Printf("PRICING: Updated rule %s %q", rule.ID, rule.Name)
+	return nil
+}
+
+// Delete удаляет правило
+func (s *pricingRuleService) Delete(id string) error {
+	if _, err := s.Get(id); err != nil {
+		return err
+	}
+	if err := s.ruleRepo.Delete(id); err != nil {
+		return fmt.Errorf("failed to delete pricing rule: %w", err)
+	}
+	log.Printf("PRICING: Deleted rule %s", id)
+	return nil
+}
+
+// validate проверяет правило и приводит код валюты к справочному
+func (s *pricingRuleService) validate(rule *db.PricingRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		rule.Name = string(rule.Kind)
+	}
+
+	switch rule.Kind {
+	case db.RuleMarkupPercent:
+		if rule.Value.Sign() <= 0 || rule.Value.Cmp(maxMarkupPercent) > 0 {
+			return fmt.Errorf("%w: markup must be between 0 and %s percent", ErrInvalidPricingRule, maxMarkupPercent)
+		}
+	case db.RuleDiscountPercent:
+		if rule.Value.Sign() <= 0 || rule.Value.Cmp(hundred) > 0 {
+			return fmt.Errorf("%w: discount must be between 0 and 100 percent", ErrInvalidPricingRule)
+		}
+	case db.RuleFixedPrice, db.RuleMinAmount, db.RuleRoundUp:
+		if rule.Value.Sign() <= 0 {
+			return fmt.Errorf("%w: %s value must be positive", ErrInvalidPricingRule, rule.Kind)
+		}
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidPricingRule, rule.Kind)
+	}
+
+	// валюта имеет смысл только для правил с суммой
+	if rule.Currency != nil {
+		if isPercentRule(rule.Kind) {
+			return fmt.Errorf("%w: currency is not allowed for %s", ErrInvalidPricingRule, rule.Kind)
+		}
+		curr, err := s.currencies.Validate(string(*rule.Currency))
+		if err != nil {
+			return err
+		}
+		rule.Currency = &curr
+	}
+
+	if rule.ValidFrom != nil && rule.ValidTo != nil && !rule.ValidTo.After(*rule.ValidFrom) {
+		return fmt.Errorf("%w: valid_to must be after valid_from", ErrInvalidPricingRule)
+	}
+
+	return s.validateScope(rule)
+}
+
+// validateScope проверяет, что заданы ровно те ID, которые нужны области действия, и что они существуют
+func (s *pricingRuleService) validateScope(rule *db.PricingRule) error {
+	var needUser, needSub bool
+	switch rule.Scope {
+	case db.ScopeGlobal:
+	case db.ScopeSubscription:
+		needSub = true
+	case db.ScopeUser:
+		needUser = true
+	case db.ScopeUserSubscription:
+		needUser, needSub = true, true
+	default:
+		return fmt.Errorf("%w: unknown scope %q", ErrInvalidPricingRule, rule.Scope)
+	}
+
+	if needUser != (rule.UserID != nil) {
+		return fmt.Errorf("%w: user_id is %s for scope %s", ErrInvalidPricingRule, requiredWord(needUser), rule.Scope)
+	}
+	if needSub != (rule.SubscriptionID != nil) {
+		return fmt.Errorf("%w: subscription_id is %s for scope %s", ErrInvalidPricingRule, requiredWord(needSub), rule.Scope)
+	}
+
+	if rule.UserID != nil {
+		if _, err := s.userRepo.FindByID(*rule.UserID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: %s", ErrUserNotFound, *rule.UserID)
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+	}
+	if rule.SubscriptionID != nil {
+		if _, err := s.subRepo.FindByID(*rule.SubscriptionID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: subscription %s", ErrSubscriptionNotFound, *rule.SubscriptionID)
+			}
+			return fmt.Errorf("failed to get subscription: %w", err)
+		}
+	}
+	return nil
+}
+
+func requiredWord(required bool) string {
+	if required {
+		return "required"
+	}
+	return "not allowed"
+}
+
+func isPercentRule(kind db.PricingRuleKind) bool {
+	return kind == db.RuleMarkupPercent || kind == db.RuleDiscountPercent
+}
+
+// legacyRules настройки цены участника и глобальная надбавка в виде правил с приоритетом 0.
+// Как и раньше, глобальная надбавка действует только без пользовательских настроек.
+func legacyRules(userSub *db.UserSubscription, settings *db.GlobalSettings) []db.PricingRule {
+	own := db.PricingRule{
+		Scope:          db.ScopeUserSubscription,
+		UserID:         &userSub.UserID,
+		SubscriptionID: &userSub.SubscriptionID,
+	}
+
+	switch userSub.PricingMode {
+	case db.Percent:
+		own.Name = "Надбавка участника"
+		own.Kind = db.RuleMarkupPercent
+		own.Value = userSub.MarkupPercent
+		return []db.PricingRule{own}
+	case db.Fixed:
+		own.Name = "Фиксированная цена участника"
+		own.Kind = db.RuleFixedPrice
+		own.Value = userSub.FixedFee
+		return []db.PricingRule{own}
+	}
+
+	if settings != nil && settings.GlobalMarkupPercent.Sign() > 0 {
+		return []db.PricingRule{{
+			Name:  "Глобальная надбавка",
+			Kind:  db.RuleMarkupPercent,
+			Scope: db.ScopeGlobal,
+			Value: settings.GlobalMarkupPercent,
+		}}
+	}
+	return nil
+}
+
+// applyPricingRules применяет правила к цене по возрастанию приоритета; при равном приоритете
+// сохраняется порядок в rules. Правила с суммой в другой валюте пропускаются.
+// digits — знаков валюты для строк расшифровки.
+func applyPricingRules(price money.Decimal, rules []db.PricingRule, currency db.Currency, digits int) (money.Decimal, []PriceStep, error) {
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].Priority < rules[j].Priority })
+
+	steps := make([]PriceStep, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Currency != nil && *rule.Currency != currency {
+			continue
+		}
+
+		next, err := applyPricingRule(price, rule)
+		if err != nil {
+			return money.Zero, nil, err
+		}
+		before := price.Round(digits, pricingRounding)
+		after := next.Round(digits, pricingRounding)
+		steps = append(steps, PriceStep{
+			RuleID:   rule.ID,
+			Name:     rule.Name,
+			Kind:     rule.Kind,
+			Scope:    rule.Scope,
+			Priority: rule.Priority,
+			Value:    rule.Value,
+			Before:   before,
+			After:    after,
+			Delta:    after.Sub(before),
+		})
+		price = next
+	}
+	return price, steps, nil
+}
+
+// applyPricingRule одно правило
+func applyPricingRule(price money.Decimal, rule db.PricingRule) (money.Decimal, error) {
+	switch rule.Kind {
+	case db.RuleMarkupPercent:
+		markup, err := price.Percent(rule.Value)
+		if err != nil {
+			return money.Zero, err
+		}
+		return price.Add(markup), nil
+	case db.RuleDiscountPercent:
+		discount, err := price.Percent(rule.Value)
+		if err != nil {
+			return money.Zero, err
+		}
+		return price.Sub(discount), nil
+	case db.RuleFixedPrice:
+		return rule.Value, nil
+	case db.RuleMinAmount:
+		if price.Cmp(rule.Value) < 0 {
+			return rule.Value, nil
+		}
+		return price, nil
+	case db.RuleRoundUp:
+		if rule.Value.Sign() <= 0 {
+			return price, nil
+		}
+		// сначала до копеек, чтобы доли копейки от процентов не поднимали цену на целый шаг
+		kopecks := price.Round(db.AmountDigits, pricingRounding)
+		steps, err := kopecks.Div(rule.Value)
+		if err != nil {
+			return money.Zero, err
+		}
+		return steps.Round(0, money.Up).Mul(rule.Value)
+	default:
+		return price, nil
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/WhoYa/subscription-manager/pkg/money"
+)
+
+// pricingCase случайная цена и набор правил для проверки свойств расчёта
+type pricingCase struct {
+	Price money.Decimal
+	Rules []db.PricingRule
+}
+
+func (pricingCase) Generate(r *rand.Rand, _ int) reflect.Value {
+	kinds := []db.PricingRuleKind{
+		db.RuleMarkupPercent, db.RuleDiscountPercent, db.RuleFixedPrice, db.RuleMinAmount, db.RuleRoundUp,
+	}
+	c := pricingCase{Price: money.NewFromMinor(r.Int63n(100_000_000), db.AmountDigits)}
+	for i := range r.Intn(5) {
+		rule := db.PricingRule{Kind: kinds[r.Intn(len(kinds))], Priority: r.Intn(3)}
+		switch rule.Kind {
+		case db.RuleMarkupPercent, db.RuleDiscountPercent:
+			rule.Value = money.NewFromMinor(r.Int63n(10_000), 2) // 0–100 %
+		case db.RuleRoundUp:
+			rule.Value = money.NewFromInt([]int64{1, 10, 50, 100}[r.Intn(4)])
+		default:
+			rule.Value = money.NewFromMinor(r.Int63n(10_000_000), db.AmountDigits)
+		}
+		rule.Name = string(rule.Kind) + "-" + string(rune('a'+i))
+		c.Rules = append(c.Rules, rule)
+	}
+	return reflect.ValueOf(c)
+}
+
+func TestPricingBaseAndProfitSumToAmount(t *testing.T) {
+	// строки расшифровки складываются из базы в итог, а счёт, оплаченный частями,
+	// в сумме платежей даёт ровно свои базу и прибыль
+	prop := func(c pricingCase, parts []uint32) bool {
+		final, steps, err := applyPricingRules(c.Price, c.Rules, db.RUB, db.AmountDigits)
+		if err != nil {
+			t.Logf("applyPricingRules(%s, %v): %v", c.Price, c.Rules, err)
+			return false
+		}
+
+		amount := toKopecks(final, db.AmountDigits)
+		base := toKopecks(c.Price, db.AmountDigits)
+
+		total := c.Price.Round(db.AmountDigits, pricingRounding)
+		for _, s := range steps {
+			total = total.Add(s.Delta)
+		}
+		if total.Minor(db.AmountDigits, pricingRounding) != amount {
+			t.Logf("steps sum to %s, amount %d", total, amount)
+			return false
+		}
+		if amount == 0 {
+			return true
+		}
+
+		inv := &db.Invoice{Amount: amount, BaseAmount: base}
+		var paidBase, paidProfit int64
+		for i := 0; inv.PaidAmount < inv.Amount; i++ {
+			pay := int64(0) // последний платёж — остаток счёта
+			if i < len(parts) {
+				pay = min(int64(parts[i])%amount+1, inv.Amount-inv.PaidAmount)
+			}
+			pl, err := invoicePayment(inv, pay)
+			if err != nil {
+				t.Logf("invoicePayment(%+v, %d): %v", inv, pay, err)
+				return false
+			}
+			if pl.BaseAmount+pl.ProfitAmount != pl.Amount {
+				t.Logf("payment %d = base %d + profit %d", pl.Amount, pl.BaseAmount, pl.ProfitAmount)
+				return false
+			}
+			paidBase += pl.BaseAmount
+			paidProfit += pl.ProfitAmount
+			inv.PaidAmount += pl.Amount
+		}
+		if paidBase != base || paidProfit != amount-base {
+			t.Logf("invoice %d (base %d) paid in parts %v: base %d, profit %d", amount, base, parts, paidBase, paidProfit)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(prop, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApplyPricingRule(t *testing.T) {
+	d := money.MustParse
+	tests := []struct {
+		name  string
+		price string
+		kind  db.PricingRuleKind
+		value string
+		want  string
+	}{
+		{"markup", "100", db.RuleMarkupPercent, "10", "110"},
+		{"discount", "100", db.RuleDiscountPercent, "25", "75"},
+		{"fixed price", "100", db.RuleFixedPrice, "42.5", "42.5"},
+		{"min amount raises", "30", db.RuleMinAmount, "50", "50"},
+		{"min amount keeps", "70", db.RuleMinAmount, "50", "70"},
+		{"round up", "101.01", db.RuleRoundUp, "10", "110"},
+		{"round up exact", "110.004", db.RuleRoundUp, "10", "110"},
+		{"round up zero step", "101", db.RuleRoundUp, "0", "101"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyPricingRule(d(tt.price), db.PricingRule{Kind: tt.kind, Value: d(tt.value)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Cmp(d(tt.want)) != 0 {
+				t.Errorf("applyPricingRule(%s, %s %s) = %s, want %s", tt.price, tt.kind, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyPricingRuleOverflow(t *testing.T) {
+	rule := db.PricingRule{Kind: db.RuleMarkupPercent, Value: money.MustParse("90000000000")}
+	_, err := applyPricingRule(money.MustParse("90000000000"), rule)
+	if !errors.Is(err, money.ErrOverflow) {
+		t.Fatalf("err = %v, want ErrOverflow", err)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	payRepo "github.com/WhoYa/subscription-manager/internal/repository/paymentlog"
 	subRepo "github.com/WhoYa/subscription-manager/internal/repository/subscription"
 	userRepo "github.com/WhoYa/subscription-manager/internal/repository/user"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/WhoYa/subscription-manager/pkg/money"
 )
 
 // profitAnalytics реализация ProfitAnalytics
@@ -14,6 +16,7 @@ type profitAnalytics struct {
 	paymentRepo payRepo.PaymentLogRepository
 	userRepo    userRepo.UserRepository
 	subRepo     subRepo.SubscriptionRepository
+	converter   Converter
 }
 
 // NewProfitAnalytics создает новый экземпляр сервиса аналитики прибыли
@@ -21,21 +24,23 @@ func NewProfitAnalytics(
 	paymentRepo payRepo.PaymentLogRepository,
 	userRepo userRepo.UserRepository,
 	subRepo subRepo.SubscriptionRepository,
+	converter Converter,
 ) ProfitAnalytics {
 	return &profitAnalytics{
 		paymentRepo: paymentRepo,
 		userRepo:    userRepo,
 		subRepo:     subRepo,
+		converter:   converter,
 	}
 }
 
 // GetMonthlyProfit возвращает общую прибыль за месяц
-func (p *profitAnalytics) GetMonthlyProfit(year int, month int) (*ProfitStats, error) {
+func (p *profitAnalytics) GetMonthlyProfit(year int, month int, currency db.Currency) (*ProfitStats, error) {
 	// Определяем границы месяца
 	from := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
 	to := from.AddDate(0, 1, 0).Add(-time.Second) // последняя секунда месяца
 
-	return p.calculateProfitForPeriod(from, to, fmt.Sprintf("%04d-%02d", year, month))
+	return p.calculateProfitForPeriod(from, to, fmt.Sprintf("%04d-%02d", year, month), currency)
 }
 
 // GetUserProfitStats возвращает статистику прибыли по пользователям за период
@@ -62,14 +67,21 @@ func (p *profitAnalytics) GetUserProfitStats(from, to time.Time) ([]UserProfitSt
 			userStats[userID] = &UserProfitStats{
 				UserID:       userID,
 				Username:     user.Username,
-				TotalProfit:  0,
+				TotalProfit:  money.Zero,
 				PaymentCount: 0,
+				Currency:     SettlementCurrency(user),
 			}
 		}
 
-		// Прибыль в рублях = копейки / 100
-		profitRubles := float64(payment.ProfitAmount) / 100
-		userStats[userID].TotalProfit += profitRubles
+		profit, err := p.profitIn(&payment, userStats[userID].Currency)
+		if err != nil {
+			return nil, err
+		}
+		total, err := money.New(userStats[userID].TotalProfit, userStats[userID].Currency).Add(profit)
+		if err != nil {
+			return nil, err
+		}
+		userStats[userID].TotalProfit = total.Amount
 		userStats[userID].PaymentCount++
 	}
 
@@ -83,7 +95,7 @@ func (p *profitAnalytics) GetUserProfitStats(from, to time.Time) ([]UserProfitSt
 }
 
 // GetSubscriptionProfitStats возвращает статистику прибыли по подпискам за период
-func (p *profitAnalytics) GetSubscriptionProfitStats(from, to time.Time) ([]SubscriptionProfitStats, error) {
+func (p *profitAnalytics) GetSubscriptionProfitStats(from, to time.Time, currency db.Currency) ([]SubscriptionProfitStats, error) {
 	// Получаем все платежи за период
 	payments, err := p.paymentRepo.FindAll(from, to)
 	if err != nil {
@@ -106,14 +118,21 @@ func (p *profitAnalytics) GetSubscriptionProfitStats(from, to time.Time) ([]Subs
 			subStats[subID] = &SubscriptionProfitStats{
 				SubscriptionID: subID,
 				ServiceName:    sub.ServiceName,
-				TotalProfit:    0,
+				TotalProfit:    money.Zero,
 				PaymentCount:   0,
+				Currency:       currency,
 			}
 		}
 
-		// Прибыль в рублях = копейки / 100
-		profitRubles := float64(payment.ProfitAmount) / 100
-		subStats[subID].TotalProfit += profitRubles
+		profit, err := p.profitIn(&payment, currency)
+		if err != nil {
+			return nil, err
+		}
+		total, err := money.New(subStats[subID].TotalProfit, currency).Add(profit)
+		if err != nil {
+			return nil, err
+		}
+		subStats[subID].TotalProfit = total.Amount
 		subStats[subID].PaymentCount++
 	}
 
@@ -127,40 +146,64 @@ func (p *profitAnalytics) GetSubscriptionProfitStats(from, to time.Time) ([]Subs
 }
 
 // GetTotalProfit возвращает общую прибыль за все время
-func (p *profitAnalytics) GetTotalProfit() (*ProfitStats, error) {
+func (p *profitAnalytics) GetTotalProfit(currency db.Currency) (*ProfitStats, error) {
 	// Используем очень широкий диапазон дат для "всего времени"
 	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
 	to := time.Now().AddDate(1, 0, 0) // год в будущее
 
-	return p.calculateProfitForPeriod(from, to, "all-time")
+	return p.calculateProfitForPeriod(from, to, "all-time", currency)
 }
 
 // calculateProfitForPeriod вспомогательная функция для расчета прибыли за период
-func (p *profitAnalytics) calculateProfitForPeriod(from, to time.Time, period string) (*ProfitStats, error) {
+func (p *profitAnalytics) calculateProfitForPeriod(from, to time.Time, period string, currency db.Currency) (*ProfitStats, error) {
 	payments, err := p.paymentRepo.FindAll(from, to)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get payments for period: %w", err)
 	}
 
-	var totalProfitKopecks int64
+	total := money.New(money.Zero, currency)
 	var paymentCount int64
 
-	for _, payment := range payments {
-		totalProfitKopecks += payment.ProfitAmount
+	for i := range payments {
+		profit, err := p.profitIn(&payments[i], currency)
+		if err != nil {
+			return nil, err
+		}
+		if total, err = total.Add(profit); err != nil {
+			return nil, err
+		}
 		paymentCount++
 	}
+	totalProfit := total.Amount
 
-	totalProfitRubles := float64(totalProfitKopecks) / 100
-
-	var averageProfit float64
+	averageProfit := money.Zero
 	if paymentCount > 0 {
-		averageProfit = totalProfitRubles / float64(paymentCount)
+		// среднее по модулю не больше суммы — деление не переполняется
+		average, _ := totalProfit.Div(money.NewFromInt(paymentCount))
+		averageProfit = average.Round(db.AmountDigits, money.HalfUp)
 	}
 
 	return &ProfitStats{
-		TotalProfit:   totalProfitRubles,
+		TotalProfit:   totalProfit,
 		TotalPayments: paymentCount,
 		AverageProfit: averageProfit,
+		Currency:      currency,
 		Period:        period,
 	}, nil
 }
+
+// profitIn возвращает прибыль платежа в валюте currency по курсу на дату оплаты,
+// округлённую до копеек: сумма отчёта складывается из уже округлённых слагаемых
+func (p *profitAnalytics) profitIn(payment *db.PaymentLog, currency db.Currency) (money.Money, error) {
+	profit := money.NewFromMinor(payment.ProfitAmount, db.AmountDigits)
+
+	from := payment.Currency
+	if from == "" {
+		from = db.RUB
+	}
+	converted, _, err := p.converter.Convert(profit, from, currency, payment.PaidAt)
+	if err != nil {
+		return money.Money{}, fmt.Errorf("payment %s: %w", payment.ID, err)
+	}
+	return money.New(converted, currency).Round(db.AmountDigits, money.HalfUp), nil
+}
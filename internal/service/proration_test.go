@@ -0,0 +1,171 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	gsRepo "github.com/WhoYa/subscription-manager/internal/repository/globalsettings"
+	prRepo "github.com/WhoYa/subscription-manager/internal/repository/pricingrule"
+	subRepo "github.com/WhoYa/subscription-manager/internal/repository/subscription"
+	userRepo "github.com/WhoYa/subscription-manager/internal/repository/user"
+	usRepo "github.com/WhoYa/subscription-manager/internal/repository/usersubscription"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/WhoYa/subscription-manager/pkg/money"
+)
+
+func TestProrate(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 7, d, 0, 0, 0, 0, time.UTC) }
+	start, end := day(1), day(31) // цикл 30 дней
+	at := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name       string
+		joined     time.Time
+		left       *time.Time
+		wantNil    bool
+		wantFrom   time.Time
+		wantTo     time.Time
+		wantBilled int
+	}{
+		{name: "whole cycle", joined: day(1), wantNil: true},
+		{name: "joined before the cycle", joined: day(1).AddDate(0, -2, 0), wantNil: true},
+		{name: "joined mid cycle", joined: day(11), wantFrom: day(11), wantTo: end, wantBilled: 20},
+		{name: "joined late in the day counts the whole day", joined: day(11).Add(23 * time.Hour), wantFrom: day(11), wantTo: end, wantBilled: 20},
+		{name: "left mid cycle", joined: day(1), left: at(day(16)), wantFrom: start, wantTo: day(16), wantBilled: 15},
+		{name: "joined and left within the cycle", joined: day(6), left: at(day(9).Add(12 * time.Hour)), wantFrom: day(6), wantTo: day(9), wantBilled: 3},
+		{name: "left after the cycle", joined: day(1), left: at(day(31).AddDate(0, 0, 5)), wantNil: true},
+		{name: "left before the cycle", joined: day(1).AddDate(0, -1, 0), left: at(day(1).AddDate(0, 0, -3)), wantFrom: start, wantTo: start, wantBilled: 0},
+		{name: "joined after the cycle", joined: day(31).AddDate(0, 0, 2), wantFrom: day(31).AddDate(0, 0, 2), wantTo: day(31).AddDate(0, 0, 2), wantBilled: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			us := &db.UserSubscription{JoinedAt: tt.joined, LeftAt: tt.left}
+			p := prorate(us, start, end)
+			if tt.wantNil {
+				if p != nil {
+					t.Fatalf("prorate = %+v, want nil", p)
+				}
+				return
+			}
+			if p == nil {
+				t.Fatal("prorate = nil")
+			}
+			if p.PeriodDays != 30 || !p.BilledFrom.Equal(tt.wantFrom) || !p.BilledTo.Equal(tt.wantTo) || p.BilledDays != tt.wantBilled {
+				t.Errorf("prorate = %s–%s (%d of %d days), want %s–%s (%d of 30)",
+					p.BilledFrom.Format(time.DateOnly), p.BilledTo.Format(time.DateOnly), p.BilledDays, p.PeriodDays,
+					tt.wantFrom.Format(time.DateOnly), tt.wantTo.Format(time.DateOnly), tt.wantBilled)
+			}
+		})
+	}
+}
+
+func TestCycleAt(t *testing.T) {
+	anchor := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name       string
+		t          time.Time
+		wantStart  time.Time
+		wantEnd    time.Time
+		periodDays int
+	}{
+		{"anchor day", day(1, 15).Add(18 * time.Hour), day(1, 15), day(2, 14), 30},
+		{"last day of the first cycle", day(2, 13), day(1, 15), day(2, 14), 30},
+		{"second cycle", day(2, 14), day(2, 14), day(3, 16), 30},
+		{"before anchor", day(1, 1), day(1, 15), day(2, 14), 30},
+		{"weekly", day(1, 30), day(1, 29), day(2, 5), 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := cycleAt(anchor, tt.periodDays, tt.t)
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("cycleAt = %s–%s, want %s–%s", start.Format(time.DateOnly), end.Format(time.DateOnly),
+					tt.wantStart.Format(time.DateOnly), tt.wantEnd.Format(time.DateOnly))
+			}
+		})
+	}
+}
+
+// members участники подписки в памяти
+type members struct {
+	usRepo.UserSubscriptionRepository
+	list []db.UserSubscription
+}
+
+func (r members) FindBySubscription(string) ([]db.UserSubscription, error) {
+	return append([]db.UserSubscription(nil), r.list...), nil
+}
+
+// subscriptions подписка семейного тарифа по ID
+type subscriptions struct {
+	subRepo.SubscriptionRepository
+	sub db.Subscription
+}
+
+func (r subscriptions) FindByID(string) (*db.Subscription, error) { return &r.sub, nil }
+
+// rubleUsers участники с расчётами в рублях
+type rubleUsers struct{ userRepo.UserRepository }
+
+func (rubleUsers) FindByID(id string) (*db.User, error) { return &db.User{ID: id}, nil }
+
+// noSettings глобальные настройки не заданы
+type noSettings struct {
+	gsRepo.GlobalSettingsRepository
+}
+
+func (noSettings) Get() (*db.GlobalSettings, error) { return nil, errors.New("no settings") }
+
+// noRules правил цены нет
+type noRules struct{ prRepo.PricingRuleRepository }
+
+func (noRules) Applicable(string, string, time.Time) ([]db.PricingRule, error) { return nil, nil }
+
+// sameCurrency конвертер без пересчёта и справочник из одной валюты
+type sameCurrency struct {
+	Converter
+	Currencies
+}
+
+func (sameCurrency) Convert(amount money.Decimal, from, to db.Currency, _ time.Time) (money.Decimal, *Conversion, error) {
+	return amount, &Conversion{From: from, To: to, Rate: money.NewFromInt(1), Path: []db.Currency{from, to}}, nil
+}
+
+func (sameCurrency) Get(code db.Currency) (*db.CurrencyInfo, error) {
+	return &db.CurrencyInfo{Code: code, MinorDigits: 2, Enabled: true}, nil
+}
+
+func TestCalculateUserPaymentMidCycleJoiner(t *testing.T) {
+	anchor := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC) // цикл 1–31 июля, 30 дней
+	member := func(id string, joined time.Time) db.UserSubscription {
+		return db.UserSubscription{ID: id, UserID: id, SubscriptionID: "s1", AnchorDate: anchor, JoinedAt: joined}
+	}
+	plan := members{list: []db.UserSubscription{
+		member("a", anchor.AddDate(0, -1, 0)),
+		member("b", anchor.AddDate(0, -1, 0)),
+		member("c", anchor.AddDate(0, 0, 20)), // вступил 21 июля: 10 дней из 30
+	}}
+	sub := db.Subscription{ID: "s1", BasePrice: money.MustParse("999"), BaseCurrency: db.RUB, PeriodDays: 30, SplitMode: db.SplitEqual}
+	s := NewService(rubleUsers{}, plan, subscriptions{sub: sub}, noSettings{}, noRules{}, sameCurrency{}, sameCurrency{})
+
+	want := map[string]int64{"a": 42814, "b": 42814, "c": 14272}
+	total := int64(0)
+	for _, m := range plan.list {
+		calc, err := s.CalculateUserPayment(m.UserID, "s1", anchor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if calc.Amount != want[m.UserID] {
+			t.Errorf("%s pays %d, want %d", m.UserID, calc.Amount, want[m.UserID])
+		}
+		if (calc.Proration != nil) != (m.UserID == "c") {
+			t.Errorf("%s proration = %+v", m.UserID, calc.Proration)
+		}
+		total += calc.Amount
+	}
+	// неполный цикл учтён в доле один раз: участники вместе платят ровно цену подписки
+	if price := sub.BasePrice.Minor(db.AmountDigits, money.HalfUp); total != price {
+		t.Errorf("members pay %d in total, want %d", total, price)
+	}
+}
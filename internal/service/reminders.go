@@ -0,0 +1,342 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	invRepo "github.com/WhoYa/subscription-manager/internal/repository/invoice"
+	remRepo "github.com/WhoYa/subscription-manager/internal/repository/reminder"
+	userRepo "github.com/WhoYa/subscription-manager/internal/repository/user"
+	usRepo "github.com/WhoYa/subscription-manager/internal/repository/usersubscription"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/WhoYa/subscription-manager/pkg/money"
+)
+
+var ErrInvalidQuietHours = errors.New("invalid quiet hours")
+
+// maxReminderAttempts сколько раз повторяется напоминание, на которое Telegram ответил ошибкой
+const maxReminderAttempts = 3
+
+// Notifier доставляет сообщение пользователю Telegram
+type Notifier interface {
+	Send(chatID int64, text string) error
+}
+
+// ReminderConfig расписание напоминаний
+type ReminderConfig struct {
+	Offsets   []int          // дни относительно даты списания: -3 — за три дня, 0 — в день списания, 2 — через два дня после
+	QuietFrom int            // тихие часы по умолчанию: с QuietFrom до QuietTo (час 0–23), равные значения — без тихих часов
+	QuietTo   int            //
+	Location  *time.Location // часовой пояс для дат и тихих часов
+}
+
+// reminderService реализация Reminders
+type reminderService struct {
+	cfg            ReminderConfig
+	notifier       Notifier
+	reminderRepo   remRepo.ReminderRepository
+	userRepo       userRepo.UserRepository
+	userSubRepo    usRepo.UserSubscriptionRepository
+	invoiceRepo    invRepo.InvoiceRepository
+	paymentService Service
+}
+
+// NewReminders создаёт сервис напоминаний об оплате
+func NewReminders(
+	cfg ReminderConfig,
+	notifier Notifier,
+	reminderRepo remRepo.ReminderRepository,
+	userRepo userRepo.UserRepository,
+	userSubRepo usRepo.UserSubscriptionRepository,
+	invoiceRepo invRepo.InvoiceRepository,
+	paymentService Service,
+) Reminders {
+	if cfg.Location == nil {
+		cfg.Location = time.UTC
+	}
+	return &reminderService{
+		cfg:            cfg,
+		notifier:       notifier,
+		reminderRepo:   reminderRepo,
+		userRepo:       userRepo,
+		userSubRepo:    userSubRepo,
+		invoiceRepo:    invoiceRepo,
+		paymentService: paymentService,
+	}
+}
+
+// reminder содержимое одного напоминания
+type reminder struct {
+	serviceName string
+	dueDate     time.Time
+	offset      int
+	invoiceID   *string
+	amount      int64 // к оплате в копейках Currency
+	paid        int64 // уже оплачено по счёту
+	base        int64 // доля в цене подписки в копейках Currency
+	fee         int64 // надбавки и скидки
+	currency    db.Currency
+	source      db.Currency   // валюта цены подписки
+	rate        money.Decimal // курс source → currency
+}
+
+// SendDue отправляет напоминания, день которых наступил в часовом поясе напоминаний
+func (s *reminderService) SendDue(now time.Time) (int, error) {
+	userSubs, err := s.userSubRepo.ListActive()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list active user subscriptions: %w", err)
+	}
+
+	local := now.In(s.cfg.Location)
+	y, m, d := local.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC) // даты циклов хранятся полуночью UTC
+
+	users := make(map[string]*db.User)
+	sent := 0
+	var errs []error
+	for i := range userSubs {
+		us := &userSubs[i]
+		user, ok := users[us.UserID]
+		if !ok {
+			user, err = s.userRepo.FindByID(us.UserID)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("user %s: %w", us.UserID, err))
+				continue
+			}
+			users[us.UserID] = user
+		}
+		if user.RemindersOff || s.isQuiet(user, local) {
+			continue // в тихие часы напоминание дождётся следующего запуска
+		}
+
+		for _, offset := range s.cfg.Offsets {
+			ok, err := s.remind(user, us, today.AddDate(0, 0, -offset), offset, now)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("user subscription %s, offset %d: %w", us.ID, offset, err))
+				continue
+			}
+			if ok {
+				sent++
+			}
+		}
+	}
+	return sent, errors.Join(errs...)
+}
+
+// History последние напоминания пользователя
+func (s *reminderService) History(userID string, limit int) ([]db.ReminderDelivery, error) {
+	list, err := s.reminderRepo.ListByUser(userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reminders: %w", err)
+	}
+	return list, nil
+}
+
+// remind отправляет напоминание о цикле, начинающемся dueDate, если оно нужно и ещё не отправлялось
+func (s *reminderService) remind(user *db.User, us *db.UserSubscription, dueDate time.Time, offset int, now time.Time) (bool, error) {
+	periodDays := us.Subscription.PeriodDays
+	if periodDays <= 0 || dueDate.Before(truncateDay(us.AnchorDate)) {
+		return false, nil
+	}
+	start, end := cycleAt(us.AnchorDate, periodDays, dueDate)
+	if !start.Equal(dueDate) || !us.ActiveDuring(start, end) {
+		return false, nil // в этот день не начинается цикл участника
+	}
+
+	r, err := s.build(us, dueDate, offset)
+	if err != nil || r == nil {
+		return false, err
+	}
+
+	delivery := &db.ReminderDelivery{
+		UserID:             user.ID,
+		UserSubscriptionID: us.ID,
+		DueDate:            dueDate,
+		OffsetDays:         offset,
+		InvoiceID:          r.invoiceID,
+		Amount:             r.amount,
+		Currency:           r.currency,
+		Status:             db.ReminderPending,
+	}
+	created, err := s.reminderRepo.Claim(delivery)
+	if err != nil {
+		return false, fmt.Errorf("failed to save reminder: %w", err)
+	}
+	if !created {
+		// pending после сбоя не повторяем: сообщение могло уйти
+		delivery, err = s.reminderRepo.FindByKey(us.ID, dueDate, offset)
+		if err != nil {
+			return false, fmt.Errorf("failed to get reminder: %w", err)
+		}
+		if delivery.Status != db.ReminderFailed || delivery.Attempts >= maxReminderAttempts {
+			return false, nil
+		}
+	}
+
+	delivery.Attempts++
+	if err := s.notifier.Send(user.TGID, reminderText(r)); err != nil {
+		delivery.Status = db.ReminderFailed
+		delivery.LastError = truncate(err.Error(), 500)
+		if upErr := s.reminderRepo.Update(delivery); upErr != nil {
+			return false, errors.Join(err, upErr)
+		}
+		return false, err
+	}
+
+	sentAt := now.UTC()
+	delivery.Status = db.ReminderSent
+	delivery.SentAt = &sentAt
+	delivery.LastError = ""
+	if err := s.reminderRepo.Update(delivery); err != nil {
+		return true, fmt.Errorf("failed to save reminder: %w", err)
+	}
+	log.Printf("REMINDERS: Sent %+d day reminder to user %s for subscription %s due %s",
+		offset, user.ID, us.SubscriptionID, dueDate.Format("2006-01-02"))
+	return true, nil
+}
+
+// build собирает напоминание: по выставленному счёту — остаток к оплате по зафиксированному курсу,
+// до выставления — по текущему расчёту. nil — напоминать не о чем: цикл оплачен,
+// а после даты списания — ещё и если счёта нет.
+func (s *reminderService) build(us *db.UserSubscription, dueDate time.Time, offset int) (*reminder, error) {
+	r := &reminder{
+		serviceName: us.Subscription.ServiceName,
+		dueDate:     dueDate,
+		offset:      offset,
+		source:      us.Subscription.BaseCurrency,
+	}
+
+	invoices, err := s.invoiceRepo.FindByUserSubscription(us.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoices: %w", err)
+	}
+	for i := range invoices {
+		inv := &invoices[i]
+		if !inv.PeriodStart.Equal(dueDate) || inv.Status == db.InvoiceVoided || inv.Status == db.InvoiceDraft {
+			continue
+		}
+		if inv.Status == db.InvoicePaid || inv.Amount <= inv.PaidAmount {
+			return nil, nil
+		}
+		r.invoiceID = &inv.ID
+		r.amount = inv.Amount - inv.PaidAmount
+		r.paid = inv.PaidAmount
+		r.base = inv.BaseAmount
+		r.fee = inv.ProfitAmount
+		r.currency = inv.Currency
+		r.rate = inv.RateUsed
+		return r, nil
+	}
+
+	if offset > 0 {
+		return nil, nil // счёт не выставлен или аннулирован
+	}
+
+	calc, err := s.paymentService.CalculateUserPayment(us.UserID, us.SubscriptionID, dueDate)
+	if err != nil {
+		return nil, err
+	}
+	r.amount = calc.Amount
+	r.base = calc.BaseKopecks
+	r.fee = calc.ProfitKopecks
+	r.currency = calc.Currency
+	r.source = calc.SourceCurrency
+	r.rate = calc.ExchangeRate
+	return r, nil
+}
+
+// isQuiet попадает ли момент local в тихие часы пользователя
+func (s *reminderService) isQuiet(user *db.User, local time.Time) bool {
+	from, to := s.cfg.QuietFrom, s.cfg.QuietTo
+	if user.QuietFrom != nil && user.QuietTo != nil {
+		from, to = *user.QuietFrom, *user.QuietTo
+	}
+	return inQuietHours(local.Hour(), from, to)
+}
+
+// ParseQuietHours разбирает тихие часы "22-9": с 22:00 до 09:00
+func ParseQuietHours(s string) (from, to int, err error) {
+	fromStr, toStr, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if ok {
+		from, err = strconv.Atoi(strings.TrimSpace(fromStr))
+	}
+	if ok && err == nil {
+		to, err = strconv.Atoi(strings.TrimSpace(toStr))
+	}
+	if !ok || err != nil || from < 0 || from > 23 || to < 0 || to > 23 {
+		return 0, 0, fmt.Errorf("%w: %q, use HH-HH, e.g. 22-9", ErrInvalidQuietHours, s)
+	}
+	return from, to, nil
+}
+
+// inQuietHours час h в интервале [from, to), интервал может переходить через полночь
+func inQuietHours(h, from, to int) bool {
+	switch {
+	case from == to:
+		return false
+	case from < to:
+		return h >= from && h < to
+	default:
+		return h >= from || h < to
+	}
+}
+
+// reminderText текст напоминания с суммой и пояснением курса
+func reminderText(r *reminder) string {
+	due := r.dueDate.Format("02.01.2006")
+	var when string
+	switch {
+	case r.offset < 0:
+		when = fmt.Sprintf("через %d дн., %s", -r.offset, due)
+	case r.offset == 0:
+		when = fmt.Sprintf("сегодня, %s", due)
+	default:
+		when = fmt.Sprintf("просрочено на %d дн. (срок %s)", r.offset, due)
+	}
+
+	lines := []string{
+		"🔔 Напоминание об оплате",
+		"",
+		"🏷️ " + r.serviceName,
+		fmt.Sprintf("💳 К оплате: %s %s — %s", formatMinor(r.amount), r.currency, when),
+	}
+
+	base := money.NewFromMinor(r.base, db.AmountDigits)
+	sourceBase, err := base.Div(r.rate)
+	if r.source != "" && r.source != r.currency && r.rate.Sign() > 0 && err == nil {
+		// доля в цене подписки в её валюте: база / курс
+		sourceBase = sourceBase.Round(db.AmountDigits, money.HalfUp)
+		lines = append(lines, fmt.Sprintf("💱 Ваша доля %s %s × курс %s = %s %s",
+			sourceBase.StringFixed(db.AmountDigits), r.source, r.rate, formatMinor(r.base), r.currency))
+	} else {
+		lines = append(lines, fmt.Sprintf("💱 Ваша доля в цене подписки: %s %s", formatMinor(r.base), r.currency))
+	}
+	if r.fee != 0 {
+		lines = append(lines, fmt.Sprintf("➕ Надбавки и скидки: %s %s", formatMinor(r.fee), r.currency))
+	}
+	if r.paid > 0 {
+		lines = append(lines, fmt.Sprintf("✅ Уже оплачено: %s %s", formatMinor(r.paid), r.currency))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatMinor сумма в копейках в основных единицах: 123456 → "1234.56"
+func formatMinor(v int64) string {
+	return money.NewFromMinor(v, db.AmountDigits).StringFixed(db.AmountDigits)
+}
+
+// truncate обрезает строку до n байт, не разрывая символы
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
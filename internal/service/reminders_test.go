@@ -0,0 +1,100 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+func TestInQuietHours(t *testing.T) {
+	tests := []struct {
+		name     string
+		h        int
+		from, to int
+		want     bool
+	}{
+		{"same day: inside", 14, 13, 15, true},
+		{"same day: start is quiet", 13, 13, 15, true},
+		{"same day: end is not quiet", 15, 13, 15, false},
+		{"same day: before", 12, 13, 15, false},
+		{"over midnight: late evening", 23, 22, 9, true},
+		{"over midnight: start", 22, 22, 9, true},
+		{"over midnight: after midnight", 0, 22, 9, true},
+		{"over midnight: early morning", 8, 22, 9, true},
+		{"over midnight: end is not quiet", 9, 22, 9, false},
+		{"over midnight: daytime", 15, 22, 9, false},
+		{"equal bounds: never quiet", 22, 22, 22, false},
+		{"from midnight", 0, 0, 7, true},
+		{"to midnight", 23, 20, 0, true},
+		{"to midnight: midnight itself", 0, 20, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inQuietHours(tt.h, tt.from, tt.to); got != tt.want {
+				t.Errorf("inQuietHours(%d, %d, %d) = %v, want %v", tt.h, tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuietHours(t *testing.T) {
+	tests := []struct {
+		in       string
+		from, to int
+		wantErr  bool
+	}{
+		{in: "22-9", from: 22, to: 9},
+		{in: " 0 - 7 ", from: 0, to: 7},
+		{in: "23-23", from: 23, to: 23},
+		{in: "24-9", wantErr: true},
+		{in: "-1-9", wantErr: true},
+		{in: "22", wantErr: true},
+		{in: "a-b", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			from, to, err := ParseQuietHours(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidQuietHours) {
+					t.Fatalf("err = %v, want ErrInvalidQuietHours", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if from != tt.from || to != tt.to {
+				t.Errorf("ParseQuietHours(%q) = %d, %d, want %d, %d", tt.in, from, to, tt.from, tt.to)
+			}
+		})
+	}
+}
+
+func TestIsQuietUsesUserHours(t *testing.T) {
+	s := &reminderService{cfg: ReminderConfig{QuietFrom: 22, QuietTo: 9}}
+	hour := func(h int) time.Time { return time.Date(2025, 7, 1, h, 30, 0, 0, time.UTC) }
+	own := func(from, to int) *db.User { return &db.User{QuietFrom: &from, QuietTo: &to} }
+
+	tests := []struct {
+		name string
+		user *db.User
+		at   time.Time
+		want bool
+	}{
+		{"default hours", &db.User{}, hour(23), true},
+		{"default hours: daytime", &db.User{}, hour(12), false},
+		{"own hours", own(12, 14), hour(13), true},
+		{"own hours replace default", own(12, 14), hour(23), false},
+		{"own hours turned off", own(0, 0), hour(3), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.isQuiet(tt.user, tt.at); got != tt.want {
+				t.Errorf("isQuiet = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,51 +3,129 @@ package service
 import (
 	"time"
 
+	"github.com/WhoYa/subscription-manager/internal/repository/query"
 	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/WhoYa/subscription-manager/pkg/money"
 )
 
 // PaymentAmount представляет рассчитанную сумму к оплате
 type PaymentAmount struct {
-	UserID         string      `json:"user_id"`
-	SubscriptionID string      `json:"subscription_id"`
-	Amount         int64       `json:"amount_kopecks"` // копейки для точности
-	AmountRubles   float64     `json:"amount_rubles"`  // рубли для удобства
-	BaseAmount     float64     `json:"base_amount"`    // "чистая" сумма в рублях
-	ProfitAmount   float64     `json:"profit_amount"`  // прибыль в рублях
-	Currency       db.Currency `json:"currency"`       // всегда RUB
-	ExchangeRate   float64     `json:"exchange_rate"`  // курс конвертации
-	DueDate        time.Time   `json:"due_date"`       // дата списания
+	UserID         string        `json:"user_id"`
+	SubscriptionID string        `json:"subscription_id"`
+	Amount         int64         `json:"amount_kopecks"`      // в сотых долях Currency; всегда BaseKopecks + ProfitKopecks
+	BaseKopecks    int64         `json:"base_kopecks"`        // "чистая" сумма в сотых долях Currency
+	ProfitKopecks  int64         `json:"profit_kopecks"`      // прибыль в сотых долях Currency
+	AmountRubles   money.Decimal `json:"amount_rubles"`       // Amount в основных единицах Currency для удобства
+	BaseAmount     money.Decimal `json:"base_amount"`         // BaseKopecks в основных единицах Currency
+	ProfitAmount   money.Decimal `json:"profit_amount"`       // ProfitKopecks в основных единицах Currency
+	Currency       db.Currency   `json:"currency"`            // валюта расчётов участника
+	SourceCurrency db.Currency   `json:"source_currency"`     // валюта цены подписки
+	ExchangeRate   money.Decimal `json:"exchange_rate"`       // курс конвертации SourceCurrency → Currency
+	RatePath       []db.Currency `json:"rate_path"`           // цепочка валют, через которую посчитан курс
+	MarkupPercent  money.Decimal `json:"markup_percent"`      // фактическая надбавка к базовой сумме, %
+	SplitMode      db.SplitMode  `json:"split_mode"`          // как цена подписки делится между участниками
+	SharePercent   money.Decimal `json:"share_percent"`       // доля участника в цене подписки, %
+	DueDate        time.Time     `json:"due_date"`            // дата списания
+	Breakdown      []PriceStep   `json:"breakdown"`           // как правила ценообразования изменили базовую сумму
+	Proration      *Proration    `json:"proration,omitempty"` // неполный цикл; nil — цикл оплачивается целиком
+}
+
+// Proration оплата неполного цикла: участник присоединился или вышел посреди периода.
+// Доля участника в цене подписки уже посчитана по BilledDays (см. db.SplitShares).
+type Proration struct {
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	PeriodDays  int       `json:"period_days"`
+	BilledFrom  time.Time `json:"billed_from"`
+	BilledTo    time.Time `json:"billed_to"` // не включительно
+	BilledDays  int       `json:"billed_days"`
+}
+
+// MembershipEnd итог выхода участника из подписки
+type MembershipEnd struct {
+	UserSubscription *db.UserSubscription `json:"user_subscription"`
+	Credit           int64                `json:"credit"`      // возврат за неиспользованные дни в сотых долях валюты участника
+	InvoiceIDs       []string             `json:"invoice_ids"` // выставленные счета, по которым сделан возврат
+}
+
+// PriceStep строка расшифровки цены: как одно правило изменило цену.
+// Before и After округлены до знаков валюты только для показа, считается без округления.
+type PriceStep struct {
+	RuleID   string              `json:"rule_id,omitempty"` // пусто для настроек цены участника и глобальной надбавки
+	Name     string              `json:"name"`
+	Kind     db.PricingRuleKind  `json:"kind"`
+	Scope    db.PricingRuleScope `json:"scope"`
+	Priority int                 `json:"priority"`
+	Value    money.Decimal       `json:"value"`
+	Before   money.Decimal       `json:"before"` // цена до правила в основных единицах Currency
+	After    money.Decimal       `json:"after"`  // цена после правила
+	Delta    money.Decimal       `json:"delta"`  // After − Before
+}
+
+// Conversion курс пересчёта между двумя валютами
+type Conversion struct {
+	From db.Currency   `json:"from"`
+	To   db.Currency   `json:"to"`
+	Rate money.Decimal `json:"rate"` // сколько единиц To стоит одна единица From (до 8 знаков)
+	Path []db.Currency `json:"path"` // From, промежуточные валюты, To
+	AsOf time.Time     `json:"as_of"`
+
+	// курс пути как дробь num/den: пересчёт суммы делается одним делением без потери точности
+	num, den money.Decimal
 }
 
 // CurrencyRate представляет курс валюты
 type CurrencyRate struct {
-	Currency db.Currency `json:"currency"`
-	Rate     float64     `json:"rate"`   // курс к рублю
-	Source   string      `json:"source"` // источник (Cifra, FF)
+	Currency db.Currency   `json:"currency"`
+	Rate     money.Decimal `json:"rate"`   // курс к рублю
+	Source   string        `json:"source"` // источник (Cifra, FF)
+}
+
+// Statement представляет выписку по лицевому счёту участника (суммы в копейках)
+type Statement struct {
+	UserID         string          `json:"user_id"`
+	Currency       db.Currency     `json:"currency"` // валюта проводок выписки
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	Balance        int64           `json:"balance"`         // текущий баланс: > 0 переплата, < 0 долг
+	OpeningBalance int64           `json:"opening_balance"` // баланс на начало периода
+	ClosingBalance int64           `json:"closing_balance"` // баланс на конец периода
+	TotalDebit     int64           `json:"total_debit"`
+	TotalCredit    int64           `json:"total_credit"`
+	Entries        []StatementLine `json:"entries"`
+}
+
+// StatementLine проводка выписки с нарастающим итогом
+type StatementLine struct {
+	db.LedgerEntry
+	Balance int64 `json:"balance"` // баланс после проводки
 }
 
 // ProfitStats представляет статистику прибыли
 type ProfitStats struct {
-	TotalProfit   float64 `json:"total_profit"`   // общая прибыль в рублях
-	TotalPayments int64   `json:"total_payments"` // количество платежей
-	AverageProfit float64 `json:"average_profit"` // средняя прибыль за платеж
-	Period        string  `json:"period"`         // период (например, "2024-07")
+	TotalProfit   money.Decimal `json:"total_profit"`   // общая прибыль в валюте отчёта
+	TotalPayments int64         `json:"total_payments"` // количество платежей
+	AverageProfit money.Decimal `json:"average_profit"` // средняя прибыль за платеж
+	Currency      db.Currency   `json:"currency"`       // валюта отчёта
+	Period        string        `json:"period"`         // период (например, "2024-07")
 }
 
-// UserProfitStats представляет статистику прибыли по пользователю
+// UserProfitStats представляет статистику прибыли по пользователю (в его валюте расчётов)
 type UserProfitStats struct {
-	UserID       string  `json:"user_id"`
-	Username     string  `json:"username"`
-	TotalProfit  float64 `json:"total_profit"`
-	PaymentCount int64   `json:"payment_count"`
+	UserID       string        `json:"user_id"`
+	Username     string        `json:"username"`
+	TotalProfit  money.Decimal `json:"total_profit"`
+	PaymentCount int64         `json:"payment_count"`
+	Currency     db.Currency   `json:"currency"`
 }
 
 // SubscriptionProfitStats представляет статистику прибыли по подписке
 type SubscriptionProfitStats struct {
-	SubscriptionID string  `json:"subscription_id"`
-	ServiceName    string  `json:"service_name"`
-	TotalProfit    float64 `json:"total_profit"`
-	PaymentCount   int64   `json:"payment_count"`
+	SubscriptionID string        `json:"subscription_id"`
+	ServiceName    string        `json:"service_name"`
+	TotalProfit    money.Decimal `json:"total_profit"`
+	PaymentCount   int64         `json:"payment_count"`
+	Currency       db.Currency   `json:"currency"`
 }
 
 // Service интерфейс для основной бизнес-логики
@@ -57,17 +135,272 @@ type Service interface {
 	CalculateUserPayment(userID, subscriptionID string, dueDate time.Time) (*PaymentAmount, error)
 }
 
+// Converter интерфейс для пересчёта между любыми валютами по прямым или кросс-курсам
+type Converter interface {
+	// Rate возвращает курс from → to, действовавший в момент t
+	Rate(from, to db.Currency, t time.Time) (*Conversion, error)
+
+	// Convert пересчитывает сумму из from в to по курсу на момент t
+	Convert(amount money.Decimal, from, to db.Currency, t time.Time) (money.Decimal, *Conversion, error)
+}
+
+// Currencies интерфейс справочника валют; Validate — единая проверка кода валюты для всего API
+type Currencies interface {
+	// Validate приводит код к верхнему регистру и проверяет, что валюта есть в справочнике и включена
+	Validate(code string) (db.Currency, error)
+
+	// Get возвращает валюту справочника по коду
+	Get(code db.Currency) (*db.CurrencyInfo, error)
+
+	// List возвращает справочник; enabledOnly — только включённые валюты
+	List(enabledOnly bool) ([]db.CurrencyInfo, error)
+
+	// Create добавляет валюту в справочник
+	Create(c *db.CurrencyInfo) error
+
+	// Update сохраняет изменения валюты
+	Update(c *db.CurrencyInfo) error
+}
+
+// PricingRules интерфейс управления правилами ценообразования
+type PricingRules interface {
+	// Get возвращает правило по ID
+	Get(id string) (*db.PricingRule, error)
+
+	// List возвращает страницу правил, по умолчанию в порядке применения
+	List(spec query.Spec) (query.Page[db.PricingRule], error)
+
+	// Create проверяет и сохраняет новое правило
+	Create(rule *db.PricingRule) error
+
+	// Update проверяет и сохраняет изменения правила
+	Update(rule *db.PricingRule) error
+
+	// Delete удаляет правило
+	Delete(id string) error
+}
+
+// Principal от чьего имени выполняется запрос к API
+type Principal struct {
+	KeyID  string         `json:"key_id"`
+	Name   string         `json:"name"`
+	Scope  db.APIKeyScope `json:"scope"`
+	UserID string         `json:"user_id,omitempty"` // пусто у ключа сервиса
+}
+
+// IsAdmin есть ли у запроса права администратора
+func (p *Principal) IsAdmin() bool {
+	return p != nil && p.Scope == db.KeyScopeAdmin
+}
+
+// CanAccessUser может ли запрос читать данные пользователя userID
+func (p *Principal) CanAccessUser(userID string) bool {
+	return p.IsAdmin() || p != nil && p.UserID != "" && p.UserID == userID
+}
+
+// Auth интерфейс ключей доступа к API
+type Auth interface {
+	// Issue проверяет и сохраняет новый ключ, возвращает сам ключ — больше его узнать нельзя
+	Issue(key *db.APIKey) (string, error)
+
+	// EnsureServiceKey регистрирует заданный в окружении ключ сервиса с правами администратора,
+	// если его ещё нет
+	EnsureServiceKey(name, token string) error
+
+	// Authenticate проверяет ключ из запроса
+	Authenticate(token string) (*Principal, error)
+
+	// Get возвращает ключ по ID без его значения
+	Get(id string) (*db.APIKey, error)
+
+	// List возвращает страницу ключей
+	List(spec query.Spec) (query.Page[db.APIKey], error)
+
+	// Revoke отзывает ключ и возвращает его
+	Revoke(id string) (*db.APIKey, error)
+
+	// PurgeExpired удаляет ключи, срок действия которых истёк раньше before, — прежде всего
+	// сессии Mini App, которые выпускаются при каждом входе; возвращает их число
+	PurgeExpired(before time.Time) (int, error)
+}
+
+// Actor кто вносит изменение: ключ запроса и, если известен, пользователь,
+// от имени которого действует ключ сервиса
+type Actor struct {
+	KeyID  string
+	Name   string
+	UserID string // пусто — пользователь не известен или ищется по TGID
+	TGID   int64  // Telegram ID из заголовка бота; 0 — не передан
+}
+
+// Audit интерфейс журнала аудита изменений через API
+type Audit interface {
+	// Record сохраняет изменение записи entity: before — состояние до (nil при создании),
+	// after — после (nil при удалении). В журнал попадают только изменившиеся поля;
+	// изменение без разницы в полях не записывается.
+	Record(actor Actor, entity db.AuditEntity, entityID string, before, after any) error
+
+	// Restored сохраняет восстановление записи из корзины; deleted — запись, как она лежала в корзине
+	Restored(actor Actor, entity db.AuditEntity, entityID string, deleted any) error
+
+	// List возвращает страницу журнала, по умолчанию новые записи первыми
+	List(spec query.Spec) (query.Page[db.AuditEntry], error)
+}
+
+// Idempotency повтор POST-запросов с заголовком Idempotency-Key
+type Idempotency interface {
+	// Begin занимает ключ key клиента apiKeyID для запроса с хэшем hash и возвращает новую
+	// запись со Status == 0. Тот же запрос уже выполнен — возвращает сохранённую запись
+	// с ответом; ErrIdempotencyKeyReused — ключ использован с другим запросом;
+	// ErrIdempotencyKeyInProgress — запрос с этим ключом ещё выполняется. Ключ запроса,
+	// не завершившегося за время аренды, занимается заново
+	Begin(apiKeyID, key, hash string) (*db.IdempotencyKey, error)
+	// Complete сохраняет ответ запроса для повторов
+	Complete(record *db.IdempotencyKey, status int, contentType string, body []byte) error
+	// Release освобождает ключ: повтор запроса выполнится заново
+	Release(record *db.IdempotencyKey) error
+	// Purge удаляет ключи, созданные раньше before; возвращает их число
+	Purge(before time.Time) (int, error)
+}
+
+// Session ключ доступа, выданный участнику при входе через Telegram Mini App
+type Session struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	User      db.User   `json:"user"`
+}
+
+// WebAppAuth интерфейс входа участников через Telegram Mini App
+type WebAppAuth interface {
+	// Login проверяет подпись initData и выдаёт ключ с правами member для пользователя
+	// с тем же Telegram ID
+	Login(initData string) (*Session, error)
+}
+
+// Reminders интерфейс напоминаний участникам об оплате
+type Reminders interface {
+	// SendDue отправляет напоминания, день которых наступил, кроме уже отправленных,
+	// отключённых участником и попадающих в тихие часы. Возвращает число отправленных.
+	SendDue(now time.Time) (int, error)
+
+	// History последние напоминания пользователя
+	History(userID string, limit int) ([]db.ReminderDelivery, error)
+}
+
+// PaymentRequest регистрация платежа
+type PaymentRequest struct {
+	UserID         string
+	SubscriptionID string
+	InvoiceID      string        // пусто — платёж гасит самый старый неоплаченный счёт по подписке
+	Amount         int64         // в сотых долях Currency; 0 — остаток счёта или расчёт
+	Currency       db.Currency   // валюта Amount; пусто — валюта расчётов участника
+	RateUsed       money.Decimal // свой курс; 0 — курс счёта или расчёта
+	PaidAt         time.Time
+}
+
+// Payments интерфейс регистрации платежей
+type Payments interface {
+	// Record гасит счёт, сохраняет платёж и проводит его по лицевому счёту участника
+	Record(req PaymentRequest) (*db.PaymentLog, error)
+
+	// RecordIn как Record, но в транзакции вызывающего: платёж откатывается вместе с ней
+	RecordIn(st Store, req PaymentRequest) (*db.PaymentLog, error)
+}
+
+// PaymentClaims интерфейс сообщений участников об оплате: pending → approved | rejected
+type PaymentClaims interface {
+	// Submit проверяет и сохраняет сообщение в очередь на подтверждение
+	Submit(claim *db.PaymentClaim) error
+
+	// Get возвращает сообщение по ID
+	Get(id string) (*db.PaymentClaim, error)
+
+	// List возвращает страницу сообщений, по умолчанию старые первыми
+	List(spec query.Spec) (query.Page[db.PaymentClaim], error)
+
+	// Approve регистрирует платёж по сообщению; повторное решение — ErrPaymentClaimResolved
+	Approve(id, reviewerID string) (*db.PaymentClaim, error)
+
+	// Reject отклоняет сообщение с причиной
+	Reject(id, reviewerID, reason string) (*db.PaymentClaim, error)
+}
+
+// Billing интерфейс для циклов списания и автоматического выставления счетов
+type Billing interface {
+	// NextDueDate возвращает ближайшую дату списания, не раньше after
+	NextDueDate(userSub *db.UserSubscription, after time.Time) time.Time
+
+	// GenerateInvoices выставляет счета за циклы, до списания по которым осталось
+	// не больше суток, и за пропущенные с последнего выставленного счёта циклы
+	// (например, пока задача не работала). Повторный вызов не создаёт дублей.
+	GenerateInvoices(now time.Time) (int, error)
+
+	// EndMembership отмечает выход участника с даты at. За дни после выхода
+	// в уже выставленных счетах участнику начисляется возврат на баланс.
+	EndMembership(userSubID string, at time.Time) (*MembershipEnd, error)
+}
+
+// Invoicing интерфейс для жизненного цикла счетов
+// draft → issued → partially_paid → paid, issued/partially_paid → overdue, любой неоплаченный → voided
+type Invoicing interface {
+	// CreateDraft создаёт черновик счёта за цикл, в который попадает dueDate
+	CreateDraft(userID, subscriptionID string, dueDate time.Time) (*db.Invoice, error)
+
+	// Issue выставляет черновик, пересчитывая и фиксируя суммы, курс и надбавку
+	Issue(id string) (*db.Invoice, error)
+
+	// Void аннулирует неоплаченный счёт
+	Void(id, reason string) (*db.Invoice, error)
+
+	// Get возвращает счёт по ID
+	Get(id string) (*db.Invoice, error)
+
+	// OutstandingFor возвращает самый старый неоплаченный счёт пользователя по подписке
+	OutstandingFor(userID, subscriptionID string) (*db.Invoice, error)
+
+	// ApplyPayment засчитывает платёж в счёт и обновляет его статус
+	ApplyPayment(id string, amount int64, paidAt time.Time) (*db.Invoice, error)
+
+	// MarkOverdue переводит просроченные счета в overdue
+	MarkOverdue(now time.Time) (int64, error)
+}
+
+// Ledger интерфейс для лицевых счетов участников: начисления — дебет, платежи — кредит
+type Ledger interface {
+	// Charge проводит начисление по выставленному счёту и гасит его переплатой
+	Charge(inv *db.Invoice) error
+
+	// ReverseCharge сторнирует начисление по аннулированному счёту
+	ReverseCharge(inv *db.Invoice) error
+
+	// RecordPayment проводит платёж; переплата засчитывается в неоплаченные и следующие счета
+	RecordPayment(pl *db.PaymentLog) error
+
+	// Adjust проводит ручную корректировку в валюте currency (amount > 0 — в пользу участника)
+	Adjust(userID string, amount int64, currency db.Currency, description, createdBy string) (*db.LedgerEntry, error)
+
+	// Balance возвращает текущий баланс участника в валюте currency;
+	// проводки в разных валютах не сворачиваются в один баланс
+	Balance(userID string, currency db.Currency) (int64, error)
+
+	// Statement возвращает текущий баланс и выписку за период в валюте currency
+	Statement(userID string, currency db.Currency, from, to time.Time) (*Statement, error)
+}
+
 // ProfitAnalytics интерфейс для аналитики прибыли (только для администраторов)
 type ProfitAnalytics interface {
-	// GetMonthlyProfit возвращает общую прибыль за месяц
-	GetMonthlyProfit(year int, month int) (*ProfitStats, error)
+	// Платежи в разных валютах пересчитываются по курсу на дату оплаты.
+
+	// GetMonthlyProfit возвращает общую прибыль за месяц в валюте currency
+	GetMonthlyProfit(year int, month int, currency db.Currency) (*ProfitStats, error)
 
-	// GetUserProfitStats возвращает статистику прибыли по пользователям за период
+	// GetUserProfitStats возвращает статистику прибыли по пользователям за период,
+	// каждому — в его валюте расчётов
 	GetUserProfitStats(from, to time.Time) ([]UserProfitStats, error)
 
-	// GetSubscriptionProfitStats возвращает статистику прибыли по подпискам за период
-	GetSubscriptionProfitStats(from, to time.Time) ([]SubscriptionProfitStats, error)
+	// GetSubscriptionProfitStats возвращает статистику прибыли по подпискам за период в валюте currency
+	GetSubscriptionProfitStats(from, to time.Time, currency db.Currency) ([]SubscriptionProfitStats, error)
 
-	// GetTotalProfit возвращает общую прибыль за все время
-	GetTotalProfit() (*ProfitStats, error)
+	// GetTotalProfit возвращает общую прибыль за все время в валюте currency
+	GetTotalProfit(currency db.Currency) (*ProfitStats, error)
 }
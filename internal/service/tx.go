@@ -0,0 +1,47 @@
+package service
+
+import (
+	invRepo "github.com/WhoYa/subscription-manager/internal/repository/invoice"
+	ledgerRepo "github.com/WhoYa/subscription-manager/internal/repository/ledger"
+	claimRepo "github.com/WhoYa/subscription-manager/internal/repository/paymentclaim"
+	"github.com/WhoYa/subscription-manager/internal/repository/paymentlog"
+	usRepo "github.com/WhoYa/subscription-manager/internal/repository/usersubscription"
+	"gorm.io/gorm"
+)
+
+// Store репозитории денежных записей в одной транзакции: счёт, проводки по лицевому
+// счёту, платёж и подтверждённое сообщение об оплате сохраняются вместе или не сохраняются вовсе
+type Store struct {
+	Invoices          invRepo.InvoiceRepository
+	Ledger            ledgerRepo.LedgerRepository
+	Payments          paymentlog.PaymentLogRepository
+	UserSubscriptions usRepo.UserSubscriptionRepository
+	Claims            claimRepo.PaymentClaimRepository
+}
+
+// Transactor выполняет fn в транзакции; ошибка из fn откатывает все изменения через Store
+type Transactor interface {
+	Transaction(fn func(st Store) error) error
+}
+
+// gormTransactor реализация Transactor на транзакциях GORM
+type gormTransactor struct {
+	orm *gorm.DB
+}
+
+// NewTransactor создаёт Transactor поверх подключения GORM
+func NewTransactor(orm *gorm.DB) Transactor {
+	return &gormTransactor{orm: orm}
+}
+
+func (t *gormTransactor) Transaction(fn func(st Store) error) error {
+	return t.orm.Transaction(func(tx *gorm.DB) error {
+		return fn(Store{
+			Invoices:          invRepo.NewInvoiceRepo(tx),
+			Ledger:            ledgerRepo.NewLedgerRepo(tx),
+			Payments:          paymentlog.NewPaymentLogRepo(tx),
+			UserSubscriptions: usRepo.NewUserSubscriptionRepo(tx),
+			Claims:            claimRepo.NewPaymentClaimRepo(tx),
+		})
+	})
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	userRepo "github.com/WhoYa/subscription-manager/internal/repository/user"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidInitData = errors.New("invalid telegram init data")
+
+const (
+	// initDataMaxAge сколько действительна подпись initData после открытия Mini App
+	initDataMaxAge = 24 * time.Hour
+	// webAppSessionTTL срок действия ключа сессии, выданного Mini App
+	webAppSessionTTL = 24 * time.Hour
+)
+
+// WebAppUser пользователь Telegram из initData
+type WebAppUser struct {
+	ID        int64  `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Username  string `json:"username"`
+}
+
+// webAppAuthService реализация WebAppAuth
+type webAppAuthService struct {
+	botToken string
+	auth     Auth
+	userRepo userRepo.UserRepository
+}
+
+// NewWebAppAuth создаёт вход через Telegram Mini App; botToken — токен бота, открывающего Mini App
+func NewWebAppAuth(botToken string, auth Auth, userRepo userRepo.UserRepository) WebAppAuth {
+	return &webAppAuthService{botToken: botToken, auth: auth, userRepo: userRepo}
+}
+
+// Login проверяет initData и выпускает участнику ключ member на webAppSessionTTL
+func (s *webAppAuthService) Login(initData string) (*Session, error) {
+	now := time.Now()
+	tgUser, err := VerifyInitData(initData, s.botToken, initDataMaxAge, now)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByTGID(tgUser.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: telegram user %d", ErrUserNotFound, tgUser.ID)
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	expiresAt := now.Add(webAppSessionTTL)
+	key := &db.APIKey{
+		Name:      fmt.Sprintf("Telegram Mini App (%d)", tgUser.ID),
+		Scope:     db.KeyScopeMember,
+		UserID:    &user.ID,
+		ExpiresAt: &expiresAt,
+	}
+	token, err := s.auth.Issue(key)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("AUTH: Mini App login of user %s (tg %d)", user.ID, tgUser.ID)
+	return &Session{Token: token, ExpiresAt: expiresAt, User: *user}, nil
+}
+
+// VerifyInitData проверяет подпись initData Telegram Mini App по алгоритму из документации Telegram:
+// secret = HMAC-SHA256("WebAppData", botToken), hash = HMAC-SHA256(secret, data_check_string),
+// где data_check_string — все поля, кроме hash, в виде key=value по алфавиту через \n.
+// Подпись старше maxAge отклоняется.
+func VerifyInitData(initData, botToken string, maxAge time.Duration, now time.Time) (*WebAppUser, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInitData, err)
+	}
+
+	hash := values.Get("hash")
+	if hash == "" {
+		return nil, fmt.Errorf("%w: hash is missing", ErrInvalidInitData)
+	}
+	got, err := hex.DecodeString(hash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed hash", ErrInvalidInitData)
+	}
+
+	if !hmac.Equal(got, signInitData(values, botToken)) {
+		return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidInitData)
+	}
+
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: auth_date is missing", ErrInvalidInitData)
+	}
+	if age := now.Sub(time.Unix(authDate, 0)); age > maxAge {
+		return nil, fmt.Errorf("%w: signed %s ago", ErrInvalidInitData, age.Round(time.Second))
+	}
+
+	var user WebAppUser
+	if err := json.Unmarshal([]byte(values.Get("user")), &user); err != nil || user.ID == 0 {
+		return nil, fmt.Errorf("%w: user is missing", ErrInvalidInitData)
+	}
+	return &user, nil
+}
+
+// signInitData HMAC полей initData, кроме hash
+func signInitData(values url.Values, botToken string) []byte {
+	pairs := make([]string, 0, len(values))
+	for key := range values {
+		if key == "hash" {
+			continue
+		}
+		pairs = append(pairs, key+"="+values.Get(key))
+	}
+	sort.Strings(pairs)
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+	return mac.Sum(nil)
+}
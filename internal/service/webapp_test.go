@@ -0,0 +1,156 @@
+package service
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	userRepo "github.com/WhoYa/subscription-manager/internal/repository/user"
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"gorm.io/gorm"
+)
+
+const testBotToken = "123456:TEST-bot-token"
+
+// signedInitData initData, подписанная токеном бота так же, как её подписывает Telegram
+func signedInitData(botToken string, authDate time.Time, user string) url.Values {
+	values := url.Values{
+		"query_id":  {"AAHdF6IQAAAAAN0XohDhrOrc"},
+		"user":      {user},
+		"auth_date": {strconv.FormatInt(authDate.Unix(), 10)},
+	}
+	values.Set("hash", hex.EncodeToString(signInitData(values, botToken)))
+	return values
+}
+
+func TestVerifyInitData(t *testing.T) {
+	now := time.Date(2025, 7, 20, 12, 0, 0, 0, time.UTC)
+	const user = `{"id":279058397,"first_name":"Vladislav","username":"vdkfrost"}`
+
+	tests := []struct {
+		name     string
+		initData func() string
+		wantErr  bool
+	}{
+		{"valid", func() string {
+			return signedInitData(testBotToken, now.Add(-time.Hour), user).Encode()
+		}, false},
+		{"expired", func() string {
+			return signedInitData(testBotToken, now.Add(-25*time.Hour), user).Encode()
+		}, true},
+		{"other bot", func() string {
+			return signedInitData("654321:OTHER-bot-token", now, user).Encode()
+		}, true},
+		{"tampered hash", func() string {
+			v := signedInitData(testBotToken, now, user)
+			hash := []byte(v.Get("hash"))
+			hash[0] ^= 1
+			v.Set("hash", string(hash))
+			return v.Encode()
+		}, true},
+		{"tampered user", func() string {
+			v := signedInitData(testBotToken, now, user)
+			v.Set("user", `{"id":1,"first_name":"Mallory"}`)
+			return v.Encode()
+		}, true},
+		{"tampered auth_date", func() string {
+			v := signedInitData(testBotToken, now.Add(-48*time.Hour), user)
+			v.Set("auth_date", strconv.FormatInt(now.Unix(), 10))
+			return v.Encode()
+		}, true},
+		{"missing hash", func() string {
+			v := signedInitData(testBotToken, now, user)
+			v.Del("hash")
+			return v.Encode()
+		}, true},
+		{"malformed hash", func() string {
+			v := signedInitData(testBotToken, now, user)
+			v.Set("hash", "not-hex")
+			return v.Encode()
+		}, true},
+		{"missing user", func() string {
+			return signedInitData(testBotToken, now, "").Encode()
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyInitData(tt.initData(), testBotToken, initDataMaxAge, now)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInitData) {
+					t.Fatalf("VerifyInitData() error = %v, want %v", err, ErrInvalidInitData)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyInitData() error = %v", err)
+			}
+			if got.ID != 279058397 || got.Username != "vdkfrost" {
+				t.Errorf("VerifyInitData() user = %+v", got)
+			}
+		})
+	}
+}
+
+// tgUsers пользователи по Telegram ID
+type tgUsers struct {
+	userRepo.UserRepository
+	users map[int64]db.User
+}
+
+func (r tgUsers) FindByTGID(tgid int64) (*db.User, error) {
+	u, ok := r.users[tgid]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &u, nil
+}
+
+// issuedKeys выпущенные ключи без сохранения
+type issuedKeys struct {
+	Auth
+	keys []db.APIKey
+}
+
+func (a *issuedKeys) Issue(key *db.APIKey) (string, error) {
+	a.keys = append(a.keys, *key)
+	return "smk_test", nil
+}
+
+func TestWebAppLogin(t *testing.T) {
+	users := tgUsers{users: map[int64]db.User{279058397: {ID: "u1", TGID: 279058397}}}
+	auth := &issuedKeys{}
+	s := NewWebAppAuth(testBotToken, auth, users)
+
+	initData := signedInitData(testBotToken, time.Now(), `{"id":279058397,"first_name":"Vladislav"}`).Encode()
+	session, err := s.Login(initData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Token != "smk_test" || session.User.ID != "u1" {
+		t.Errorf("Login() = %+v", session)
+	}
+
+	// ключ сессии — только к данным участника и с ограниченным сроком, чтобы очистка его удалила
+	if len(auth.keys) != 1 {
+		t.Fatalf("issued %d keys, want 1", len(auth.keys))
+	}
+	key := auth.keys[0]
+	if key.Scope != db.KeyScopeMember || key.UserID == nil || *key.UserID != "u1" {
+		t.Errorf("issued key = %+v", key)
+	}
+	if key.ExpiresAt == nil || !key.ExpiresAt.Equal(session.ExpiresAt) ||
+		key.ExpiresAt.After(time.Now().Add(webAppSessionTTL)) {
+		t.Errorf("key expires at %v, session at %v", key.ExpiresAt, session.ExpiresAt)
+	}
+
+	unknown := signedInitData(testBotToken, time.Now(), `{"id":42,"first_name":"Stranger"}`).Encode()
+	if _, err := s.Login(unknown); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Login() unknown user error = %v, want %v", err, ErrUserNotFound)
+	}
+	if _, err := s.Login(initData + "x"); !errors.Is(err, ErrInvalidInitData) {
+		t.Errorf("Login() tampered error = %v, want %v", err, ErrInvalidInitData)
+	}
+}
@@ -0,0 +1,147 @@
+// Package apierr модель ошибок REST API: HTTP-статус, машиночитаемый код и тело ответа
+// application/problem+json (RFC 7807). Общий для сервера и клиента pkg/client.
+package apierr
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ContentType тип тела ответа с ошибкой
+const ContentType = "application/problem+json"
+
+// Code машиночитаемый код ошибки; в отличие от текста не меняется между версиями
+type Code string
+
+// Общие коды: подставляются по HTTP-статусу, если точного кода нет
+const (
+	CodeInvalidRequest   Code = "invalid_request"
+	CodeInvalidQuery     Code = "invalid_query" // неверный фильтр, сортировка или курсор списка
+	CodeUnauthorized     Code = "unauthorized"
+	CodeForbidden        Code = "forbidden"
+	CodeNotFound         Code = "not_found"
+	CodeMethodNotAllowed Code = "method_not_allowed"
+	CodeConflict         Code = "conflict"
+	CodeUnprocessable    Code = "unprocessable"
+	CodeTooLarge         Code = "request_too_large"
+	CodeInternal         Code = "internal"
+	CodeUnavailable      Code = "unavailable"
+)
+
+// Сущность не найдена (404)
+const (
+	CodeUserNotFound             Code = "user_not_found"
+	CodeSubscriptionNotFound     Code = "subscription_not_found"
+	CodeUserSubscriptionNotFound Code = "user_subscription_not_found"
+	CodeSettingsNotFound         Code = "settings_not_found"
+	CodePaymentNotFound          Code = "payment_not_found"
+	CodeInvoiceNotFound          Code = "invoice_not_found"
+	CodePaymentClaimNotFound     Code = "payment_claim_not_found"
+	CodePricingRuleNotFound      Code = "pricing_rule_not_found"
+	CodeAPIKeyNotFound           Code = "api_key_not_found"
+	CodeCurrencyNotFound         Code = "currency_not_found"
+	CodeCurrencyRateNotFound     Code = "currency_rate_not_found"
+)
+
+// Конфликт с текущим состоянием (409)
+const (
+	CodeDuplicateTGID             Code = "duplicate_tg_id"
+	CodeDuplicateServiceName      Code = "duplicate_service_name"
+	CodeDuplicateUserSubscription Code = "duplicate_user_subscription"
+	CodeSettingsExist             Code = "settings_exist"
+	CodeCurrencyExists            Code = "currency_exists"
+	CodeInvoiceExists             Code = "invoice_exists"
+	CodeInvalidInvoiceTransition  Code = "invalid_invoice_transition"
+	CodePaymentClaimResolved      Code = "payment_claim_resolved"
+	CodeMembershipEnded           Code = "membership_ended"
+	CodeBalanceNotZero            Code = "balance_not_zero"
+	CodeIdempotencyKeyInProgress  Code = "idempotency_key_in_progress"
+)
+
+// Неверные данные (400, 401, 422)
+const (
+	CodeUnsupportedCurrency   Code = "unsupported_currency"
+	CodeInvalidCurrency       Code = "invalid_currency"
+	CodeInvalidSplit          Code = "invalid_split"
+	CodeInvalidPricingRule    Code = "invalid_pricing_rule"
+	CodeInvalidPaymentClaim   Code = "invalid_payment_claim"
+	CodeInvalidAdjustment     Code = "invalid_adjustment"
+	CodeInvalidLeaveDate      Code = "invalid_leave_date"
+	CodeInvalidQuietHours     Code = "invalid_quiet_hours"
+	CodeInvalidAPIKey         Code = "invalid_api_key"
+	CodeInvalidInitData       Code = "invalid_init_data"
+	CodePaymentNotCalculated  Code = "payment_not_calculated"
+	CodeExchangeRateNotFound  Code = "exchange_rate_not_found"
+	CodeInvalidIdempotencyKey Code = "invalid_idempotency_key"
+	CodeIdempotencyKeyReused  Code = "idempotency_key_reused" // ключ уже использован с другим запросом
+	CodeAmountOutOfRange      Code = "amount_out_of_range"    // сумма или курс не помещаются в расчёт
+)
+
+// Problem тело ответа с ошибкой (RFC 7807) с расширением code
+type Problem struct {
+	Type     string `json:"type"`  // всегда about:blank: смысл ошибки передаёт Code
+	Title    string `json:"title"` // текст HTTP-статуса
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"` // путь запроса
+	Code     Code   `json:"code"`
+}
+
+// Error ошибка обработчика с HTTP-статусом и кодом
+type Error struct {
+	Status int
+	Code   Code
+	Detail string // текст для клиента
+}
+
+// New создаёт ошибку API; пустой code заменяется общим кодом статуса
+func New(status int, code Code, detail string) *Error {
+	if code == "" {
+		code = DefaultCode(status)
+	}
+	return &Error{Status: status, Code: code, Detail: detail}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.Status, e.Code, e.Detail)
+}
+
+// Problem тело ответа для запроса по пути instance
+func (e *Error) Problem(instance string) Problem {
+	return Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(e.Status),
+		Status:   e.Status,
+		Detail:   e.Detail,
+		Instance: instance,
+		Code:     e.Code,
+	}
+}
+
+// DefaultCode общий код для HTTP-статуса
+func DefaultCode(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodeTooLarge
+	case http.StatusUnprocessableEntity:
+		return CodeUnprocessable
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeInvalidRequest
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+// GetMonthlyProfit возвращает прибыль за месяц в валюте currency (пусто — RUB)
+func (c *Client) GetMonthlyProfit(ctx context.Context, adminUserID string, year, month int, currency db.Currency) (*ProfitStats, error) {
+	q := url.Values{}
+	setString(q, "currency", string(currency))
+	var stats ProfitStats
+	path := endpoint("admin", adminUserID, "profit", "monthly", strconv.Itoa(year), strconv.Itoa(month))
+	if err := c.get(ctx, path, q, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetTotalProfit возвращает прибыль за всё время в валюте currency (пусто — RUB)
+func (c *Client) GetTotalProfit(ctx context.Context, adminUserID string, currency db.Currency) (*ProfitStats, error) {
+	q := url.Values{}
+	setString(q, "currency", string(currency))
+	var stats ProfitStats
+	if err := c.get(ctx, endpoint("admin", adminUserID, "profit", "total"), q, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetUserProfitStats возвращает прибыль по пользователям за период
+func (c *Client) GetUserProfitStats(ctx context.Context, adminUserID string, from, to time.Time) ([]UserProfitStats, error) {
+	q := url.Values{}
+	q.Set("from", from.Format(time.RFC3339))
+	q.Set("to", to.Format(time.RFC3339))
+	var stats []UserProfitStats
+	if err := c.get(ctx, endpoint("admin", adminUserID, "profit", "users"), q, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// GetSubscriptionProfitStats возвращает прибыль по подпискам за период в валюте currency (пусто — RUB)
+func (c *Client) GetSubscriptionProfitStats(ctx context.Context, adminUserID string, from, to time.Time, currency db.Currency) ([]SubscriptionProfitStats, error) {
+	q := url.Values{}
+	q.Set("from", from.Format(time.RFC3339))
+	q.Set("to", to.Format(time.RFC3339))
+	setString(q, "currency", string(currency))
+	var stats []SubscriptionProfitStats
+	if err := c.get(ctx, endpoint("admin", adminUserID, "profit", "subscriptions"), q, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// ListPricingRules возвращает правила ценообразования, по умолчанию в порядке применения
+func (c *Client) ListPricingRules(ctx context.Context, adminUserID string, filter PricingRuleFilter) (*Page[db.PricingRule], error) {
+	q := url.Values{}
+	setString(q, "scope", string(filter.Scope))
+	setString(q, "user_id", filter.UserID)
+	setString(q, "subscription_id", filter.SubscriptionID)
+	filter.apply(q)
+	return getPage[db.PricingRule](ctx, c, endpoint("admin", adminUserID, "pricing_rules"), q)
+}
+
+// CreatePricingRule добавляет правило ценообразования
+func (c *Client) CreatePricingRule(ctx context.Context, adminUserID string, req PricingRuleRequest) (*db.PricingRule, error) {
+	var rule db.PricingRule
+	if err := c.post(ctx, endpoint("admin", adminUserID, "pricing_rules"), req, &rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// GetPricingRule возвращает правило ценообразования
+func (c *Client) GetPricingRule(ctx context.Context, adminUserID, id string) (*db.PricingRule, error) {
+	var rule db.PricingRule
+	if err := c.get(ctx, endpoint("admin", adminUserID, "pricing_rules", id), nil, &rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// UpdatePricingRule меняет переданные поля правила
+func (c *Client) UpdatePricingRule(ctx context.Context, adminUserID, id string, req PricingRuleRequest) (*db.PricingRule, error) {
+	var rule db.PricingRule
+	if err := c.patch(ctx, endpoint("admin", adminUserID, "pricing_rules", id), req, &rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// DeletePricingRule удаляет правило ценообразования
+func (c *Client) DeletePricingRule(ctx context.Context, adminUserID, id string) error {
+	return c.delete(ctx, endpoint("admin", adminUserID, "pricing_rules", id))
+}
+
+// ListAPIKeys возвращает ключи доступа пользователя; пустой userID — все ключи
+func (c *Client) ListAPIKeys(ctx context.Context, adminUserID, userID string, opts ListOptions) (*Page[db.APIKey], error) {
+	q := url.Values{}
+	setString(q, "user_id", userID)
+	opts.apply(q)
+	return getPage[db.APIKey](ctx, c, endpoint("admin", adminUserID, "api_keys"), q)
+}
+
+// CreateAPIKey выпускает ключ доступа; сам ключ есть только в ответе
+func (c *Client) CreateAPIKey(ctx context.Context, adminUserID string, req CreateAPIKeyRequest) (*IssuedAPIKey, error) {
+	var issued IssuedAPIKey
+	if err := c.post(ctx, endpoint("admin", adminUserID, "api_keys"), req, &issued); err != nil {
+		return nil, err
+	}
+	return &issued, nil
+}
+
+// RevokeAPIKey отзывает ключ доступа
+func (c *Client) RevokeAPIKey(ctx context.Context, adminUserID, id string) error {
+	return c.delete(ctx, endpoint("admin", adminUserID, "api_keys", id))
+}
+
+// ListAudit возвращает журнал изменений, новые первыми
+func (c *Client) ListAudit(ctx context.Context, adminUserID string, filter AuditFilter) (*Page[db.AuditEntry], error) {
+	q := url.Values{}
+	setString(q, "entity", string(filter.Entity))
+	setString(q, "entity_id", filter.EntityID)
+	setString(q, "action", string(filter.Action))
+	setString(q, "actor_user_id", filter.ActorUserID)
+	setTime(q, "created_at[gte]", filter.From)
+	setTime(q, "created_at[lte]", filter.To)
+	filter.apply(q)
+	return getPage[db.AuditEntry](ctx, c, endpoint("admin", adminUserID, "audit"), q)
+}
@@ -0,0 +1,191 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+// CalculatePayment рассчитывает платёж пользователя по подписке на дату списания
+// dueDate; нулевая дата — завтра
+func (c *Client) CalculatePayment(ctx context.Context, userID, subscriptionID string, dueDate time.Time) (*PaymentAmount, error) {
+	q := url.Values{}
+	setDate(q, "due_date", dueDate)
+	var calc PaymentAmount
+	if err := c.get(ctx, endpoint("calculate", userID, subscriptionID), q, &calc); err != nil {
+		return nil, err
+	}
+	return &calc, nil
+}
+
+// CreatePayment регистрирует платёж пользователя и гасит им счёт
+func (c *Client) CreatePayment(ctx context.Context, userID string, req CreatePaymentRequest) (*db.PaymentLog, error) {
+	var payment db.PaymentLog
+	if err := c.post(ctx, endpoint("users", userID, "payments"), req, &payment); err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// ListUserPayments возвращает платежи пользователя
+func (c *Client) ListUserPayments(ctx context.Context, userID string, filter PaymentFilter) (*Page[db.PaymentLog], error) {
+	return c.listPayments(ctx, endpoint("users", userID, "payments"), filter)
+}
+
+// ListSubscriptionPayments возвращает платежи по подписке
+func (c *Client) ListSubscriptionPayments(ctx context.Context, subscriptionID string, filter PaymentFilter) (*Page[db.PaymentLog], error) {
+	return c.listPayments(ctx, endpoint("subscriptions", subscriptionID, "payments"), filter)
+}
+
+// ListPayments возвращает платежи всех пользователей
+func (c *Client) ListPayments(ctx context.Context, filter PaymentFilter) (*Page[db.PaymentLog], error) {
+	return c.listPayments(ctx, endpoint("payments"), filter)
+}
+
+func (c *Client) listPayments(ctx context.Context, path string, filter PaymentFilter) (*Page[db.PaymentLog], error) {
+	q := url.Values{}
+	setTime(q, "from", filter.From)
+	setTime(q, "to", filter.To)
+	filter.apply(q)
+	return getPage[db.PaymentLog](ctx, c, path, q)
+}
+
+// CreatePaymentClaim отправляет сообщение участника об оплате на подтверждение
+func (c *Client) CreatePaymentClaim(ctx context.Context, userID string, req CreatePaymentClaimRequest) (*db.PaymentClaim, error) {
+	var claim db.PaymentClaim
+	if err := c.post(ctx, endpoint("users", userID, "payment_claims"), req, &claim); err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// ListUserPaymentClaims возвращает сообщения пользователя об оплате
+func (c *Client) ListUserPaymentClaims(ctx context.Context, userID string, filter PaymentClaimFilter) (*Page[db.PaymentClaim], error) {
+	filter.UserID = ""
+	return c.listPaymentClaims(ctx, endpoint("users", userID, "payment_claims"), filter)
+}
+
+// PaymentClaimQueue возвращает очередь сообщений об оплате, старые первыми
+func (c *Client) PaymentClaimQueue(ctx context.Context, adminUserID string, filter PaymentClaimFilter) (*Page[db.PaymentClaim], error) {
+	return c.listPaymentClaims(ctx, endpoint("admin", adminUserID, "payment_claims"), filter)
+}
+
+func (c *Client) listPaymentClaims(ctx context.Context, path string, filter PaymentClaimFilter) (*Page[db.PaymentClaim], error) {
+	q := url.Values{}
+	setString(q, "user_id", filter.UserID)
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, st := range filter.Statuses {
+			statuses[i] = string(st)
+		}
+		q.Set("status", strings.Join(statuses, ","))
+	}
+	filter.apply(q)
+	return getPage[db.PaymentClaim](ctx, c, path, q)
+}
+
+// GetPaymentClaim возвращает сообщение об оплате
+func (c *Client) GetPaymentClaim(ctx context.Context, adminUserID, claimID string) (*db.PaymentClaim, error) {
+	var claim db.PaymentClaim
+	if err := c.get(ctx, endpoint("admin", adminUserID, "payment_claims", claimID), nil, &claim); err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// ApprovePaymentClaim подтверждает сообщение об оплате: API регистрирует платёж.
+// Уже рассмотренное сообщение — StatusCode(err) == 409.
+func (c *Client) ApprovePaymentClaim(ctx context.Context, adminUserID, claimID string) (*db.PaymentClaim, error) {
+	var claim db.PaymentClaim
+	if err := c.post(ctx, endpoint("admin", adminUserID, "payment_claims", claimID, "approve"), nil, &claim); err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// RejectPaymentClaim отклоняет сообщение об оплате с причиной.
+// Уже рассмотренное сообщение — StatusCode(err) == 409.
+func (c *Client) RejectPaymentClaim(ctx context.Context, adminUserID, claimID, reason string) (*db.PaymentClaim, error) {
+	var claim db.PaymentClaim
+	body := map[string]string{"reason": reason}
+	if err := c.post(ctx, endpoint("admin", adminUserID, "payment_claims", claimID, "reject"), body, &claim); err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// CreateInvoice создаёт черновик счёта, при req.Issue — сразу выставляет
+func (c *Client) CreateInvoice(ctx context.Context, userID string, req CreateInvoiceRequest) (*db.Invoice, error) {
+	var inv db.Invoice
+	if err := c.post(ctx, endpoint("users", userID, "invoices"), req, &inv); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// ListUserInvoices возвращает счета пользователя
+func (c *Client) ListUserInvoices(ctx context.Context, userID string, filter InvoiceFilter) (*Page[db.Invoice], error) {
+	filter.UserID = ""
+	return c.listInvoices(ctx, endpoint("users", userID, "invoices"), filter)
+}
+
+// ListInvoices возвращает счета всех пользователей
+func (c *Client) ListInvoices(ctx context.Context, filter InvoiceFilter) (*Page[db.Invoice], error) {
+	return c.listInvoices(ctx, endpoint("invoices"), filter)
+}
+
+func (c *Client) listInvoices(ctx context.Context, path string, filter InvoiceFilter) (*Page[db.Invoice], error) {
+	q := url.Values{}
+	setString(q, "user_id", filter.UserID)
+	setString(q, "subscription_id", filter.SubscriptionID)
+	setString(q, "status", strings.Join(filter.Statuses, ","))
+	setDate(q, "from", filter.From)
+	setDate(q, "to", filter.To)
+	filter.apply(q)
+	return getPage[db.Invoice](ctx, c, path, q)
+}
+
+// GetInvoice возвращает счёт
+func (c *Client) GetInvoice(ctx context.Context, id string) (*db.Invoice, error) {
+	var inv db.Invoice
+	if err := c.get(ctx, endpoint("invoices", id), nil, &inv); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// IssueInvoice выставляет черновик счёта, фиксируя суммы и курс
+func (c *Client) IssueInvoice(ctx context.Context, id string) (*db.Invoice, error) {
+	var inv db.Invoice
+	if err := c.post(ctx, endpoint("invoices", id, "issue"), nil, &inv); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// VoidInvoice аннулирует счёт
+func (c *Client) VoidInvoice(ctx context.Context, id, reason string) (*db.Invoice, error) {
+	var inv db.Invoice
+	body := map[string]string{"reason": reason}
+	if err := c.post(ctx, endpoint("invoices", id, "void"), body, &inv); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// AdjustBalance ручная корректировка баланса участника; amount в сотых долях его валюты расчётов:
+// > 0 — в пользу участника, < 0 — доначисление
+func (c *Client) AdjustBalance(ctx context.Context, adminUserID, userID string, amount int64, description string) (*db.LedgerEntry, error) {
+	body := struct {
+		Amount      int64  `json:"amount"`
+		Description string `json:"description,omitempty"`
+	}{amount, description}
+	var entry db.LedgerEntry
+	if err := c.post(ctx, endpoint("admin", adminUserID, "users", userID, "adjustments"), body, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
@@ -0,0 +1,297 @@
+// Package client типизированный Go-клиент REST API, описанного в api/openapi.json.
+// Им пользуется Telegram-бот; подходит и для внешних инструментов и скриптов.
+//
+// Сущности возвращаются моделями pkg/db, ответы сервисов (расчёт платежа, выписка,
+// аналитика) — типами этого пакета. Суммы в копейках — int64 в сотых долях валюты,
+// цены и курсы — money.Decimal.
+//
+// Ошибки API возвращаются как *Error; ErrorCode отдаёт машиночитаемый код из
+// pkg/apierr, по которому удобно ветвиться вместо разбора текста.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/apierr"
+)
+
+// DateLayout формат дат без времени в запросах (YYYY-MM-DD)
+const DateLayout = "2006-01-02"
+
+// defaultTimeout таймаут запроса клиента, созданного New
+const defaultTimeout = 30 * time.Second
+
+// Client клиент REST API
+type Client struct {
+	BaseURL    string // адрес сервера без /api, например http://localhost:8080
+	HTTPClient *http.Client
+}
+
+// New создаёт клиент; apiKey отправляется с каждым запросом в заголовке Authorization
+func New(baseURL, apiKey string) *Client {
+	return &Client{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{
+			Timeout:   defaultTimeout,
+			Transport: &authTransport{apiKey: apiKey, base: http.DefaultTransport},
+		},
+	}
+}
+
+// authTransport добавляет ключ доступа в заголовок Authorization
+type authTransport struct {
+	apiKey string
+	base   http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTripper не должен менять исходный запрос
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	return t.base.RoundTrip(req)
+}
+
+// headerActorTGID заголовок, которым ключ сервиса сообщает, от имени какого
+// администратора из Telegram вносится изменение
+const headerActorTGID = "X-Actor-TG-ID"
+
+type actorKey struct{}
+
+// WithActor возвращает контекст, запросы с которым выполняются от имени пользователя
+// с Telegram ID tgID: сервер записывает его в журнал аудита. Нужен ключам сервиса,
+// например боту; у ключа пользователя сервер знает владельца сам.
+func WithActor(ctx context.Context, tgID int64) context.Context {
+	return context.WithValue(ctx, actorKey{}, tgID)
+}
+
+// headerIdempotencyKey заголовок, по которому сервер выполняет POST-запрос один раз
+const headerIdempotencyKey = "Idempotency-Key"
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey возвращает контекст, POST-запросы с которым отправляются с ключом
+// идемпотентности key: повтор с тем же ключом и телом получает первый ответ сервера,
+// а не создаёт запись второй раз. Ключ с другим телом — ErrorCode(err) == "idempotency_key_reused".
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// Error ответ API с кодом ошибки
+type Error struct {
+	StatusCode int
+	Code       apierr.Code // машиночитаемый код из application/problem+json
+	Message    string      // поле detail из ответа, иначе тело ответа целиком
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+// StatusCode HTTP-статус ошибки API; 0 — ошибка не от API (сеть, разбор ответа)
+func StatusCode(err error) int {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// ErrorCode машиночитаемый код ошибки API; пусто — ошибка не от API
+func ErrorCode(err error) apierr.Code {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return ""
+}
+
+// IsNotFound ответил ли API 404
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// Заголовки ответа со списком
+const (
+	headerTotalCount = "X-Total-Count"
+	headerNextCursor = "X-Next-Cursor"
+)
+
+// ListOptions страница, порядок и фильтры списка; нулевые значения — умолчания сервера
+type ListOptions struct {
+	Limit  int
+	Offset int    // только без Cursor
+	Cursor string // Page.NextCursor предыдущей страницы
+	Sort   string // поле сортировки, "-" — по убыванию: "-base_price"
+	// Filter фильтры по полям списка: "base_currency": {"USD"}, "amount[gte]": {"100000"}.
+	// Поля и операторы описаны в api/openapi.json.
+	Filter url.Values
+}
+
+func (o ListOptions) apply(q url.Values) {
+	if o.Limit > 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		q.Set("offset", strconv.Itoa(o.Offset))
+	}
+	setString(q, "cursor", o.Cursor)
+	setString(q, "sort", o.Sort)
+	for key, values := range o.Filter {
+		for _, v := range values {
+			q.Add(key, v)
+		}
+	}
+}
+
+// Page страница списка
+type Page[T any] struct {
+	Items      []T
+	Total      int64  // всего записей по фильтрам
+	NextCursor string // для ListOptions.Cursor; пусто — страница последняя
+}
+
+// getPage запрашивает страницу списка; число записей и курсор приходят в заголовках
+func getPage[T any](ctx context.Context, c *Client, path string, q url.Values) (*Page[T], error) {
+	page := &Page[T]{}
+	header, err := c.send(ctx, http.MethodGet, path, q, nil, &page.Items)
+	if err != nil {
+		return nil, err
+	}
+	page.Total, _ = strconv.ParseInt(header.Get(headerTotalCount), 10, 64)
+	page.NextCursor = header.Get(headerNextCursor)
+	return page, nil
+}
+
+// endpoint собирает путь /api/... из сегментов, экранируя каждый
+func endpoint(segments ...string) string {
+	var b strings.Builder
+	b.WriteString("/api")
+	for _, s := range segments {
+		b.WriteByte('/')
+		b.WriteString(url.PathEscape(s))
+	}
+	return b.String()
+}
+
+// do выполняет запрос и разбирает JSON-ответ в out; out == nil — ответ не нужен
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	_, err := c.send(ctx, method, path, query, body, out)
+	return err
+}
+
+// send как do, но возвращает и заголовки ответа
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body, out any) (http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if len(query) > 0 {
+		req.URL.RawQuery = query.Encode()
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json, "+apierr.ContentType)
+	if tgID, ok := ctx.Value(actorKey{}).(int64); ok {
+		req.Header.Set(headerActorTGID, strconv.FormatInt(tgID, 10))
+	}
+	if key, ok := ctx.Value(idempotencyKey{}).(string); ok && method == http.MethodPost {
+		req.Header.Set(headerIdempotencyKey, key)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, readError(resp)
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.Header, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return resp.Header, nil
+}
+
+// readError собирает *Error из ответа с кодом ошибки
+func readError(resp *http.Response) error {
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	apiErr := &Error{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(raw))}
+
+	var problem apierr.Problem
+	if json.Unmarshal(raw, &problem) == nil && problem.Code != "" {
+		apiErr.Code = problem.Code
+		if problem.Detail != "" {
+			apiErr.Message = problem.Detail
+		}
+		return apiErr
+	}
+	// ответ не в формате problem+json (прокси, старый сервер): код по статусу
+	apiErr.Code = apierr.DefaultCode(resp.StatusCode)
+	return apiErr
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	return c.do(ctx, http.MethodGet, path, query, nil, out)
+}
+
+func (c *Client) post(ctx context.Context, path string, body, out any) error {
+	return c.do(ctx, http.MethodPost, path, nil, body, out)
+}
+
+func (c *Client) put(ctx context.Context, path string, body, out any) error {
+	return c.do(ctx, http.MethodPut, path, nil, body, out)
+}
+
+func (c *Client) patch(ctx context.Context, path string, body, out any) error {
+	return c.do(ctx, http.MethodPatch, path, nil, body, out)
+}
+
+func (c *Client) delete(ctx context.Context, path string) error {
+	return c.do(ctx, http.MethodDelete, path, nil, nil, nil)
+}
+
+// setTime добавляет в запрос время в RFC 3339; нулевое время не передаётся
+func setTime(q url.Values, key string, t time.Time) {
+	if !t.IsZero() {
+		q.Set(key, t.Format(time.RFC3339))
+	}
+}
+
+// setDate добавляет в запрос дату YYYY-MM-DD; нулевая дата не передаётся
+func setDate(q url.Values, key string, t time.Time) {
+	if !t.IsZero() {
+		q.Set(key, t.Format(DateLayout))
+	}
+}
+
+// setString добавляет в запрос непустую строку
+func setString(q url.Values, key, value string) {
+	if value != "" {
+		q.Set(key, value)
+	}
+}
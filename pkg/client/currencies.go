@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/WhoYa/subscription-manager/pkg/money"
+)
+
+// ListCurrencies возвращает справочник валют; all — вместе с выключенными
+func (c *Client) ListCurrencies(ctx context.Context, all bool) ([]db.CurrencyInfo, error) {
+	q := url.Values{}
+	if all {
+		q.Set("all", "true")
+	}
+	var list []db.CurrencyInfo
+	if err := c.get(ctx, endpoint("currencies"), q, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// CreateCurrency добавляет валюту в справочник
+func (c *Client) CreateCurrency(ctx context.Context, adminUserID string, req CreateCurrencyRequest) (*db.CurrencyInfo, error) {
+	var info db.CurrencyInfo
+	if err := c.post(ctx, endpoint("admin", adminUserID, "currencies"), req, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// UpdateCurrency меняет валюту справочника
+func (c *Client) UpdateCurrency(ctx context.Context, adminUserID string, code db.Currency, req UpdateCurrencyRequest) (*db.CurrencyInfo, error) {
+	var info db.CurrencyInfo
+	if err := c.patch(ctx, endpoint("admin", adminUserID, "currencies", string(code)), req, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// CreateCurrencyRate добавляет курс
+func (c *Client) CreateCurrencyRate(ctx context.Context, req CreateCurrencyRateRequest) (*db.CurrencyRate, error) {
+	var rate db.CurrencyRate
+	if err := c.post(ctx, endpoint("currency_rates"), req, &rate); err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// ListCurrencyRates возвращает страницу курсов
+func (c *Client) ListCurrencyRates(ctx context.Context, opts ListOptions) (*Page[db.CurrencyRate], error) {
+	q := url.Values{}
+	opts.apply(q)
+	return getPage[db.CurrencyRate](ctx, c, endpoint("currency_rates"), q)
+}
+
+// GetCurrencyRate возвращает курс по ID
+func (c *Client) GetCurrencyRate(ctx context.Context, id string) (*db.CurrencyRate, error) {
+	var rate db.CurrencyRate
+	if err := c.get(ctx, endpoint("currency_rates", id), nil, &rate); err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// LatestCurrencyRate возвращает последний курс валюты
+func (c *Client) LatestCurrencyRate(ctx context.Context, currency db.Currency) (*db.CurrencyRate, error) {
+	var rate db.CurrencyRate
+	if err := c.get(ctx, endpoint("currency_rates", "latest", string(currency)), nil, &rate); err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// CurrencyRateHistory возвращает курсы валюты за период; нулевые from и to — последние 30 дней
+func (c *Client) CurrencyRateHistory(ctx context.Context, currency db.Currency, from, to time.Time) ([]db.CurrencyRate, error) {
+	q := url.Values{}
+	setTime(q, "from", from)
+	setTime(q, "to", to)
+	var rates []db.CurrencyRate
+	if err := c.get(ctx, endpoint("currency_rates", "history", string(currency)), q, &rates); err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// UpdateCurrencyRate меняет курс
+func (c *Client) UpdateCurrencyRate(ctx context.Context, id string, req UpdateCurrencyRateRequest) (*db.CurrencyRate, error) {
+	var rate db.CurrencyRate
+	if err := c.put(ctx, endpoint("currency_rates", id), req, &rate); err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// DeleteCurrencyRate удаляет курс
+func (c *Client) DeleteCurrencyRate(ctx context.Context, id string) error {
+	return c.delete(ctx, endpoint("currency_rates", id))
+}
+
+// Convert пересчитывает сумму между валютами по курсу на момент at; нулевое at — сейчас
+func (c *Client) Convert(ctx context.Context, amount money.Decimal, from, to db.Currency, at time.Time) (*ConversionResult, error) {
+	q := url.Values{}
+	q.Set("from", string(from))
+	q.Set("to", string(to))
+	q.Set("amount", amount.String())
+	setTime(q, "at", at)
+	var result ConversionResult
+	if err := c.get(ctx, endpoint("currency_rates", "convert"), q, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SetManualRate вводит курс валюты к рублю от имени администратора
+func (c *Client) SetManualRate(ctx context.Context, adminUserID string, rate RateInput) (*ManualRateResult, error) {
+	var result ManualRateResult
+	if err := c.post(ctx, endpoint("admin", adminUserID, "currency", "set"), rate, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SetRates вводит курсы нескольких валют; ошибки по отдельным валютам — в результатах
+func (c *Client) SetRates(ctx context.Context, adminUserID string, rates []RateInput) (*BulkRateResult, error) {
+	var result BulkRateResult
+	body := map[string][]RateInput{"rates": rates}
+	if err := c.post(ctx, endpoint("admin", adminUserID, "currency", "bulk"), body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetRatesStatus возвращает текущие курсы всех валют к рублю
+func (c *Client) GetRatesStatus(ctx context.Context, adminUserID string) (*RatesStatus, error) {
+	var status RatesStatus
+	if err := c.get(ctx, endpoint("admin", adminUserID, "currency", "status"), nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+// CreateSubscription создаёт подписку
+func (c *Client) CreateSubscription(ctx context.Context, req CreateSubscriptionRequest) (*db.Subscription, error) {
+	var sub db.Subscription
+	if err := c.post(ctx, endpoint("subscriptions"), req, &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// ListSubscriptions возвращает страницу подписок
+func (c *Client) ListSubscriptions(ctx context.Context, opts ListOptions) (*Page[db.Subscription], error) {
+	q := url.Values{}
+	opts.apply(q)
+	return getPage[db.Subscription](ctx, c, endpoint("subscriptions"), q)
+}
+
+// GetSubscription возвращает подписку по ID
+func (c *Client) GetSubscription(ctx context.Context, id string) (*db.Subscription, error) {
+	var sub db.Subscription
+	if err := c.get(ctx, endpoint("subscriptions", id), nil, &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// UpdateSubscription меняет переданные поля подписки
+func (c *Client) UpdateSubscription(ctx context.Context, id string, req UpdateSubscriptionRequest) (*db.Subscription, error) {
+	var sub db.Subscription
+	if err := c.patch(ctx, endpoint("subscriptions", id), req, &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// DeleteSubscription удаляет подписку
+func (c *Client) DeleteSubscription(ctx context.Context, id string) error {
+	return c.delete(ctx, endpoint("subscriptions", id))
+}
+
+// ListSubscriptionMembers возвращает участников подписки с их долями в цене
+func (c *Client) ListSubscriptionMembers(ctx context.Context, subscriptionID string) ([]db.UserSubscription, error) {
+	var list []db.UserSubscription
+	if err := c.get(ctx, endpoint("subscriptions", subscriptionID, "members"), nil, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetGlobalSettings возвращает глобальные настройки; ещё не созданы — IsNotFound(err)
+func (c *Client) GetGlobalSettings(ctx context.Context) (*db.GlobalSettings, error) {
+	var gs db.GlobalSettings
+	if err := c.get(ctx, endpoint("settings"), nil, &gs); err != nil {
+		return nil, err
+	}
+	return &gs, nil
+}
+
+// CreateGlobalSettings создаёт глобальные настройки
+func (c *Client) CreateGlobalSettings(ctx context.Context, req GlobalSettingsRequest) (*db.GlobalSettings, error) {
+	var gs db.GlobalSettings
+	if err := c.post(ctx, endpoint("settings"), req, &gs); err != nil {
+		return nil, err
+	}
+	return &gs, nil
+}
+
+// UpdateGlobalSettings меняет глобальные настройки
+func (c *Client) UpdateGlobalSettings(ctx context.Context, req GlobalSettingsRequest) (*db.GlobalSettings, error) {
+	var gs db.GlobalSettings
+	if err := c.put(ctx, endpoint("settings"), req, &gs); err != nil {
+		return nil, err
+	}
+	return &gs, nil
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+// ListTrashedUsers возвращает удалённых пользователей, недавно удалённые первыми.
+// Кроме полей списка пользователей фильтруется по deleted_at.
+func (c *Client) ListTrashedUsers(ctx context.Context, adminUserID string, opts ListOptions) (*Page[db.User], error) {
+	return listTrash[db.User](ctx, c, adminUserID, "users", opts)
+}
+
+// RestoreUser возвращает пользователя из корзины. Уже зарегистрирован новый пользователь
+// с тем же Telegram ID — ErrorCode(err) == "duplicate_tg_id".
+func (c *Client) RestoreUser(ctx context.Context, adminUserID, id string) (*db.User, error) {
+	return restoreTrash[db.User](ctx, c, adminUserID, "users", id)
+}
+
+// ListTrashedSubscriptions возвращает удалённые подписки, недавно удалённые первыми
+func (c *Client) ListTrashedSubscriptions(ctx context.Context, adminUserID string, opts ListOptions) (*Page[db.Subscription], error) {
+	return listTrash[db.Subscription](ctx, c, adminUserID, "subscriptions", opts)
+}
+
+// RestoreSubscription возвращает подписку из корзины. Уже есть подписка
+// с тем же названием — ErrorCode(err) == "duplicate_service_name".
+func (c *Client) RestoreSubscription(ctx context.Context, adminUserID, id string) (*db.Subscription, error) {
+	return restoreTrash[db.Subscription](ctx, c, adminUserID, "subscriptions", id)
+}
+
+// ListTrashedCurrencyRates возвращает удалённые курсы, недавно удалённые первыми
+func (c *Client) ListTrashedCurrencyRates(ctx context.Context, adminUserID string, opts ListOptions) (*Page[db.CurrencyRate], error) {
+	return listTrash[db.CurrencyRate](ctx, c, adminUserID, "currency_rates", opts)
+}
+
+// RestoreCurrencyRate возвращает курс из корзины
+func (c *Client) RestoreCurrencyRate(ctx context.Context, adminUserID, id string) (*db.CurrencyRate, error) {
+	return restoreTrash[db.CurrencyRate](ctx, c, adminUserID, "currency_rates", id)
+}
+
+// ListTrashedPricingRules возвращает удалённые правила ценообразования, недавно удалённые первыми
+func (c *Client) ListTrashedPricingRules(ctx context.Context, adminUserID string, opts ListOptions) (*Page[db.PricingRule], error) {
+	return listTrash[db.PricingRule](ctx, c, adminUserID, "pricing_rules", opts)
+}
+
+// RestorePricingRule возвращает правило ценообразования из корзины
+func (c *Client) RestorePricingRule(ctx context.Context, adminUserID, id string) (*db.PricingRule, error) {
+	return restoreTrash[db.PricingRule](ctx, c, adminUserID, "pricing_rules", id)
+}
+
+func listTrash[T any](ctx context.Context, c *Client, adminUserID, kind string, opts ListOptions) (*Page[T], error) {
+	q := url.Values{}
+	opts.apply(q)
+	return getPage[T](ctx, c, endpoint("admin", adminUserID, "trash", kind), q)
+}
+
+func restoreTrash[T any](ctx context.Context, c *Client, adminUserID, kind, id string) (*T, error) {
+	var restored T
+	if err := c.post(ctx, endpoint("admin", adminUserID, "trash", kind, id, "restore"), nil, &restored); err != nil {
+		return nil, err
+	}
+	return &restored, nil
+}
@@ -0,0 +1,401 @@
+package client
+
+import (
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/WhoYa/subscription-manager/pkg/money"
+)
+
+// Ответы API, которых нет среди моделей pkg/db. Имена совпадают со схемами api/openapi.json.
+
+// PaymentAmount расчёт суммы к оплате по подписке
+type PaymentAmount struct {
+	UserID         string        `json:"user_id"`
+	SubscriptionID string        `json:"subscription_id"`
+	Amount         int64         `json:"amount_kopecks"` // в сотых долях Currency
+	BaseKopecks    int64         `json:"base_kopecks"`
+	ProfitKopecks  int64         `json:"profit_kopecks"`
+	AmountRubles   money.Decimal `json:"amount_rubles"` // Amount в основных единицах Currency
+	BaseAmount     money.Decimal `json:"base_amount"`
+	ProfitAmount   money.Decimal `json:"profit_amount"`
+	Currency       db.Currency   `json:"currency"`
+	SourceCurrency db.Currency   `json:"source_currency"`
+	ExchangeRate   money.Decimal `json:"exchange_rate"`
+	RatePath       []db.Currency `json:"rate_path"`
+	MarkupPercent  money.Decimal `json:"markup_percent"`
+	SplitMode      db.SplitMode  `json:"split_mode"`
+	SharePercent   money.Decimal `json:"share_percent"`
+	DueDate        time.Time     `json:"due_date"`
+	Breakdown      []PriceStep   `json:"breakdown"`
+	Proration      *Proration    `json:"proration,omitempty"`
+}
+
+// PriceStep строка расшифровки цены: как одно правило изменило цену
+type PriceStep struct {
+	RuleID   string              `json:"rule_id,omitempty"`
+	Name     string              `json:"name"`
+	Kind     db.PricingRuleKind  `json:"kind"`
+	Scope    db.PricingRuleScope `json:"scope"`
+	Priority int                 `json:"priority"`
+	Value    money.Decimal       `json:"value"`
+	Before   money.Decimal       `json:"before"`
+	After    money.Decimal       `json:"after"`
+	Delta    money.Decimal       `json:"delta"`
+}
+
+// Proration неполный цикл при вступлении или выходе посреди периода
+type Proration struct {
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	PeriodDays  int       `json:"period_days"`
+	BilledFrom  time.Time `json:"billed_from"`
+	BilledTo    time.Time `json:"billed_to"` // не включительно
+	BilledDays  int       `json:"billed_days"`
+}
+
+// MembershipEnd итог выхода участника из подписки
+type MembershipEnd struct {
+	UserSubscription *db.UserSubscription `json:"user_subscription"`
+	Credit           int64                `json:"credit"` // возврат в сотых долях валюты участника
+	InvoiceIDs       []string             `json:"invoice_ids"`
+}
+
+// Statement баланс участника и выписка за период; суммы в сотых долях Currency
+type Statement struct {
+	UserID         string          `json:"user_id"`
+	Currency       db.Currency     `json:"currency"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	Balance        int64           `json:"balance"` // > 0 переплата, < 0 долг
+	OpeningBalance int64           `json:"opening_balance"`
+	ClosingBalance int64           `json:"closing_balance"`
+	TotalDebit     int64           `json:"total_debit"`
+	TotalCredit    int64           `json:"total_credit"`
+	Entries        []StatementLine `json:"entries"`
+}
+
+// StatementLine проводка выписки с нарастающим итогом
+type StatementLine struct {
+	db.LedgerEntry
+	Balance int64 `json:"balance"` // баланс после проводки
+}
+
+// Conversion курс пересчёта между двумя валютами
+type Conversion struct {
+	From db.Currency   `json:"from"`
+	To   db.Currency   `json:"to"`
+	Rate money.Decimal `json:"rate"`
+	Path []db.Currency `json:"path"`
+	AsOf time.Time     `json:"as_of"`
+}
+
+// ConversionResult пересчитанная сумма
+type ConversionResult struct {
+	Amount     money.Decimal `json:"amount"`
+	Result     money.Decimal `json:"result"`
+	Conversion Conversion    `json:"conversion"`
+}
+
+// ProfitStats прибыль за период
+type ProfitStats struct {
+	TotalProfit   money.Decimal `json:"total_profit"`
+	TotalPayments int64         `json:"total_payments"`
+	AverageProfit money.Decimal `json:"average_profit"`
+	Currency      db.Currency   `json:"currency"`
+	Period        string        `json:"period"`
+}
+
+// UserProfitStats прибыль по пользователю в его валюте расчётов
+type UserProfitStats struct {
+	UserID       string        `json:"user_id"`
+	Username     string        `json:"username"`
+	TotalProfit  money.Decimal `json:"total_profit"`
+	PaymentCount int64         `json:"payment_count"`
+	Currency     db.Currency   `json:"currency"`
+}
+
+// SubscriptionProfitStats прибыль по подписке
+type SubscriptionProfitStats struct {
+	SubscriptionID string        `json:"subscription_id"`
+	ServiceName    string        `json:"service_name"`
+	TotalProfit    money.Decimal `json:"total_profit"`
+	PaymentCount   int64         `json:"payment_count"`
+	Currency       db.Currency   `json:"currency"`
+}
+
+// Principal владелец ключа запроса
+type Principal struct {
+	KeyID  string         `json:"key_id"`
+	Name   string         `json:"name"`
+	Scope  db.APIKeyScope `json:"scope"`
+	UserID string         `json:"user_id,omitempty"` // пусто у ключа сервиса
+}
+
+// Session ключ, выданный участнику при входе через Telegram Mini App
+type Session struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	User      db.User   `json:"user"`
+}
+
+// ReminderSettings настройки напоминаний участника и последние напоминания
+type ReminderSettings struct {
+	Enabled    bool                  `json:"enabled"`
+	QuietHours string                `json:"quiet_hours"` // "22-9"; пусто — общие тихие часы сервера
+	History    []db.ReminderDelivery `json:"history,omitempty"`
+}
+
+// IssuedAPIKey выпущенный ключ; Key больше нигде не показывается
+type IssuedAPIKey struct {
+	Key    string    `json:"key"`
+	APIKey db.APIKey `json:"api_key"`
+}
+
+// ManualRateResult курс, введённый администратором
+type ManualRateResult struct {
+	Message  string        `json:"message"`
+	Currency string        `json:"currency"`
+	Rate     money.Decimal `json:"rate"`
+	Source   string        `json:"source"`
+	SetAt    time.Time     `json:"set_at"`
+	ID       string        `json:"id"`
+}
+
+// BulkRateItem результат по одной валюте из BulkRatesRequest
+type BulkRateItem struct {
+	Currency string        `json:"currency"`
+	Success  bool          `json:"success"`
+	Rate     money.Decimal `json:"rate"`
+	ID       string        `json:"id,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// BulkRateResult результат ввода нескольких курсов
+type BulkRateResult struct {
+	Message   string         `json:"message"`
+	Results   []BulkRateItem `json:"results"`
+	Processed int            `json:"processed"`
+	SetAt     time.Time      `json:"set_at"`
+}
+
+// RateStatus текущий курс валюты к рублю
+type RateStatus struct {
+	Available bool          `json:"available"`
+	Rate      money.Decimal `json:"rate"`
+	Source    string        `json:"source,omitempty"`
+	SetAt     time.Time     `json:"set_at"`
+	AgeHours  float64       `json:"age_hours"`
+	Message   string        `json:"message,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// RatesStatus текущие курсы всех валют
+type RatesStatus struct {
+	Rates     map[db.Currency]RateStatus `json:"rates"`
+	CheckedAt time.Time                  `json:"checked_at"`
+}
+
+// Тела запросов. В Update*-запросах nil-поля не меняются.
+
+// CreateUserRequest новый пользователь
+type CreateUserRequest struct {
+	TGID               int64       `json:"tg_id"`
+	Username           string      `json:"username,omitempty"`
+	Fullname           string      `json:"fullname,omitempty"`
+	IsAdmin            bool        `json:"is_admin,omitempty"`
+	SettlementCurrency db.Currency `json:"settlement_currency,omitempty"` // по умолчанию RUB
+}
+
+// UpdateUserRequest изменение пользователя
+type UpdateUserRequest struct {
+	Username           *string      `json:"username,omitempty"`
+	Fullname           *string      `json:"fullname,omitempty"`
+	IsAdmin            *bool        `json:"is_admin,omitempty"`
+	SettlementCurrency *db.Currency `json:"settlement_currency,omitempty"` // только при нулевом балансе
+}
+
+// CreateSubscriptionRequest новая подписка
+type CreateSubscriptionRequest struct {
+	ServiceName       string         `json:"service_name"`
+	BasePrice         money.Decimal  `json:"base_price"`
+	BaseCurrency      db.Currency    `json:"base_currency"`
+	PeriodDays        int            `json:"period_days"`
+	SplitMode         db.SplitMode   `json:"split_mode,omitempty"`
+	OwnerID           string         `json:"owner_id,omitempty"`
+	OwnerSharePercent *money.Decimal `json:"owner_share_percent,omitempty"`
+}
+
+// UpdateSubscriptionRequest изменение подписки
+type UpdateSubscriptionRequest struct {
+	ServiceName       *string        `json:"service_name,omitempty"`
+	IconURL           *string        `json:"icon_url,omitempty"`
+	BasePrice         *money.Decimal `json:"base_price,omitempty"`
+	BaseCurrency      *db.Currency   `json:"base_currency,omitempty"`
+	IsActive          *bool          `json:"is_active,omitempty"`
+	PeriodDays        *int           `json:"period_days,omitempty"`
+	SplitMode         *db.SplitMode  `json:"split_mode,omitempty"`
+	OwnerID           *string        `json:"owner_id,omitempty"` // пустая строка снимает владельца
+	OwnerSharePercent *money.Decimal `json:"owner_share_percent,omitempty"`
+}
+
+// CreateUserSubscriptionRequest подключение пользователя к подписке
+type CreateUserSubscriptionRequest struct {
+	SubscriptionID string         `json:"subscription_id"`
+	PricingMode    db.PricingMode `json:"pricing_mode"`
+	MarkupPercent  *money.Decimal `json:"markup_percent,omitempty"`
+	FixedFee       *money.Decimal `json:"fixed_fee,omitempty"`
+	AnchorDate     string         `json:"anchor_date,omitempty"` // DateLayout
+	JoinedAt       string         `json:"joined_at,omitempty"`   // DateLayout, по умолчанию сегодня
+	ShareWeight    *money.Decimal `json:"share_weight,omitempty"`
+}
+
+// UpdateUserSubscriptionRequest изменение цены участника
+type UpdateUserSubscriptionRequest struct {
+	PricingMode   *db.PricingMode `json:"pricing_mode,omitempty"`
+	MarkupPercent *money.Decimal  `json:"markup_percent,omitempty"`
+	FixedFee      *money.Decimal  `json:"fixed_fee,omitempty"`
+	ShareWeight   *money.Decimal  `json:"share_weight,omitempty"`
+}
+
+// CreatePaymentRequest регистрация платежа; без Amount сумма берётся из счёта или расчёта
+type CreatePaymentRequest struct {
+	SubscriptionID string         `json:"subscription_id"`
+	Amount         int64          `json:"amount,omitempty"`    // в сотых долях Currency
+	Currency       db.Currency    `json:"currency,omitempty"`  // по умолчанию валюта расчётов
+	RateUsed       *money.Decimal `json:"rate_used,omitempty"` // по умолчанию текущий курс
+	PaidAt         time.Time      `json:"paid_at"`
+	InvoiceID      string         `json:"invoice_id,omitempty"` // по умолчанию самый старый неоплаченный счёт
+}
+
+// CreatePaymentClaimRequest сообщение участника об оплате; без Currency — валюта расчётов участника
+type CreatePaymentClaimRequest struct {
+	SubscriptionID string           `json:"subscription_id"`
+	InvoiceID      string           `json:"invoice_id,omitempty"`
+	Amount         int64            `json:"amount"` // в сотых долях Currency
+	Currency       db.Currency      `json:"currency,omitempty"`
+	Method         db.PaymentMethod `json:"method"`
+	ReceiptFileID  string           `json:"receipt_file_id,omitempty"`
+	PaidAt         *time.Time       `json:"paid_at,omitempty"` // по умолчанию сейчас
+}
+
+// PaymentFilter фильтр платежей; без Limit сервер отдаёт все платежи за период
+type PaymentFilter struct {
+	From, To time.Time // по дате оплаты, включительно
+	ListOptions
+}
+
+// PaymentClaimFilter фильтр сообщений об оплате
+type PaymentClaimFilter struct {
+	UserID   string                  // только для PaymentClaimQueue
+	Statuses []db.PaymentClaimStatus // пусто: у участника — все, в очереди — pending
+	ListOptions
+}
+
+// CreateInvoiceRequest новый счёт; Issue — сразу выставить
+type CreateInvoiceRequest struct {
+	SubscriptionID string `json:"subscription_id"`
+	DueDate        string `json:"due_date,omitempty"` // DateLayout, по умолчанию сегодня
+	Issue          bool   `json:"issue,omitempty"`
+}
+
+// InvoiceFilter фильтр счетов
+type InvoiceFilter struct {
+	UserID         string // только для ListInvoices
+	SubscriptionID string
+	Statuses       []string  // статусы счёта или outstanding
+	From, To       time.Time // даты, To включительно
+	ListOptions
+}
+
+// GlobalSettingsRequest глобальная надбавка
+type GlobalSettingsRequest struct {
+	GlobalMarkupPercent money.Decimal `json:"global_markup_percent"`
+}
+
+// CreateCurrencyRequest новая валюта справочника
+type CreateCurrencyRequest struct {
+	Code        db.Currency `json:"code"`
+	Name        string      `json:"name,omitempty"`
+	Symbol      string      `json:"symbol,omitempty"`
+	MinorDigits *int        `json:"minor_digits,omitempty"` // по умолчанию 2
+	Enabled     *bool       `json:"enabled,omitempty"`      // по умолчанию true
+}
+
+// UpdateCurrencyRequest изменение валюты справочника
+type UpdateCurrencyRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Symbol      *string `json:"symbol,omitempty"`
+	MinorDigits *int    `json:"minor_digits,omitempty"`
+	Enabled     *bool   `json:"enabled,omitempty"`
+}
+
+// CreateCurrencyRateRequest новый курс; без QuoteCurrency курс к рублю
+type CreateCurrencyRateRequest struct {
+	Currency      db.Currency   `json:"currency"`
+	QuoteCurrency db.Currency   `json:"quote_currency,omitempty"`
+	Value         money.Decimal `json:"value"`
+	Source        db.RateSource `json:"source"`
+	FetchedAt     *time.Time    `json:"fetched_at,omitempty"` // по умолчанию сейчас
+}
+
+// UpdateCurrencyRateRequest изменение курса
+type UpdateCurrencyRateRequest struct {
+	Value     *money.Decimal `json:"value,omitempty"`
+	Source    *db.RateSource `json:"source,omitempty"`
+	FetchedAt *time.Time     `json:"fetched_at,omitempty"`
+}
+
+// RateInput курс валюты к рублю для ручного ввода
+type RateInput struct {
+	Currency db.Currency   `json:"currency"`
+	Rate     money.Decimal `json:"rate"`
+}
+
+// PricingRuleRequest создание и изменение правила ценообразования.
+// При создании обязательны Kind, Scope и Value; пустая строка в Currency,
+// ValidFrom и ValidTo снимает ограничение.
+type PricingRuleRequest struct {
+	Name           *string              `json:"name,omitempty"`
+	Kind           *db.PricingRuleKind  `json:"kind,omitempty"`
+	Scope          *db.PricingRuleScope `json:"scope,omitempty"`
+	UserID         *string              `json:"user_id,omitempty"`
+	SubscriptionID *string              `json:"subscription_id,omitempty"`
+	Value          *money.Decimal       `json:"value,omitempty"`
+	Currency       *string              `json:"currency,omitempty"`
+	Priority       *int                 `json:"priority,omitempty"`
+	ValidFrom      *string              `json:"valid_from,omitempty"` // DateLayout
+	ValidTo        *string              `json:"valid_to,omitempty"`   // DateLayout, не включительно
+}
+
+// PricingRuleFilter фильтр правил ценообразования
+type PricingRuleFilter struct {
+	Scope          db.PricingRuleScope
+	UserID         string
+	SubscriptionID string
+	ListOptions
+}
+
+// CreateAPIKeyRequest новый ключ доступа; UserID обязателен для scope member
+type CreateAPIKeyRequest struct {
+	Name      string         `json:"name"`
+	Scope     db.APIKeyScope `json:"scope"`
+	UserID    string         `json:"user_id,omitempty"`
+	ExpiresAt string         `json:"expires_at,omitempty"` // DateLayout, пусто — бессрочно
+}
+
+// UpdateReminderSettingsRequest изменение настроек напоминаний
+type UpdateReminderSettingsRequest struct {
+	Enabled    *bool   `json:"enabled,omitempty"`
+	QuietHours *string `json:"quiet_hours,omitempty"` // пустая строка — общие тихие часы сервера
+}
+
+// AuditFilter фильтр журнала аудита
+type AuditFilter struct {
+	Entity      db.AuditEntity
+	EntityID    string
+	Action      db.AuditAction
+	ActorUserID string
+	From, To    time.Time // по времени изменения, включительно
+	ListOptions
+}
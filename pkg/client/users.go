@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+)
+
+// Healthz проверяет, что API доступен
+func (c *Client) Healthz(ctx context.Context) error {
+	return c.get(ctx, endpoint("healthz"), nil, nil)
+}
+
+// TelegramLogin обменивает initData Telegram Mini App на ключ участника
+func (c *Client) TelegramLogin(ctx context.Context, initData string) (*Session, error) {
+	var session Session
+	body := map[string]string{"init_data": initData}
+	if err := c.post(ctx, endpoint("auth", "telegram"), body, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Me возвращает владельца ключа клиента
+func (c *Client) Me(ctx context.Context) (*Principal, error) {
+	var p Principal
+	if err := c.get(ctx, endpoint("auth", "me"), nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// CreateUser создаёт пользователя
+func (c *Client) CreateUser(ctx context.Context, req CreateUserRequest) (*db.User, error) {
+	var user db.User
+	if err := c.post(ctx, endpoint("users"), req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ListUsers возвращает страницу пользователей
+func (c *Client) ListUsers(ctx context.Context, opts ListOptions) (*Page[db.User], error) {
+	q := url.Values{}
+	opts.apply(q)
+	return getPage[db.User](ctx, c, endpoint("users"), q)
+}
+
+// GetUser возвращает пользователя по ID
+func (c *Client) GetUser(ctx context.Context, id string) (*db.User, error) {
+	var user db.User
+	if err := c.get(ctx, endpoint("users", id), nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindUserByTGID находит пользователя по Telegram ID; не найден — IsNotFound(err)
+func (c *Client) FindUserByTGID(ctx context.Context, tgid int64) (*db.User, error) {
+	var user db.User
+	if err := c.get(ctx, endpoint("users", "tgid", strconv.FormatInt(tgid, 10)), nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateUser меняет переданные поля пользователя
+func (c *Client) UpdateUser(ctx context.Context, id string, req UpdateUserRequest) (*db.User, error) {
+	var user db.User
+	if err := c.patch(ctx, endpoint("users", id), req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DeleteUser удаляет пользователя
+func (c *Client) DeleteUser(ctx context.Context, id string) error {
+	return c.delete(ctx, endpoint("users", id))
+}
+
+// GetBalance возвращает баланс и выписку за период; нулевые from и to — с начала истории и по сегодня
+func (c *Client) GetBalance(ctx context.Context, userID string, from, to time.Time) (*Statement, error) {
+	q := url.Values{}
+	setDate(q, "from", from)
+	setDate(q, "to", to)
+	var st Statement
+	if err := c.get(ctx, endpoint("users", userID, "balance"), q, &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// GetReminderSettings возвращает настройки и последние напоминания пользователя
+func (c *Client) GetReminderSettings(ctx context.Context, userID string) (*ReminderSettings, error) {
+	var settings ReminderSettings
+	if err := c.get(ctx, endpoint("users", userID, "reminders"), nil, &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// UpdateReminderSettings меняет настройки напоминаний пользователя
+func (c *Client) UpdateReminderSettings(ctx context.Context, userID string, req UpdateReminderSettingsRequest) (*ReminderSettings, error) {
+	var settings ReminderSettings
+	if err := c.put(ctx, endpoint("users", userID, "reminders"), req, &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// CreateUserSubscription подключает пользователя к подписке
+func (c *Client) CreateUserSubscription(ctx context.Context, userID string, req CreateUserSubscriptionRequest) (*db.UserSubscription, error) {
+	var us db.UserSubscription
+	if err := c.post(ctx, endpoint("users", userID, "subscriptions"), req, &us); err != nil {
+		return nil, err
+	}
+	return &us, nil
+}
+
+// ListUserSubscriptions возвращает подписки пользователя вместе с подпиской и пользователем
+func (c *Client) ListUserSubscriptions(ctx context.Context, userID string, opts ListOptions) (*Page[db.UserSubscription], error) {
+	q := url.Values{}
+	opts.apply(q)
+	return getPage[db.UserSubscription](ctx, c, endpoint("users", userID, "subscriptions"), q)
+}
+
+// UpdateUserSubscription меняет цену участника в подписке
+func (c *Client) UpdateUserSubscription(ctx context.Context, userID, id string, req UpdateUserSubscriptionRequest) (*db.UserSubscription, error) {
+	var us db.UserSubscription
+	if err := c.patch(ctx, endpoint("users", userID, "subscriptions", id), req, &us); err != nil {
+		return nil, err
+	}
+	return &us, nil
+}
+
+// LeaveSubscription отмечает выход участника с даты date (нулевая — сегодня)
+func (c *Client) LeaveSubscription(ctx context.Context, userID, id string, date time.Time) (*MembershipEnd, error) {
+	body := map[string]string{}
+	if !date.IsZero() {
+		body["date"] = date.Format(DateLayout)
+	}
+	var result MembershipEnd
+	if err := c.post(ctx, endpoint("users", userID, "subscriptions", id, "leave"), body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteUserSubscription удаляет привязку пользователя к подписке
+func (c *Client) DeleteUserSubscription(ctx context.Context, userID, id string) error {
+	return c.delete(ctx, endpoint("users", userID, "subscriptions", id))
+}
@@ -1,24 +1,22 @@
 package db
 
-import "database/sql/driver"
+import (
+	"database/sql/driver"
 
-// Currency
-type Currency string
+	"github.com/WhoYa/subscription-manager/pkg/money"
+)
+
+// Currency ISO 4217 код валюты. Допустимые валюты хранятся в таблице currencies (CurrencyInfo),
+// константы ниже — только те, на которые опирается код.
+type Currency = money.Currency
 
 const (
 	USD Currency = "USD"
 	EUR Currency = "EUR"
-	RUB Currency = "RUB"
+	RUB Currency = "RUB" // базовая валюта: к ней котируются курсы по умолчанию
+	KZT Currency = "KZT"
 )
 
-func (c *Currency) Scan(value any) error {
-	*c = Currency(value.(string))
-	return nil
-}
-func (c Currency) Value() (driver.Value, error) {
-	return string(c), nil
-}
-
 // PricingMode
 type PricingMode string
 
@@ -37,6 +35,25 @@ func (c PricingMode) Value() (driver.Value, error) {
 	return string(c), nil
 }
 
+// SplitMode как цена подписки делится между участниками
+type SplitMode string
+
+const (
+	SplitNone       SplitMode = "none"        // каждый участник платит полную цену
+	SplitEqual      SplitMode = "equal"       // поровну
+	SplitWeighted   SplitMode = "weighted"    // пропорционально UserSubscription.ShareWeight
+	SplitOwnerFixed SplitMode = "owner_fixed" // владелец платит OwnerSharePercent, остальные делят остаток поровну
+)
+
+func (c *SplitMode) Scan(value any) error {
+	*c = SplitMode(value.(string))
+	return nil
+}
+
+func (c SplitMode) Value() (driver.Value, error) {
+	return string(c), nil
+}
+
 // RateSource
 type RateSource string
 
@@ -54,3 +71,204 @@ func (c *RateSource) Scan(value any) error {
 func (c RateSource) Value() (driver.Value, error) {
 	return string(c), nil
 }
+
+// InvoiceStatus
+type InvoiceStatus string
+
+const (
+	InvoiceDraft         InvoiceStatus = "draft"          // черновик, суммы ещё не зафиксированы
+	InvoiceIssued        InvoiceStatus = "issued"         // выставлен, суммы зафиксированы
+	InvoicePartiallyPaid InvoiceStatus = "partially_paid" // оплачен частично
+	InvoicePaid          InvoiceStatus = "paid"           // оплачен полностью
+	InvoiceOverdue       InvoiceStatus = "overdue"        // просрочен
+	InvoiceVoided        InvoiceStatus = "voided"         // аннулирован
+)
+
+// OutstandingInvoiceStatuses статусы счетов, по которым ещё ждём деньги
+var OutstandingInvoiceStatuses = []InvoiceStatus{InvoiceIssued, InvoicePartiallyPaid, InvoiceOverdue}
+
+func (c *InvoiceStatus) Scan(value any) error {
+	*c = InvoiceStatus(value.(string))
+	return nil
+}
+
+func (c InvoiceStatus) Value() (driver.Value, error) {
+	return string(c), nil
+}
+
+// LedgerEntryType
+type LedgerEntryType string
+
+const (
+	LedgerCharge     LedgerEntryType = "charge"     // начисление по выставленному счёту (дебет)
+	LedgerPayment    LedgerEntryType = "payment"    // поступивший платёж (кредит)
+	LedgerAdjustment LedgerEntryType = "adjustment" // ручная корректировка администратором
+	LedgerReversal   LedgerEntryType = "reversal"   // сторно начисления при аннулировании счёта
+)
+
+func (c *LedgerEntryType) Scan(value any) error {
+	*c = LedgerEntryType(value.(string))
+	return nil
+}
+
+func (c LedgerEntryType) Value() (driver.Value, error) {
+	return string(c), nil
+}
+
+// PricingRuleKind что правило делает с ценой
+type PricingRuleKind string
+
+const (
+	RuleMarkupPercent   PricingRuleKind = "markup_percent"   // + Value % к текущей цене
+	RuleDiscountPercent PricingRuleKind = "discount_percent" // − Value % от текущей цены
+	RuleFixedPrice      PricingRuleKind = "fixed_price"      // цена = Value
+	RuleMinAmount       PricingRuleKind = "min_amount"       // цена не меньше Value
+	RuleRoundUp         PricingRuleKind = "round_up"         // округление вверх до кратного Value (10 — до десятков рублей)
+)
+
+func (c *PricingRuleKind) Scan(value any) error {
+	*c = PricingRuleKind(value.(string))
+	return nil
+}
+
+func (c PricingRuleKind) Value() (driver.Value, error) {
+	return string(c), nil
+}
+
+// PricingRuleScope к кому относится правило
+type PricingRuleScope string
+
+const (
+	ScopeGlobal           PricingRuleScope = "global"            // все участники и подписки
+	ScopeSubscription     PricingRuleScope = "subscription"      // все участники одной подписки
+	ScopeUser             PricingRuleScope = "user"              // все подписки одного участника
+	ScopeUserSubscription PricingRuleScope = "user_subscription" // участник в одной подписке
+)
+
+func (c *PricingRuleScope) Scan(value any) error {
+	*c = PricingRuleScope(value.(string))
+	return nil
+}
+
+func (c PricingRuleScope) Value() (driver.Value, error) {
+	return string(c), nil
+}
+
+// APIKeyScope права ключа доступа к API
+type APIKeyScope string
+
+const (
+	KeyScopeAdmin  APIKeyScope = "admin"  // все методы API
+	KeyScopeMember APIKeyScope = "member" // чтение данных своего пользователя
+)
+
+func (c *APIKeyScope) Scan(value any) error {
+	*c = APIKeyScope(value.(string))
+	return nil
+}
+
+func (c APIKeyScope) Value() (driver.Value, error) {
+	return string(c), nil
+}
+
+// ReminderStatus состояние отправки напоминания
+type ReminderStatus string
+
+const (
+	ReminderPending ReminderStatus = "pending" // отправляется; после сбоя повторно не отправляется
+	ReminderSent    ReminderStatus = "sent"
+	ReminderFailed  ReminderStatus = "failed" // Telegram вернул ошибку, будет повтор
+)
+
+func (c *ReminderStatus) Scan(value any) error {
+	*c = ReminderStatus(value.(string))
+	return nil
+}
+
+func (c ReminderStatus) Value() (driver.Value, error) {
+	return string(c), nil
+}
+
+// PaymentMethod способ оплаты, указанный участником
+type PaymentMethod string
+
+const (
+	PaymentCard     PaymentMethod = "card"     // перевод по номеру карты
+	PaymentTransfer PaymentMethod = "transfer" // банковский перевод, СБП
+	PaymentCash     PaymentMethod = "cash"
+	PaymentOther    PaymentMethod = "other"
+)
+
+func (c *PaymentMethod) Scan(value any) error {
+	*c = PaymentMethod(value.(string))
+	return nil
+}
+
+func (c PaymentMethod) Value() (driver.Value, error) {
+	return string(c), nil
+}
+
+// PaymentClaimStatus состояние сообщения участника об оплате: pending → approved | rejected
+type PaymentClaimStatus string
+
+const (
+	ClaimPending  PaymentClaimStatus = "pending"
+	ClaimApproved PaymentClaimStatus = "approved" // платёж зарегистрирован
+	ClaimRejected PaymentClaimStatus = "rejected"
+)
+
+func (c *PaymentClaimStatus) Scan(value any) error {
+	*c = PaymentClaimStatus(value.(string))
+	return nil
+}
+
+func (c PaymentClaimStatus) Value() (driver.Value, error) {
+	return string(c), nil
+}
+
+// AuditEntity тип изменённой записи в журнале аудита
+type AuditEntity string
+
+const (
+	AuditUser             AuditEntity = "user"
+	AuditSubscription     AuditEntity = "subscription"
+	AuditUserSubscription AuditEntity = "user_subscription"
+	AuditPayment          AuditEntity = "payment"
+	AuditPaymentClaim     AuditEntity = "payment_claim"
+	AuditInvoice          AuditEntity = "invoice"
+	AuditLedgerEntry      AuditEntity = "ledger_entry"
+	AuditGlobalSettings   AuditEntity = "global_settings"
+	AuditCurrency         AuditEntity = "currency"
+	AuditCurrencyRate     AuditEntity = "currency_rate"
+	AuditPricingRule      AuditEntity = "pricing_rule"
+	AuditAPIKey           AuditEntity = "api_key"
+	AuditReminderSettings AuditEntity = "reminder_settings"
+)
+
+func (c *AuditEntity) Scan(value any) error {
+	*c = AuditEntity(value.(string))
+	return nil
+}
+
+func (c AuditEntity) Value() (driver.Value, error) {
+	return string(c), nil
+}
+
+// AuditAction что сделали с записью
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore" // восстановление из корзины
+)
+
+func (c *AuditAction) Scan(value any) error {
+	*c = AuditAction(value.(string))
+	return nil
+}
+
+func (c AuditAction) Value() (driver.Value, error) {
+	return string(c), nil
+}
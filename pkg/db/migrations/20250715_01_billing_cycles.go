@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func BillingCycles() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20250715_01_billing_cycles",
+		Migrate: func(tx *gorm.DB) error {
+			// только anchor_date: AutoMigrate текущей структуры добавил бы и колонки поздних
+			// миграций, в том числе NOT NULL joined_at, которую не заполнить в непустой таблице
+			if err := addColumns(tx, &db.UserSubscription{}, "AnchorDate"); err != nil {
+				return err
+			}
+			if err := tx.AutoMigrate(&db.Invoice{}); err != nil {
+				return err
+			}
+			// для уже существующих привязок циклы отсчитываются от даты создания
+			return tx.Exec(`
+                UPDATE user_subscriptions SET anchor_date = created_at WHERE anchor_date IS NULL;
+            `).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&db.Invoice{}); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&db.UserSubscription{}, "AnchorDate")
+		},
+	}
+}
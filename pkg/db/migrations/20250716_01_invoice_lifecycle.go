@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func InvoiceLifecycle() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20250716_01_invoice_lifecycle",
+		Migrate: func(tx *gorm.DB) error {
+			// индекс пересоздаётся частичным: аннулированный счёт не занимает цикл
+			if err := tx.Exec(`DROP INDEX IF EXISTS invoice_cycle_uq;`).Error; err != nil {
+				return err
+			}
+			if err := tx.AutoMigrate(&db.Invoice{}, &db.PaymentLog{}); err != nil {
+				return err
+			}
+			return tx.Exec(`
+                UPDATE invoices SET status = 'issued', issued_at = created_at WHERE status = 'pending';
+            `).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&db.PaymentLog{}, "InvoiceID")
+		},
+	}
+}
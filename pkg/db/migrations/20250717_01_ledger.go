@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func Ledger() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20250717_01_ledger",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&db.LedgerEntry{}); err != nil {
+				return err
+			}
+			// переносим уже выставленные счета и полученные платежи, чтобы баланс сошёлся;
+			// записи без валюты появились до мультивалютности и считаются в рублях
+			if err := tx.Exec(`
+                INSERT INTO ledger_entries (id, user_id, type, debit, credit, currency, invoice_id, occurred_at, created_at)
+                SELECT gen_random_uuid(), user_id, 'charge', amount, 0, COALESCE(currency::text, 'RUB'), id, COALESCE(issued_at, created_at), NOW()
+                FROM invoices WHERE status NOT IN ('draft', 'voided');
+            `).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`
+                INSERT INTO ledger_entries (id, user_id, type, debit, credit, currency, invoice_id, payment_log_id, occurred_at, created_at)
+                SELECT gen_random_uuid(), user_id, 'payment', 0, amount, COALESCE(currency::text, 'RUB'), invoice_id, id, paid_at, NOW()
+                FROM payment_logs;
+            `).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&db.LedgerEntry{})
+		},
+	}
+}
@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// CurrencyRateHistory индекс для поиска курса на дату
+func CurrencyRateHistory() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20250718_01_currency_rate_history",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.Exec(`
+                CREATE INDEX IF NOT EXISTS currency_rates_currency_fetched_idx ON currency_rates (currency, fetched_at);
+            `).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Exec(`DROP INDEX IF EXISTS currency_rates_currency_fetched_idx;`).Error
+		},
+	}
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func MultiCurrency() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20250719_01_multi_currency",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.Exec(`ALTER TYPE currency_enum ADD VALUE IF NOT EXISTS 'KZT';`).Error; err != nil {
+				return err
+			}
+			if err := tx.AutoMigrate(&db.User{}, &db.CurrencyRate{}); err != nil {
+				return err
+			}
+			// платежи без валюты вносились до выбора валюты при вводе — они в рублях;
+			// указанная валюта платежа остаётся как есть
+			return tx.Exec(`
+                UPDATE payment_logs SET currency = 'RUB' WHERE currency IS NULL;
+            `).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			// значение enum в PostgreSQL удалить нельзя, KZT остаётся в типе
+			if err := tx.Migrator().DropColumn(&db.CurrencyRate{}, "QuoteCurrency"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&db.User{}, "SettlementCurrency")
+		},
+	}
+}
@@ -0,0 +1,95 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// currencyColumns колонки с кодом валюты и их значения по умолчанию
+var currencyColumns = []struct {
+	table, column, def string
+}{
+	{"users", "settlement_currency", "RUB"},
+	{"subscriptions", "base_currency", ""},
+	{"payment_logs", "currency", ""},
+	{"currency_rates", "currency", ""},
+	{"currency_rates", "quote_currency", "RUB"},
+	{"invoices", "currency", ""},
+}
+
+func CurrencyRegistry() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20250720_01_currency_registry",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&db.CurrencyInfo{}); err != nil {
+				return err
+			}
+			// валюты, которые уже были в currency_enum, и несколько выключенных заготовок
+			if err := tx.Exec(`
+                INSERT INTO currencies (code, name, symbol, minor_digits, enabled, created_at, updated_at) VALUES
+                    ('RUB', 'Российский рубль', '₽', 2, true, now(), now()),
+                    ('USD', 'Доллар США', '$', 2, true, now(), now()),
+                    ('EUR', 'Евро', '€', 2, true, now(), now()),
+                    ('KZT', 'Казахстанский тенге', '₸', 2, true, now(), now()),
+                    ('TRY', 'Турецкая лира', '₺', 2, false, now(), now()),
+                    ('GEL', 'Грузинский лари', '₾', 2, false, now(), now()),
+                    ('ARS', 'Аргентинское песо', '$', 2, false, now(), now())
+                ON CONFLICT (code) DO NOTHING;
+            `).Error; err != nil {
+				return err
+			}
+
+			// enum-колонки → varchar с внешним ключом на справочник
+			for _, col := range currencyColumns {
+				stmts := []string{
+					fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT`, col.table, col.column),
+					fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN %s TYPE varchar(3) USING %s::text`, col.table, col.column, col.column),
+					fmt.Sprintf(`ALTER TABLE %s ADD CONSTRAINT fk_%s_%s FOREIGN KEY (%s) REFERENCES currencies (code)`,
+						col.table, col.table, col.column, col.column),
+				}
+				if col.def != "" {
+					stmts = append(stmts, fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN %s SET DEFAULT '%s'`, col.table, col.column, col.def))
+				}
+				for _, stmt := range stmts {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+			}
+			return tx.Exec(`DROP TYPE IF EXISTS currency_enum;`).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			// тип восстанавливается со всеми валютами справочника, чтобы данные влезли обратно
+			if err := tx.Exec(`
+                DO $$
+                BEGIN
+                    EXECUTE (
+                        SELECT 'CREATE TYPE currency_enum AS ENUM (' || string_agg(quote_literal(code), ',' ORDER BY code) || ')'
+                        FROM currencies
+                    );
+                END$$;
+            `).Error; err != nil {
+				return err
+			}
+			for _, col := range currencyColumns {
+				stmts := []string{
+					fmt.Sprintf(`ALTER TABLE %s DROP CONSTRAINT IF EXISTS fk_%s_%s`, col.table, col.table, col.column),
+					fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT`, col.table, col.column),
+					fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN %s TYPE currency_enum USING %s::currency_enum`, col.table, col.column, col.column),
+				}
+				if col.def != "" {
+					stmts = append(stmts, fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN %s SET DEFAULT '%s'`, col.table, col.column, col.def))
+				}
+				for _, stmt := range stmts {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+			}
+			return tx.Migrator().DropTable(&db.CurrencyInfo{})
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// decimalColumns float-колонки с деньгами, курсами и процентами и их новый numeric(precision, scale)
+var decimalColumns = []struct {
+	table, column    string
+	precision, scale int
+}{
+	{"user_subscriptions", "markup_percent", 7, 2},
+	{"user_subscriptions", "fixed_fee", 12, 2},
+	{"global_settings", "global_markup_percent", 7, 2},
+	{"currency_rates", "value", 18, 8},
+	{"payment_logs", "rate_used", 18, 8},
+	{"invoices", "rate_used", 18, 8},
+	{"invoices", "markup_percent", 7, 2},
+}
+
+func DecimalMoney() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20250721_01_decimal_money",
+		Migrate: func(tx *gorm.DB) error {
+			for _, col := range decimalColumns {
+				stmt := fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN %s TYPE numeric(%d,%d) USING round(%s::numeric, %d)`,
+					col.table, col.column, col.precision, col.scale, col.column, col.scale)
+				if err := tx.Exec(stmt).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			for _, col := range decimalColumns {
+				stmt := fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN %s TYPE double precision`, col.table, col.column)
+				if err := tx.Exec(stmt).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
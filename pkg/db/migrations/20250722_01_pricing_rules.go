@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func PricingRules() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20250722_01_pricing_rules",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&db.PricingRule{}); err != nil {
+				return err
+			}
+			// валюта суммовых правил — из справочника, как и во всех остальных таблицах
+			return tx.Exec(`
+                ALTER TABLE pricing_rules
+                    ADD CONSTRAINT fk_pricing_rules_currency FOREIGN KEY (currency) REFERENCES currencies (code);
+            `).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&db.PricingRule{})
+		},
+	}
+}
@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func FamilySplit() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20250723_01_family_split",
+		Migrate: func(tx *gorm.DB) error {
+			// существующие подписки остаются в режиме none: каждый платит полную цену
+			if err := addColumns(tx, &db.Subscription{}, "SplitMode", "OwnerID", "OwnerSharePercent"); err != nil {
+				return err
+			}
+			if err := addColumns(tx, &db.UserSubscription{}, "ShareWeight"); err != nil {
+				return err
+			}
+			return tx.Exec(`
+                ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS fk_subscriptions_owner;
+                ALTER TABLE subscriptions
+                    ADD CONSTRAINT fk_subscriptions_owner FOREIGN KEY (owner_id) REFERENCES users (id);
+            `).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropColumn(&db.UserSubscription{}, "ShareWeight"); err != nil {
+				return err
+			}
+			for _, field := range []string{"OwnerSharePercent", "OwnerID", "SplitMode"} {
+				if err := tx.Migrator().DropColumn(&db.Subscription{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
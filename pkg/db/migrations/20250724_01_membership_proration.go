@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func MembershipProration() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20250724_01_membership_proration",
+		Migrate: func(tx *gorm.DB) error {
+			// существующие участники считаются состоящими с первого цикла, чтобы
+			// уже начатые циклы не стали неполными задним числом; NOT NULL — только
+			// после заполнения. На новой базе колонка уже создана по структуре.
+			stmts := []string{
+				`ALTER TABLE user_subscriptions ADD COLUMN IF NOT EXISTS joined_at timestamptz`,
+				`UPDATE user_subscriptions SET joined_at = COALESCE(LEAST(anchor_date, created_at), now()) WHERE joined_at IS NULL`,
+				`ALTER TABLE user_subscriptions ALTER COLUMN joined_at SET NOT NULL`,
+			}
+			for _, stmt := range stmts {
+				if err := tx.Exec(stmt).Error; err != nil {
+					return err
+				}
+			}
+			return addColumns(tx, &db.UserSubscription{}, "LeftAt")
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropColumn(&db.UserSubscription{}, "LeftAt"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&db.UserSubscription{}, "JoinedAt")
+		},
+	}
+}
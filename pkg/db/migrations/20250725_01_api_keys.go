@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func APIKeys() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20250725_01_api_keys",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&db.APIKey{}); err != nil {
+				return err
+			}
+			return tx.Exec(`
+                ALTER TABLE api_keys
+                    ADD CONSTRAINT fk_api_keys_user FOREIGN KEY (user_id) REFERENCES users (id);
+            `).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&db.APIKey{})
+		},
+	}
+}
@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func Reminders() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20250726_01_reminders",
+		Migrate: func(tx *gorm.DB) error {
+			// по умолчанию напоминания включены, тихие часы — общие
+			if err := addColumns(tx, &db.User{}, "RemindersOff", "QuietFrom", "QuietTo"); err != nil {
+				return err
+			}
+			if err := tx.AutoMigrate(&db.ReminderDelivery{}); err != nil {
+				return err
+			}
+			return tx.Exec(`
+                ALTER TABLE reminder_deliveries
+                    ADD CONSTRAINT fk_reminder_deliveries_user FOREIGN KEY (user_id) REFERENCES users (id),
+                    ADD CONSTRAINT fk_reminder_deliveries_user_subscription FOREIGN KEY (user_subscription_id) REFERENCES user_subscriptions (id);
+            `).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&db.ReminderDelivery{}); err != nil {
+				return err
+			}
+			for _, field := range []string{"QuietTo", "QuietFrom", "RemindersOff"} {
+				if err := tx.Migrator().DropColumn(&db.User{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func PaymentClaims() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20250727_01_payment_claims",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&db.PaymentClaim{}); err != nil {
+				return err
+			}
+			return tx.Exec(`
+                ALTER TABLE payment_claims
+                    ADD CONSTRAINT fk_payment_claims_user FOREIGN KEY (user_id) REFERENCES users (id),
+                    ADD CONSTRAINT fk_payment_claims_subscription FOREIGN KEY (subscription_id) REFERENCES subscriptions (id),
+                    ADD CONSTRAINT fk_payment_claims_payment_log FOREIGN KEY (payment_log_id) REFERENCES payment_logs (id);
+            `).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&db.PaymentClaim{})
+		},
+	}
+}
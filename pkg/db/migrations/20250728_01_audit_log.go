@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func AuditLog() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20250728_01_audit_log",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&db.AuditEntry{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&db.AuditEntry{})
+		},
+	}
+}
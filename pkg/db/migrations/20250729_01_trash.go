@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// Trash Telegram ID уникален только среди неудалённых пользователей,
+// чтобы пользователь из корзины не мешал зарегистрироваться заново
+func Trash() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20250729_01_trash",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.Exec(`
+                ALTER TABLE users DROP CONSTRAINT IF EXISTS users_tg_id_key;
+                DROP INDEX IF EXISTS idx_users_tg_id;
+                CREATE UNIQUE INDEX idx_users_tg_id ON users (tg_id) WHERE deleted_at IS NULL;
+            `).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Exec(`
+                DROP INDEX IF EXISTS idx_users_tg_id;
+                CREATE UNIQUE INDEX idx_users_tg_id ON users (tg_id);
+            `).Error
+		},
+	}
+}
@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func IdempotencyKeys() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20250730_01_idempotency_keys",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&db.IdempotencyKey{}); err != nil {
+				return err
+			}
+			return tx.Exec(`
+                ALTER TABLE idempotency_keys
+                    ADD CONSTRAINT fk_idempotency_keys_api_key FOREIGN KEY (api_key_id) REFERENCES api_keys (id) ON DELETE CASCADE;
+            `).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&db.IdempotencyKey{})
+		},
+	}
+}
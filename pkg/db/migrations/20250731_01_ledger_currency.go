@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"github.com/WhoYa/subscription-manager/pkg/db"
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func LedgerCurrency() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20250731_01_ledger_currency",
+		Migrate: func(tx *gorm.DB) error {
+			stmts := []string{
+				`ALTER TABLE ledger_entries ADD COLUMN IF NOT EXISTS currency varchar(3);`,
+				// валюта проводки — валюта платежа или счёта, корректировки — в валюте расчётов участника
+				`UPDATE ledger_entries le SET currency = pl.currency
+                    FROM payment_logs pl
+                    WHERE le.currency IS NULL AND le.payment_log_id = pl.id AND pl.currency IS NOT NULL;`,
+				`UPDATE ledger_entries le SET currency = i.currency
+                    FROM invoices i
+                    WHERE le.currency IS NULL AND le.invoice_id = i.id AND i.currency IS NOT NULL;`,
+				`UPDATE ledger_entries le SET currency = u.settlement_currency
+                    FROM users u
+                    WHERE le.currency IS NULL AND le.user_id = u.id;`,
+				`UPDATE ledger_entries SET currency = 'RUB' WHERE currency IS NULL;`,
+				`ALTER TABLE ledger_entries ALTER COLUMN currency SET NOT NULL;`,
+				`ALTER TABLE ledger_entries DROP CONSTRAINT IF EXISTS fk_ledger_entries_currency;`,
+				`ALTER TABLE ledger_entries
+                    ADD CONSTRAINT fk_ledger_entries_currency FOREIGN KEY (currency) REFERENCES currencies (code);`,
+			}
+			for _, stmt := range stmts {
+				if err := tx.Exec(stmt).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&db.LedgerEntry{}, "Currency")
+		},
+	}
+}
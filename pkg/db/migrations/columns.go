@@ -0,0 +1,19 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// addColumns добавляет колонки полей модели, которых ещё нет в таблице.
+// Ранние миграции создают таблицы по текущим структурам из pkg/db, поэтому на новой базе
+// колонки поздних миграций уже есть, а на старой — ещё нет.
+func addColumns(tx *gorm.DB, model any, fields ...string) error {
+	m := tx.Migrator()
+	for _, field := range fields {
+		if m.HasColumn(model, field) {
+			continue
+		}
+		if err := m.AddColumn(model, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,84 +1,311 @@
 package db
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
+	"github.com/WhoYa/subscription-manager/pkg/money"
 	"gorm.io/gorm"
 )
 
+// AmountDigits суммы в копейках (Amount, BaseAmount, Debit, …) хранятся в сотых долях валюты
+const AmountDigits = 2
+
 type User struct {
-	ID            string         `gorm:"type:uuid;primaryKey"`
-	TGID          int64          `gorm:"uniqueIndex;not null"`
-	Username      string         `gorm:"size:200"`
-	Fullname      string         `gorm:"size:200"`
-	IsAdmin       bool           `gorm:"default:false"`
-	Subscriptions []Subscription `gorm:"many2many:user_subscriptions"`
-	Payments      []PaymentLog
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	DeletedAt     gorm.DeletedAt `gorm:"index"`
+	ID                 string         `gorm:"type:uuid;primaryKey" json:"id"`
+	TGID               int64          `gorm:"uniqueIndex:idx_users_tg_id,where:deleted_at IS NULL;not null" json:"tg_id"` // уникален среди неудалённых пользователей
+	Username           string         `gorm:"size:200" json:"username"`
+	Fullname           string         `gorm:"size:200" json:"fullname"`
+	IsAdmin            bool           `gorm:"default:false" json:"is_admin"`
+	SettlementCurrency Currency       `gorm:"type:varchar(3);not null;default:'RUB'" json:"settlement_currency"` // валюта счетов и баланса участника
+	RemindersOff       bool           `gorm:"not null;default:false" json:"reminders_off"`                       // участник отказался от напоминаний об оплате
+	QuietFrom          *int           `gorm:"type:smallint" json:"quiet_from"`                                   // начало тихих часов (час 0–23); nil — общие тихие часы
+	QuietTo            *int           `gorm:"type:smallint" json:"quiet_to"`                                     // конец тихих часов, не включительно
+	Subscriptions      []Subscription `gorm:"many2many:user_subscriptions" json:"subscriptions,omitempty"`
+	Payments           []PaymentLog   `json:"payments,omitempty"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 }
 
 type Subscription struct {
-	ID           string   `gorm:"type:uuid;primaryKey"`
-	ServiceName  string   `gorm:"size:200"`
-	IconURL      string   `gorm:"size:800"`
-	BasePrice    float64  `gorm:"type:numeric(12,2)"`
-	BaseCurrency Currency `gorm:"type:currency_enum"`
-	IsActive     bool     `gorm:"default:true"`
-	Users        []User   `gorm:"many2many:user_subscriptions"`
-	PeriodDays   int      `gorm:"not null"`
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
-	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	ID           string        `gorm:"type:uuid;primaryKey" json:"id"`
+	ServiceName  string        `gorm:"size:200" json:"service_name"`
+	IconURL      string        `gorm:"size:800" json:"icon_url"`
+	BasePrice    money.Decimal `gorm:"type:numeric(12,2)" json:"base_price"`
+	BaseCurrency Currency      `gorm:"type:varchar(3)" json:"base_currency"`
+	IsActive     bool          `gorm:"default:true" json:"is_active"`
+	Users        []User        `gorm:"many2many:user_subscriptions" json:"users,omitempty"`
+	PeriodDays   int           `gorm:"not null" json:"period_days"`
+	// Семейный тариф: как BasePrice делится между участниками (см. SplitShares)
+	SplitMode         SplitMode      `gorm:"type:varchar(20);not null;default:'none'" json:"split_mode"`
+	OwnerID           *string        `gorm:"type:uuid" json:"owner_id,omitempty"`                    // владелец для SplitOwnerFixed
+	OwnerSharePercent money.Decimal  `gorm:"type:numeric(5,2);default:0" json:"owner_share_percent"` // доля владельца для SplitOwnerFixed, %
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 }
 
 type UserSubscription struct {
-	ID             string      `gorm:"type:uuid;primaryKey"`
-	UserID         string      `gorm:"type:uuid;not null;uniqueIndex:user_sub_uq"`
-	SubscriptionID string      `gorm:"type:uuid;not null;uniqueIndex:user_sub_uq"`
-	PricingMode    PricingMode `gorm:"type:pricing_mode_enum;default:'none'"`
-	MarkupPercent  float64     `gorm:"default:0"`
-	FixedFee       float64     `gorm:"default:0"`
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
+	ID             string        `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID         string        `gorm:"type:uuid;not null;uniqueIndex:user_sub_uq" json:"user_id"`
+	SubscriptionID string        `gorm:"type:uuid;not null;uniqueIndex:user_sub_uq" json:"subscription_id"`
+	PricingMode    PricingMode   `gorm:"type:pricing_mode_enum;default:'none'" json:"pricing_mode"`
+	MarkupPercent  money.Decimal `gorm:"type:numeric(7,2);default:0" json:"markup_percent"`
+	FixedFee       money.Decimal `gorm:"type:numeric(12,2);default:0" json:"fixed_fee"`
+	AnchorDate     time.Time     `json:"anchor_date"`                                              // дата первого списания, от неё отсчитываются циклы по PeriodDays
+	JoinedAt       time.Time     `gorm:"not null" json:"joined_at"`                                // с какого дня участник в подписке; цикл, начатый раньше, оплачивается по дням
+	LeftAt         *time.Time    `json:"left_at"`                                                  // с какого дня участник вышел; nil — состоит
+	ShareWeight    money.Decimal `gorm:"type:numeric(7,2);not null;default:1" json:"share_weight"` // вес участника для SplitWeighted
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+
+	// Доля участника в цене подписки, не хранится: заполняется SplitShares по текущему составу
+	SharePercent money.Decimal `gorm:"-" json:"share_percent"` // % от BasePrice
+	ShareAmount  int64         `gorm:"-" json:"share_amount"`  // в сотых долях BaseCurrency
 
-	User         User         `gorm:"foreignkey:UserID;references:ID"`
-	Subscription Subscription `gorm:"foreignkey:SubscriptionID;references:ID"`
+	User         User         `gorm:"foreignkey:UserID;references:ID" json:"user"`
+	Subscription Subscription `gorm:"foreignkey:SubscriptionID;references:ID" json:"subscription"`
 }
 
 type PaymentLog struct {
-	ID             string   `gorm:"type:uuid;primaryKey"`
-	UserID         string   `gorm:"type:uuid;not null"`
-	SubscriptionID string   `gorm:"type:uuid;not null"`
-	Amount         int64    `gorm:"type:bigint"` // итоговая сумма в копейках
-	BaseAmount     int64    `gorm:"type:bigint"` // базовая "чистая" сумма в копейках
-	ProfitAmount   int64    `gorm:"type:bigint"` // прибыль в копейках
-	Currency       Currency `gorm:"type:currency_enum"`
-	RateUsed       float64  `gorm:"not null"`
-	PaidAt         time.Time
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-
-	User         User         `gorm:"foreignkey:UserID;references:ID"`
-	Subscription Subscription `gorm:"foreignkey:SubscriptionID;references:ID"`
+	ID             string        `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID         string        `gorm:"type:uuid;not null" json:"user_id"`
+	SubscriptionID string        `gorm:"type:uuid;not null" json:"subscription_id"`
+	Amount         int64         `gorm:"type:bigint" json:"amount"`        // итоговая сумма в копейках
+	BaseAmount     int64         `gorm:"type:bigint" json:"base_amount"`   // базовая "чистая" сумма в копейках
+	ProfitAmount   int64         `gorm:"type:bigint" json:"profit_amount"` // прибыль в копейках
+	Currency       Currency      `gorm:"type:varchar(3)" json:"currency"`
+	RateUsed       money.Decimal `gorm:"type:numeric(18,8);not null" json:"rate_used"`
+	InvoiceID      *string       `gorm:"type:uuid;index" json:"invoice_id"` // счёт, который гасит платёж
+	PaidAt         time.Time     `json:"paid_at"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+
+	User         User         `gorm:"foreignkey:UserID;references:ID" json:"user"`
+	Subscription Subscription `gorm:"foreignkey:SubscriptionID;references:ID" json:"subscription"`
 }
 
 type GlobalSettings struct {
 	ID                  string         `gorm:"type:uuid;primaryKey" json:"id"`
-	GlobalMarkupPercent float64        `gorm:"default:0" json:"global_markup_percent"`
+	GlobalMarkupPercent money.Decimal  `gorm:"type:numeric(7,2);default:0" json:"global_markup_percent"`
 	UpdatedAt           time.Time      `json:"updated_at"`
 	CreatedAt           time.Time      `json:"created_at"`
 	DeletedAt           gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 }
 
+// CurrencyInfo запись справочника валют. Новая валюта добавляется строкой в таблицу,
+// выключенная (Enabled = false) не принимается API и не загружается из источников курсов.
+type CurrencyInfo struct {
+	Code        Currency  `gorm:"type:varchar(3);primaryKey" json:"code"` // ISO 4217
+	Name        string    `gorm:"size:100" json:"name"`
+	Symbol      string    `gorm:"size:8" json:"symbol"`
+	MinorDigits int       `gorm:"not null" json:"minor_digits"` // знаков дробной части (2 для копеек и центов)
+	Enabled     bool      `gorm:"not null" json:"enabled"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (CurrencyInfo) TableName() string { return "currencies" }
+
 type CurrencyRate struct {
-	ID        string     `gorm:"type:uuid;primaryKey"`
-	Currency  Currency   `gorm:"type:currency_enum"`
-	Value     float64    `gorm:"not null"`
-	Source    RateSource `gorm:"type:ratesource_enum"`
-	FetchedAt time.Time
-	UpdatedAt time.Time
-	CreatedAt time.Time
-	DeletedAt gorm.DeletedAt `gorm:"index"`
+	ID            string         `gorm:"type:uuid;primaryKey" json:"id"`
+	Currency      Currency       `gorm:"type:varchar(3);index:currency_rates_currency_fetched_idx,priority:1" json:"currency"`
+	Value         money.Decimal  `gorm:"type:numeric(18,8);not null" json:"value"` // стоимость одной единицы Currency в QuoteCurrency
+	Source        RateSource     `gorm:"type:ratesource_enum" json:"source"`
+	QuoteCurrency Currency       `gorm:"type:varchar(3);not null;default:'RUB'" json:"quote_currency"` // валюта котировки
+	FetchedAt     time.Time      `gorm:"index:currency_rates_currency_fetched_idx,priority:2" json:"fetched_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+}
+
+// Invoice счёт за один цикл подписки пользователя.
+// Пара (UserSubscriptionID, PeriodStart) уникальна среди неаннулированных счетов,
+// поэтому цикл не может быть выставлен дважды.
+// Суммы, курс и надбавка фиксируются в момент выставления (draft → issued).
+type Invoice struct {
+	ID                 string        `gorm:"type:uuid;primaryKey" json:"id"`
+	UserSubscriptionID string        `gorm:"type:uuid;not null;uniqueIndex:invoice_cycle_uq,where:status <> 'voided'" json:"user_subscription_id"`
+	UserID             string        `gorm:"type:uuid;not null;index" json:"user_id"`
+	SubscriptionID     string        `gorm:"type:uuid;not null;index" json:"subscription_id"`
+	PeriodStart        time.Time     `gorm:"not null;uniqueIndex:invoice_cycle_uq" json:"period_start"`
+	PeriodEnd          time.Time     `gorm:"not null" json:"period_end"`
+	DueDate            time.Time     `gorm:"not null;index" json:"due_date"`
+	Amount             int64         `gorm:"type:bigint" json:"amount"`                // итоговая сумма в копейках
+	BaseAmount         int64         `gorm:"type:bigint" json:"base_amount"`           // базовая "чистая" сумма в копейках
+	ProfitAmount       int64         `gorm:"type:bigint" json:"profit_amount"`         // прибыль в копейках
+	PaidAmount         int64         `gorm:"type:bigint;default:0" json:"paid_amount"` // уже оплачено в копейках
+	Currency           Currency      `gorm:"type:varchar(3)" json:"currency"`
+	RateUsed           money.Decimal `gorm:"type:numeric(18,8);not null" json:"rate_used"`
+	MarkupPercent      money.Decimal `gorm:"type:numeric(7,2);default:0" json:"markup_percent"` // фактическая надбавка на момент выставления
+	Status             InvoiceStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	IssuedAt           *time.Time    `json:"issued_at"`
+	PaidAt             *time.Time    `json:"paid_at"`
+	VoidedAt           *time.Time    `json:"voided_at"`
+	VoidReason         string        `gorm:"size:500" json:"void_reason,omitempty"`
+	CreatedAt          time.Time     `json:"created_at"`
+	UpdatedAt          time.Time     `json:"updated_at"`
+
+	User         User         `gorm:"foreignkey:UserID;references:ID" json:"-"`
+	Subscription Subscription `gorm:"foreignkey:SubscriptionID;references:ID" json:"-"`
+}
+
+// LedgerEntry проводка по лицевому счёту участника.
+// Баланс = сумма кредитов − сумма дебетов: положительный — переплата, отрицательный — долг.
+type LedgerEntry struct {
+	ID           string          `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID       string          `gorm:"type:uuid;not null;index:ledger_user_time_idx,priority:1" json:"user_id"`
+	Type         LedgerEntryType `gorm:"type:varchar(20);not null" json:"type"`
+	Debit        int64           `gorm:"type:bigint;not null;default:0" json:"debit"`  // начислено в копейках
+	Credit       int64           `gorm:"type:bigint;not null;default:0" json:"credit"` // поступило в копейках
+	Currency     Currency        `gorm:"type:varchar(3);not null" json:"currency"`     // баланс считается отдельно по каждой валюте
+	InvoiceID    *string         `gorm:"type:uuid;index" json:"invoice_id,omitempty"`
+	PaymentLogID *string         `gorm:"type:uuid;uniqueIndex" json:"payment_log_id,omitempty"`
+	CreatedBy    *string         `gorm:"type:uuid" json:"created_by,omitempty"` // администратор для корректировок
+	Description  string          `gorm:"size:500" json:"description,omitempty"`
+	OccurredAt   time.Time       `gorm:"not null;index:ledger_user_time_idx,priority:2" json:"occurred_at"`
+	CreatedAt    time.Time       `json:"created_at"`
+
+	User User `gorm:"foreignkey:UserID;references:ID" json:"-"`
+}
+
+// PricingRule правило ценообразования. При расчёте суммы к оплате берутся правила,
+// подходящие под участника и подписку и действующие на дату списания,
+// и применяются к цене по очереди по возрастанию Priority.
+type PricingRule struct {
+	ID             string           `gorm:"type:uuid;primaryKey" json:"id"`
+	Name           string           `gorm:"size:200;not null" json:"name"`
+	Kind           PricingRuleKind  `gorm:"type:varchar(20);not null" json:"kind"`
+	Scope          PricingRuleScope `gorm:"type:varchar(20);not null;index" json:"scope"`
+	UserID         *string          `gorm:"type:uuid;index" json:"user_id,omitempty"`         // для scope user и user_subscription
+	SubscriptionID *string          `gorm:"type:uuid;index" json:"subscription_id,omitempty"` // для scope subscription и user_subscription
+	Value          money.Decimal    `gorm:"type:numeric(12,2);not null" json:"value"`         // проценты или сумма, в зависимости от Kind
+	Currency       *Currency        `gorm:"type:varchar(3)" json:"currency,omitempty"`        // для сумм: правило действует только при расчёте в этой валюте
+	Priority       int              `gorm:"not null" json:"priority"`
+	ValidFrom      *time.Time       `json:"valid_from,omitempty"` // включительно; nil — без ограничения
+	ValidTo        *time.Time       `json:"valid_to,omitempty"`   // не включительно; nil — бессрочно
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt   `gorm:"index" json:"deleted_at"`
+}
+
+// APIKey ключ доступа к REST API. Ключ показывается один раз при выпуске,
+// в базе хранится только его SHA-256.
+type APIKey struct {
+	ID         string      `gorm:"type:uuid;primaryKey" json:"id"`
+	Name       string      `gorm:"size:200;not null" json:"name"`
+	Scope      APIKeyScope `gorm:"type:varchar(10);not null" json:"scope"`
+	UserID     *string     `gorm:"type:uuid;index" json:"user_id,omitempty"` // nil — ключ сервиса, например бота
+	Prefix     string      `gorm:"size:16;not null" json:"prefix"`           // начало ключа, чтобы узнать его в списке
+	KeyHash    string      `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt  *time.Time  `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time  `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time  `gorm:"index" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// IdempotencyKey запрос с заголовком Idempotency-Key и его ответ. Повтор запроса с тем же
+// ключом получает сохранённый ответ, а не выполняется второй раз.
+type IdempotencyKey struct {
+	ID          string    `gorm:"type:uuid;primaryKey" json:"id"`
+	APIKeyID    string    `gorm:"type:uuid;not null;uniqueIndex:idempotency_key_uq,priority:1" json:"api_key_id"` // ключи разных клиентов не пересекаются
+	Key         string    `gorm:"size:255;not null;uniqueIndex:idempotency_key_uq,priority:2" json:"key"`
+	RequestHash string    `gorm:"size:64;not null" json:"request_hash"` // SHA-256 метода, пути и тела запроса
+	Status      int       `gorm:"not null;default:0" json:"status"`     // HTTP-статус ответа; 0 — запрос ещё выполняется
+	ContentType string    `gorm:"size:100" json:"content_type"`
+	Response    []byte    `gorm:"type:bytea" json:"-"`
+	CreatedAt   time.Time `gorm:"index" json:"created_at"`
+}
+
+// ReminderDelivery попытка отправить участнику напоминание об оплате цикла.
+// Запись создаётся до отправки, поэтому после перезапуска напоминание не дублируется.
+type ReminderDelivery struct {
+	ID                 string         `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID             string         `gorm:"type:uuid;not null;index" json:"user_id"`
+	UserSubscriptionID string         `gorm:"type:uuid;not null;uniqueIndex:reminder_delivery_uq,priority:1" json:"user_subscription_id"`
+	DueDate            time.Time      `gorm:"type:date;not null;uniqueIndex:reminder_delivery_uq,priority:2" json:"due_date"`
+	OffsetDays         int            `gorm:"not null;uniqueIndex:reminder_delivery_uq,priority:3" json:"offset_days"` // < 0 — до даты списания, > 0 — после
+	InvoiceID          *string        `gorm:"type:uuid" json:"invoice_id,omitempty"`
+	Amount             int64          `gorm:"type:bigint" json:"amount"` // сумма в напоминании в копейках
+	Currency           Currency       `gorm:"type:varchar(3)" json:"currency"`
+	Status             ReminderStatus `gorm:"type:varchar(10);not null" json:"status"`
+	Attempts           int            `gorm:"not null;default:0" json:"attempts"`
+	LastError          string         `gorm:"size:500" json:"last_error,omitempty"`
+	SentAt             *time.Time     `json:"sent_at,omitempty"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+}
+
+// PaymentClaim сообщение участника об оплате, которое подтверждает или отклоняет администратор.
+// Подтверждение регистрирует PaymentLog.
+type PaymentClaim struct {
+	ID             string             `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID         string             `gorm:"type:uuid;not null;index" json:"user_id"`
+	SubscriptionID string             `gorm:"type:uuid;not null" json:"subscription_id"`
+	InvoiceID      *string            `gorm:"type:uuid" json:"invoice_id,omitempty"` // nil — платёж гасит самый старый неоплаченный счёт
+	Amount         int64              `gorm:"type:bigint;not null" json:"amount"`    // в копейках Currency
+	Currency       Currency           `gorm:"type:varchar(3);not null" json:"currency"`
+	Method         PaymentMethod      `gorm:"type:varchar(10);not null" json:"method"`
+	ReceiptFileID  string             `gorm:"size:255" json:"receipt_file_id,omitempty"` // file_id фото чека в Telegram
+	PaidAt         time.Time          `json:"paid_at"`
+	Status         PaymentClaimStatus `gorm:"type:varchar(10);not null;index" json:"status"`
+	RejectReason   string             `gorm:"size:500" json:"reject_reason,omitempty"`
+	ReviewedBy     *string            `gorm:"type:uuid" json:"reviewed_by,omitempty"` // администратор, принявший решение
+	ReviewedAt     *time.Time         `json:"reviewed_at,omitempty"`
+	PaymentLogID   *string            `gorm:"type:uuid" json:"payment_log_id,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+// AuditEntry запись журнала аудита: кто, что и как изменил через API.
+// Актор — ключ доступа; если ключ сервиса действует от имени пользователя
+// (бот передаёт Telegram ID администратора), заполняются и поля пользователя.
+type AuditEntry struct {
+	ID          string       `gorm:"type:uuid;primaryKey" json:"id"`
+	ActorKeyID  *string      `gorm:"type:uuid;index" json:"actor_key_id,omitempty"`
+	ActorName   string       `gorm:"size:200" json:"actor_name"` // имя ключа на момент изменения
+	ActorUserID *string      `gorm:"type:uuid;index" json:"actor_user_id,omitempty"`
+	ActorTGID   *int64       `json:"actor_tg_id,omitempty"`
+	Entity      AuditEntity  `gorm:"type:varchar(30);not null;index:audit_entity_idx,priority:1" json:"entity"`
+	EntityID    string       `gorm:"size:64;not null;index:audit_entity_idx,priority:2" json:"entity_id"` // UUID или код валюты
+	Action      AuditAction  `gorm:"type:varchar(10);not null" json:"action"`
+	Changes     AuditChanges `gorm:"type:jsonb" json:"changes"`
+	CreatedAt   time.Time    `gorm:"index" json:"created_at"`
+}
+
+// AuditChange значение поля до и после изменения; при создании before — null, при удалении after — null
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditChanges изменённые поля записи по их JSON-именам
+type AuditChanges map[string]AuditChange
+
+func (c *AuditChanges) Scan(value any) error {
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	}
+	return fmt.Errorf("audit changes: unsupported type %T", value)
+}
+
+func (c AuditChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "{}", nil
+	}
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
 }
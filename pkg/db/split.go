@@ -0,0 +1,219 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/money"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidSplit = errors.New("invalid split settings")
+
+var hundred = money.NewFromInt(100)
+
+// ValidateSplit проверяет настройки разделения цены подписки
+func ValidateSplit(sub *Subscription) error {
+	if sub.OwnerID != nil {
+		if _, err := uuid.Parse(*sub.OwnerID); err != nil {
+			return fmt.Errorf("%w: invalid owner_id", ErrInvalidSplit)
+		}
+	}
+
+	switch sub.SplitMode {
+	case "", SplitNone, SplitEqual, SplitWeighted:
+		return nil
+	case SplitOwnerFixed:
+		if sub.OwnerID == nil {
+			return fmt.Errorf("%w: owner_id is required for %s", ErrInvalidSplit, SplitOwnerFixed)
+		}
+		if sub.OwnerSharePercent.Sign() < 0 || sub.OwnerSharePercent.Cmp(hundred) > 0 {
+			return fmt.Errorf("%w: owner_share_percent must be between 0 and 100", ErrInvalidSplit)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown split_mode %q", ErrInvalidSplit, sub.SplitMode)
+	}
+}
+
+// ActiveDuring состоял ли участник в подписке хотя бы день из периода [from, to)
+func (us *UserSubscription) ActiveDuring(from, to time.Time) bool {
+	return us.JoinedAt.Before(to) && (us.LeftAt == nil || us.LeftAt.After(from))
+}
+
+// BilledPeriod часть цикла [start, end), в которой участник состоял в подписке;
+// дни вступления и выхода берутся по дате в UTC
+func (us *UserSubscription) BilledPeriod(start, end time.Time) (time.Time, time.Time) {
+	from := truncateDay(us.JoinedAt)
+	if from.Before(start) {
+		from = start
+	}
+	to := end
+	if us.LeftAt != nil {
+		if left := truncateDay(*us.LeftAt); left.Before(end) {
+			to = left
+		}
+	}
+	if to.Before(from) {
+		to = from
+	}
+	return from, to
+}
+
+// DaysBetween число полных суток между датами
+func DaysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// SplitShares делит BasePrice подписки за цикл [from, to) между участниками по SplitMode
+// и заполняет у all ShareAmount и SharePercent (у не состоявших в цикле — ноль).
+// Вес участника умножается на долю цикла, которую он состоял в подписке: вступивший
+// посреди цикла платит меньше, остальные — больше, и доли не нужно пересчитывать по дням.
+// Доли считаются в сотых долях BaseCurrency методом наибольших остатков, поэтому в сумме
+// дают ровно BasePrice. Исключения: в SplitNone каждый платит цену за свои дни, а в
+// SplitOwnerFixed владелец платит свой процент за свои дни, остальные — ровно BasePrice без него.
+func SplitShares(sub *Subscription, all []UserSubscription, from, to time.Time) error {
+	for i := range all {
+		all[i].ShareAmount = 0
+		all[i].SharePercent = money.Zero
+	}
+
+	// делим только между участниками, оплачивающими хотя бы день цикла, доли пишем обратно в all
+	cycleDays := DaysBetween(from, to)
+	var members []UserSubscription
+	var idx []int
+	var days []int64
+	for i := range all {
+		billedFrom, billedTo := all[i].BilledPeriod(from, to)
+		if n := DaysBetween(billedFrom, billedTo); n > 0 {
+			members = append(members, all[i])
+			idx = append(idx, i)
+			days = append(days, int64(n))
+		}
+	}
+	if len(members) == 0 {
+		return nil
+	}
+	total := sub.BasePrice.Minor(AmountDigits, money.HalfUp)
+
+	// forDays часть суммы amount за n дней цикла
+	forDays := func(amount int64, n int64) (int64, error) {
+		part, err := money.NewFromInt(amount).MulDiv(money.NewFromInt(n), money.NewFromInt(int64(cycleDays)))
+		if err != nil {
+			return 0, err
+		}
+		return part.Minor(0, money.HalfUp), nil
+	}
+
+	amounts := make([]int64, len(members))
+	switch sub.SplitMode {
+	case "", SplitNone:
+		for i := range amounts {
+			amount, err := forDays(total, days[i])
+			if err != nil {
+				return err
+			}
+			amounts[i] = amount
+		}
+
+	case SplitEqual:
+		shares, err := money.Allocate(total, dayWeights(days))
+		if err != nil {
+			return err
+		}
+		amounts = shares
+
+	case SplitWeighted:
+		// вес × дни; общий делитель — длина цикла — на пропорцию не влияет
+		weights := make([]money.Decimal, len(members))
+		for i, m := range members {
+			w, err := m.ShareWeight.Mul(money.NewFromInt(days[i]))
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidSplit, err)
+			}
+			weights[i] = w
+		}
+		shares, err := money.Allocate(total, weights)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSplit, err)
+		}
+		amounts = shares
+
+	case SplitOwnerFixed:
+		owner := -1
+		for i, m := range members {
+			if sub.OwnerID != nil && m.UserID == *sub.OwnerID {
+				owner = i
+			}
+		}
+		// владелец без других участников платит всю цену за свои дни
+		if owner >= 0 && len(members) == 1 {
+			amount, err := forDays(total, days[owner])
+			if err != nil {
+				return err
+			}
+			amounts[owner] = amount
+			break
+		}
+
+		ownerPrice, err := money.NewFromMinor(total, AmountDigits).Percent(sub.OwnerSharePercent)
+		if err != nil {
+			return err
+		}
+		ownerAmount := ownerPrice.Minor(AmountDigits, money.HalfUp)
+		if owner >= 0 {
+			if amounts[owner], err = forDays(ownerAmount, days[owner]); err != nil {
+				return err
+			}
+		}
+
+		// остаток — между остальными участниками по их дням
+		var others []int64
+		for i := range members {
+			if i != owner {
+				others = append(others, days[i])
+			}
+		}
+		shares, err := money.Allocate(total-ownerAmount, dayWeights(others))
+		if err != nil {
+			return err
+		}
+		for i := range members {
+			if i == owner {
+				continue
+			}
+			amounts[i], shares = shares[0], shares[1:]
+		}
+
+	default:
+		return fmt.Errorf("%w: unknown split_mode %q", ErrInvalidSplit, sub.SplitMode)
+	}
+
+	for i, j := range idx {
+		all[j].ShareAmount = amounts[i]
+		if total > 0 {
+			percent, err := money.NewFromInt(amounts[i]).MulDiv(hundred, money.NewFromInt(total))
+			if err != nil {
+				return err
+			}
+			all[j].SharePercent = percent.Round(2, money.HalfUp)
+		}
+	}
+	return nil
+}
+
+// dayWeights веса участников по оплачиваемым дням
+func dayWeights(days []int64) []money.Decimal {
+	weights := make([]money.Decimal, len(days))
+	for i, n := range days {
+		weights[i] = money.NewFromInt(n)
+	}
+	return weights
+}
+
+// truncateDay отбрасывает время суток
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
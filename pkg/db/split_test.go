@@ -0,0 +1,180 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/WhoYa/subscription-manager/pkg/money"
+)
+
+func TestSplitShares(t *testing.T) {
+	from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 30)
+	owner := "00000000-0000-0000-0000-000000000001"
+	member := func(id string, weight string) UserSubscription {
+		return UserSubscription{UserID: id, JoinedAt: from.AddDate(0, -1, 0), ShareWeight: money.MustParse(weight)}
+	}
+	left := member("left", "1")
+	leftAt := from.AddDate(0, 0, -1)
+	left.LeftAt = &leftAt
+	// joined вступил в день day цикла, leaving вышел в день day цикла
+	joined := func(m UserSubscription, day int) UserSubscription {
+		m.JoinedAt = from.AddDate(0, 0, day-1).Add(15 * time.Hour)
+		return m
+	}
+	leaving := func(m UserSubscription, day int) UserSubscription {
+		at := from.AddDate(0, 0, day-1)
+		m.LeftAt = &at
+		return m
+	}
+
+	tests := []struct {
+		name        string
+		mode        SplitMode
+		price       string
+		ownerShare  string
+		members     []UserSubscription
+		wantAmounts []int64
+		wantPercent []string
+		wantErr     error
+	}{
+		{
+			name:        "none: everyone pays full price",
+			mode:        SplitNone,
+			price:       "999",
+			members:     []UserSubscription{member("a", "1"), member("b", "1")},
+			wantAmounts: []int64{99900, 99900},
+			wantPercent: []string{"100", "100"},
+		},
+		{
+			name:        "equal: remainder goes to the first members",
+			mode:        SplitEqual,
+			price:       "1000",
+			members:     []UserSubscription{member("a", "1"), member("b", "1"), member("c", "1")},
+			wantAmounts: []int64{33334, 33333, 33333},
+			wantPercent: []string{"33.33", "33.33", "33.33"},
+		},
+		{
+			name:        "weighted",
+			mode:        SplitWeighted,
+			price:       "1000",
+			members:     []UserSubscription{member("a", "2"), member("b", "1"), member("c", "1")},
+			wantAmounts: []int64{50000, 25000, 25000},
+			wantPercent: []string{"50", "25", "25"},
+		},
+		{
+			name:    "weighted: zero weights",
+			mode:    SplitWeighted,
+			price:   "1000",
+			members: []UserSubscription{member("a", "0"), member("b", "0")},
+			wantErr: ErrInvalidSplit,
+		},
+		{
+			name:        "owner fixed: others split the rest",
+			mode:        SplitOwnerFixed,
+			price:       "1000",
+			ownerShare:  "40",
+			members:     []UserSubscription{member("b", "1"), member(owner, "1"), member("c", "1")},
+			wantAmounts: []int64{30000, 40000, 30000},
+			wantPercent: []string{"30", "40", "30"},
+		},
+		{
+			name:        "owner fixed: owner alone pays everything",
+			mode:        SplitOwnerFixed,
+			price:       "1000",
+			ownerShare:  "40",
+			members:     []UserSubscription{member(owner, "1")},
+			wantAmounts: []int64{100000},
+			wantPercent: []string{"100"},
+		},
+		{
+			name:        "member who left before the period has no share",
+			mode:        SplitEqual,
+			price:       "100",
+			members:     []UserSubscription{member("a", "1"), left, member("b", "1")},
+			wantAmounts: []int64{5000, 0, 5000},
+			wantPercent: []string{"50", "0", "50"},
+		},
+		{
+			name:        "equal: mid-cycle joiner pays for their days",
+			mode:        SplitEqual,
+			price:       "900",
+			members:     []UserSubscription{member("a", "1"), joined(member("b", "1"), 21)},
+			wantAmounts: []int64{67500, 22500},
+			wantPercent: []string{"75", "25"},
+		},
+		{
+			name:        "weighted: mid-cycle joiner pays for their days",
+			mode:        SplitWeighted,
+			price:       "1000",
+			members:     []UserSubscription{member("a", "2"), joined(member("b", "1"), 16), member("c", "1")},
+			wantAmounts: []int64{57143, 14286, 28571},
+			wantPercent: []string{"57.14", "14.29", "28.57"},
+		},
+		{
+			name:        "none: mid-cycle joiner pays the price for their days",
+			mode:        SplitNone,
+			price:       "999",
+			members:     []UserSubscription{member("a", "1"), joined(member("b", "1"), 21)},
+			wantAmounts: []int64{99900, 33300},
+			wantPercent: []string{"100", "33.33"},
+		},
+		{
+			name:        "owner fixed: others split the rest by their days",
+			mode:        SplitOwnerFixed,
+			price:       "1000",
+			ownerShare:  "40",
+			members:     []UserSubscription{member(owner, "1"), member("b", "1"), leaving(member("c", "1"), 16)},
+			wantAmounts: []int64{40000, 40000, 20000},
+			wantPercent: []string{"40", "40", "20"},
+		},
+		{
+			name:        "member who left at the start of the cycle has no share",
+			mode:        SplitEqual,
+			price:       "100",
+			members:     []UserSubscription{member("a", "1"), leaving(member("b", "1"), 1)},
+			wantAmounts: []int64{10000, 0},
+			wantPercent: []string{"100", "0"},
+		},
+		{
+			name:    "unknown mode",
+			mode:    SplitMode("halves"),
+			price:   "100",
+			members: []UserSubscription{member("a", "1")},
+			wantErr: ErrInvalidSplit,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := &Subscription{SplitMode: tt.mode, BasePrice: money.MustParse(tt.price), OwnerID: &owner}
+			if tt.ownerShare != "" {
+				sub.OwnerSharePercent = money.MustParse(tt.ownerShare)
+			}
+			err := SplitShares(sub, tt.members, from, to)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			sum := int64(0)
+			for i, m := range tt.members {
+				sum += m.ShareAmount
+				if m.ShareAmount != tt.wantAmounts[i] {
+					t.Errorf("member %d amount = %d, want %d", i, m.ShareAmount, tt.wantAmounts[i])
+				}
+				if m.SharePercent.Cmp(money.MustParse(tt.wantPercent[i])) != 0 {
+					t.Errorf("member %d percent = %s, want %s", i, m.SharePercent, tt.wantPercent[i])
+				}
+			}
+			// делимая цена собирается целиком, даже если кто-то состоял в подписке не весь цикл
+			if price := sub.BasePrice.Minor(AmountDigits, money.HalfUp); (tt.mode == SplitEqual || tt.mode == SplitWeighted) && sum != price {
+				t.Errorf("shares sum to %d, want %d", sum, price)
+			}
+		})
+	}
+}
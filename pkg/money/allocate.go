@@ -0,0 +1,54 @@
+package money
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+)
+
+var ErrInvalidWeights = errors.New("weights must be non-negative with a positive sum")
+
+// Allocate делит total минимальных единиц пропорционально весам методом наибольших остатков:
+// каждая доля отличается от точной пропорции меньше чем на единицу, а сумма долей ровно total.
+// Лишние единицы достаются долям с наибольшим остатком, при равенстве — идущим раньше.
+func Allocate(total int64, weights []Decimal) ([]int64, error) {
+	sum := new(big.Int)
+	for _, w := range weights {
+		if w.Sign() < 0 {
+			return nil, ErrInvalidWeights
+		}
+		sum.Add(sum, big.NewInt(w.v))
+	}
+	if sum.Sign() == 0 {
+		return nil, ErrInvalidWeights
+	}
+
+	shares := make([]int64, len(weights))
+	rems := make([]*big.Int, len(weights))
+	allocated := int64(0)
+	for i, w := range weights {
+		// total × w / sum с отбрасыванием; остаток запоминаем для распределения лишних единиц
+		num := new(big.Int).Mul(big.NewInt(total), big.NewInt(w.v))
+		q, r := new(big.Int).QuoRem(num, sum, new(big.Int))
+		shares[i] = q.Int64()
+		rems[i] = r.Abs(r)
+		allocated += shares[i]
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return rems[order[a]].Cmp(rems[order[b]]) > 0 })
+
+	// при отрицательном total доли отброшены к нулю, недостающие единицы тоже отрицательные
+	step := int64(1)
+	if total < 0 {
+		step = -1
+	}
+	for i := 0; allocated != total; i++ {
+		shares[order[i]] += step
+		allocated += step
+	}
+	return shares, nil
+}
@@ -0,0 +1,319 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Scale число знаков после запятой, которое хранит Decimal
+const Scale = 8
+
+// scaleFactor 10^Scale
+const scaleFactor int64 = 100_000_000
+
+// maxExponent предел показателя в записи "1e3": дальше Decimal всё равно не хватает
+// ни разрядов, ни точности, а большой показатель дорого возводить в степень
+const maxExponent = 30
+
+var (
+	ErrInvalidDecimal = errors.New("invalid decimal")
+	ErrOverflow       = errors.New("decimal overflow")
+	ErrDivisionByZero = errors.New("decimal division by zero")
+)
+
+// Decimal десятичное число с фиксированной точкой: значение × 10^Scale в int64.
+// Хватает на суммы до ~92 млрд и курсы с точностью до 1e-8.
+// Сложение и вычитание точные, умножение и деление округляются до Scale знаков
+// по HalfEven, явное округление до нужного числа знаков — Round.
+type Decimal struct {
+	v int64
+}
+
+// Zero нулевое значение Decimal
+var Zero = Decimal{}
+
+// NewFromInt целое число
+func NewFromInt(i int64) Decimal {
+	return Decimal{v: i * scaleFactor}
+}
+
+// NewFromMinor сумма в минимальных единицах: NewFromMinor(12345, 2) = 123.45
+func NewFromMinor(minor int64, digits int) Decimal {
+	return Decimal{v: minor * pow10(Scale-digits)}
+}
+
+// NewFromFloat переводит float64 в Decimal, округляя до Scale знаков.
+// Нужен только на границе со старыми float-значениями; суммы из ввода лучше разбирать Parse.
+func NewFromFloat(f float64) Decimal {
+	d, err := Parse(strconv.FormatFloat(f, 'f', Scale, 64))
+	if err != nil {
+		return Zero // NaN и бесконечности
+	}
+	return d
+}
+
+// Parse разбирает десятичную запись ("-12.34", "1e3"); лишние знаки дробной части
+// округляются по HalfEven
+func Parse(s string) (Decimal, error) {
+	str := strings.TrimSpace(s)
+	if str == "" {
+		return Zero, fmt.Errorf("%w: empty string", ErrInvalidDecimal)
+	}
+
+	exp := 0
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		e, err := strconv.Atoi(str[i+1:])
+		if err != nil {
+			return Zero, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+		}
+		if e > maxExponent || e < -maxExponent {
+			return Zero, fmt.Errorf("%w: %q exponent out of range", ErrInvalidDecimal, s)
+		}
+		exp = e
+		str = str[:i]
+	}
+
+	neg := false
+	switch {
+	case strings.HasPrefix(str, "-"):
+		neg = true
+		str = str[1:]
+	case strings.HasPrefix(str, "+"):
+		str = str[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(str, ".")
+	if intPart == "" && fracPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return Zero, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+	}
+
+	// digits × 10^(exp - len(fracPart)) приводим к масштабу Scale
+	num, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return Zero, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+	}
+	if neg {
+		num.Neg(num)
+	}
+
+	shift := Scale + exp - len(fracPart)
+	den := big.NewInt(1)
+	if shift >= 0 {
+		num.Mul(num, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(shift)), nil))
+	} else {
+		den.Exp(big.NewInt(10), big.NewInt(int64(-shift)), nil)
+	}
+
+	v, err := quo(num, den, HalfEven)
+	if err != nil {
+		return Zero, fmt.Errorf("%w: %q out of range", ErrInvalidDecimal, s)
+	}
+	return Decimal{v: v}, nil
+}
+
+// MustParse как Parse, но паникует на ошибке; для констант
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d Decimal) Add(x Decimal) Decimal { return Decimal{v: d.v + x.v} }
+func (d Decimal) Sub(x Decimal) Decimal { return Decimal{v: d.v - x.v} }
+func (d Decimal) Neg() Decimal          { return Decimal{v: -d.v} }
+
+// Abs модуль числа
+func (d Decimal) Abs() Decimal {
+	if d.v < 0 {
+		return d.Neg()
+	}
+	return d
+}
+
+// Sign возвращает -1, 0 или 1
+func (d Decimal) Sign() int {
+	switch {
+	case d.v < 0:
+		return -1
+	case d.v > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (d Decimal) IsZero() bool { return d.v == 0 }
+
+// Cmp сравнивает d и x: -1, 0 или 1
+func (d Decimal) Cmp(x Decimal) int {
+	switch {
+	case d.v < x.v:
+		return -1
+	case d.v > x.v:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Mul произведение, округлённое до Scale знаков по HalfEven
+func (d Decimal) Mul(x Decimal) (Decimal, error) {
+	return d.MulDiv(x, NewFromInt(1))
+}
+
+// Div частное, округлённое до Scale знаков по HalfEven
+func (d Decimal) Div(x Decimal) (Decimal, error) {
+	return d.MulDiv(NewFromInt(1), x)
+}
+
+// MulDiv вычисляет d × m / q с одним округлением до Scale знаков по HalfEven,
+// поэтому цепочка курсов не накапливает ошибку промежуточных округлений.
+// Результат, не помещающийся в Decimal, — ErrOverflow, q = 0 — ErrDivisionByZero.
+func (d Decimal) MulDiv(m, q Decimal) (Decimal, error) {
+	if q.v == 0 {
+		return Zero, ErrDivisionByZero
+	}
+	// во внутреннем представлении множители 10^Scale сокращаются: v = d.v × m.v / q.v
+	num := new(big.Int).Mul(big.NewInt(d.v), big.NewInt(m.v))
+	v, err := quo(num, big.NewInt(q.v), HalfEven)
+	if err != nil {
+		return Zero, fmt.Errorf("%w: %s × %s / %s", ErrOverflow, d, m, q)
+	}
+	return Decimal{v: v}, nil
+}
+
+// Percent p процентов от d: d × p / 100, с округлением до Scale знаков по HalfEven
+func (d Decimal) Percent(p Decimal) (Decimal, error) {
+	return d.MulDiv(p, NewFromInt(100))
+}
+
+// Round округляет до places знаков после запятой в заданном режиме
+func (d Decimal) Round(places int, mode RoundingMode) Decimal {
+	if places >= Scale {
+		return d
+	}
+	unit := pow10(Scale - places)
+	v, _ := quo(big.NewInt(d.v), big.NewInt(unit), mode)
+	return Decimal{v: v * unit}
+}
+
+// Minor округляет до digits знаков и возвращает сумму в минимальных единицах:
+// Parse("123.455").Minor(2, HalfUp) = 12346
+func (d Decimal) Minor(digits int, mode RoundingMode) int64 {
+	return d.Round(digits, mode).v / pow10(Scale-digits)
+}
+
+// Float64 приближённое значение для вывода и сравнения с внешними данными
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String десятичная запись без лишних нулей: "12.3", "-0.5", "100"
+func (d Decimal) String() string {
+	s := d.StringFixed(Scale)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// StringFixed запись ровно с places знаками после запятой (с округлением HalfUp)
+func (d Decimal) StringFixed(places int) string {
+	places = min(max(places, 0), Scale)
+	r := d.Round(places, HalfUp)
+
+	abs := r.v
+	sign := ""
+	if abs < 0 {
+		abs = -abs
+		sign = "-"
+	}
+	intPart := abs / scaleFactor
+	if places == 0 {
+		return sign + strconv.FormatInt(intPart, 10)
+	}
+	frac := fmt.Sprintf("%0*d", Scale, abs%scaleFactor)[:places]
+	return sign + strconv.FormatInt(intPart, 10) + "." + frac
+}
+
+// MarshalJSON пишет число без кавычек, чтобы формат ответов API не менялся
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON принимает число или строку с числом
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		*d = Zero
+		return nil
+	}
+	if unq, err := strconv.Unquote(s); err == nil {
+		s = unq
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Value пишет число строкой: PostgreSQL приводит её к numeric без потерь
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan читает numeric (строкой), а также float и целые колонки
+func (d *Decimal) Scan(value any) error {
+	switch v := value.(type) {
+	case nil:
+		*d = Zero
+	case string:
+		return d.scanString(v)
+	case []byte:
+		return d.scanString(string(v))
+	case float64:
+		*d = NewFromFloat(v)
+	case float32:
+		*d = NewFromFloat(float64(v))
+	case int64:
+		*d = NewFromInt(v)
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidDecimal, value)
+	}
+	return nil
+}
+
+func (d *Decimal) scanString(s string) error {
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for range n {
+		p *= 10
+	}
+	return p
+}
@@ -0,0 +1,79 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "12.34", want: "12.34"},
+		{in: "-0.5", want: "-0.5"},
+		{in: "1e3", want: "1000"},
+		{in: "1.5E-2", want: "0.015"},
+		{in: "0.000000005", want: "0"}, // половина последнего знака — к чётному
+		{in: "0.000000015", want: "0.00000002"},
+		{in: "1e30", wantErr: true},
+		{in: "1e-30", want: "0"},
+		{in: "1e31", wantErr: true},
+		{in: "1e-31", wantErr: true},
+		{in: "1e999999999", wantErr: true},
+		{in: "100000000000", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "1.2.3", wantErr: true},
+		{in: "abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidDecimal) {
+					t.Fatalf("Parse(%q) err = %v, want ErrInvalidDecimal", tt.in, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.in, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMulDiv(t *testing.T) {
+	d := MustParse
+	tests := []struct {
+		name    string
+		x, m, q string
+		want    string
+		wantErr error
+	}{
+		{name: "exact", x: "10", m: "3", q: "2", want: "15"},
+		{name: "rounds half even", x: "1", m: "1", q: "3", want: "0.33333333"},
+		{name: "division by zero", x: "1", m: "1", q: "0", wantErr: ErrDivisionByZero},
+		{name: "overflow", x: "90000000000", m: "90000000000", q: "1", wantErr: ErrOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := d(tt.x).MulDiv(d(tt.m), d(tt.q))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("%s × %s / %s = %s, want %s", tt.x, tt.m, tt.q, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// Currency ISO 4217 код валюты
+type Currency string
+
+func (c *Currency) Scan(value any) error {
+	switch v := value.(type) {
+	case string:
+		*c = Currency(v)
+	case []byte:
+		*c = Currency(v)
+	case nil:
+		*c = ""
+	default:
+		return fmt.Errorf("cannot scan %T into Currency", value)
+	}
+	return nil
+}
+
+func (c Currency) Value() (driver.Value, error) {
+	return string(c), nil
+}
+
+// Money сумма в валюте. Складываются, вычитаются и сравниваются только суммы одной валюты:
+// разные валюты сначала пересчитываются по курсу, иначе — ErrCurrencyMismatch.
+type Money struct {
+	Amount   Decimal  `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+// New сумма amount в валюте currency
+func New(amount Decimal, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Add сумма m + x
+func (m Money) Add(x Money) (Money, error) {
+	if err := m.sameCurrency(x); err != nil {
+		return Money{}, err
+	}
+	return New(m.Amount.Add(x.Amount), m.Currency), nil
+}
+
+// Sub разность m - x
+func (m Money) Sub(x Money) (Money, error) {
+	if err := m.sameCurrency(x); err != nil {
+		return Money{}, err
+	}
+	return New(m.Amount.Sub(x.Amount), m.Currency), nil
+}
+
+// Cmp сравнивает m и x: -1, 0 или 1
+func (m Money) Cmp(x Money) (int, error) {
+	if err := m.sameCurrency(x); err != nil {
+		return 0, err
+	}
+	return m.Amount.Cmp(x.Amount), nil
+}
+
+// Round округляет сумму до places знаков после запятой
+func (m Money) Round(places int, mode RoundingMode) Money {
+	return New(m.Amount.Round(places, mode), m.Currency)
+}
+
+func (m Money) IsZero() bool { return m.Amount.IsZero() }
+
+func (m Money) String() string {
+	return m.Amount.String() + " " + string(m.Currency)
+}
+
+func (m Money) sameCurrency(x Money) error {
+	if m.Currency != x.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, x.Currency)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMoneyArithmetic(t *testing.T) {
+	a := New(MustParse("100.10"), "RUB")
+	b := New(MustParse("0.25"), "RUB")
+
+	sum, err := a.Add(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.String() != "100.35 RUB" {
+		t.Errorf("Add = %s, want 100.35 RUB", sum)
+	}
+	diff, err := b.Sub(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.String() != "-99.85 RUB" {
+		t.Errorf("Sub = %s, want -99.85 RUB", diff)
+	}
+	if c, err := a.Cmp(b); err != nil || c != 1 {
+		t.Errorf("Cmp = %d, %v, want 1", c, err)
+	}
+}
+
+func TestMoneyCurrencyMismatch(t *testing.T) {
+	rub := New(MustParse("100"), "RUB")
+	usd := New(MustParse("1"), "USD")
+
+	if _, err := rub.Add(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add err = %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := rub.Sub(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sub err = %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := rub.Cmp(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Cmp err = %v, want ErrCurrencyMismatch", err)
+	}
+}
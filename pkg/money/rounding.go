@@ -0,0 +1,55 @@
+package money
+
+import (
+	"errors"
+	"math/big"
+)
+
+// RoundingMode правило округления до заданного числа знаков
+type RoundingMode int
+
+const (
+	HalfUp   RoundingMode = iota // половина — от нуля (2.5 → 3, -2.5 → -3), коммерческое округление
+	HalfEven                     // половина — к чётному (2.5 → 2, 3.5 → 4), банковское округление
+	Down                         // к нулю, отбрасывание (2.9 → 2, -2.9 → -2)
+	Up                           // от нуля (2.1 → 3, -2.1 → -3)
+)
+
+var errOverflow = errors.New("decimal overflow")
+
+// quo делит num на den и округляет частное до целого в режиме mode
+func quo(num, den *big.Int, mode RoundingMode) (int64, error) {
+	n := new(big.Int).Set(num)
+	d := new(big.Int).Set(den)
+	if d.Sign() < 0 {
+		n.Neg(n)
+		d.Neg(d)
+	}
+
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if r.Sign() != 0 {
+		// сравниваем удвоенный остаток с делителем: <0 — меньше половины, 0 — ровно половина
+		half := new(big.Int).Abs(r)
+		half.Lsh(half, 1)
+		cmp := half.Cmp(d)
+
+		away := false
+		switch mode {
+		case HalfUp:
+			away = cmp >= 0
+		case HalfEven:
+			away = cmp > 0 || cmp == 0 && q.Bit(0) == 1
+		case Up:
+			away = true
+		case Down:
+		}
+		if away {
+			q.Add(q, big.NewInt(int64(n.Sign())))
+		}
+	}
+
+	if !q.IsInt64() {
+		return 0, errOverflow
+	}
+	return q.Int64(), nil
+}